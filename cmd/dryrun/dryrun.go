@@ -0,0 +1,153 @@
+package dryrun
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/traefik/paerser/cli"
+	"github.com/traefik/paerser/file"
+	"github.com/traefik/traefik/v3/pkg/api"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// Configuration holds the dry run command configuration.
+type Configuration struct {
+	APIURL   string `description:"URL of the Traefik API." json:"apiURL,omitempty" toml:"apiURL,omitempty" yaml:"apiURL,omitempty"`
+	Provider string `description:"Name of the provider whose configuration is replaced by the candidate one." json:"provider,omitempty" toml:"provider,omitempty" yaml:"provider,omitempty"`
+	File     string `description:"Candidate dynamic configuration file (TOML, YAML or JSON)." json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty"`
+}
+
+// NewCmd builds a new DryRun command.
+func NewCmd() *cli.Command {
+	config := &Configuration{
+		APIURL:   "http://127.0.0.1:8080",
+		Provider: "file",
+	}
+
+	return &cli.Command{
+		Name: "dryrun",
+		Description: `Calls the Traefik API (/api/dryrun endpoint) to evaluate a candidate dynamic configuration without applying it.
+Prints the routers, services and middlewares which would be added, changed or removed, and the errors which would be reported.`,
+		Configuration: config,
+		Resources:     []cli.ResourceLoader{&cli.FlagLoader{}},
+		Run: func(_ []string) error {
+			return run(config, os.Stdout)
+		},
+	}
+}
+
+func run(config *Configuration, out io.Writer) error {
+	if config.File == "" {
+		return errors.New("the candidate configuration file must be defined with --file")
+	}
+
+	content, err := os.ReadFile(config.File)
+	if err != nil {
+		return err
+	}
+
+	configuration := &dynamic.Configuration{}
+	if err := file.DecodeContent(string(content), strings.ToLower(filepath.Ext(config.File)), configuration); err != nil {
+		return fmt.Errorf("decoding %s: %w", config.File, err)
+	}
+
+	result, err := Do(config.APIURL, config.Provider, configuration)
+	if err != nil {
+		return err
+	}
+
+	Print(out, result)
+
+	if !result.Valid {
+		return errors.New("the candidate configuration is invalid")
+	}
+
+	return nil
+}
+
+// Do calls the dry run endpoint of the Traefik API with the candidate configuration of the given provider.
+func Do(apiURL, providerName string, configuration *dynamic.Configuration) (*api.DryRunRepresentation, error) {
+	body, err := json.Marshal(configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	endpoint := strings.TrimSuffix(apiURL, "/") + "/api/dryrun?provider=" + url.QueryEscape(providerName)
+
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("calling dry run endpoint: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("dry run failed with status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	result := &api.DryRunRepresentation{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("decoding dry run result: %w", err)
+	}
+
+	return result, nil
+}
+
+// Print writes a human-readable summary of the dry run result.
+func Print(out io.Writer, result *api.DryRunRepresentation) {
+	sections := []struct {
+		name string
+		diff *api.ObjectsDiff
+	}{
+		{name: "HTTP routers", diff: result.Routers},
+		{name: "HTTP middlewares", diff: result.Middlewares},
+		{name: "HTTP services", diff: result.Services},
+		{name: "TCP routers", diff: result.TCPRouters},
+		{name: "TCP middlewares", diff: result.TCPMiddlewares},
+		{name: "TCP services", diff: result.TCPServices},
+		{name: "UDP routers", diff: result.UDPRouters},
+		{name: "UDP middlewares", diff: result.UDPMiddlewares},
+		{name: "UDP services", diff: result.UDPServices},
+	}
+
+	var changed bool
+	for _, section := range sections {
+		if section.diff == nil {
+			continue
+		}
+		changed = true
+
+		_, _ = fmt.Fprintf(out, "%s:\n", section.name)
+		for _, name := range section.diff.Added {
+			_, _ = fmt.Fprintf(out, "  + %s\n", name)
+		}
+		for _, name := range section.diff.Changed {
+			_, _ = fmt.Fprintf(out, "  ~ %s\n", name)
+		}
+		for _, name := range section.diff.Removed {
+			_, _ = fmt.Fprintf(out, "  - %s\n", name)
+		}
+	}
+
+	if !changed {
+		_, _ = fmt.Fprintln(out, "No changes.")
+	}
+
+	if len(result.Errors) > 0 {
+		_, _ = fmt.Fprintln(out, "Errors:")
+		for _, e := range result.Errors {
+			_, _ = fmt.Fprintf(out, "  %s %s (%s): %s\n", e.Type, e.Name, e.Status, strings.Join(e.Errors, "; "))
+		}
+	}
+}
@@ -23,8 +23,10 @@ import (
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"github.com/traefik/paerser/cli"
 	"github.com/traefik/traefik/v3/cmd"
+	"github.com/traefik/traefik/v3/cmd/dryrun"
 	"github.com/traefik/traefik/v3/cmd/healthcheck"
 	cmdVersion "github.com/traefik/traefik/v3/cmd/version"
+	"github.com/traefik/traefik/v3/pkg/api"
 	tcli "github.com/traefik/traefik/v3/pkg/cli"
 	"github.com/traefik/traefik/v3/pkg/collector"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
@@ -44,7 +46,9 @@ import (
 	"github.com/traefik/traefik/v3/pkg/redactor"
 	"github.com/traefik/traefik/v3/pkg/safe"
 	"github.com/traefik/traefik/v3/pkg/server"
+	"github.com/traefik/traefik/v3/pkg/server/history"
 	"github.com/traefik/traefik/v3/pkg/server/middleware"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 	"github.com/traefik/traefik/v3/pkg/server/service"
 	"github.com/traefik/traefik/v3/pkg/tcp"
 	traefiktls "github.com/traefik/traefik/v3/pkg/tls"
@@ -80,6 +84,12 @@ Complete documentation is available at https://traefik.io`,
 		os.Exit(1)
 	}
 
+	err = cmdTraefik.AddCommand(dryrun.NewCmd())
+	if err != nil {
+		stdlog.Println(err)
+		os.Exit(1)
+	}
+
 	err = cli.Execute(cmdTraefik)
 	if err != nil {
 		log.Error().Err(err).Msg("Command error")
@@ -303,7 +313,28 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 
 	dialerManager := tcp.NewDialerManager(spiffeX509Source)
 	acmeHTTPHandler := getHTTPChallengeHandler(acmeProviders, httpChallengeProvider)
-	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, observabilityMgr, transportManager, proxyBuilder, acmeHTTPHandler, tlsManager)
+	dryRunner := server.NewDryRunner()
+
+	var configurationHistory *history.History
+	if staticConfiguration.API != nil && staticConfiguration.API.HistorySize > 0 {
+		configurationHistory = history.New(staticConfiguration.API.HistorySize)
+	}
+
+	var eventHub *api.EventHub
+	if staticConfiguration.API != nil {
+		eventHub = api.NewEventHub()
+	}
+
+	var serverOverrides *override.Store
+	if staticConfiguration.API != nil && staticConfiguration.API.ServerOverrides {
+		serverOverrides = override.NewStore()
+	}
+
+	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, observabilityMgr, transportManager, proxyBuilder, acmeHTTPHandler, tlsManager).
+		WithDryRunner(dryRunner).
+		WithHistory(configurationHistory).
+		WithEvents(eventHub).
+		WithOverrides(serverOverrides)
 
 	// Router factory
 
@@ -312,6 +343,8 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		return nil, fmt.Errorf("creating router factory: %w", err)
 	}
 
+	routerFactory.SetOverrides(serverOverrides)
+
 	// Watcher
 
 	watcher := server.NewConfigurationWatcher(
@@ -322,15 +355,32 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		staticConfiguration.Core != nil && staticConfiguration.Core.StrictTLSOptions,
 	)
 
+	dryRunner.SetUp(watcher, routerFactory)
+
+	if configurationHistory != nil {
+		watcher.SetHistory(configurationHistory)
+	}
+
+	// The load balancers are rebuilt when the server overrides change.
+	if serverOverrides != nil {
+		serverOverrides.OnChange(watcher.Refresh)
+	}
+
 	// TLS
 	watcher.AddListener(func(conf dynamic.Configuration) {
 		ctx := context.Background()
 		tlsManager.UpdateConfigs(ctx, conf.TLS.Stores, conf.TLS.Options, conf.TLS.Certificates)
 
+		certificates := tlsManager.GetServerCertificates()
+
 		gauge := metricsRegistry.TLSCertsNotAfterTimestampGauge()
-		for _, certificate := range tlsManager.GetServerCertificates() {
+		for _, certificate := range certificates {
 			appendCertMetric(gauge, certificate)
 		}
+
+		if eventHub != nil {
+			eventHub.CertificatesChanged(certificates)
+		}
 	})
 
 	// Metrics
@@ -347,7 +397,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	})
 
 	// Switch router
-	watcher.AddListener(switchRouter(routerFactory, serverEntryPointsTCP, serverEntryPointsUDP))
+	watcher.AddListener(switchRouter(routerFactory, serverEntryPointsTCP, serverEntryPointsUDP, eventHub))
 
 	// Metrics
 	if metricsRegistry.IsEpEnabled() || metricsRegistry.IsRouterEnabled() || metricsRegistry.IsSvcEnabled() {
@@ -370,6 +420,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	// ACME
 	for _, p := range acmeProviders {
 		resolverNames[p.ResolverName] = struct{}{}
+		p.SetMetricsRegistry(metricsRegistry)
 		watcher.AddListener(p.ListenConfiguration)
 	}
 
@@ -441,14 +492,21 @@ func getDefaultsEntrypoints(staticConfiguration *static.Configuration) []string
 	return defaultEntryPoints
 }
 
-func switchRouter(routerFactory *server.RouterFactory, serverEntryPointsTCP server.TCPEntryPoints, serverEntryPointsUDP server.UDPEntryPoints) func(conf dynamic.Configuration) {
+func switchRouter(routerFactory *server.RouterFactory, serverEntryPointsTCP server.TCPEntryPoints, serverEntryPointsUDP server.UDPEntryPoints, eventHub *api.EventHub) func(conf dynamic.Configuration) {
 	return func(conf dynamic.Configuration) {
 		rtConf := runtime.NewConfig(conf)
+		if eventHub != nil {
+			rtConf.OnServerStatusChange(eventHub.ServerStatusChanged)
+		}
 
 		routers, udpRouters := routerFactory.CreateRouters(rtConf)
 
 		serverEntryPointsTCP.Switch(routers)
 		serverEntryPointsUDP.Switch(udpRouters)
+
+		if eventHub != nil {
+			eventHub.RuntimeConfigurationChanged(rtConf)
+		}
 	}
 }
 
@@ -462,13 +520,26 @@ func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.Pr
 			continue
 		}
 
-		if localStores[resolver.ACME.Storage] == nil {
-			localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage, routinesPool)
+		var store acme.Store
+		if resolver.ACME.SharedStorage != nil {
+			sharedStore, err := acme.NewSharedStore(resolver.ACME.SharedStorage)
+			if err != nil {
+				log.Error().Err(err).Str("resolver", name).Msg("The ACME resolve is skipped from the resolvers list")
+				continue
+			}
+
+			store = sharedStore
+		} else {
+			if localStores[resolver.ACME.Storage] == nil {
+				localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage, routinesPool)
+			}
+
+			store = localStores[resolver.ACME.Storage]
 		}
 
 		p := &acme.Provider{
 			Configuration:         resolver.ACME,
-			Store:                 localStores[resolver.ACME.Storage],
+			Store:                 store,
 			ResolverName:          name,
 			HTTPChallengeProvider: httpChallengeProvider,
 			TLSChallengeProvider:  tlsChallengeProvider,
@@ -480,6 +551,9 @@ func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.Pr
 		}
 
 		p.SetTLSManager(tlsManager)
+		if resolver.ACME.OnDemand != nil {
+			tlsManager.AddOnDemandResolver(p)
+		}
 
 		p.SetConfigListenerChan(make(chan dynamic.Configuration))
 
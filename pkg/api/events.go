@@ -0,0 +1,182 @@
+package api
+
+import (
+	"crypto/x509"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+)
+
+// Types of the events pushed on the events endpoint.
+const (
+	EventTypeRuntime      = "runtime"
+	EventTypeServerStatus = "serverStatus"
+	EventTypeCertificates = "certificates"
+)
+
+// subscriberBufferSize is the number of events buffered for a subscriber,
+// which is disconnected when it does not keep up.
+const subscriberBufferSize = 64
+
+// Event is a change of the running configuration pushed on the events endpoint.
+type Event struct {
+	ID   uint64
+	Type string
+	// Data is either a RuntimeChanges, a ServerStatusChange, or a CertificatesChange, depending on the type.
+	Data any
+}
+
+// ServerStatusChange is the change of the status of a server of a service.
+type ServerStatusChange struct {
+	Protocol string `json:"protocol"`
+	Service  string `json:"service"`
+	Server   string `json:"server"`
+	Status   string `json:"status"`
+}
+
+// CertificatesChange lists the IDs of the certificates changed in the default TLS store.
+// A certificate is renewed when it replaces a certificate for the same domains.
+type CertificatesChange struct {
+	Added   []string `json:"added,omitempty"`
+	Renewed []string `json:"renewed,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// EventHub broadcasts the changes of the running configuration to the subscribers of the events endpoint.
+type EventHub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	subscribers map[chan Event]struct{}
+
+	runtimeConfiguration *runtime.Configuration
+	certificates         map[string]*x509.Certificate
+}
+
+// NewEventHub creates a new EventHub.
+func NewEventHub() *EventHub {
+	return &EventHub{
+		subscribers:          make(map[chan Event]struct{}),
+		runtimeConfiguration: &runtime.Configuration{},
+	}
+}
+
+// Subscribe returns the channel receiving the events, and the function to unsubscribe.
+// The channel is closed when the subscriber is unsubscribed, or when it does not keep up with the events.
+func (e *EventHub) Subscribe() (<-chan Event, func()) {
+	events := make(chan Event, subscriberBufferSize)
+
+	e.mu.Lock()
+	e.subscribers[events] = struct{}{}
+	e.mu.Unlock()
+
+	return events, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		if _, ok := e.subscribers[events]; ok {
+			delete(e.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// RuntimeConfigurationChanged publishes the objects changed by the new runtime configuration.
+func (e *EventHub) RuntimeConfigurationChanged(rtConf *runtime.Configuration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	changes := diffRuntime(e.runtimeConfiguration, rtConf)
+	e.runtimeConfiguration = rtConf
+
+	e.publish(EventTypeRuntime, changes)
+}
+
+// ServerStatusChanged publishes the change of the status of a server.
+func (e *EventHub) ServerStatusChanged(protocol, serviceName, server, status string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.publish(EventTypeServerStatus, ServerStatusChange{
+		Protocol: protocol,
+		Service:  serviceName,
+		Server:   server,
+		Status:   status,
+	})
+}
+
+// CertificatesChanged publishes the certificates added, renewed or removed,
+// given the certificates of the default TLS store keyed by their ID.
+func (e *EventHub) CertificatesChanged(certificates map[string]*x509.Certificate) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	previousDomains := make(map[string]struct{})
+	for id, cert := range e.certificates {
+		if _, ok := certificates[id]; !ok {
+			previousDomains[certificateDomains(cert)] = struct{}{}
+		}
+	}
+
+	var changes CertificatesChange
+	domains := make(map[string]struct{})
+	for _, id := range slices.Sorted(maps.Keys(certificates)) {
+		if _, ok := e.certificates[id]; ok {
+			continue
+		}
+
+		key := certificateDomains(certificates[id])
+		domains[key] = struct{}{}
+
+		if _, ok := previousDomains[key]; ok {
+			changes.Renewed = append(changes.Renewed, id)
+		} else {
+			changes.Added = append(changes.Added, id)
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(e.certificates)) {
+		if _, ok := certificates[id]; ok {
+			continue
+		}
+
+		// The certificates replaced by a renewed one are not reported as removed.
+		if _, ok := domains[certificateDomains(e.certificates[id])]; !ok {
+			changes.Removed = append(changes.Removed, id)
+		}
+	}
+
+	e.certificates = certificates
+
+	if changes.Added == nil && changes.Renewed == nil && changes.Removed == nil {
+		return
+	}
+
+	e.publish(EventTypeCertificates, changes)
+}
+
+// publish sends the event to the subscribers, and must be called with the lock held.
+func (e *EventHub) publish(typ string, data any) {
+	e.lastID++
+
+	event := Event{ID: e.lastID, Type: typ, Data: data}
+
+	for subscriber := range e.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			// The subscriber does not keep up: it is disconnected rather than missing events silently.
+			delete(e.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+func certificateDomains(cert *x509.Certificate) string {
+	domains := slices.Clone(cert.DNSNames)
+	slices.Sort(domains)
+
+	return cert.Subject.CommonName + "," + strings.Join(domains, ",")
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bufio"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+)
+
+func TestEventHub_RuntimeConfigurationChanged(t *testing.T) {
+	hub := NewEventHub()
+
+	events, unsubscribe := hub.Subscribe()
+	t.Cleanup(unsubscribe)
+
+	hub.RuntimeConfigurationChanged(runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo@file": {Rule: "Host(`foo`)"},
+				"bar@file": {Rule: "Host(`bar`)"},
+			},
+		},
+	}))
+
+	hub.RuntimeConfigurationChanged(runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Routers: map[string]*dynamic.Router{
+				"foo@file": {Rule: "Host(`foo.localhost`)"},
+			},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Services: map[string]*dynamic.TCPService{"baz@file": {}},
+		},
+	}))
+
+	event := <-events
+	assert.Equal(t, uint64(1), event.ID)
+	assert.Equal(t, EventTypeRuntime, event.Type)
+	assert.Equal(t, RuntimeChanges{Routers: &ObjectsDiff{Added: []string{"bar@file", "foo@file"}}}, event.Data)
+
+	event = <-events
+	assert.Equal(t, uint64(2), event.ID)
+	expected := RuntimeChanges{
+		Routers:     &ObjectsDiff{Changed: []string{"foo@file"}, Removed: []string{"bar@file"}},
+		TCPServices: &ObjectsDiff{Added: []string{"baz@file"}},
+	}
+	assert.Equal(t, expected, event.Data)
+}
+
+func TestEventHub_CertificatesChanged(t *testing.T) {
+	certificate := func(domains ...string) *x509.Certificate {
+		return &x509.Certificate{Subject: pkix.Name{CommonName: domains[0]}, DNSNames: domains}
+	}
+
+	hub := NewEventHub()
+	hub.CertificatesChanged(map[string]*x509.Certificate{
+		"1": certificate("foo.localhost"),
+		"2": certificate("bar.localhost", "www.bar.localhost"),
+	})
+
+	events, unsubscribe := hub.Subscribe()
+	t.Cleanup(unsubscribe)
+
+	// Unchanged certificates are not published.
+	hub.CertificatesChanged(map[string]*x509.Certificate{
+		"1": certificate("foo.localhost"),
+		"2": certificate("bar.localhost", "www.bar.localhost"),
+	})
+
+	hub.CertificatesChanged(map[string]*x509.Certificate{
+		"3": certificate("bar.localhost", "www.bar.localhost"),
+		"4": certificate("baz.localhost"),
+	})
+
+	require.Len(t, events, 1)
+
+	event := <-events
+	assert.Equal(t, EventTypeCertificates, event.Type)
+	assert.Equal(t, CertificatesChange{Added: []string{"4"}, Renewed: []string{"3"}, Removed: []string{"1"}}, event.Data)
+}
+
+func TestEventHub_slowSubscriber(t *testing.T) {
+	hub := NewEventHub()
+
+	events, unsubscribe := hub.Subscribe()
+	t.Cleanup(unsubscribe)
+
+	for range subscriberBufferSize + 1 {
+		hub.ServerStatusChanged("http", "foo@file", "http://127.0.0.1", runtime.StatusDown)
+	}
+
+	var count int
+	for range events {
+		count++
+	}
+
+	// The subscriber is disconnected instead of missing the last event.
+	assert.Equal(t, subscriberBufferSize, count)
+}
+
+func TestHandler_Events(t *testing.T) {
+	hub := NewEventHub()
+
+	handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, &runtime.Configuration{}).WithEvents(hub)
+	server := httptest.NewServer(handler.createRouter())
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/events", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The subscription happens before the headers are sent.
+	hub.ServerStatusChanged("http", "foo@file", "http://127.0.0.1", runtime.StatusDown)
+
+	reader := bufio.NewReader(resp.Body)
+
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	expected := []string{
+		"id: 1",
+		"event: serverStatus",
+		`data: {"protocol":"http","service":"foo@file","server":"http://127.0.0.1","status":"DOWN"}`,
+	}
+	assert.Equal(t, expected, lines)
+}
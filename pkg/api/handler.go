@@ -11,6 +11,8 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/server/history"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 	"github.com/traefik/traefik/v3/pkg/tls"
 	"github.com/traefik/traefik/v3/pkg/version"
 )
@@ -50,6 +52,7 @@ type RunTimeRepresentation struct {
 	TCPMiddlewares map[string]*runtime.TCPMiddlewareInfo    `json:"tcpMiddlewares,omitempty"`
 	TCPServices    map[string]*tcpServiceInfoRepresentation `json:"tcpServices,omitempty"`
 	UDPRouters     map[string]*runtime.UDPRouterInfo        `json:"udpRouters,omitempty"`
+	UDPMiddlewares map[string]*runtime.UDPMiddlewareInfo    `json:"udpMiddlewares,omitempty"`
 	UDPServices    map[string]*runtime.UDPServiceInfo       `json:"udpServices,omitempty"`
 }
 
@@ -61,12 +64,34 @@ type Handler struct {
 	runtimeConfiguration *runtime.Configuration
 
 	tlsManager *tls.Manager
+
+	dryRunner DryRunner
+
+	history *history.History
+
+	events *EventHub
+
+	overrides *override.Store
 }
 
 // NewBuilder returns a http.Handler builder based on runtime.Configuration.
 func NewBuilder(staticConfig static.Configuration, tlsManager *tls.Manager) func(*runtime.Configuration) http.Handler {
+	return New(staticConfig, nil).WithTLSManager(tlsManager).Builder()
+}
+
+// Builder returns a http.Handler builder based on runtime.Configuration,
+// building handlers with the same static configuration and options as this one.
+// The options set afterward with the With* setters apply to the handlers built from then on.
+func (h *Handler) Builder() func(*runtime.Configuration) http.Handler {
 	return func(configuration *runtime.Configuration) http.Handler {
-		return New(staticConfig, configuration).WithTLSManager(tlsManager).createRouter()
+		handler := New(h.staticConfig, configuration)
+		handler.tlsManager = h.tlsManager
+		handler.dryRunner = h.dryRunner
+		handler.history = h.history
+		handler.events = h.events
+		handler.overrides = h.overrides
+
+		return handler.createRouter()
 	}
 }
 
@@ -90,6 +115,30 @@ func (h *Handler) WithTLSManager(tlsManager *tls.Manager) *Handler {
 	return h
 }
 
+// WithDryRunner sets the dry runner on the handler, enabling the configuration dry run endpoint.
+func (h *Handler) WithDryRunner(dryRunner DryRunner) *Handler {
+	h.dryRunner = dryRunner
+	return h
+}
+
+// WithHistory sets the configuration history on the handler, enabling the history API endpoints.
+func (h *Handler) WithHistory(configurationHistory *history.History) *Handler {
+	h.history = configurationHistory
+	return h
+}
+
+// WithEvents sets the event hub on the handler, enabling the events streaming endpoint.
+func (h *Handler) WithEvents(eventHub *EventHub) *Handler {
+	h.events = eventHub
+	return h
+}
+
+// WithOverrides sets the server overrides store on the handler, enabling the server overrides endpoints.
+func (h *Handler) WithOverrides(overrides *override.Store) *Handler {
+	h.overrides = overrides
+	return h
+}
+
 // createRouter creates API routes and router.
 func (h *Handler) createRouter() *mux.Router {
 	router := mux.NewRouter().UseEncodedPath()
@@ -107,6 +156,27 @@ func (h *Handler) createRouter() *mux.Router {
 
 	apiRouter.Methods(http.MethodGet).Path("/api/support-dump").HandlerFunc(h.getSupportDump)
 
+	if h.dryRunner != nil {
+		apiRouter.Methods(http.MethodPost).Path("/api/dryrun").HandlerFunc(h.dryRun)
+	}
+
+	if h.overrides != nil {
+		apiRouter.Methods(http.MethodPut).Path("/api/{protocol:http|tcp|udp}/services/{serviceID}/overrides").HandlerFunc(h.setServerOverride)
+		apiRouter.Methods(http.MethodDelete).Path("/api/{protocol:http|tcp|udp}/services/{serviceID}/overrides").HandlerFunc(h.deleteServerOverrides)
+	}
+
+	if h.events != nil {
+		apiRouter.Methods(http.MethodGet).Path("/api/events").HandlerFunc(h.getEvents)
+	}
+
+	if h.history != nil {
+		apiRouter.Methods(http.MethodGet).Path("/api/history").HandlerFunc(h.getHistory)
+		apiRouter.Methods(http.MethodDelete).Path("/api/history/pin").HandlerFunc(h.unpinSnapshot)
+		apiRouter.Methods(http.MethodGet).Path("/api/history/{snapshotID}").HandlerFunc(h.getSnapshot)
+		apiRouter.Methods(http.MethodPost).Path("/api/history/{snapshotID}/reapply").HandlerFunc(h.reapplySnapshot)
+		apiRouter.Methods(http.MethodPost).Path("/api/history/{snapshotID}/pin").HandlerFunc(h.pinSnapshot)
+	}
+
 	apiRouter.Methods(http.MethodGet).Path("/api/entrypoints").HandlerFunc(h.getEntryPoints)
 	apiRouter.Methods(http.MethodGet).Path("/api/entrypoints/{entryPointID}").HandlerFunc(h.getEntryPoint)
 
@@ -116,6 +186,11 @@ func (h *Handler) createRouter() *mux.Router {
 	apiRouter.Methods(http.MethodGet).Path("/api/http/services/{serviceID}").HandlerFunc(h.getService)
 	apiRouter.Methods(http.MethodGet).Path("/api/http/middlewares").HandlerFunc(h.getMiddlewares)
 	apiRouter.Methods(http.MethodGet).Path("/api/http/middlewares/{middlewareID}").HandlerFunc(h.getMiddleware)
+	apiRouter.Methods(http.MethodDelete).Path("/api/http/middlewares/{middlewareID}/cache").HandlerFunc(h.purgeMiddlewareCache)
+	apiRouter.Methods(http.MethodGet).Path("/api/http/middlewares/{middlewareID}/captures").HandlerFunc(h.getMiddlewareCaptures)
+	if h.staticConfig.API.ClearCaptures {
+		apiRouter.Methods(http.MethodDelete).Path("/api/http/middlewares/{middlewareID}/captures").HandlerFunc(h.clearMiddlewareCaptures)
+	}
 
 	apiRouter.Methods(http.MethodGet).Path("/api/tcp/routers").HandlerFunc(h.getTCPRouters)
 	apiRouter.Methods(http.MethodGet).Path("/api/tcp/routers/{routerID}").HandlerFunc(h.getTCPRouter)
@@ -128,6 +203,8 @@ func (h *Handler) createRouter() *mux.Router {
 	apiRouter.Methods(http.MethodGet).Path("/api/udp/routers/{routerID}").HandlerFunc(h.getUDPRouter)
 	apiRouter.Methods(http.MethodGet).Path("/api/udp/services").HandlerFunc(h.getUDPServices)
 	apiRouter.Methods(http.MethodGet).Path("/api/udp/services/{serviceID}").HandlerFunc(h.getUDPService)
+	apiRouter.Methods(http.MethodGet).Path("/api/udp/middlewares").HandlerFunc(h.getUDPMiddlewares)
+	apiRouter.Methods(http.MethodGet).Path("/api/udp/middlewares/{middlewareID}").HandlerFunc(h.getUDPMiddleware)
 
 	apiRouter.Methods(http.MethodGet).Path("/api/certificates").HandlerFunc(h.getCertificates)
 	apiRouter.Methods(http.MethodGet).Path("/api/certificates/{certificateID}").HandlerFunc(h.getCertificate)
@@ -162,6 +239,7 @@ func (h *Handler) getRuntimeConfiguration(rw http.ResponseWriter, request *http.
 		TCPMiddlewares: h.runtimeConfiguration.TCPMiddlewares,
 		TCPServices:    tcpSIRepr,
 		UDPRouters:     h.runtimeConfiguration.UDPRouters,
+		UDPMiddlewares: h.runtimeConfiguration.UDPMiddlewares,
 		UDPServices:    h.runtimeConfiguration.UDPServices,
 	}
 
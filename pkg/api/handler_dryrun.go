@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+)
+
+// DryRunner evaluates the configuration of a provider without applying it.
+type DryRunner interface {
+	// DryRun returns the runtime configuration currently applied,
+	// and the one which would be applied if the given provider provided the candidate configuration.
+	DryRun(ctx context.Context, providerName string, configuration *dynamic.Configuration) (current, candidate *runtime.Configuration, err error)
+}
+
+// DryRunRepresentation is the outcome of a configuration dry run exposed by the API handler.
+type DryRunRepresentation struct {
+	// Valid reports whether none of the routers, services and middlewares would be disabled.
+	Valid bool `json:"valid"`
+
+	RuntimeChanges
+
+	Errors []DryRunError `json:"errors,omitempty"`
+}
+
+// RuntimeChanges lists the routers, services and middlewares changed between two runtime configurations.
+type RuntimeChanges struct {
+	Routers        *ObjectsDiff `json:"routers,omitempty"`
+	Middlewares    *ObjectsDiff `json:"middlewares,omitempty"`
+	Services       *ObjectsDiff `json:"services,omitempty"`
+	TCPRouters     *ObjectsDiff `json:"tcpRouters,omitempty"`
+	TCPMiddlewares *ObjectsDiff `json:"tcpMiddlewares,omitempty"`
+	TCPServices    *ObjectsDiff `json:"tcpServices,omitempty"`
+	UDPRouters     *ObjectsDiff `json:"udpRouters,omitempty"`
+	UDPMiddlewares *ObjectsDiff `json:"udpMiddlewares,omitempty"`
+	UDPServices    *ObjectsDiff `json:"udpServices,omitempty"`
+}
+
+// ObjectsDiff lists the names of the objects added, changed and removed by a configuration.
+type ObjectsDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// DryRunError holds the errors of an object of the runtime configuration, which is disabled or in a warning state.
+type DryRunError struct {
+	Type   string   `json:"type"`
+	Name   string   `json:"name"`
+	Status string   `json:"status"`
+	Errors []string `json:"errors"`
+}
+
+func (h *Handler) dryRun(rw http.ResponseWriter, request *http.Request) {
+	providerName := request.URL.Query().Get("provider")
+	if providerName == "" {
+		writeError(rw, "missing provider query parameter", http.StatusBadRequest)
+		return
+	}
+
+	configuration := &dynamic.Configuration{}
+	if err := json.NewDecoder(request.Body).Decode(configuration); err != nil {
+		writeError(rw, fmt.Sprintf("invalid configuration: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	current, candidate, err := h.dryRunner.DryRun(request.Context(), providerName, configuration)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(rw).Encode(newDryRunRepresentation(current, candidate))
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func newDryRunRepresentation(current, candidate *runtime.Configuration) DryRunRepresentation {
+	result := DryRunRepresentation{RuntimeChanges: diffRuntime(current, candidate)}
+
+	result.Errors = slices.Concat(
+		collectErrors("router", candidate.Routers, func(i *runtime.RouterInfo) (string, []string) { return i.Status, i.Err }),
+		collectErrors("middleware", candidate.Middlewares, func(i *runtime.MiddlewareInfo) (string, []string) { return i.Status, i.Err }),
+		collectErrors("service", candidate.Services, func(i *runtime.ServiceInfo) (string, []string) { return i.Status, i.Err }),
+		collectErrors("tcpRouter", candidate.TCPRouters, func(i *runtime.TCPRouterInfo) (string, []string) { return i.Status, i.Err }),
+		collectErrors("tcpMiddleware", candidate.TCPMiddlewares, func(i *runtime.TCPMiddlewareInfo) (string, []string) { return i.Status, i.Err }),
+		collectErrors("tcpService", candidate.TCPServices, func(i *runtime.TCPServiceInfo) (string, []string) { return i.Status, i.Err }),
+		collectErrors("udpRouter", candidate.UDPRouters, func(i *runtime.UDPRouterInfo) (string, []string) { return i.Status, i.Err }),
+		collectErrors("udpMiddleware", candidate.UDPMiddlewares, func(i *runtime.UDPMiddlewareInfo) (string, []string) { return i.Status, i.Err }),
+		collectErrors("udpService", candidate.UDPServices, func(i *runtime.UDPServiceInfo) (string, []string) { return i.Status, i.Err }),
+	)
+
+	result.Valid = !slices.ContainsFunc(result.Errors, func(e DryRunError) bool {
+		return e.Status == runtime.StatusDisabled
+	})
+
+	return result
+}
+
+// diffRuntime compares the dynamic configurations of the objects of two runtime configurations.
+func diffRuntime(previous, next *runtime.Configuration) RuntimeChanges {
+	return RuntimeChanges{
+		Routers:        diffObjects(previous.Routers, next.Routers, func(i *runtime.RouterInfo) any { return i.Router }),
+		Middlewares:    diffObjects(previous.Middlewares, next.Middlewares, func(i *runtime.MiddlewareInfo) any { return i.Middleware }),
+		Services:       diffObjects(previous.Services, next.Services, func(i *runtime.ServiceInfo) any { return i.Service }),
+		TCPRouters:     diffObjects(previous.TCPRouters, next.TCPRouters, func(i *runtime.TCPRouterInfo) any { return i.TCPRouter }),
+		TCPMiddlewares: diffObjects(previous.TCPMiddlewares, next.TCPMiddlewares, func(i *runtime.TCPMiddlewareInfo) any { return i.TCPMiddleware }),
+		TCPServices:    diffObjects(previous.TCPServices, next.TCPServices, func(i *runtime.TCPServiceInfo) any { return i.TCPService }),
+		UDPRouters:     diffObjects(previous.UDPRouters, next.UDPRouters, func(i *runtime.UDPRouterInfo) any { return i.UDPRouter }),
+		UDPMiddlewares: diffObjects(previous.UDPMiddlewares, next.UDPMiddlewares, func(i *runtime.UDPMiddlewareInfo) any { return i.UDPMiddleware }),
+		UDPServices:    diffObjects(previous.UDPServices, next.UDPServices, func(i *runtime.UDPServiceInfo) any { return i.UDPService }),
+	}
+}
+
+// diffObjects compares the dynamic configurations of the current and candidate objects.
+func diffObjects[T any](current, candidate map[string]T, config func(T) any) *ObjectsDiff {
+	diff := &ObjectsDiff{}
+
+	for name, object := range candidate {
+		currentObject, ok := current[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+		case !reflect.DeepEqual(config(currentObject), config(object)):
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+
+	for name := range current {
+		if _, ok := candidate[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	if len(diff.Added) == 0 && len(diff.Changed) == 0 && len(diff.Removed) == 0 {
+		return nil
+	}
+
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Changed)
+	slices.Sort(diff.Removed)
+
+	return diff
+}
+
+func collectErrors[T any](typ string, objects map[string]T, info func(T) (string, []string)) []DryRunError {
+	var errs []DryRunError
+	for _, name := range slices.Sorted(maps.Keys(objects)) {
+		status, messages := info(objects[name])
+		if len(messages) == 0 && status != runtime.StatusDisabled {
+			continue
+		}
+
+		errs = append(errs, DryRunError{Type: typ, Name: name, Status: status, Errors: messages})
+	}
+
+	return errs
+}
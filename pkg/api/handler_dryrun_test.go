@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+)
+
+type dryRunnerMock func(ctx context.Context, providerName string, configuration *dynamic.Configuration) (*runtime.Configuration, *runtime.Configuration, error)
+
+func (m dryRunnerMock) DryRun(ctx context.Context, providerName string, configuration *dynamic.Configuration) (*runtime.Configuration, *runtime.Configuration, error) {
+	return m(ctx, providerName, configuration)
+}
+
+func TestHandler_DryRun(t *testing.T) {
+	current := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"changed@file": {Router: &dynamic.Router{Rule: "Host(`changed.localhost`)", Service: "svc@file"}},
+			"removed@file": {Router: &dynamic.Router{Rule: "Host(`removed.localhost`)", Service: "svc@file"}},
+			"same@docker":  {Router: &dynamic.Router{Rule: "Host(`same.localhost`)", Service: "svc@docker"}},
+		},
+		Services: map[string]*runtime.ServiceInfo{
+			"svc@file": {Service: &dynamic.Service{}},
+		},
+	}
+
+	candidate := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"added@file": {
+				Router: &dynamic.Router{Rule: "Host(`added.localhost`)", Service: "unknown@file"},
+				Status: runtime.StatusDisabled,
+				Err:    []string{"the service \"unknown@file\" does not exist"},
+			},
+			"changed@file": {Router: &dynamic.Router{Rule: "Host(`other.localhost`)", Service: "svc@file"}, Status: runtime.StatusEnabled},
+			"same@docker":  {Router: &dynamic.Router{Rule: "Host(`same.localhost`)", Service: "svc@docker"}, Status: runtime.StatusEnabled},
+		},
+		Services: map[string]*runtime.ServiceInfo{
+			"svc@file": {Service: &dynamic.Service{}, Status: runtime.StatusWarning, Err: []string{"no servers"}},
+		},
+	}
+
+	testCases := []struct {
+		desc           string
+		query          string
+		body           string
+		dryRunErr      error
+		expectedStatus int
+		expected       *DryRunRepresentation
+	}{
+		{
+			desc:           "diff and errors",
+			query:          "?provider=file",
+			body:           `{"http":{"routers":{"added":{"rule":"Host(` + "`added.localhost`" + `)","service":"unknown"}}}}`,
+			expectedStatus: http.StatusOK,
+			expected: &DryRunRepresentation{
+				Valid: false,
+				RuntimeChanges: RuntimeChanges{
+					Routers: &ObjectsDiff{
+						Added:   []string{"added@file"},
+						Changed: []string{"changed@file"},
+						Removed: []string{"removed@file"},
+					},
+				},
+				Errors: []DryRunError{
+					{Type: "router", Name: "added@file", Status: runtime.StatusDisabled, Errors: []string{"the service \"unknown@file\" does not exist"}},
+					{Type: "service", Name: "svc@file", Status: runtime.StatusWarning, Errors: []string{"no servers"}},
+				},
+			},
+		},
+		{
+			desc:           "missing provider",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "invalid configuration",
+			query:          "?provider=file",
+			body:           `{"http":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "dry run unavailable",
+			query:          "?provider=file",
+			body:           `{}`,
+			dryRunErr:      errors.New("not available"),
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			dryRunner := dryRunnerMock(func(_ context.Context, providerName string, configuration *dynamic.Configuration) (*runtime.Configuration, *runtime.Configuration, error) {
+				assert.Equal(t, "file", providerName)
+				assert.NotNil(t, configuration)
+
+				return current, candidate, test.dryRunErr
+			})
+
+			handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, &runtime.Configuration{}).WithDryRunner(dryRunner)
+			server := httptest.NewServer(handler.createRouter())
+			t.Cleanup(server.Close)
+
+			resp, err := http.Post(server.URL+"/api/dryrun"+test.query, "application/json", strings.NewReader(test.body))
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			if test.expected == nil {
+				return
+			}
+
+			var result DryRunRepresentation
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			assert.Equal(t, *test.expected, result)
+		})
+	}
+}
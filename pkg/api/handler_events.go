@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// eventsKeepAliveInterval is the interval at which a comment is sent on idle event streams,
+// so that intermediaries do not close them.
+const eventsKeepAliveInterval = 15 * time.Second
+
+func (h *Handler) getEvents(rw http.ResponseWriter, request *http.Request) {
+	logger := log.Ctx(request.Context())
+
+	events, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	rc := http.NewResponseController(rw)
+
+	// The stream lasts until the client disconnects.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	rw.Header().Set("Content-Type", "text/event-stream")
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		logger.Error().Err(err).Msg("Unable to stream events")
+		return
+	}
+
+	ticker := time.NewTicker(eventsKeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+
+		case <-ticker.C:
+			if _, err := fmt.Fprint(rw, ": keep-alive\n\n"); err != nil {
+				return
+			}
+
+		case event, ok := <-events:
+			if !ok {
+				// The client does not keep up with the events, it has to reconnect and reload the configuration.
+				return
+			}
+
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				logger.Error().Err(err).Msg("Unable to encode event")
+				continue
+			}
+
+			if _, err := fmt.Fprintf(rw, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/redactor"
+	"github.com/traefik/traefik/v3/pkg/server/history"
+)
+
+type snapshotRepresentation struct {
+	history.Snapshot
+
+	Pinned bool `json:"pinned,omitempty"`
+}
+
+type snapshotConfigurationRepresentation struct {
+	snapshotRepresentation
+
+	Configuration json.RawMessage `json:"configuration"`
+}
+
+func (h *Handler) getHistory(rw http.ResponseWriter, request *http.Request) {
+	pinned := h.history.Pinned()
+
+	results := make([]snapshotRepresentation, 0)
+	for _, snapshot := range h.history.Snapshots() {
+		results = append(results, snapshotRepresentation{Snapshot: snapshot, Pinned: snapshot.ID == pinned})
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(rw).Encode(results); err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getSnapshot(rw http.ResponseWriter, request *http.Request) {
+	id, ok := snapshotID(rw, request)
+	if !ok {
+		return
+	}
+
+	snapshot, ok := h.history.Get(id)
+	if !ok {
+		writeError(rw, fmt.Sprintf("snapshot not found: %d", id), http.StatusNotFound)
+		return
+	}
+
+	// The configuration may hold credentials, such as private keys or middleware secrets.
+	configuration, err := redactor.RemoveCredentials(snapshot.Configuration)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := snapshotConfigurationRepresentation{
+		snapshotRepresentation: snapshotRepresentation{Snapshot: snapshot, Pinned: snapshot.ID == h.history.Pinned()},
+		Configuration:          json.RawMessage(configuration),
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(rw).Encode(result); err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) reapplySnapshot(rw http.ResponseWriter, request *http.Request) {
+	id, ok := snapshotID(rw, request)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.history.Reapply(request.Context(), id)
+	writeSnapshot(rw, request, snapshot, false, err)
+}
+
+func (h *Handler) pinSnapshot(rw http.ResponseWriter, request *http.Request) {
+	id, ok := snapshotID(rw, request)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.history.Pin(request.Context(), id)
+	writeSnapshot(rw, request, snapshot, true, err)
+}
+
+func (h *Handler) unpinSnapshot(rw http.ResponseWriter, request *http.Request) {
+	snapshot, err := h.history.Unpin(request.Context())
+	if err == nil && snapshot.ID == 0 {
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeSnapshot(rw, request, snapshot, false, err)
+}
+
+// writeSnapshot writes the snapshot recorded when applying a configuration of the history.
+func writeSnapshot(rw http.ResponseWriter, request *http.Request, snapshot history.Snapshot, pinned bool, err error) {
+	if errors.Is(err, history.ErrNotFound) {
+		writeError(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(rw).Encode(snapshotRepresentation{Snapshot: snapshot, Pinned: pinned}); err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func snapshotID(rw http.ResponseWriter, request *http.Request) (uint64, bool) {
+	rawID := mux.Vars(request)["snapshotID"]
+
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil || id == 0 {
+		writeError(rw, fmt.Sprintf("invalid snapshot ID: %s", rawID), http.StatusBadRequest)
+		return 0, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/server/history"
+)
+
+func TestHandler_History(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		method         string
+		path           string
+		expectedStatus int
+		expected       []string
+		notExpected    []string
+	}{
+		{
+			desc:           "list snapshots",
+			method:         http.MethodGet,
+			path:           "/api/history",
+			expectedStatus: http.StatusOK,
+			expected:       []string{`"id":2`, `"id":1`, `"providers":["file"]`},
+		},
+		{
+			desc:           "get snapshot without credentials",
+			method:         http.MethodGet,
+			path:           "/api/history/1",
+			expectedStatus: http.StatusOK,
+			expected:       []string{`"id":1`, `"configuration":{`, `"clientID":"traefik"`},
+			notExpected:    []string{"s3cr3t"},
+		},
+		{
+			desc:           "get missing snapshot",
+			method:         http.MethodGet,
+			path:           "/api/history/42",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "invalid snapshot ID",
+			method:         http.MethodGet,
+			path:           "/api/history/foo",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "reapply snapshot",
+			method:         http.MethodPost,
+			path:           "/api/history/1/reapply",
+			expectedStatus: http.StatusOK,
+			expected:       []string{`"id":3`, `"reappliedFrom":1`},
+		},
+		{
+			desc:           "pin snapshot",
+			method:         http.MethodPost,
+			path:           "/api/history/1/pin",
+			expectedStatus: http.StatusOK,
+			expected:       []string{`"id":3`, `"reappliedFrom":1`, `"pinned":true`},
+		},
+		{
+			desc:           "reapply missing snapshot",
+			method:         http.MethodPost,
+			path:           "/api/history/42/reapply",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "unpin without pinned snapshot",
+			method:         http.MethodDelete,
+			path:           "/api/history/pin",
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			configurationHistory := history.New(10)
+			configurationHistory.Record(dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Middlewares: map[string]*dynamic.Middleware{
+						"oidc": {OIDC: &dynamic.OIDC{ClientID: "traefik", ClientSecret: "s3cr3t"}},
+					},
+				},
+			}, []string{"file"}, 0)
+			configurationHistory.Record(dynamic.Configuration{}, []string{"docker"}, 0)
+
+			// Handles the requests as the configuration watcher does.
+			go func() {
+				for {
+					select {
+					case <-t.Context().Done():
+						return
+					case req := <-configurationHistory.Requests():
+						if req.Action == history.ActionUnpin {
+							req.Done(history.Snapshot{}, nil)
+							continue
+						}
+
+						snapshot, ok := configurationHistory.Get(req.ID)
+						if !ok {
+							req.Done(history.Snapshot{}, history.ErrNotFound)
+							continue
+						}
+
+						req.Done(configurationHistory.Record(snapshot.Configuration, nil, snapshot.ID), nil)
+					}
+				}
+			}()
+
+			handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, &runtime.Configuration{}).WithHistory(configurationHistory)
+			server := httptest.NewServer(handler.createRouter())
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequestWithContext(t.Context(), test.method, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			if test.expectedStatus == http.StatusOK {
+				assert.True(t, json.Valid(body))
+			}
+
+			for _, expected := range test.expected {
+				assert.Contains(t, string(body), expected)
+			}
+
+			for _, notExpected := range test.notExpected {
+				assert.NotContains(t, string(body), notExpected)
+			}
+		})
+	}
+}
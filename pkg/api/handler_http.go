@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,6 +12,9 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/middlewares/cache"
+	"github.com/traefik/traefik/v3/pkg/middlewares/debugcapture"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 	"github.com/traefik/traefik/v3/pkg/tls"
 )
 
@@ -38,10 +42,11 @@ func newRouterRepresentation(name string, rt *runtime.RouterInfo) routerRepresen
 type serviceRepresentation struct {
 	*runtime.ServiceInfo
 
-	Name         string            `json:"name,omitempty"`
-	Provider     string            `json:"provider,omitempty"`
-	Type         string            `json:"type,omitempty"`
-	ServerStatus map[string]string `json:"serverStatus,omitempty"`
+	Name            string                       `json:"name,omitempty"`
+	Provider        string                       `json:"provider,omitempty"`
+	Type            string                       `json:"type,omitempty"`
+	ServerStatus    map[string]string            `json:"serverStatus,omitempty"`
+	ServerOverrides map[string]override.Override `json:"serverOverrides,omitempty"`
 }
 
 func newServiceRepresentation(name string, si *runtime.ServiceInfo) serviceRepresentation {
@@ -177,6 +182,7 @@ func (h *Handler) getService(rw http.ResponseWriter, request *http.Request) {
 	}
 
 	result := newServiceRepresentation(serviceID, service)
+	result.ServerOverrides = h.overrides.Service(override.ProtocolHTTP, serviceID)
 
 	err = json.NewEncoder(rw).Encode(result)
 	if err != nil {
@@ -242,6 +248,109 @@ func (h *Handler) getMiddleware(rw http.ResponseWriter, request *http.Request) {
 	}
 }
 
+// purgeMiddlewareCache removes the responses stored by a cache middleware.
+// The optional "key" query parameter restricts the purge to the responses stored under this key.
+func (h *Handler) purgeMiddlewareCache(rw http.ResponseWriter, request *http.Request) {
+	scapedMiddlewareID := mux.Vars(request)["middlewareID"]
+
+	middlewareID, err := url.PathUnescape(scapedMiddlewareID)
+	if err != nil {
+		writeError(rw, fmt.Sprintf("unable to decode middlewareID %q: %s", scapedMiddlewareID, err), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	middleware, ok := h.runtimeConfiguration.Middlewares[middlewareID]
+	if !ok || middleware.Cache == nil {
+		writeError(rw, fmt.Sprintf("cache middleware not found: %s", middlewareID), http.StatusNotFound)
+		return
+	}
+
+	err = cache.Purge(middlewareID, request.URL.Query().Get("key"))
+	if errors.Is(err, cache.ErrNotFound) {
+		writeError(rw, fmt.Sprintf("cache middleware not found: %s", middlewareID), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// getMiddlewareCaptures returns the requests and responses recorded by a debug capture middleware, the most recent first.
+func (h *Handler) getMiddlewareCaptures(rw http.ResponseWriter, request *http.Request) {
+	middlewareID, ok := h.debugCaptureMiddleware(rw, request)
+	if !ok {
+		return
+	}
+
+	records, err := debugcapture.Records(middlewareID)
+	if errors.Is(err, debugcapture.ErrNotFound) {
+		// The middleware did not capture any request yet.
+		records = []debugcapture.Record{}
+	} else if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	pageInfo, err := pagination(request, len(records))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(records[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// clearMiddlewareCaptures removes the requests and responses recorded by a debug capture middleware.
+func (h *Handler) clearMiddlewareCaptures(rw http.ResponseWriter, request *http.Request) {
+	middlewareID, ok := h.debugCaptureMiddleware(rw, request)
+	if !ok {
+		return
+	}
+
+	err := debugcapture.Clear(middlewareID)
+	if err != nil && !errors.Is(err, debugcapture.ErrNotFound) {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// debugCaptureMiddleware returns the ID of the debug capture middleware targeted by the request.
+func (h *Handler) debugCaptureMiddleware(rw http.ResponseWriter, request *http.Request) (string, bool) {
+	scapedMiddlewareID := mux.Vars(request)["middlewareID"]
+
+	middlewareID, err := url.PathUnescape(scapedMiddlewareID)
+	if err != nil {
+		writeError(rw, fmt.Sprintf("unable to decode middlewareID %q: %s", scapedMiddlewareID, err), http.StatusBadRequest)
+		return "", false
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	middleware, ok := h.runtimeConfiguration.Middlewares[middlewareID]
+	if !ok || middleware.DebugCapture == nil {
+		writeError(rw, fmt.Sprintf("debug capture middleware not found: %s", middlewareID), http.StatusNotFound)
+		return "", false
+	}
+
+	return middlewareID, true
+}
+
 func keepRouter(name string, item *runtime.RouterInfo, criterion *searchCriterion) bool {
 	if criterion == nil {
 		return true
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/server/override"
+)
+
+type serverOverrideRequest struct {
+	// Server is the URL of an HTTP server, or the address of a TCP or UDP server.
+	Server string `json:"server"`
+
+	override.Override
+}
+
+func (h *Handler) setServerOverride(rw http.ResponseWriter, request *http.Request) {
+	protocol, serviceID, ok := h.overridesTarget(rw, request)
+	if !ok {
+		return
+	}
+
+	var req serverOverrideRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		writeError(rw, fmt.Sprintf("invalid server override: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	servers, ok := h.loadBalancerServers(protocol, serviceID)
+	if !ok {
+		writeError(rw, fmt.Sprintf("service %s is not a load balancer", serviceID), http.StatusBadRequest)
+		return
+	}
+
+	if !slices.Contains(servers, req.Server) {
+		writeError(rw, fmt.Sprintf("server not found in service %s: %s", serviceID, req.Server), http.StatusNotFound)
+		return
+	}
+
+	if err := h.overrides.Set(protocol, serviceID, req.Server, req.Override); err != nil {
+		writeError(rw, fmt.Sprintf("invalid server override: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(rw).Encode(h.overrides.Service(protocol, serviceID)); err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) deleteServerOverrides(rw http.ResponseWriter, request *http.Request) {
+	protocol, serviceID, ok := h.overridesTarget(rw, request)
+	if !ok {
+		return
+	}
+
+	// Without server, the overrides of all the servers of the service are deleted.
+	server := request.URL.Query().Get("server")
+
+	if !h.overrides.Delete(protocol, serviceID, server) {
+		writeError(rw, fmt.Sprintf("server override not found in service %s", serviceID), http.StatusNotFound)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// overridesTarget returns the protocol and the ID of the service targeted by the request,
+// which must exist in the runtime configuration.
+func (h *Handler) overridesTarget(rw http.ResponseWriter, request *http.Request) (string, string, bool) {
+	protocol := mux.Vars(request)["protocol"]
+	scapedServiceID := mux.Vars(request)["serviceID"]
+
+	serviceID, err := url.PathUnescape(scapedServiceID)
+	if err != nil {
+		writeError(rw, fmt.Sprintf("unable to decode serviceID %q: %s", scapedServiceID, err), http.StatusBadRequest)
+		return "", "", false
+	}
+
+	var exists bool
+	switch protocol {
+	case override.ProtocolHTTP:
+		_, exists = h.runtimeConfiguration.Services[serviceID]
+	case override.ProtocolTCP:
+		_, exists = h.runtimeConfiguration.TCPServices[serviceID]
+	case override.ProtocolUDP:
+		_, exists = h.runtimeConfiguration.UDPServices[serviceID]
+	}
+
+	if !exists {
+		writeError(rw, fmt.Sprintf("service not found: %s", serviceID), http.StatusNotFound)
+		return "", "", false
+	}
+
+	return protocol, serviceID, true
+}
+
+// loadBalancerServers returns the servers of the service, and false if it is not a load balancer.
+func (h *Handler) loadBalancerServers(protocol, serviceID string) ([]string, bool) {
+	var servers []string
+
+	switch protocol {
+	case override.ProtocolHTTP:
+		service := h.runtimeConfiguration.Services[serviceID]
+		if service.LoadBalancer == nil {
+			return nil, false
+		}
+
+		for _, server := range service.LoadBalancer.Servers {
+			servers = append(servers, server.URL)
+		}
+
+	case override.ProtocolTCP:
+		service := h.runtimeConfiguration.TCPServices[serviceID]
+		if service.LoadBalancer == nil {
+			return nil, false
+		}
+
+		for _, server := range service.LoadBalancer.Servers {
+			servers = append(servers, server.Address)
+		}
+
+	case override.ProtocolUDP:
+		service := h.runtimeConfiguration.UDPServices[serviceID]
+		if service.LoadBalancer == nil {
+			return nil, false
+		}
+
+		for _, server := range service.LoadBalancer.Servers {
+			servers = append(servers, server.Address)
+		}
+	}
+
+	return servers, true
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/server/override"
+)
+
+func TestHandler_ServerOverrides(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expected       string
+	}{
+		{
+			desc:           "drain an HTTP server",
+			method:         http.MethodPut,
+			path:           "/api/http/services/foo@file/overrides",
+			body:           `{"server":"http://127.0.0.1:8080","state":"drain"}`,
+			expectedStatus: http.StatusOK,
+			expected:       `{"http://127.0.0.1:8080":{"state":"drain"},"http://127.0.0.2:8080":{"weight":3}}`,
+		},
+		{
+			desc:           "reweight a TCP server",
+			method:         http.MethodPut,
+			path:           "/api/tcp/services/bar@file/overrides",
+			body:           `{"server":"127.0.0.1:9000","weight":2}`,
+			expectedStatus: http.StatusOK,
+			expected:       `{"127.0.0.1:9000":{"weight":2}}`,
+		},
+		{
+			desc:           "disable a UDP server",
+			method:         http.MethodPut,
+			path:           "/api/udp/services/baz@file/overrides",
+			body:           `{"server":"127.0.0.1:5353","state":"disabled"}`,
+			expectedStatus: http.StatusOK,
+			expected:       `{"127.0.0.1:5353":{"state":"disabled"}}`,
+		},
+		{
+			desc:           "invalid state",
+			method:         http.MethodPut,
+			path:           "/api/http/services/foo@file/overrides",
+			body:           `{"server":"http://127.0.0.1:8080","state":"foo"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "zero weight",
+			method:         http.MethodPut,
+			path:           "/api/http/services/foo@file/overrides",
+			body:           `{"server":"http://127.0.0.1:8080","weight":0}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "invalid body",
+			method:         http.MethodPut,
+			path:           "/api/http/services/foo@file/overrides",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "drain a TCP server",
+			method:         http.MethodPut,
+			path:           "/api/tcp/services/bar@file/overrides",
+			body:           `{"server":"127.0.0.1:9000","state":"drain"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "unknown server",
+			method:         http.MethodPut,
+			path:           "/api/http/services/foo@file/overrides",
+			body:           `{"server":"http://127.0.0.3:8080","state":"drain"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "unknown service",
+			method:         http.MethodPut,
+			path:           "/api/tcp/services/foo@file/overrides",
+			body:           `{"server":"127.0.0.1:9000","state":"drain"}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "service without load balancer",
+			method:         http.MethodPut,
+			path:           "/api/http/services/wrr@file/overrides",
+			body:           `{"server":"http://127.0.0.1:8080","state":"drain"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "delete the override of a server",
+			method:         http.MethodDelete,
+			path:           "/api/http/services/foo@file/overrides?server=http://127.0.0.2:8080",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			desc:           "delete the overrides of a service",
+			method:         http.MethodDelete,
+			path:           "/api/http/services/foo@file/overrides",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			desc:           "delete missing override",
+			method:         http.MethodDelete,
+			path:           "/api/http/services/foo@file/overrides?server=http://127.0.0.1:8080",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "get service with overrides",
+			method:         http.MethodGet,
+			path:           "/api/http/services/foo@file",
+			expectedStatus: http.StatusOK,
+			expected:       `"serverOverrides":{"http://127.0.0.2:8080":{"weight":3}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rtConf := &runtime.Configuration{
+				Services: map[string]*runtime.ServiceInfo{
+					"foo@file": {
+						Service: &dynamic.Service{
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{{URL: "http://127.0.0.1:8080"}, {URL: "http://127.0.0.2:8080"}},
+							},
+						},
+					},
+					"wrr@file": {
+						Service: &dynamic.Service{Weighted: &dynamic.WeightedRoundRobin{}},
+					},
+				},
+				TCPServices: map[string]*runtime.TCPServiceInfo{
+					"bar@file": {
+						TCPService: &dynamic.TCPService{
+							LoadBalancer: &dynamic.TCPServersLoadBalancer{
+								Servers: []dynamic.TCPServer{{Address: "127.0.0.1:9000"}},
+							},
+						},
+					},
+				},
+				UDPServices: map[string]*runtime.UDPServiceInfo{
+					"baz@file": {
+						UDPService: &dynamic.UDPService{
+							LoadBalancer: &dynamic.UDPServersLoadBalancer{
+								Servers: []dynamic.UDPServer{{Address: "127.0.0.1:5353"}},
+							},
+						},
+					},
+				},
+			}
+
+			overrides := override.NewStore()
+			weight := 3
+			err := overrides.Set(override.ProtocolHTTP, "foo@file", "http://127.0.0.2:8080", override.Override{Weight: &weight})
+			require.NoError(t, err)
+
+			handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, rtConf).WithOverrides(overrides)
+			server := httptest.NewServer(handler.createRouter())
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequestWithContext(t.Context(), test.method, server.URL+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			if test.expected != "" {
+				assert.Contains(t, string(body), test.expected)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 )
 
 type tcpRouterRepresentation struct {
@@ -33,10 +34,11 @@ func newTCPRouterRepresentation(name string, rt *runtime.TCPRouterInfo) tcpRoute
 type tcpServiceRepresentation struct {
 	*runtime.TCPServiceInfo
 
-	Name         string            `json:"name,omitempty"`
-	Provider     string            `json:"provider,omitempty"`
-	Type         string            `json:"type,omitempty"`
-	ServerStatus map[string]string `json:"serverStatus,omitempty"`
+	Name            string                       `json:"name,omitempty"`
+	Provider        string                       `json:"provider,omitempty"`
+	Type            string                       `json:"type,omitempty"`
+	ServerStatus    map[string]string            `json:"serverStatus,omitempty"`
+	ServerOverrides map[string]override.Override `json:"serverOverrides,omitempty"`
 }
 
 func newTCPServiceRepresentation(name string, si *runtime.TCPServiceInfo) tcpServiceRepresentation {
@@ -172,6 +174,7 @@ func (h *Handler) getTCPService(rw http.ResponseWriter, request *http.Request) {
 	}
 
 	result := newTCPServiceRepresentation(serviceID, service)
+	result.ServerOverrides = h.overrides.Service(override.ProtocolTCP, serviceID)
 
 	err = json.NewEncoder(rw).Encode(result)
 	if err != nil {
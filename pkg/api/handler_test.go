@@ -14,6 +14,8 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/middlewares/cache"
+	"github.com/traefik/traefik/v3/pkg/middlewares/debugcapture"
 )
 
 var updateExpected = flag.Bool("update_expected", false, "Update expected files in testdata")
@@ -279,3 +281,182 @@ func TestHandler_GetMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_PurgeMiddlewareCache(t *testing.T) {
+	config := dynamic.Cache{}
+	config.SetDefaults()
+
+	_, err := cache.New(t.Context(), http.NotFoundHandler(), config, nil, "cache@myprovider")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		middlewareName string
+		expectedStatus int
+	}{
+		{
+			desc:           "Middleware not found",
+			middlewareName: "foo@myprovider",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "Not a cache middleware",
+			middlewareName: "auth@myprovider",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "Cache middleware not instantiated",
+			middlewareName: "unused@myprovider",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "Purge cache",
+			middlewareName: "cache@myprovider",
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	conf := runtime.Configuration{
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"auth@myprovider": {
+				Middleware: &dynamic.Middleware{
+					BasicAuth: &dynamic.BasicAuth{
+						Users: []string{"admin:admin"},
+					},
+				},
+			},
+			"unused@myprovider": {
+				Middleware: &dynamic.Middleware{
+					Cache: &config,
+				},
+			},
+			"cache@myprovider": {
+				Middleware: &dynamic.Middleware{
+					Cache: &config,
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, &conf)
+			server := httptest.NewServer(handler.createRouter())
+
+			req, err := http.NewRequest(http.MethodDelete, server.URL+"/api/http/middlewares/"+test.middlewareName+"/cache?key=localhost/foo", nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+		})
+	}
+}
+
+func TestHandler_MiddlewareCaptures(t *testing.T) {
+	config := dynamic.DebugCapture{}
+	config.SetDefaults()
+
+	capture, err := debugcapture.New(t.Context(), http.NotFoundHandler(), config, "capture@myprovider")
+	require.NoError(t, err)
+
+	capture.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+	testCases := []struct {
+		desc           string
+		method         string
+		middlewareName string
+		clearCaptures  bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "Middleware not found",
+			method:         http.MethodGet,
+			middlewareName: "foo@myprovider",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "Not a debug capture middleware",
+			method:         http.MethodGet,
+			middlewareName: "auth@myprovider",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "Debug capture middleware not instantiated",
+			method:         http.MethodGet,
+			middlewareName: "unused@myprovider",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]",
+		},
+		{
+			desc:           "Get captures",
+			method:         http.MethodGet,
+			middlewareName: "capture@myprovider",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"url":"/foo"`,
+		},
+		{
+			desc:           "Clear captures of a middleware not instantiated",
+			method:         http.MethodDelete,
+			middlewareName: "unused@myprovider",
+			clearCaptures:  true,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			desc:           "Clear captures not enabled",
+			method:         http.MethodDelete,
+			middlewareName: "capture@myprovider",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	conf := runtime.Configuration{
+		Middlewares: map[string]*runtime.MiddlewareInfo{
+			"auth@myprovider": {
+				Middleware: &dynamic.Middleware{
+					BasicAuth: &dynamic.BasicAuth{
+						Users: []string{"admin:admin"},
+					},
+				},
+			},
+			"unused@myprovider": {
+				Middleware: &dynamic.Middleware{
+					DebugCapture: &config,
+				},
+			},
+			"capture@myprovider": {
+				Middleware: &dynamic.Middleware{
+					DebugCapture: &config,
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := New(static.Configuration{API: &static.API{ClearCaptures: test.clearCaptures}, Global: &static.Global{}}, &conf)
+			server := httptest.NewServer(handler.createRouter())
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequestWithContext(t.Context(), test.method, server.URL+"/api/http/middlewares/"+test.middlewareName+"/captures", nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Contains(t, string(body), test.expectedBody)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 )
 
 type udpRouterRepresentation struct {
@@ -31,9 +32,11 @@ func newUDPRouterRepresentation(name string, rt *runtime.UDPRouterInfo) udpRoute
 type udpServiceRepresentation struct {
 	*runtime.UDPServiceInfo
 
-	Name     string `json:"name,omitempty"`
-	Provider string `json:"provider,omitempty"`
-	Type     string `json:"type,omitempty"`
+	Name            string                       `json:"name,omitempty"`
+	Provider        string                       `json:"provider,omitempty"`
+	Type            string                       `json:"type,omitempty"`
+	ServerStatus    map[string]string            `json:"serverStatus,omitempty"`
+	ServerOverrides map[string]override.Override `json:"serverOverrides,omitempty"`
 }
 
 func newUDPServiceRepresentation(name string, si *runtime.UDPServiceInfo) udpServiceRepresentation {
@@ -42,6 +45,24 @@ func newUDPServiceRepresentation(name string, si *runtime.UDPServiceInfo) udpSer
 		Name:           name,
 		Provider:       getProviderName(name),
 		Type:           strings.ToLower(extractType(si.UDPService)),
+		ServerStatus:   si.GetAllStatus(),
+	}
+}
+
+type udpMiddlewareRepresentation struct {
+	*runtime.UDPMiddlewareInfo
+
+	Name     string `json:"name,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+func newUDPMiddlewareRepresentation(name string, mi *runtime.UDPMiddlewareInfo) udpMiddlewareRepresentation {
+	return udpMiddlewareRepresentation{
+		UDPMiddlewareInfo: mi,
+		Name:              name,
+		Provider:          getProviderName(name),
+		Type:              strings.ToLower(extractType(mi.UDPMiddleware)),
 	}
 }
 
@@ -151,6 +172,64 @@ func (h *Handler) getUDPService(rw http.ResponseWriter, request *http.Request) {
 	}
 
 	result := newUDPServiceRepresentation(serviceID, service)
+	result.ServerOverrides = h.overrides.Service(override.ProtocolUDP, serviceID)
+
+	err = json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getUDPMiddlewares(rw http.ResponseWriter, request *http.Request) {
+	results := make([]udpMiddlewareRepresentation, 0, len(h.runtimeConfiguration.UDPMiddlewares))
+
+	query := request.URL.Query()
+	criterion := newSearchCriterion(query)
+
+	for name, mi := range h.runtimeConfiguration.UDPMiddlewares {
+		if keepUDPMiddleware(name, mi, criterion) {
+			results = append(results, newUDPMiddlewareRepresentation(name, mi))
+		}
+	}
+
+	sortMiddlewares(query, results)
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) getUDPMiddleware(rw http.ResponseWriter, request *http.Request) {
+	scapedMiddlewareID := mux.Vars(request)["middlewareID"]
+
+	middlewareID, err := url.PathUnescape(scapedMiddlewareID)
+	if err != nil {
+		writeError(rw, fmt.Sprintf("unable to decode middlewareID %q: %s", scapedMiddlewareID, err), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	middleware, ok := h.runtimeConfiguration.UDPMiddlewares[middlewareID]
+	if !ok {
+		writeError(rw, fmt.Sprintf("middleware not found: %s", middlewareID), http.StatusNotFound)
+		return
+	}
+
+	result := newUDPMiddlewareRepresentation(middlewareID, middleware)
 
 	err = json.NewEncoder(rw).Encode(result)
 	if err != nil {
@@ -166,7 +245,8 @@ func keepUDPRouter(name string, item *runtime.UDPRouterInfo, criterion *searchCr
 
 	return criterion.withStatus(item.Status) &&
 		criterion.searchIn(name) &&
-		criterion.filterService(item.Service)
+		criterion.filterService(item.Service) &&
+		criterion.filterMiddleware(item.Middlewares)
 }
 
 func keepUDPService(name string, item *runtime.UDPServiceInfo, criterion *searchCriterion) bool {
@@ -176,3 +256,11 @@ func keepUDPService(name string, item *runtime.UDPServiceInfo, criterion *search
 
 	return criterion.withStatus(item.Status) && criterion.searchIn(name)
 }
+
+func keepUDPMiddleware(name string, item *runtime.UDPMiddlewareInfo, criterion *searchCriterion) bool {
+	if criterion == nil {
+		return true
+	}
+
+	return criterion.withStatus(item.Status) && criterion.searchIn(name)
+}
@@ -560,6 +560,147 @@ func TestHandler_UDP(t *testing.T) {
 				statusCode: http.StatusNotFound,
 			},
 		},
+		{
+			desc: "all udp middlewares, but no config",
+			path: "/api/udp/middlewares",
+			conf: runtime.Configuration{},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/udpmiddlewares-empty.json",
+			},
+		},
+		{
+			desc: "all udp middlewares",
+			path: "/api/udp/middlewares",
+			conf: runtime.Configuration{
+				UDPRouters: map[string]*runtime.UDPRouterInfo{
+					"bar@myprovider": {
+						UDPRouter: &dynamic.UDPRouter{
+							EntryPoints: []string{"web"},
+							Service:     "foo-service@myprovider",
+							Middlewares: []string{"ipallowlist1", "inflightsession2@anotherprovider"},
+						},
+					},
+				},
+				UDPMiddlewares: map[string]*runtime.UDPMiddlewareInfo{
+					"ipallowlist1@myprovider": {
+						UDPMiddleware: &dynamic.UDPMiddleware{
+							IPAllowList: &dynamic.UDPIPAllowList{
+								SourceRange: []string{"127.0.0.1/32"},
+							},
+						},
+						Status: runtime.StatusEnabled,
+					},
+					"inflightsession2@anotherprovider": {
+						UDPMiddleware: &dynamic.UDPMiddleware{
+							InFlightSession: &dynamic.UDPInFlightSession{
+								Amount: 10,
+							},
+						},
+						Status: runtime.StatusEnabled,
+					},
+					"ratelimit3@myprovider": {
+						UDPMiddleware: &dynamic.UDPMiddleware{
+							RateLimit: &dynamic.UDPRateLimit{
+								Average: 10,
+								Burst:   20,
+								Packets: &dynamic.UDPPacketRateLimit{
+									Average:    100,
+									Burst:      200,
+									PerSession: true,
+								},
+							},
+						},
+						Err:    []string{"redis is not reachable"},
+						Status: runtime.StatusDisabled,
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/udpmiddlewares.json",
+			},
+		},
+		{
+			desc: "udp middlewares filtered by status",
+			path: "/api/udp/middlewares?status=enabled",
+			conf: runtime.Configuration{
+				UDPMiddlewares: map[string]*runtime.UDPMiddlewareInfo{
+					"ipallowlist1@myprovider": {
+						UDPMiddleware: &dynamic.UDPMiddleware{
+							IPAllowList: &dynamic.UDPIPAllowList{
+								SourceRange: []string{"127.0.0.1/32"},
+							},
+						},
+						Status: runtime.StatusEnabled,
+					},
+					"ratelimit3@myprovider": {
+						UDPMiddleware: &dynamic.UDPMiddleware{
+							RateLimit: &dynamic.UDPRateLimit{
+								Average: 10,
+							},
+						},
+						Err:    []string{"redis is not reachable"},
+						Status: runtime.StatusDisabled,
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusOK,
+				nextPage:   "1",
+				jsonFile:   "testdata/udpmiddlewares-filtered-status.json",
+			},
+		},
+		{
+			desc: "one udp middleware by id",
+			path: "/api/udp/middlewares/ipallowlist1@myprovider",
+			conf: runtime.Configuration{
+				UDPMiddlewares: map[string]*runtime.UDPMiddlewareInfo{
+					"ipallowlist1@myprovider": {
+						UDPMiddleware: &dynamic.UDPMiddleware{
+							IPAllowList: &dynamic.UDPIPAllowList{
+								SourceRange: []string{"127.0.0.1/32"},
+							},
+						},
+						UsedBy: []string{"bar@myprovider", "test@myprovider"},
+						Status: runtime.StatusEnabled,
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusOK,
+				jsonFile:   "testdata/udpmiddleware-ipallowlist1.json",
+			},
+		},
+		{
+			desc: "one udp middleware by id, that does not exist",
+			path: "/api/udp/middlewares/foo@myprovider",
+			conf: runtime.Configuration{
+				UDPMiddlewares: map[string]*runtime.UDPMiddlewareInfo{
+					"ipallowlist1@myprovider": {
+						UDPMiddleware: &dynamic.UDPMiddleware{
+							IPAllowList: &dynamic.UDPIPAllowList{
+								SourceRange: []string{"127.0.0.1/32"},
+							},
+						},
+						Status: runtime.StatusEnabled,
+					},
+				},
+			},
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			desc: "one udp middleware by id, but no config",
+			path: "/api/udp/middlewares/foo@myprovider",
+			conf: runtime.Configuration{},
+			expected: expected{
+				statusCode: http.StatusNotFound,
+			},
+		},
 	}
 
 	for _, test := range testCases {
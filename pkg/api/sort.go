@@ -352,6 +352,22 @@ func (m tcpMiddlewareRepresentation) status() string {
 	return m.Status
 }
 
+func (m udpMiddlewareRepresentation) name() string {
+	return m.Name
+}
+
+func (m udpMiddlewareRepresentation) resourceType() string {
+	return m.Type
+}
+
+func (m udpMiddlewareRepresentation) provider() string {
+	return m.Provider
+}
+
+func (m udpMiddlewareRepresentation) status() string {
+	return m.Status
+}
+
 func sortCertificates[T orderedCertificate](values url.Values, certificates []T) {
 	sortBy := values.Get(sortByParam)
 
@@ -40,13 +40,20 @@ type Middleware struct {
 	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty" export:"true"`
 	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty" export:"true"`
 	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty" export:"true"`
+	JWT               *JWT               `json:"jwt,omitempty" toml:"jwt,omitempty" yaml:"jwt,omitempty" export:"true"`
+	OIDC              *OIDC              `json:"oidc,omitempty" toml:"oidc,omitempty" yaml:"oidc,omitempty" export:"true"`
 	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty" export:"true"`
+	Cache             *Cache             `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty" export:"true"`
 	Compress          *Compress          `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty" export:"true"`
 	Retry             *Retry             `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty" export:"true"`
 	ContentType       *ContentType       `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	GrpcWeb           *GrpcWeb           `json:"grpcWeb,omitempty" toml:"grpcWeb,omitempty" yaml:"grpcWeb,omitempty" export:"true"`
+	GrpcTranscoder    *GrpcTranscoder    `json:"grpcTranscoder,omitempty" toml:"grpcTranscoder,omitempty" yaml:"grpcTranscoder,omitempty" export:"true"`
+	WAF               *WAF               `json:"waf,omitempty" toml:"waf,omitempty" yaml:"waf,omitempty" export:"true"`
+	BodyRewrite       *BodyRewrite       `json:"bodyRewrite,omitempty" toml:"bodyRewrite,omitempty" yaml:"bodyRewrite,omitempty" export:"true"`
+	DebugCapture      *DebugCapture      `json:"debugCapture,omitempty" toml:"debugCapture,omitempty" yaml:"debugCapture,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 
 	Plugin map[string]PluginConf `json:"plugin,omitempty" toml:"plugin,omitempty" yaml:"plugin,omitempty" export:"true"`
 
@@ -66,6 +73,33 @@ type Middleware struct {
 
 // +k8s:deepcopy-gen=true
 
+// GrpcTranscoder holds the gRPC-JSON transcoder middleware configuration.
+// This middleware converts HTTP/JSON requests to gRPC requests, according to the google.api.http annotations of the gRPC methods.
+// More info: https://doc.traefik.io/traefik/v3.7/reference/routing-configuration/http/middlewares/grpctranscoder/
+type GrpcTranscoder struct {
+	// DescriptorSetFile defines the path to the protobuf descriptor set of the gRPC services,
+	// as generated with protoc --include_imports --descriptor_set_out.
+	DescriptorSetFile string `json:"descriptorSetFile,omitempty" toml:"descriptorSetFile,omitempty" yaml:"descriptorSetFile,omitempty" export:"true"`
+	// Services defines the fully-qualified names of the gRPC services to transcode, e.g. helloworld.Greeter.
+	// Default: all the services of the descriptor set.
+	Services []string `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty" export:"true"`
+	// UseProtoNames defines whether the JSON responses use the protobuf field names instead of their lowerCamelCase names.
+	UseProtoNames bool `json:"useProtoNames,omitempty" toml:"useProtoNames,omitempty" yaml:"useProtoNames,omitempty" export:"true"`
+	// EmitUnpopulated defines whether the JSON responses include the fields with default values.
+	EmitUnpopulated bool `json:"emitUnpopulated,omitempty" toml:"emitUnpopulated,omitempty" yaml:"emitUnpopulated,omitempty" export:"true"`
+	// MaxRequestBodyBytes defines the maximum size of the JSON request bodies read to build the gRPC messages (in bytes).
+	// If the request exceeds the allowed size, it is not forwarded to the service, and the client gets a 413 (Request Entity Too Large) response.
+	// Default: 4194304 (4Mi), the default maximum message size of the gRPC servers. 0 means no maximum.
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes,omitempty" toml:"maxRequestBodyBytes,omitempty" yaml:"maxRequestBodyBytes,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a GrpcTranscoder.
+func (g *GrpcTranscoder) SetDefaults() {
+	g.MaxRequestBodyBytes = 4 * 1024 * 1024
+}
+
+// +k8s:deepcopy-gen=true
+
 // GrpcWeb holds the gRPC web middleware configuration.
 // This middleware converts a gRPC web request to an HTTP/2 gRPC request.
 type GrpcWeb struct {
@@ -123,6 +157,62 @@ type BasicAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// BodyRewrite holds the body rewrite middleware configuration.
+// This middleware rewrites the request and response bodies with regular expression substitutions and JSON operations.
+// More info: https://doc.traefik.io/traefik/v3.7/reference/routing-configuration/http/middlewares/bodyrewrite/
+type BodyRewrite struct {
+	// Request defines the rewriting of the request bodies.
+	Request *BodyRewriteRules `json:"request,omitempty" toml:"request,omitempty" yaml:"request,omitempty" export:"true"`
+	// Response defines the rewriting of the response bodies.
+	Response *BodyRewriteRules `json:"response,omitempty" toml:"response,omitempty" yaml:"response,omitempty" export:"true"`
+	// MaxRequestBodyBytes defines the maximum size of the request bodies buffered to be rewritten (in bytes).
+	// If the request exceeds the allowed size, it is not forwarded to the service, and the client gets a 413 (Request Entity Too Large) response.
+	// Default: 0 (no maximum).
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes,omitempty" toml:"maxRequestBodyBytes,omitempty" yaml:"maxRequestBodyBytes,omitempty" export:"true"`
+	// MaxResponseBodyBytes defines the maximum size of the response bodies buffered to be rewritten (in bytes).
+	// If the response exceeds the allowed size, it is not forwarded to the client. The client gets a 500 (Internal Server Error) response instead.
+	// Default: 0 (no maximum).
+	MaxResponseBodyBytes int64 `json:"maxResponseBodyBytes,omitempty" toml:"maxResponseBodyBytes,omitempty" yaml:"maxResponseBodyBytes,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// BodyRewriteRules holds the rewriting rules of the request or response bodies.
+type BodyRewriteRules struct {
+	// ContentTypes defines the media types of the bodies to rewrite, e.g. text/html or application/*.
+	// Default: all the media types.
+	ContentTypes []string `json:"contentTypes,omitempty" toml:"contentTypes,omitempty" yaml:"contentTypes,omitempty" export:"true"`
+	// JSON defines the operations applied, in order, to the JSON bodies.
+	// They are applied before the regular expression substitutions.
+	JSON []BodyRewriteJSON `json:"json,omitempty" toml:"json,omitempty" yaml:"json,omitempty" export:"true"`
+	// Replacements defines the regular expression substitutions applied, in order, to the bodies.
+	Replacements []BodyRewriteReplacement `json:"replacements,omitempty" toml:"replacements,omitempty" yaml:"replacements,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// BodyRewriteJSON holds a JSON operation of the body rewrite middleware.
+type BodyRewriteJSON struct {
+	// Path defines the JSONPath of the values to set or delete, e.g. $.user.roles[0] or $.items[*].price.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty" export:"true"`
+	// Value defines the JSON-encoded value to set at the path, e.g. "\"foo\"", 42 or {"enabled":true}.
+	Value string `json:"value,omitempty" toml:"value,omitempty" yaml:"value,omitempty" export:"true"`
+	// Delete defines whether the values at the path are deleted.
+	Delete bool `json:"delete,omitempty" toml:"delete,omitempty" yaml:"delete,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// BodyRewriteReplacement holds a regular expression substitution of the body rewrite middleware.
+type BodyRewriteReplacement struct {
+	// Regex defines the regular expression to match in the body.
+	Regex string `json:"regex,omitempty" toml:"regex,omitempty" yaml:"regex,omitempty" export:"true"`
+	// Replacement defines the replacement of the matches, which can reference the capture groups, e.g. $1.
+	Replacement string `json:"replacement,omitempty" toml:"replacement,omitempty" yaml:"replacement,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Buffering holds the buffering middleware configuration.
 // This middleware retries or limits the size of requests that can be forwarded to backends.
 // More info: https://doc.traefik.io/traefik/v3.7/middlewares/http/buffering/#maxrequestbodybytes
@@ -153,6 +243,42 @@ type Buffering struct {
 
 // +k8s:deepcopy-gen=true
 
+// Cache holds the cache middleware configuration.
+// This middleware stores the responses of the services and serves them back to the clients,
+// following the HTTP caching semantics (RFC 9111).
+type Cache struct {
+	// MaxSize defines the maximum size (in bytes) of the stored responses.
+	// When the limit is reached, the least recently used responses are evicted.
+	// Default: 67108864 (64Mi).
+	MaxSize int64 `json:"maxSize,omitempty" toml:"maxSize,omitempty" yaml:"maxSize,omitempty" export:"true"`
+	// MaxEntrySize defines the maximum size (in bytes) of a response body to be stored.
+	// Larger responses are forwarded to the client without being stored.
+	// Default: 1048576 (1Mi).
+	MaxEntrySize int64 `json:"maxEntrySize,omitempty" toml:"maxEntrySize,omitempty" yaml:"maxEntrySize,omitempty" export:"true"`
+	// DefaultTTL defines the freshness lifetime applied to the cacheable responses which do not define an explicit expiration time,
+	// and which cannot be heuristically computed from the Last-Modified header.
+	// Default: 0 (such responses are stored but always revalidated).
+	DefaultTTL ptypes.Duration `json:"defaultTTL,omitempty" toml:"defaultTTL,omitempty" yaml:"defaultTTL,omitempty" export:"true"`
+	// Disk defines the configuration to store the responses on disk instead of in memory.
+	Disk *CacheDisk `json:"disk,omitempty" toml:"disk,omitempty" yaml:"disk,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Cache.
+func (c *Cache) SetDefaults() {
+	c.MaxSize = 64 * 1024 * 1024
+	c.MaxEntrySize = 1024 * 1024
+}
+
+// +k8s:deepcopy-gen=true
+
+// CacheDisk holds the disk storage configuration of the cache middleware.
+type CacheDisk struct {
+	// Path defines the directory where the responses are stored.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Chain holds the chain middleware configuration.
 // This middleware enables to define reusable combinations of other pieces of middleware.
 type Chain struct {
@@ -212,6 +338,35 @@ func (c *Compress) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// DebugCapture holds the debug capture middleware configuration.
+// This middleware records the headers and the beginning of the bodies of the requests and responses,
+// to troubleshoot the exchanges with a client from the API.
+// More info: https://doc.traefik.io/traefik/v3.7/reference/routing-configuration/http/middlewares/debugcapture/
+type DebugCapture struct {
+	// Rule defines the requests to capture, with the same syntax as the router rules, e.g. Header(`User-Agent`, `curl/8.5.0`).
+	// Default: all the requests.
+	Rule string `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty" export:"true"`
+	// MaxBodyBytes defines the maximum number of bytes recorded from each request and response body.
+	// 0 records the headers only.
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty" toml:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty" export:"true"`
+	// MaxRecords defines the number of records kept by the middleware, the oldest being dropped first.
+	MaxRecords int `json:"maxRecords,omitempty" toml:"maxRecords,omitempty" yaml:"maxRecords,omitempty" export:"true"`
+	// RedactHeaders defines the headers whose values are redacted in the records,
+	// in addition to the ones carrying credentials, such as Authorization or Cookie.
+	RedactHeaders []string `json:"redactHeaders,omitempty" toml:"redactHeaders,omitempty" yaml:"redactHeaders,omitempty" export:"true"`
+	// RedactQueryParams defines the query parameters whose values are redacted in the records,
+	// in addition to the ones carrying credentials, such as access_token or api_key.
+	RedactQueryParams []string `json:"redactQueryParams,omitempty" toml:"redactQueryParams,omitempty" yaml:"redactQueryParams,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a DebugCapture.
+func (d *DebugCapture) SetDefaults() {
+	d.MaxBodyBytes = 4096
+	d.MaxRecords = 100
+}
+
+// +k8s:deepcopy-gen=true
+
 // DigestAuth holds the digest auth middleware configuration.
 // This middleware restricts access to your services to known users.
 // More info: https://doc.traefik.io/traefik/v3.7/middlewares/http/digestauth/
@@ -573,6 +728,110 @@ type InFlightReq struct {
 
 // +k8s:deepcopy-gen=true
 
+// JWT holds the JWT middleware configuration.
+// This middleware validates the JSON Web Token given as a bearer token in the Authorization header of the requests.
+type JWT struct {
+	// JWKSURL defines the URL of the JSON Web Key Set used to verify the signature of the tokens.
+	JWKSURL string `json:"jwksUrl,omitempty" toml:"jwksUrl,omitempty" yaml:"jwksUrl,omitempty"`
+	// TLS defines the configuration used to secure the connection to the JWKS endpoint.
+	TLS *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	// JWKSRefreshInterval defines how long the JSON Web Key Set is cached before being fetched again.
+	// The key set is also fetched again when a token is signed with an unknown key.
+	JWKSRefreshInterval ptypes.Duration `json:"jwksRefreshInterval,omitempty" toml:"jwksRefreshInterval,omitempty" yaml:"jwksRefreshInterval,omitempty" export:"true"`
+	// Issuer defines the expected value of the iss claim.
+	Issuer string `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty" export:"true"`
+	// Audience defines the accepted values of the aud claim, the token must be intended for at least one of them.
+	Audience []string `json:"audience,omitempty" toml:"audience,omitempty" yaml:"audience,omitempty" export:"true"`
+	// AllowMissingExpiration defines whether the tokens without an exp claim are accepted.
+	// By default, they are rejected, as they would be valid forever.
+	AllowMissingExpiration bool `json:"allowMissingExpiration,omitempty" toml:"allowMissingExpiration,omitempty" yaml:"allowMissingExpiration,omitempty" export:"true"`
+	// Claims defines the expression the claims of the token must match for the request to be authorized.
+	// More info: https://doc.traefik.io/traefik/v3.7/reference/routing-configuration/http/middlewares/builtin-jwt/#claims
+	Claims string `json:"claims,omitempty" toml:"claims,omitempty" yaml:"claims,omitempty" export:"true"`
+	// ForwardHeaders defines the headers to set on the forwarded request, and the claims used as their values.
+	// Any existing conflicting header is replaced.
+	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty" toml:"forwardHeaders,omitempty" yaml:"forwardHeaders,omitempty" export:"true"`
+	// ForwardAuthorization defines whether to forward the Authorization header to the service.
+	ForwardAuthorization bool `json:"forwardAuthorization,omitempty" toml:"forwardAuthorization,omitempty" yaml:"forwardAuthorization,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a JWT.
+func (j *JWT) SetDefaults() {
+	j.JWKSRefreshInterval = ptypes.Duration(10 * time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
+// OIDC holds the OpenID Connect middleware configuration.
+// This middleware authenticates the users with an OpenID Connect provider, using the authorization code flow,
+// and keeps their session in an encrypted cookie.
+type OIDC struct {
+	// Issuer defines the URL of the OpenID Connect provider.
+	// The provider endpoints are discovered from its /.well-known/openid-configuration document.
+	Issuer string `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// TLS defines the configuration used to secure the connection to the OpenID Connect provider.
+	TLS *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	// ClientID defines the client identifier registered with the OpenID Connect provider.
+	ClientID string `json:"clientID,omitempty" toml:"clientID,omitempty" yaml:"clientID,omitempty"`
+	// ClientSecret defines the client secret registered with the OpenID Connect provider.
+	ClientSecret string `json:"clientSecret,omitempty" toml:"clientSecret,omitempty" yaml:"clientSecret,omitempty" loggable:"false"`
+	// Scopes defines the scopes to request, in addition to the openid scope.
+	Scopes []string `json:"scopes,omitempty" toml:"scopes,omitempty" yaml:"scopes,omitempty" export:"true"`
+	// RedirectURL defines the URL, or the path on the requested host, of the callback handled by the middleware.
+	// The router using the middleware must match it.
+	RedirectURL string `json:"redirectURL,omitempty" toml:"redirectURL,omitempty" yaml:"redirectURL,omitempty" export:"true"`
+	// LogoutPath defines the path on which the session is removed and the user is logged out from the OpenID Connect provider.
+	LogoutPath string `json:"logoutPath,omitempty" toml:"logoutPath,omitempty" yaml:"logoutPath,omitempty" export:"true"`
+	// Session defines the session cookie configuration.
+	Session *OIDCSession `json:"session,omitempty" toml:"session,omitempty" yaml:"session,omitempty" export:"true"`
+	// ForwardHeaders defines the headers to set on the forwarded request, and the ID token claims used as their values.
+	// Any existing conflicting header is replaced.
+	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty" toml:"forwardHeaders,omitempty" yaml:"forwardHeaders,omitempty" export:"true"`
+	// ForwardAccessToken defines whether to forward the access token to the service, as a bearer token in the Authorization header.
+	ForwardAccessToken bool `json:"forwardAccessToken,omitempty" toml:"forwardAccessToken,omitempty" yaml:"forwardAccessToken,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an OIDC.
+func (o *OIDC) SetDefaults() {
+	o.Scopes = []string{"profile", "email"}
+	o.RedirectURL = "/oidc/callback"
+	o.Session = &OIDCSession{}
+	o.Session.SetDefaults()
+}
+
+// +k8s:deepcopy-gen=true
+
+// OIDCSession holds the OpenID Connect session cookie configuration.
+type OIDCSession struct {
+	// Name defines the cookie name.
+	// When not provided, a name is generated from the middleware name.
+	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
+	// Secret defines the secret used to encrypt the cookie, it must be at least 32 characters long.
+	Secret string `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" loggable:"false"`
+	// Secure defines whether the cookie can only be transmitted over an encrypted connection (i.e. HTTPS).
+	Secure bool `json:"secure,omitempty" toml:"secure,omitempty" yaml:"secure,omitempty" export:"true"`
+	// SameSite defines the same site policy.
+	// More info: https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Set-Cookie/SameSite
+	// +kubebuilder:validation:Enum=none;lax;strict;None;Lax;Strict
+	SameSite string `json:"sameSite,omitempty" toml:"sameSite,omitempty" yaml:"sameSite,omitempty" export:"true"`
+	// MaxAge defines the number of seconds until the session expires, whether its tokens can be refreshed or not.
+	MaxAge int `json:"maxAge,omitempty" toml:"maxAge,omitempty" yaml:"maxAge,omitempty" export:"true"`
+	// Path defines the path that must exist in the requested URL for the browser to send the cookie.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty" export:"true"`
+	// Domain defines the host to which the cookie will be sent.
+	Domain string `json:"domain,omitempty" toml:"domain,omitempty" yaml:"domain,omitempty"`
+}
+
+// SetDefaults sets the default values on an OIDCSession.
+func (o *OIDCSession) SetDefaults() {
+	o.Secure = true
+	o.SameSite = "lax"
+	o.MaxAge = 24 * 60 * 60
+	o.Path = "/"
+}
+
+// +k8s:deepcopy-gen=true
+
 // PassTLSClientCert holds the pass TLS client cert middleware configuration.
 // This middleware adds the selected data from the passed client TLS certificate to a header.
 // More info: https://doc.traefik.io/traefik/v3.7/middlewares/http/passtlsclientcert/
@@ -870,6 +1129,16 @@ type TLSClientCertificateSubjectDNInfo struct {
 
 // +k8s:deepcopy-gen=true
 
+// WAF holds the WAF middleware configuration.
+// This middleware evaluates rules written in a subset of the ModSecurity rule language (SecLang) against the requests.
+// More info: https://doc.traefik.io/traefik/v3.7/reference/routing-configuration/http/middlewares/builtin-waf/
+type WAF struct {
+	// Directives defines the SecLang directives, such as SecRuleEngine, SecDefaultAction and SecRule, evaluated in order.
+	Directives []string `json:"directives,omitempty" toml:"directives,omitempty" yaml:"directives,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Users holds a list of users.
 type Users []string
 
@@ -33,6 +33,8 @@ type TCPModel struct {
 type TCPService struct {
 	LoadBalancer *TCPServersLoadBalancer `json:"loadBalancer,omitempty" toml:"loadBalancer,omitempty" yaml:"loadBalancer,omitempty" export:"true"`
 	Weighted     *TCPWeightedRoundRobin  `json:"weighted,omitempty" toml:"weighted,omitempty" yaml:"weighted,omitempty" label:"-" export:"true"`
+	Failover     *TCPFailover            `json:"failover,omitempty" toml:"failover,omitempty" yaml:"failover,omitempty" label:"-" export:"true"`
+	Mirroring    *TCPMirroring           `json:"mirroring,omitempty" toml:"mirroring,omitempty" yaml:"mirroring,omitempty" label:"-" export:"true"`
 }
 
 // Merge merges another TCPService into this one.
@@ -69,6 +71,32 @@ func (w *TCPWRRService) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// TCPFailover forwards the connections to a fallback service when the main service is down.
+type TCPFailover struct {
+	Service     string       `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
+	Fallback    string       `json:"fallback,omitempty" toml:"fallback,omitempty" yaml:"fallback,omitempty" export:"true"`
+	HealthCheck *HealthCheck `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPMirroring forwards the connections to a service, and copies the data sent by the clients to mirror services.
+type TCPMirroring struct {
+	Service     string             `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
+	Mirrors     []TCPMirrorService `json:"mirrors,omitempty" toml:"mirrors,omitempty" yaml:"mirrors,omitempty" export:"true"`
+	HealthCheck *HealthCheck       `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPMirrorService is a reference to a tcp service receiving a copy of a percentage of the connections.
+type TCPMirrorService struct {
+	Name    string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
+	Percent int    `json:"percent,omitempty" toml:"percent,omitempty" yaml:"percent,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // TCPRouter holds the router configuration.
 type TCPRouter struct {
 	EntryPoints []string `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
@@ -91,12 +119,29 @@ type RouterTCPTLSConfig struct {
 	Domains      []types.Domain `json:"domains,omitempty" toml:"domains,omitempty" yaml:"domains,omitempty" export:"true"`
 }
 
+// TCPBalancerStrategy is the strategy used to balance the connections between the servers of a TCP load-balancer.
+type TCPBalancerStrategy string
+
+const (
+	// TCPBalancerStrategyWRR is the weighted round-robin strategy.
+	TCPBalancerStrategyWRR TCPBalancerStrategy = "wrr"
+	// TCPBalancerStrategyLeastConn is the weighted least-connections strategy.
+	TCPBalancerStrategyLeastConn TCPBalancerStrategy = "leastconn"
+	// TCPBalancerStrategyP2C is the power of two choices strategy, based on the number of active connections.
+	TCPBalancerStrategyP2C TCPBalancerStrategy = "p2c"
+	// TCPBalancerStrategyHRWClientIP is the highest random weight strategy, hashing the client IP.
+	TCPBalancerStrategyHRWClientIP TCPBalancerStrategy = "hrwclientip"
+	// TCPBalancerStrategyHRWSNI is the highest random weight strategy, hashing the TLS server name.
+	TCPBalancerStrategyHRWSNI TCPBalancerStrategy = "hrwsni"
+)
+
 // +k8s:deepcopy-gen=true
 
 // TCPServersLoadBalancer holds the LoadBalancerService configuration.
 type TCPServersLoadBalancer struct {
-	Servers          []TCPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
-	ServersTransport string      `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	Servers          []TCPServer         `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
+	Strategy         TCPBalancerStrategy `json:"strategy,omitempty" toml:"strategy,omitempty" yaml:"strategy,omitempty" export:"true"`
+	ServersTransport string              `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
 	// ProxyProtocol holds the PROXY Protocol configuration.
 	//
 	// Deprecated: use ServersTransport to configure ProxyProtocol instead.
@@ -168,6 +213,8 @@ type ProxyProtocol struct {
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=2
 	Version int `description:"Defines the PROXY Protocol version to use." json:"version,omitempty" toml:"version,omitempty" yaml:"version,omitempty" export:"true"`
+	// TLSInfo defines whether to send the TLS information and the client certificate identity in the PROXY Protocol version 2 header.
+	TLSInfo bool `description:"Defines whether to send the TLS information and the client certificate identity in the PROXY Protocol version 2 header." json:"tlsInfo,omitempty" toml:"tlsInfo,omitempty" yaml:"tlsInfo,omitempty" export:"true"`
 }
 
 // SetDefaults Default values for a ProxyProtocol.
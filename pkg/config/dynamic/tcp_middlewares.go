@@ -1,5 +1,11 @@
 package dynamic
 
+import (
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+)
+
 // +k8s:deepcopy-gen=true
 
 // TCPMiddleware holds the TCPMiddleware configuration.
@@ -8,6 +14,7 @@ type TCPMiddleware struct {
 	// Deprecated: please use IPAllowList instead.
 	IPWhiteList *TCPIPWhiteList `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty" export:"true"`
 	IPAllowList *TCPIPAllowList `json:"ipAllowList,omitempty" toml:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty" export:"true"`
+	RateLimit   *TCPRateLimit   `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -42,3 +49,52 @@ type TCPIPAllowList struct {
 	// SourceRange defines the allowed IPs (or ranges of allowed IPs by using CIDR notation).
 	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
 }
+
+// +k8s:deepcopy-gen=true
+
+// TCPRateLimit holds the TCP RateLimit middleware configuration.
+// This middleware limits the rate at which a client IP opens connections,
+// and the rate at which the connections of a client IP transfer bytes.
+type TCPRateLimit struct {
+	// Average is the maximum rate, by default in connections/s, allowed for a client IP.
+	// It defaults to 0, which means no rate limiting of the connections.
+	// The rate is actually defined by dividing Average by Period.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	// Burst is the maximum number of connections allowed to be opened in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+	// Bytes limits the rate at which the connections of a client IP transfer bytes, in both directions.
+	Bytes *TCPByteRateLimit `json:"bytes,omitempty" toml:"bytes,omitempty" yaml:"bytes,omitempty" export:"true"`
+	// Redis stores the configuration for using Redis as a bucket in the rate-limiting algorithm.
+	// If not specified, Traefik will default to an in-memory bucket for the algorithm.
+	Redis *Redis `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a TCPRateLimit.
+func (r *TCPRateLimit) SetDefaults() {
+	r.Burst = 1
+	r.Period = ptypes.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPByteRateLimit holds the byte rate limiting configuration of the TCP RateLimit middleware.
+type TCPByteRateLimit struct {
+	// Average is the maximum rate, by default in bytes/s, allowed for the connections of a client IP.
+	// The rate is actually defined by dividing Average by Period.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	// Burst is the maximum number of bytes allowed to be transferred in the same arbitrarily small period of time.
+	// It defaults to Average.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a TCPByteRateLimit.
+func (r *TCPByteRateLimit) SetDefaults() {
+	r.Period = ptypes.Duration(time.Second)
+}
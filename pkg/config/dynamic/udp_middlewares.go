@@ -0,0 +1,89 @@
+package dynamic
+
+import (
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+)
+
+// +k8s:deepcopy-gen=true
+
+// UDPMiddleware holds the UDPMiddleware configuration.
+type UDPMiddleware struct {
+	InFlightSession *UDPInFlightSession `json:"inFlightSession,omitempty" toml:"inFlightSession,omitempty" yaml:"inFlightSession,omitempty" export:"true"`
+	IPAllowList     *UDPIPAllowList     `json:"ipAllowList,omitempty" toml:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty" export:"true"`
+	RateLimit       *UDPRateLimit       `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// UDPInFlightSession holds the UDP InFlightSession middleware configuration.
+// This middleware prevents services from being overwhelmed with high load,
+// by limiting the number of allowed simultaneous sessions for one IP.
+type UDPInFlightSession struct {
+	// Amount defines the maximum amount of allowed simultaneous sessions.
+	// The middleware closes the session if there are already amount sessions opened.
+	Amount int64 `json:"amount,omitempty" toml:"amount,omitempty" yaml:"amount,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// UDPIPAllowList holds the UDP IPAllowList middleware configuration.
+// This middleware limits the allowed sessions based on the client IP.
+type UDPIPAllowList struct {
+	// SourceRange defines the allowed IPs (or ranges of allowed IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// UDPRateLimit holds the UDP RateLimit middleware configuration.
+// This middleware limits the rate at which a client IP opens sessions,
+// and the rate at which the sessions of a client IP send datagrams.
+type UDPRateLimit struct {
+	// Average is the maximum rate, by default in sessions/s, allowed for a client IP.
+	// It defaults to 0, which means no rate limiting of the sessions.
+	// The rate is actually defined by dividing Average by Period.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	// Burst is the maximum number of sessions allowed to be opened in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+	// Packets limits the rate at which the sessions of a client IP send datagrams.
+	Packets *UDPPacketRateLimit `json:"packets,omitempty" toml:"packets,omitempty" yaml:"packets,omitempty" export:"true"`
+	// Redis stores the configuration for using Redis as a bucket in the rate-limiting algorithm.
+	// If not specified, Traefik will default to an in-memory bucket for the algorithm.
+	Redis *Redis `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a UDPRateLimit.
+func (r *UDPRateLimit) SetDefaults() {
+	r.Burst = 1
+	r.Period = ptypes.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// UDPPacketRateLimit holds the datagram rate limiting configuration of the UDP RateLimit middleware.
+type UDPPacketRateLimit struct {
+	// Average is the maximum rate, by default in datagrams/s, allowed for the sessions of a client IP,
+	// or for each session when PerSession is true.
+	// The rate is actually defined by dividing Average by Period.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	// Burst is the maximum number of datagrams allowed to be sent in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+	// PerSession applies the rate to each session, instead of sharing it between all the sessions of a client IP.
+	PerSession bool `json:"perSession,omitempty" toml:"perSession,omitempty" yaml:"perSession,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a UDPPacketRateLimit.
+func (r *UDPPacketRateLimit) SetDefaults() {
+	r.Burst = 1
+	r.Period = ptypes.Duration(time.Second)
+}
@@ -132,6 +132,95 @@ func (in *BasicAuth) DeepCopy() *BasicAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyRewrite) DeepCopyInto(out *BodyRewrite) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(BodyRewriteRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(BodyRewriteRules)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyRewrite.
+func (in *BodyRewrite) DeepCopy() *BodyRewrite {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyRewrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyRewriteJSON) DeepCopyInto(out *BodyRewriteJSON) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyRewriteJSON.
+func (in *BodyRewriteJSON) DeepCopy() *BodyRewriteJSON {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyRewriteJSON)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyRewriteReplacement) DeepCopyInto(out *BodyRewriteReplacement) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyRewriteReplacement.
+func (in *BodyRewriteReplacement) DeepCopy() *BodyRewriteReplacement {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyRewriteReplacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyRewriteRules) DeepCopyInto(out *BodyRewriteRules) {
+	*out = *in
+	if in.ContentTypes != nil {
+		in, out := &in.ContentTypes, &out.ContentTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JSON != nil {
+		in, out := &in.JSON, &out.JSON
+		*out = make([]BodyRewriteJSON, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replacements != nil {
+		in, out := &in.Replacements, &out.Replacements
+		*out = make([]BodyRewriteReplacement, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyRewriteRules.
+func (in *BodyRewriteRules) DeepCopy() *BodyRewriteRules {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyRewriteRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Buffering) DeepCopyInto(out *Buffering) {
 	*out = *in
@@ -148,6 +237,43 @@ func (in *Buffering) DeepCopy() *Buffering {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cache) DeepCopyInto(out *Cache) {
+	*out = *in
+	if in.Disk != nil {
+		in, out := &in.Disk, &out.Disk
+		*out = new(CacheDisk)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cache.
+func (in *Cache) DeepCopy() *Cache {
+	if in == nil {
+		return nil
+	}
+	out := new(Cache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheDisk) DeepCopyInto(out *CacheDisk) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheDisk.
+func (in *CacheDisk) DeepCopy() *CacheDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Chain) DeepCopyInto(out *Chain) {
 	*out = *in
@@ -345,6 +471,32 @@ func (in *Cookie) DeepCopy() *Cookie {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DebugCapture) DeepCopyInto(out *DebugCapture) {
+	*out = *in
+	if in.RedactHeaders != nil {
+		in, out := &in.RedactHeaders, &out.RedactHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RedactQueryParams != nil {
+		in, out := &in.RedactQueryParams, &out.RedactQueryParams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DebugCapture.
+func (in *DebugCapture) DeepCopy() *DebugCapture {
+	if in == nil {
+		return nil
+	}
+	out := new(DebugCapture)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DigestAuth) DeepCopyInto(out *DigestAuth) {
 	*out = *in
@@ -569,6 +721,27 @@ func (in *GRPCStatus) DeepCopy() *GRPCStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrpcTranscoder) DeepCopyInto(out *GrpcTranscoder) {
+	*out = *in
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrpcTranscoder.
+func (in *GrpcTranscoder) DeepCopy() *GrpcTranscoder {
+	if in == nil {
+		return nil
+	}
+	out := new(GrpcTranscoder)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrpcWeb) DeepCopyInto(out *GrpcWeb) {
 	*out = *in
@@ -988,6 +1161,39 @@ func (in *InFlightReq) DeepCopy() *InFlightReq {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWT) DeepCopyInto(out *JWT) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ClientTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Audience != nil {
+		in, out := &in.Audience, &out.Audience
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForwardHeaders != nil {
+		in, out := &in.ForwardHeaders, &out.ForwardHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWT.
+func (in *JWT) DeepCopy() *JWT {
+	if in == nil {
+		return nil
+	}
+	out := new(JWT)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernetesIngressMetadata) DeepCopyInto(out *KubernetesIngressMetadata) {
 	*out = *in
@@ -1118,11 +1324,26 @@ func (in *Middleware) DeepCopyInto(out *Middleware) {
 		*out = new(InFlightReq)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = new(JWT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDC)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Buffering != nil {
 		in, out := &in.Buffering, &out.Buffering
 		*out = new(Buffering)
 		**out = **in
 	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(Cache)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CircuitBreaker != nil {
 		in, out := &in.CircuitBreaker, &out.CircuitBreaker
 		*out = new(CircuitBreaker)
@@ -1153,6 +1374,26 @@ func (in *Middleware) DeepCopyInto(out *Middleware) {
 		*out = new(GrpcWeb)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GrpcTranscoder != nil {
+		in, out := &in.GrpcTranscoder, &out.GrpcTranscoder
+		*out = new(GrpcTranscoder)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WAF != nil {
+		in, out := &in.WAF, &out.WAF
+		*out = new(WAF)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BodyRewrite != nil {
+		in, out := &in.BodyRewrite, &out.BodyRewrite
+		*out = new(BodyRewrite)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DebugCapture != nil {
+		in, out := &in.DebugCapture, &out.DebugCapture
+		*out = new(DebugCapture)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Plugin != nil {
 		in, out := &in.Plugin, &out.Plugin
 		*out = make(map[string]PluginConf, len(*in))
@@ -1302,6 +1543,60 @@ func (in *Model) DeepCopy() *Model {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDC) DeepCopyInto(out *OIDC) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ClientTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Session != nil {
+		in, out := &in.Session, &out.Session
+		*out = new(OIDCSession)
+		**out = **in
+	}
+	if in.ForwardHeaders != nil {
+		in, out := &in.ForwardHeaders, &out.ForwardHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDC.
+func (in *OIDC) DeepCopy() *OIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCSession) DeepCopyInto(out *OIDCSession) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCSession.
+func (in *OIDCSession) DeepCopy() *OIDCSession {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCSession)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObservabilityMetadata) DeepCopyInto(out *ObservabilityMetadata) {
 	*out = *in
@@ -2081,6 +2376,22 @@ func (in *StripPrefixRegex) DeepCopy() *StripPrefixRegex {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPByteRateLimit) DeepCopyInto(out *TCPByteRateLimit) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPByteRateLimit.
+func (in *TCPByteRateLimit) DeepCopy() *TCPByteRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPByteRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPConfiguration) DeepCopyInto(out *TCPConfiguration) {
 	*out = *in
@@ -2172,6 +2483,27 @@ func (in *TCPConfiguration) DeepCopy() *TCPConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPFailover) DeepCopyInto(out *TCPFailover) {
+	*out = *in
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(HealthCheck)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPFailover.
+func (in *TCPFailover) DeepCopy() *TCPFailover {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPFailover)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPIPAllowList) DeepCopyInto(out *TCPIPAllowList) {
 	*out = *in
@@ -2248,6 +2580,11 @@ func (in *TCPMiddleware) DeepCopyInto(out *TCPMiddleware) {
 		*out = new(TCPIPAllowList)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(TCPRateLimit)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2261,6 +2598,48 @@ func (in *TCPMiddleware) DeepCopy() *TCPMiddleware {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPMirrorService) DeepCopyInto(out *TCPMirrorService) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPMirrorService.
+func (in *TCPMirrorService) DeepCopy() *TCPMirrorService {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPMirrorService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPMirroring) DeepCopyInto(out *TCPMirroring) {
+	*out = *in
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]TCPMirrorService, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(HealthCheck)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPMirroring.
+func (in *TCPMirroring) DeepCopy() *TCPMirroring {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPMirroring)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPModel) DeepCopyInto(out *TCPModel) {
 	*out = *in
@@ -2277,6 +2656,32 @@ func (in *TCPModel) DeepCopy() *TCPModel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPRateLimit) DeepCopyInto(out *TCPRateLimit) {
+	*out = *in
+	if in.Bytes != nil {
+		in, out := &in.Bytes, &out.Bytes
+		*out = new(TCPByteRateLimit)
+		**out = **in
+	}
+	if in.Redis != nil {
+		in, out := &in.Redis, &out.Redis
+		*out = new(Redis)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPRateLimit.
+func (in *TCPRateLimit) DeepCopy() *TCPRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPRouter) DeepCopyInto(out *TCPRouter) {
 	*out = *in
@@ -2420,6 +2825,16 @@ func (in *TCPService) DeepCopyInto(out *TCPService) {
 		*out = new(TCPWeightedRoundRobin)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = new(TCPFailover)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mirroring != nil {
+		in, out := &in.Mirroring, &out.Mirroring
+		*out = new(TCPMirroring)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2650,6 +3065,21 @@ func (in *UDPConfiguration) DeepCopyInto(out *UDPConfiguration) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.Middlewares != nil {
+		in, out := &in.Middlewares, &out.Middlewares
+		*out = make(map[string]*UDPMiddleware, len(*in))
+		for key, val := range *in {
+			var outVal *UDPMiddleware
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(UDPMiddleware)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	return
 }
 
@@ -2663,6 +3093,116 @@ func (in *UDPConfiguration) DeepCopy() *UDPConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UDPIPAllowList) DeepCopyInto(out *UDPIPAllowList) {
+	*out = *in
+	if in.SourceRange != nil {
+		in, out := &in.SourceRange, &out.SourceRange
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UDPIPAllowList.
+func (in *UDPIPAllowList) DeepCopy() *UDPIPAllowList {
+	if in == nil {
+		return nil
+	}
+	out := new(UDPIPAllowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UDPInFlightSession) DeepCopyInto(out *UDPInFlightSession) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UDPInFlightSession.
+func (in *UDPInFlightSession) DeepCopy() *UDPInFlightSession {
+	if in == nil {
+		return nil
+	}
+	out := new(UDPInFlightSession)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UDPMiddleware) DeepCopyInto(out *UDPMiddleware) {
+	*out = *in
+	if in.InFlightSession != nil {
+		in, out := &in.InFlightSession, &out.InFlightSession
+		*out = new(UDPInFlightSession)
+		**out = **in
+	}
+	if in.IPAllowList != nil {
+		in, out := &in.IPAllowList, &out.IPAllowList
+		*out = new(UDPIPAllowList)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(UDPRateLimit)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UDPMiddleware.
+func (in *UDPMiddleware) DeepCopy() *UDPMiddleware {
+	if in == nil {
+		return nil
+	}
+	out := new(UDPMiddleware)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UDPPacketRateLimit) DeepCopyInto(out *UDPPacketRateLimit) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UDPPacketRateLimit.
+func (in *UDPPacketRateLimit) DeepCopy() *UDPPacketRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(UDPPacketRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UDPRateLimit) DeepCopyInto(out *UDPRateLimit) {
+	*out = *in
+	if in.Packets != nil {
+		in, out := &in.Packets, &out.Packets
+		*out = new(UDPPacketRateLimit)
+		**out = **in
+	}
+	if in.Redis != nil {
+		in, out := &in.Redis, &out.Redis
+		*out = new(Redis)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UDPRateLimit.
+func (in *UDPRateLimit) DeepCopy() *UDPRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(UDPRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UDPRouter) DeepCopyInto(out *UDPRouter) {
 	*out = *in
@@ -2671,6 +3211,11 @@ func (in *UDPRouter) DeepCopyInto(out *UDPRouter) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Middlewares != nil {
+		in, out := &in.Middlewares, &out.Middlewares
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2700,6 +3245,27 @@ func (in *UDPServer) DeepCopy() *UDPServer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UDPServerHealthCheck) DeepCopyInto(out *UDPServerHealthCheck) {
+	*out = *in
+	if in.UnhealthyInterval != nil {
+		in, out := &in.UnhealthyInterval, &out.UnhealthyInterval
+		*out = new(paersertypes.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UDPServerHealthCheck.
+func (in *UDPServerHealthCheck) DeepCopy() *UDPServerHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(UDPServerHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UDPServersLoadBalancer) DeepCopyInto(out *UDPServersLoadBalancer) {
 	*out = *in
@@ -2708,6 +3274,11 @@ func (in *UDPServersLoadBalancer) DeepCopyInto(out *UDPServersLoadBalancer) {
 		*out = make([]UDPServer, len(*in))
 		copy(*out, *in)
 	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(UDPServerHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2778,6 +3349,11 @@ func (in *UDPWeightedRoundRobin) DeepCopyInto(out *UDPWeightedRoundRobin) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(HealthCheck)
+		**out = **in
+	}
 	return
 }
 
@@ -2865,6 +3441,27 @@ func (in Users) DeepCopy() Users {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WAF) DeepCopyInto(out *WAF) {
+	*out = *in
+	if in.Directives != nil {
+		in, out := &in.Directives, &out.Directives
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WAF.
+func (in *WAF) DeepCopy() *WAF {
+	if in == nil {
+		return nil
+	}
+	out := new(WAF)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WRRService) DeepCopyInto(out *WRRService) {
 	*out = *in
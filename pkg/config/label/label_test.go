@@ -234,6 +234,8 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.tcp.services.Service1.loadbalancer.proxyProtocol":         "true",
 		"traefik.tcp.services.Service1.loadbalancer.serversTransport":      "foo",
 
+		"traefik.udp.routers.Router0.rule":                       "foobar",
+		"traefik.udp.routers.Router0.priority":                   "42",
 		"traefik.udp.routers.Router0.entrypoints":                "foobar, fiibar",
 		"traefik.udp.routers.Router0.service":                    "foobar",
 		"traefik.udp.routers.Router1.entrypoints":                "foobar, fiibar",
@@ -325,7 +327,9 @@ func TestDecodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 				},
 				"Router1": {
 					EntryPoints: []string{
@@ -868,7 +872,9 @@ func TestEncodeConfiguration(t *testing.T) {
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
+					Service:  "foobar",
+					Rule:     "foobar",
+					Priority: 42,
 				},
 				"Router1": {
 					EntryPoints: []string{
@@ -1567,8 +1573,11 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.TLS.Stores.default.DefaultGeneratedCert.Domain.Main": "foobar",
 		"traefik.TLS.Stores.default.DefaultGeneratedCert.Domain.SANs": "foobar, fiibar",
 
+		"traefik.UDP.Routers.Router0.Rule":                       "foobar",
+		"traefik.UDP.Routers.Router0.Priority":                   "42",
 		"traefik.UDP.Routers.Router0.EntryPoints":                "foobar, fiibar",
 		"traefik.UDP.Routers.Router0.Service":                    "foobar",
+		"traefik.UDP.Routers.Router1.Priority":                   "0",
 		"traefik.UDP.Routers.Router1.EntryPoints":                "foobar, fiibar",
 		"traefik.UDP.Routers.Router1.Service":                    "foobar",
 		"traefik.UDP.Services.Service0.LoadBalancer.server.Port": "42",
@@ -32,6 +32,7 @@ type Configuration struct {
 	TCPMiddlewares map[string]*TCPMiddlewareInfo `json:"tcpMiddlewares,omitempty"`
 	TCPServices    map[string]*TCPServiceInfo    `json:"tcpServices,omitempty"`
 	UDPRouters     map[string]*UDPRouterInfo     `json:"udpRouters,omitempty"`
+	UDPMiddlewares map[string]*UDPMiddlewareInfo `json:"udpMiddlewares,omitempty"`
 	UDPServices    map[string]*UDPServiceInfo    `json:"udpServices,omitempty"`
 }
 
@@ -108,11 +109,41 @@ func NewConfig(conf dynamic.Configuration) *Configuration {
 				runtimeConfig.UDPServices[k] = &UDPServiceInfo{UDPService: v, Status: StatusEnabled}
 			}
 		}
+
+		if len(conf.UDP.Middlewares) > 0 {
+			runtimeConfig.UDPMiddlewares = make(map[string]*UDPMiddlewareInfo, len(conf.UDP.Middlewares))
+			for k, v := range conf.UDP.Middlewares {
+				runtimeConfig.UDPMiddlewares[k] = &UDPMiddlewareInfo{UDPMiddleware: v, Status: StatusEnabled}
+			}
+		}
 	}
 
 	return runtimeConfig
 }
 
+// OnServerStatusChange registers a function called when the status of a server of a service changes,
+// such as when a health check flips it. The protocol is either "http", "tcp" or "udp".
+// It must be called before the statuses are updated.
+func (c *Configuration) OnServerStatusChange(listener func(protocol, serviceName, server, status string)) {
+	for name, info := range c.Services {
+		info.serverStatusMu.Lock()
+		info.serverStatusListener = func(server, status string) { listener("http", name, server, status) }
+		info.serverStatusMu.Unlock()
+	}
+
+	for name, info := range c.TCPServices {
+		info.serverStatusMu.Lock()
+		info.serverStatusListener = func(server, status string) { listener("tcp", name, server, status) }
+		info.serverStatusMu.Unlock()
+	}
+
+	for name, info := range c.UDPServices {
+		info.serverStatusMu.Lock()
+		info.serverStatusListener = func(server, status string) { listener("udp", name, server, status) }
+		info.serverStatusMu.Unlock()
+	}
+}
+
 // PopulateUsedBy populates all the UsedBy lists of the underlying fields of r,
 // based on the relations between the included services, routers, and middlewares.
 func (c *Configuration) PopulateUsedBy() {
@@ -214,6 +245,14 @@ func (c *Configuration) PopulateUsedBy() {
 			continue
 		}
 
+		for _, midName := range routerInfo.UDPRouter.Middlewares {
+			fullMidName := getQualifiedName(providerName, midName)
+			if _, ok := c.UDPMiddlewares[fullMidName]; !ok {
+				continue
+			}
+			c.UDPMiddlewares[fullMidName].UsedBy = append(c.UDPMiddlewares[fullMidName].UsedBy, routerName)
+		}
+
 		serviceName := getQualifiedName(providerName, routerInfo.UDPRouter.Service)
 		if _, ok := c.UDPServices[serviceName]; !ok {
 			continue
@@ -229,6 +268,15 @@ func (c *Configuration) PopulateUsedBy() {
 
 		sort.Strings(c.UDPServices[k].UsedBy)
 	}
+
+	for midName, mid := range c.UDPMiddlewares {
+		// lazily initialize Status in case caller forgot to do it
+		if mid.Status == "" {
+			mid.Status = StatusEnabled
+		}
+
+		sort.Strings(c.UDPMiddlewares[midName].UsedBy)
+	}
 }
 
 func getProviderName(elementName string) string {
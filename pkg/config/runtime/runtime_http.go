@@ -152,6 +152,9 @@ type ServiceInfo struct {
 
 	serverStatusMu sync.RWMutex
 	serverStatus   map[string]string // keyed by server URL
+
+	// serverStatusListener is called when the status of a server changes.
+	serverStatusListener func(server, status string)
 }
 
 // AddError adds err to s.Err, if it does not already exist.
@@ -177,12 +180,20 @@ func (s *ServiceInfo) AddError(err error, critical bool) {
 // It is the responsibility of the caller to check that s is not nil.
 func (s *ServiceInfo) UpdateServerStatus(server, status string) {
 	s.serverStatusMu.Lock()
-	defer s.serverStatusMu.Unlock()
 
 	if s.serverStatus == nil {
 		s.serverStatus = make(map[string]string)
 	}
+
+	previous, known := s.serverStatus[server]
 	s.serverStatus[server] = status
+	listener := s.serverStatusListener
+
+	s.serverStatusMu.Unlock()
+
+	if known && previous != status && listener != nil {
+		listener(server, status)
+	}
 }
 
 // GetAllStatus returns all the statuses of all the servers in ServiceInfo.
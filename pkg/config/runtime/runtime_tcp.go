@@ -92,6 +92,9 @@ type TCPServiceInfo struct {
 
 	serverStatusMu sync.RWMutex
 	serverStatus   map[string]string // keyed by server address
+
+	// serverStatusListener is called when the status of a server changes.
+	serverStatusListener func(server, status string)
 }
 
 // AddError adds err to s.Err, if it does not already exist.
@@ -116,12 +119,20 @@ func (s *TCPServiceInfo) AddError(err error, critical bool) {
 // UpdateServerStatus sets the status of the server in the TCPServiceInfo.
 func (s *TCPServiceInfo) UpdateServerStatus(server, status string) {
 	s.serverStatusMu.Lock()
-	defer s.serverStatusMu.Unlock()
 
 	if s.serverStatus == nil {
 		s.serverStatus = make(map[string]string)
 	}
+
+	previous, known := s.serverStatus[server]
 	s.serverStatus[server] = status
+	listener := s.serverStatusListener
+
+	s.serverStatusMu.Unlock()
+
+	if known && previous != status && listener != nil {
+		listener(server, status)
+	}
 }
 
 // GetAllStatus returns all the statuses of all the servers in TCPServiceInfo.
@@ -688,3 +688,35 @@ func TestPopulateUsedBy(t *testing.T) {
 		})
 	}
 }
+
+func TestConfiguration_OnServerStatusChange(t *testing.T) {
+	conf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Services: map[string]*dynamic.Service{"foo@file": {}},
+		},
+		TCP: &dynamic.TCPConfiguration{
+			Services: map[string]*dynamic.TCPService{"bar@file": {}},
+		},
+	})
+
+	var changes []string
+	conf.OnServerStatusChange(func(protocol, serviceName, server, status string) {
+		changes = append(changes, protocol+" "+serviceName+" "+server+" "+status)
+	})
+
+	// The initial status of a server is not a change.
+	conf.Services["foo@file"].UpdateServerStatus("http://127.0.0.1", runtime.StatusUp)
+	conf.TCPServices["bar@file"].UpdateServerStatus("127.0.0.1:80", runtime.StatusUp)
+
+	conf.Services["foo@file"].UpdateServerStatus("http://127.0.0.1", runtime.StatusUp)
+	conf.Services["foo@file"].UpdateServerStatus("http://127.0.0.1", runtime.StatusDown)
+	conf.TCPServices["bar@file"].UpdateServerStatus("127.0.0.1:80", runtime.StatusDown)
+	conf.TCPServices["bar@file"].UpdateServerStatus("127.0.0.1:80", runtime.StatusUp)
+
+	expected := []string{
+		"http foo@file http://127.0.0.1 DOWN",
+		"tcp bar@file 127.0.0.1:80 DOWN",
+		"tcp bar@file 127.0.0.1:80 UP",
+	}
+	assert.Equal(t, expected, changes)
+}
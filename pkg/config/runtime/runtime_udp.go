@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
@@ -93,6 +95,12 @@ type UDPServiceInfo struct {
 	// It is the caller's responsibility to set the initial status.
 	Status string   `json:"status,omitempty"`
 	UsedBy []string `json:"usedBy,omitempty"` // list of routers using that service
+
+	serverStatusMu sync.RWMutex
+	serverStatus   map[string]string // keyed by server address
+
+	// serverStatusListener is called when the status of a server changes.
+	serverStatusListener func(server, status string)
 }
 
 // AddError adds err to s.Err, if it does not already exist.
@@ -113,3 +121,65 @@ func (s *UDPServiceInfo) AddError(err error, critical bool) {
 		s.Status = StatusWarning
 	}
 }
+
+// UpdateServerStatus sets the status of the server in the UDPServiceInfo.
+func (s *UDPServiceInfo) UpdateServerStatus(server, status string) {
+	s.serverStatusMu.Lock()
+
+	if s.serverStatus == nil {
+		s.serverStatus = make(map[string]string)
+	}
+
+	previous, known := s.serverStatus[server]
+	s.serverStatus[server] = status
+	listener := s.serverStatusListener
+
+	s.serverStatusMu.Unlock()
+
+	if known && previous != status && listener != nil {
+		listener(server, status)
+	}
+}
+
+// GetAllStatus returns all the statuses of all the servers in UDPServiceInfo.
+func (s *UDPServiceInfo) GetAllStatus() map[string]string {
+	s.serverStatusMu.RLock()
+	defer s.serverStatusMu.RUnlock()
+
+	if len(s.serverStatus) == 0 {
+		return nil
+	}
+
+	allStatus := make(map[string]string, len(s.serverStatus))
+	maps.Copy(allStatus, s.serverStatus)
+	return allStatus
+}
+
+// UDPMiddlewareInfo holds information about a currently running UDP middleware.
+type UDPMiddlewareInfo struct {
+	*dynamic.UDPMiddleware // dynamic configuration
+
+	// Err contains all the errors that occurred during middleware creation.
+	Err    []string `json:"error,omitempty"`
+	Status string   `json:"status,omitempty"`
+	UsedBy []string `json:"usedBy,omitempty"` // list of UDP routers using that middleware.
+}
+
+// AddError adds err to m.Err, if it does not already exist.
+// If critical is set, m is marked as disabled.
+func (m *UDPMiddlewareInfo) AddError(err error, critical bool) {
+	if slices.Contains(m.Err, err.Error()) {
+		return
+	}
+
+	m.Err = append(m.Err, err.Error())
+	if critical {
+		m.Status = StatusDisabled
+		return
+	}
+
+	// only set it to "warning" if not already in a worse state
+	if m.Status != StatusDisabled {
+		m.Status = StatusWarning
+	}
+}
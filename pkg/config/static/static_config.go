@@ -186,6 +186,9 @@ type API struct {
 	Debug              bool   `description:"Enable additional endpoints for debugging and profiling." json:"debug,omitempty" toml:"debug,omitempty" yaml:"debug,omitempty" export:"true"`
 	DisableDashboardAd bool   `description:"Disable ad in the dashboard." json:"disableDashboardAd,omitempty" toml:"disableDashboardAd,omitempty" yaml:"disableDashboardAd,omitempty" export:"true"`
 	DashboardName      string `description:"Custom name for the dashboard." json:"dashboardName,omitempty" toml:"dashboardName,omitempty" yaml:"dashboardName,omitempty" export:"true"`
+	HistorySize        int    `description:"Number of applied dynamic configurations kept in the history, which can be re-applied through the API (0 to disable)." json:"historySize,omitempty" toml:"historySize,omitempty" yaml:"historySize,omitempty" export:"true"`
+	ServerOverrides    bool   `description:"Enable the endpoints overriding the state and weight of the load balancer servers at runtime." json:"serverOverrides,omitempty" toml:"serverOverrides,omitempty" yaml:"serverOverrides,omitempty" export:"true"`
+	ClearCaptures      bool   `description:"Enable the endpoint removing the records of the debug capture middlewares." json:"clearCaptures,omitempty" toml:"clearCaptures,omitempty" yaml:"clearCaptures,omitempty" export:"true"`
 	// TODO: Re-enable statistics
 	// Statistics      *types.Statistics `description:"Enable more detailed statistics." json:"statistics,omitempty" toml:"statistics,omitempty" yaml:"statistics,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
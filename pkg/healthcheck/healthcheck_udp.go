@@ -0,0 +1,210 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+)
+
+type UDPHealthCheckTarget struct {
+	Address string
+}
+
+type ServiceUDPHealthChecker struct {
+	balancer StatusSetter
+	info     *runtime.UDPServiceInfo
+
+	config            *dynamic.UDPServerHealthCheck
+	interval          time.Duration
+	unhealthyInterval time.Duration
+	timeout           time.Duration
+
+	healthyTargets   chan *UDPHealthCheckTarget
+	unhealthyTargets chan *UDPHealthCheckTarget
+
+	serviceName string
+}
+
+func NewServiceUDPHealthChecker(ctx context.Context, config *dynamic.UDPServerHealthCheck, service StatusSetter, info *runtime.UDPServiceInfo, targets []UDPHealthCheckTarget, serviceName string) *ServiceUDPHealthChecker {
+	logger := log.Ctx(ctx)
+	interval := time.Duration(config.Interval)
+	if interval <= 0 {
+		logger.Error().Msg("Health check interval smaller than zero, default value will be used instead.")
+		interval = time.Duration(dynamic.DefaultHealthCheckInterval)
+	}
+
+	// If the unhealthyInterval option is not set, we use the interval option value,
+	// to check the unhealthy targets as often as the healthy ones.
+	var unhealthyInterval time.Duration
+	if config.UnhealthyInterval == nil {
+		unhealthyInterval = interval
+	} else {
+		unhealthyInterval = time.Duration(*config.UnhealthyInterval)
+		if unhealthyInterval <= 0 {
+			logger.Error().Msg("Health check unhealthy interval smaller than zero, default value will be used instead.")
+			unhealthyInterval = time.Duration(dynamic.DefaultHealthCheckInterval)
+		}
+	}
+
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		logger.Error().Msg("Health check timeout smaller than zero, default value will be used instead.")
+		timeout = time.Duration(dynamic.DefaultHealthCheckTimeout)
+	}
+
+	if len(config.Send) > maxPayloadSize {
+		logger.Error().Msgf("Health check payload size exceeds maximum allowed size of %d bytes, falling back to an empty datagram.", maxPayloadSize)
+		config.Send = ""
+	}
+
+	if len(config.Expect) > maxPayloadSize {
+		logger.Error().Msgf("Health check expected response size exceeds maximum allowed size of %d bytes, falling back to no expected response.", maxPayloadSize)
+		config.Expect = ""
+	}
+
+	healthyTargets := make(chan *UDPHealthCheckTarget, len(targets))
+	for _, target := range targets {
+		healthyTargets <- &target
+	}
+	unhealthyTargets := make(chan *UDPHealthCheckTarget, len(targets))
+
+	return &ServiceUDPHealthChecker{
+		balancer:          service,
+		info:              info,
+		config:            config,
+		interval:          interval,
+		unhealthyInterval: unhealthyInterval,
+		timeout:           timeout,
+		healthyTargets:    healthyTargets,
+		unhealthyTargets:  unhealthyTargets,
+		serviceName:       serviceName,
+	}
+}
+
+func (thc *ServiceUDPHealthChecker) Launch(ctx context.Context) {
+	go thc.healthcheck(ctx, thc.unhealthyTargets, thc.unhealthyInterval)
+
+	thc.healthcheck(ctx, thc.healthyTargets, thc.interval)
+}
+
+func (thc *ServiceUDPHealthChecker) healthcheck(ctx context.Context, targets chan *UDPHealthCheckTarget, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			// We collect the targets to check once for all,
+			// to avoid rechecking a target that has been moved during the health check.
+			var targetsToCheck []*UDPHealthCheckTarget
+			hasMoreTargets := true
+			for hasMoreTargets {
+				select {
+				case <-ctx.Done():
+					return
+				case target := <-targets:
+					targetsToCheck = append(targetsToCheck, target)
+				default:
+					hasMoreTargets = false
+				}
+			}
+
+			// Now we can check the targets.
+			for _, target := range targetsToCheck {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+
+				up := true
+
+				if err := thc.executeHealthCheck(ctx, thc.config, target); err != nil {
+					// The context is canceled when the dynamic configuration is refreshed.
+					if errors.Is(err, context.Canceled) {
+						return
+					}
+
+					log.Ctx(ctx).Warn().
+						Str("targetAddress", target.Address).
+						Err(err).
+						Msg("Health check failed.")
+
+					up = false
+				}
+
+				thc.balancer.SetStatus(ctx, target.Address, up)
+
+				var statusStr string
+				if up {
+					statusStr = runtime.StatusUp
+					thc.healthyTargets <- target
+				} else {
+					statusStr = runtime.StatusDown
+					thc.unhealthyTargets <- target
+				}
+
+				thc.info.UpdateServerStatus(target.Address, statusStr)
+			}
+		}
+	}
+}
+
+func (thc *ServiceUDPHealthChecker) executeHealthCheck(ctx context.Context, config *dynamic.UDPServerHealthCheck, target *UDPHealthCheckTarget) error {
+	addr := target.Address
+	if config.Port != 0 {
+		host, _, err := net.SplitHostPort(target.Address)
+		if err != nil {
+			return fmt.Errorf("parsing address %q: %w", target.Address, err)
+		}
+
+		addr = net.JoinHostPort(host, strconv.Itoa(config.Port))
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(thc.timeout))
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(thc.timeout)); err != nil {
+		return fmt.Errorf("setting timeout to %s: %w", thc.timeout, err)
+	}
+
+	if _, err = conn.Write([]byte(config.Send)); err != nil {
+		return fmt.Errorf("sending to %s: %w", addr, err)
+	}
+
+	buf := make([]byte, maxPayloadSize)
+	n, err := conn.Read(buf)
+	if err != nil {
+		// Without an expected response, the silence of the server is not a failure:
+		// only an ICMP port unreachable, reported as a refused connection, is.
+		if config.Expect == "" && errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil
+		}
+
+		return fmt.Errorf("reading from %s: %w", addr, err)
+	}
+
+	if config.Expect != "" && string(buf[:n]) != config.Expect {
+		return errors.New("unexpected heath check response")
+	}
+
+	return nil
+}
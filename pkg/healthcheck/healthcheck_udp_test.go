@@ -0,0 +1,175 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	truntime "github.com/traefik/traefik/v3/pkg/config/runtime"
+)
+
+func TestServiceUDPHealthChecker_executeHealthCheck(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		response        string
+		closed          bool
+		config          *dynamic.UDPServerHealthCheck
+		expectedSuccess bool
+	}{
+		{
+			desc:     "expected response received",
+			response: "PONG",
+			config: &dynamic.UDPServerHealthCheck{
+				Send:   "PING",
+				Expect: "PONG",
+			},
+			expectedSuccess: true,
+		},
+		{
+			desc:     "wrong response received",
+			response: "WRONG",
+			config: &dynamic.UDPServerHealthCheck{
+				Send:   "PING",
+				Expect: "PONG",
+			},
+		},
+		{
+			desc: "no response while expecting one",
+			config: &dynamic.UDPServerHealthCheck{
+				Send:   "PING",
+				Expect: "PONG",
+			},
+		},
+		{
+			desc: "no response and no expectation",
+			config: &dynamic.UDPServerHealthCheck{
+				Send: "PING",
+			},
+			expectedSuccess: true,
+		},
+		{
+			desc:   "port unreachable",
+			closed: true,
+			config: &dynamic.UDPServerHealthCheck{
+				Send: "PING",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.config.Timeout = ptypes.Duration(200 * time.Millisecond)
+
+			addr := newUDPServer(t, test.response)
+			if test.closed {
+				addr = closedUDPAddress(t)
+			}
+
+			targets := []UDPHealthCheckTarget{{Address: addr}}
+			healthChecker := NewServiceUDPHealthChecker(t.Context(), test.config, nil, nil, targets, "test")
+
+			err := healthChecker.executeHealthCheck(t.Context(), test.config, &targets[0])
+			if test.expectedSuccess {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestServiceUDPHealthChecker_Launch(t *testing.T) {
+	ctx, cancel := context.WithCancel(log.Logger.WithContext(t.Context()))
+	defer cancel()
+
+	healthyAddr := newUDPServer(t, "PONG")
+	unhealthyAddr := newUDPServer(t, "WRONG")
+
+	config := &dynamic.UDPServerHealthCheck{
+		Send:     "PING",
+		Expect:   "PONG",
+		Interval: ptypes.Duration(50 * time.Millisecond),
+		Timeout:  ptypes.Duration(40 * time.Millisecond),
+	}
+
+	lb := &testLoadBalancer{
+		RWMutex: &sync.RWMutex{},
+		eventCh: make(chan struct{}, 10),
+	}
+	serviceInfo := &truntime.UDPServiceInfo{}
+
+	targets := []UDPHealthCheckTarget{{Address: healthyAddr}, {Address: unhealthyAddr}}
+	healthChecker := NewServiceUDPHealthChecker(ctx, config, lb, serviceInfo, targets, "serviceName")
+
+	go healthChecker.Launch(ctx)
+
+	for i := range 2 {
+		select {
+		case <-lb.eventCh:
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for health check event %d/2", i+1)
+		}
+	}
+
+	cancel()
+
+	lb.RLock()
+	defer lb.RUnlock()
+
+	assert.Equal(t, 1, lb.numUpsertedServers)
+	assert.Equal(t, 1, lb.numRemovedServers)
+	assert.Equal(t, map[string]string{
+		healthyAddr:   truntime.StatusUp,
+		unhealthyAddr: truntime.StatusDown,
+	}, serviceInfo.GetAllStatus())
+}
+
+// newUDPServer starts a UDP server answering every datagram with response,
+// or not answering at all if response is empty.
+func newUDPServer(t *testing.T, response string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxPayloadSize)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			if response == "" {
+				continue
+			}
+
+			_, _ = conn.WriteTo([]byte(response), addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// closedUDPAddress returns the address of a UDP port nobody listens on.
+func closedUDPAddress(t *testing.T) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	return addr
+}
@@ -98,6 +98,15 @@ const (
 	KubernetesServiceName = "KubernetesServiceName"
 	// KubernetesServicePort is the port of the Kubernetes service associated with Ingress the router handles.
 	KubernetesServicePort = "KubernetesServicePort"
+
+	// WAF fields.
+
+	// WAFAction is the map key used for the outcome of the WAF rules matching the request, either blocked or detected.
+	WAFAction = "WAFAction"
+	// WAFMatchedRules is the map key used for the comma-separated IDs of the WAF rules matching the request.
+	WAFMatchedRules = "WAFMatchedRules"
+	// WAFMessage is the map key used for the message of the WAF rule that blocked the request, or of the last matching one.
+	WAFMessage = "WAFMessage"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
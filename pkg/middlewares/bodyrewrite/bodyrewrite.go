@@ -0,0 +1,152 @@
+// Package bodyrewrite implements a middleware rewriting the request and response bodies
+// with regular expression substitutions and JSON operations.
+package bodyrewrite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+)
+
+const typeName = "BodyRewrite"
+
+var errRequestTooLarge = errors.New("request body too large")
+
+type bodyRewrite struct {
+	next                 http.Handler
+	name                 string
+	request              *rules
+	response             *rules
+	maxRequestBodyBytes  int64
+	maxResponseBodyBytes int64
+}
+
+// New creates a body rewrite middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.BodyRewrite, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.Request == nil && config.Response == nil {
+		return nil, errors.New("request or response rules must be defined")
+	}
+
+	if config.MaxRequestBodyBytes < 0 || config.MaxResponseBodyBytes < 0 {
+		return nil, errors.New("maximum body sizes must be greater than or equal to zero")
+	}
+
+	b := &bodyRewrite{
+		next:                 next,
+		name:                 name,
+		maxRequestBodyBytes:  config.MaxRequestBodyBytes,
+		maxResponseBodyBytes: config.MaxResponseBodyBytes,
+	}
+
+	var err error
+	if config.Request != nil {
+		b.request, err = newRules(config.Request)
+		if err != nil {
+			return nil, fmt.Errorf("request rules: %w", err)
+		}
+	}
+
+	if config.Response != nil {
+		b.response, err = newRules(config.Response)
+		if err != nil {
+			return nil, fmt.Errorf("response rules: %w", err)
+		}
+	}
+
+	return b, nil
+}
+
+func (b *bodyRewrite) GetTracingInformation() (string, string) {
+	return b.name, typeName
+}
+
+func (b *bodyRewrite) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), b.name, typeName)
+
+	if b.request != nil && req.Body != nil && req.Body != http.NoBody && isIdentity(req.Header) && b.request.matches(req.Header) {
+		if err := b.rewriteRequest(req); err != nil {
+			if errors.Is(err, errRequestTooLarge) {
+				logger.Debug().Msgf("Request body larger than %d bytes", b.maxRequestBodyBytes)
+				http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			logger.Debug().Err(err).Msg("Error while reading request body")
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if b.response == nil {
+		b.next.ServeHTTP(rw, req)
+		return
+	}
+
+	// The response body cannot be rewritten once compressed by the service.
+	req.Header.Del("Accept-Encoding")
+
+	writer := &responseWriter{
+		rw:       rw,
+		logger:   logger,
+		method:   req.Method,
+		rules:    b.response,
+		maxBytes: b.maxResponseBodyBytes,
+	}
+
+	b.next.ServeHTTP(writer, req)
+
+	if err := writer.close(); err != nil {
+		logger.Debug().Err(err).Msg("Error while writing response body")
+	}
+}
+
+// rewriteRequest rewrites the request body, line by line while it is read when possible,
+// or buffering it otherwise.
+func (b *bodyRewrite) rewriteRequest(req *http.Request) error {
+	if b.request.streamable {
+		req.Body = newLineReader(req.Body, b.request)
+		req.GetBody = nil
+		req.ContentLength = -1
+		req.Header.Del("Content-Length")
+		return nil
+	}
+
+	reader := io.Reader(req.Body)
+	if b.maxRequestBodyBytes > 0 {
+		reader = io.LimitReader(req.Body, b.maxRequestBodyBytes+1)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	_ = req.Body.Close()
+
+	if b.maxRequestBodyBytes > 0 && int64(len(body)) > b.maxRequestBodyBytes {
+		return errRequestTooLarge
+	}
+
+	body, err = b.request.rewrite(body)
+	if err != nil {
+		middlewares.GetLogger(req.Context(), b.name, typeName).Debug().Err(err).Msg("Request body not rewritten as JSON")
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	return nil
+}
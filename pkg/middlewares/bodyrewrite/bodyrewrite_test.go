@@ -0,0 +1,361 @@
+package bodyrewrite
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.BodyRewrite
+	}{
+		{
+			desc:   "no rules",
+			config: dynamic.BodyRewrite{},
+		},
+		{
+			desc:   "empty rules",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{}},
+		},
+		{
+			desc: "invalid regex",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: "(foo"}},
+			}},
+		},
+		{
+			desc: "invalid content type",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				ContentTypes: []string{"text/"},
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: "foo"}},
+			}},
+		},
+		{
+			desc: "invalid JSON path",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				JSON: []dynamic.BodyRewriteJSON{{Path: "user", Delete: true}},
+			}},
+		},
+		{
+			desc: "invalid JSON value",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				JSON: []dynamic.BodyRewriteJSON{{Path: "$.user", Value: "foo"}},
+			}},
+		},
+		{
+			desc: "JSON operation without value nor delete",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				JSON: []dynamic.BodyRewriteJSON{{Path: "$.user"}},
+			}},
+		},
+		{
+			desc: "JSON operation with value and delete",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				JSON: []dynamic.BodyRewriteJSON{{Path: "$.user", Value: "1", Delete: true}},
+			}},
+		},
+		{
+			desc: "negative maximum body size",
+			config: dynamic.BodyRewrite{
+				Request:             &dynamic.BodyRewriteRules{Replacements: []dynamic.BodyRewriteReplacement{{Regex: "foo"}}},
+				MaxRequestBodyBytes: -1,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(t.Context(), next, test.config, "bodyrewrite")
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestBodyRewrite_request(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		config                dynamic.BodyRewrite
+		contentType           string
+		body                  string
+		expectedStatus        int
+		expectedBody          string
+		expectedContentLength int64
+	}{
+		{
+			desc: "JSON operations",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				ContentTypes: []string{"application/json"},
+				JSON: []dynamic.BodyRewriteJSON{
+					{Path: "$.user.password", Delete: true},
+					{Path: "$.user.roles[*]", Value: `"guest"`},
+					{Path: "$.source", Value: `"traefik"`},
+				},
+			}},
+			contentType:           "application/json",
+			body:                  `{"user":{"name":"foo","password":"secret","roles":["admin","ops"]}}`,
+			expectedStatus:        http.StatusOK,
+			expectedBody:          `{"source":"traefik","user":{"name":"foo","roles":["guest","guest"]}}`,
+			expectedContentLength: 68,
+		},
+		{
+			desc: "JSON operations and replacements",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				JSON:         []dynamic.BodyRewriteJSON{{Path: "$.id", Value: "42"}},
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: `"id":(\d+)`, Replacement: `"id":"$1"`}},
+			}},
+			contentType:           "application/json",
+			body:                  `{"id":1}`,
+			expectedStatus:        http.StatusOK,
+			expectedBody:          `{"id":"42"}`,
+			expectedContentLength: 11,
+		},
+		{
+			desc: "invalid JSON body",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				JSON:         []dynamic.BodyRewriteJSON{{Path: "$.id", Delete: true}},
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: "foo", Replacement: "bar"}},
+			}},
+			contentType:           "application/json",
+			body:                  `{"id":"foo"`,
+			expectedStatus:        http.StatusOK,
+			expectedBody:          `{"id":"bar"`,
+			expectedContentLength: 11,
+		},
+		{
+			desc: "streamed replacements",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: `(?m)^(\w+)=`, Replacement: "${1}_v2="}},
+			}},
+			contentType:           "text/plain",
+			body:                  "foo=1\nbar=2",
+			expectedStatus:        http.StatusOK,
+			expectedBody:          "foo_v2=1\nbar_v2=2",
+			expectedContentLength: -1,
+		},
+		{
+			desc: "content type not matching",
+			config: dynamic.BodyRewrite{Request: &dynamic.BodyRewriteRules{
+				ContentTypes: []string{"application/json"},
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: "foo", Replacement: "bar"}},
+			}},
+			contentType:           "text/plain",
+			body:                  "foo",
+			expectedStatus:        http.StatusOK,
+			expectedBody:          "foo",
+			expectedContentLength: 3,
+		},
+		{
+			desc: "body too large",
+			config: dynamic.BodyRewrite{
+				Request: &dynamic.BodyRewriteRules{
+					JSON: []dynamic.BodyRewriteJSON{{Path: "$.id", Delete: true}},
+				},
+				MaxRequestBodyBytes: 4,
+			},
+			contentType:    "application/json",
+			body:           `{"id":1}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, test.expectedBody, string(body))
+				assert.Equal(t, test.expectedContentLength, req.ContentLength)
+			})
+
+			handler, err := New(t.Context(), next, test.config, "bodyrewrite")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+		})
+	}
+}
+
+func TestBodyRewrite_response(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		config                dynamic.BodyRewrite
+		headers               map[string]string
+		chunks                []string
+		expectedStatus        int
+		expectedBody          string
+		expectedContentLength string
+	}{
+		{
+			desc: "streamed replacements",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				ContentTypes: []string{"text/html"},
+				Replacements: []dynamic.BodyRewriteReplacement{
+					{Regex: `http://backend\.local`, Replacement: "https://example.com"},
+					{Regex: `(?i)</head>`, Replacement: `<script src="/analytics.js"></script></head>`},
+				},
+			}},
+			headers: map[string]string{"Content-Type": "text/html; charset=utf-8", "Content-Length": "79"},
+			chunks: []string{
+				"<html><head></he",
+				"ad>\n<a href=\"http://backend.loc",
+				"al/foo\">foo</a>\n<a href=\"http://backend.local/bar\">bar</a>",
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<html><head><script src=\"/analytics.js\"></script></head>\n<a href=\"https://example.com/foo\">foo</a>\n<a href=\"https://example.com/bar\">bar</a>",
+		},
+		{
+			desc: "buffered replacements",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: `(?s)<!--.*?-->`}},
+			}},
+			headers:               map[string]string{"Content-Type": "text/html"},
+			chunks:                []string{"<p>foo</p><!-- a\n", "comment -->"},
+			expectedStatus:        http.StatusOK,
+			expectedBody:          "<p>foo</p>",
+			expectedContentLength: "10",
+		},
+		{
+			desc: "JSON operations",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				JSON: []dynamic.BodyRewriteJSON{{Path: "$.items[*].internal", Delete: true}},
+			}},
+			headers:               map[string]string{"Content-Type": "application/json"},
+			chunks:                []string{`{"items":[{"id":1,"internal":true},`, `{"id":2,"internal":false}]}`},
+			expectedStatus:        http.StatusCreated,
+			expectedBody:          `{"items":[{"id":1},{"id":2}]}`,
+			expectedContentLength: "29",
+		},
+		{
+			desc: "content type not matching",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				ContentTypes: []string{"text/html"},
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: "foo", Replacement: "bar"}},
+			}},
+			headers:               map[string]string{"Content-Type": "text/plain", "Content-Length": "3"},
+			chunks:                []string{"foo"},
+			expectedStatus:        http.StatusOK,
+			expectedBody:          "foo",
+			expectedContentLength: "3",
+		},
+		{
+			desc: "encoded body",
+			config: dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: "foo", Replacement: "bar"}},
+			}},
+			headers:        map[string]string{"Content-Type": "text/plain", "Content-Encoding": "br"},
+			chunks:         []string{"foo"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "foo",
+		},
+		{
+			desc: "body too large",
+			config: dynamic.BodyRewrite{
+				Response: &dynamic.BodyRewriteRules{
+					JSON: []dynamic.BodyRewriteJSON{{Path: "$.id", Delete: true}},
+				},
+				MaxResponseBodyBytes: 10,
+			},
+			headers:        map[string]string{"Content-Type": "application/json"},
+			chunks:         []string{`{"id":1,`, `"name":"foo"}`},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   http.StatusText(http.StatusInternalServerError) + "\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Empty(t, req.Header.Get("Accept-Encoding"))
+
+				for name, value := range test.headers {
+					rw.Header().Set(name, value)
+				}
+
+				if test.expectedStatus != http.StatusOK && test.expectedStatus != http.StatusInternalServerError {
+					rw.WriteHeader(test.expectedStatus)
+				}
+
+				for _, chunk := range test.chunks {
+					_, err := rw.Write([]byte(chunk))
+					require.NoError(t, err)
+				}
+			})
+
+			handler, err := New(t.Context(), next, test.config, "bodyrewrite")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			assert.Equal(t, test.expectedBody, rw.Body.String())
+			assert.Equal(t, test.expectedContentLength, rw.Header().Get("Content-Length"))
+		})
+	}
+}
+
+func TestBodyRewrite_responseFlush(t *testing.T) {
+	flushed := make(chan struct{})
+	resume := make(chan struct{})
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/event-stream")
+
+		_, _ = rw.Write([]byte("data: foo\n\n"))
+		rw.(http.Flusher).Flush()
+
+		close(flushed)
+		<-resume
+
+		_, _ = rw.Write([]byte("data: foo"))
+	})
+
+	handler, err := New(t.Context(), next, dynamic.BodyRewrite{Response: &dynamic.BodyRewriteRules{
+		Replacements: []dynamic.BodyRewriteReplacement{{Regex: "foo", Replacement: "bar"}},
+	}}, "bodyrewrite")
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	<-flushed
+	assert.True(t, rw.Flushed)
+	assert.Equal(t, "data: bar\n\n", rw.Body.String())
+
+	close(resume)
+	<-done
+
+	assert.Equal(t, "data: bar\n\ndata: bar", rw.Body.String())
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
@@ -0,0 +1,233 @@
+package bodyrewrite
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	segmentKey segmentKind = iota
+	segmentIndex
+	segmentWildcard
+)
+
+// segment is an element of a JSONPath, i.e. an object member, an array index or a wildcard.
+type segment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+// jsonPath is a JSONPath restricted to the member, index and wildcard selectors,
+// e.g. $.user.roles[0], $['user']['name'] or $.items[*].price.
+type jsonPath []segment
+
+func parseJSONPath(raw string) (jsonPath, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(raw), "$")
+	if !ok {
+		return nil, fmt.Errorf("path %q must start with $", raw)
+	}
+
+	var path jsonPath
+	for rest != "" {
+		var seg segment
+		var err error
+
+		switch rest[0] {
+		case '.':
+			seg, rest, err = parseMember(rest[1:])
+		case '[':
+			seg, rest, err = parseBracket(rest[1:])
+		default:
+			err = fmt.Errorf("unexpected character %q", rest[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", raw, err)
+		}
+
+		path = append(path, seg)
+	}
+
+	if len(path) == 0 {
+		return nil, fmt.Errorf("path %q must select a value inside the document", raw)
+	}
+
+	return path, nil
+}
+
+// parseMember parses a dot-notation member, e.g. the "user" of ".user".
+func parseMember(s string) (segment, string, error) {
+	if rest, ok := strings.CutPrefix(s, "*"); ok {
+		return segment{kind: segmentWildcard}, rest, nil
+	}
+
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		end = len(s)
+	}
+
+	if end == 0 {
+		return segment{}, "", errors.New("empty member name")
+	}
+
+	return segment{kind: segmentKey, key: s[:end]}, s[end:], nil
+}
+
+// parseBracket parses a bracket-notation selector, e.g. the "0]" of "[0]", or the "'user']" of "['user']".
+func parseBracket(s string) (segment, string, error) {
+	if s == "" {
+		return segment{}, "", errors.New("unterminated bracket")
+	}
+
+	if quote := s[0]; quote == '\'' || quote == '"' {
+		end := strings.IndexByte(s[1:], quote)
+		if end < 0 || !strings.HasPrefix(s[end+2:], "]") {
+			return segment{}, "", errors.New("unterminated quoted member name")
+		}
+
+		return segment{kind: segmentKey, key: s[1 : end+1]}, s[end+3:], nil
+	}
+
+	selector, rest, ok := strings.Cut(s, "]")
+	if !ok {
+		return segment{}, "", errors.New("unterminated bracket")
+	}
+
+	if selector == "*" {
+		return segment{kind: segmentWildcard}, rest, nil
+	}
+
+	index, err := strconv.Atoi(selector)
+	if err != nil || index < 0 {
+		return segment{}, "", fmt.Errorf("invalid array index %q", selector)
+	}
+
+	return segment{kind: segmentIndex, index: index}, rest, nil
+}
+
+// set sets the values selected by the path, and returns the updated document.
+// The missing object members are created, as well as the array element right after the last one.
+// The value function is called for each set value, so that they do not share their content.
+func (p jsonPath) set(node any, value func() any) any {
+	result, _ := setSegments(node, p, value)
+	return result
+}
+
+func setSegments(node any, segments []segment, value func() any) (any, bool) {
+	if len(segments) == 0 {
+		return value(), true
+	}
+
+	seg, rest := segments[0], segments[1:]
+
+	switch seg.kind {
+	case segmentKey:
+		object, ok := node.(map[string]any)
+		if !ok {
+			if node != nil {
+				return node, false
+			}
+			object = make(map[string]any)
+		}
+
+		child, changed := setSegments(object[seg.key], rest, value)
+		if !changed {
+			return node, false
+		}
+
+		object[seg.key] = child
+		return object, true
+
+	case segmentIndex:
+		array, ok := node.([]any)
+		if !ok && node != nil {
+			return node, false
+		}
+
+		switch {
+		case seg.index < len(array):
+			child, changed := setSegments(array[seg.index], rest, value)
+			array[seg.index] = child
+			return array, changed
+
+		case seg.index == len(array):
+			child, changed := setSegments(nil, rest, value)
+			if !changed {
+				return node, false
+			}
+			return append(array, child), true
+
+		default:
+			return node, false
+		}
+
+	default:
+		var changed bool
+		switch v := node.(type) {
+		case map[string]any:
+			for key, child := range v {
+				var childChanged bool
+				v[key], childChanged = setSegments(child, rest, value)
+				changed = changed || childChanged
+			}
+		case []any:
+			for i, child := range v {
+				var childChanged bool
+				v[i], childChanged = setSegments(child, rest, value)
+				changed = changed || childChanged
+			}
+		}
+		return node, changed
+	}
+}
+
+// delete deletes the values selected by the path, and returns the updated document.
+// The deleted array elements are removed from the array, shifting the following ones.
+func (p jsonPath) delete(node any) any {
+	seg, rest := p[0], p[1:]
+
+	if len(rest) > 0 {
+		switch v := node.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if seg.kind == segmentWildcard || seg.kind == segmentKey && key == seg.key {
+					v[key] = rest.delete(child)
+				}
+			}
+		case []any:
+			for i, child := range v {
+				if seg.kind == segmentWildcard || seg.kind == segmentIndex && i == seg.index {
+					v[i] = rest.delete(child)
+				}
+			}
+		}
+
+		return node
+	}
+
+	switch v := node.(type) {
+	case map[string]any:
+		switch seg.kind {
+		case segmentKey:
+			delete(v, seg.key)
+		case segmentWildcard:
+			clear(v)
+		}
+	case []any:
+		switch seg.kind {
+		case segmentIndex:
+			if seg.index < len(v) {
+				return slices.Delete(v, seg.index, seg.index+1)
+			}
+		case segmentWildcard:
+			return v[:0]
+		}
+	}
+
+	return node
+}
@@ -0,0 +1,209 @@
+package bodyrewrite
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		path      string
+		expected  jsonPath
+		expectErr bool
+	}{
+		{
+			desc:     "dot notation",
+			path:     "$.user.name",
+			expected: jsonPath{{kind: segmentKey, key: "user"}, {kind: segmentKey, key: "name"}},
+		},
+		{
+			desc:     "bracket notation",
+			path:     `$['user']["first name"]`,
+			expected: jsonPath{{kind: segmentKey, key: "user"}, {kind: segmentKey, key: "first name"}},
+		},
+		{
+			desc:     "index and wildcards",
+			path:     "$.items[2].tags[*].*",
+			expected: jsonPath{{kind: segmentKey, key: "items"}, {kind: segmentIndex, index: 2}, {kind: segmentKey, key: "tags"}, {kind: segmentWildcard}, {kind: segmentWildcard}},
+		},
+		{
+			desc:      "missing root",
+			path:      "user.name",
+			expectErr: true,
+		},
+		{
+			desc:      "root only",
+			path:      "$",
+			expectErr: true,
+		},
+		{
+			desc:      "empty member",
+			path:      "$..name",
+			expectErr: true,
+		},
+		{
+			desc:      "negative index",
+			path:      "$.items[-1]",
+			expectErr: true,
+		},
+		{
+			desc:      "unterminated bracket",
+			path:      "$['user'",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := parseJSONPath(test.path)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, path)
+		})
+	}
+}
+
+func TestJSONPath_set(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		document string
+		path     string
+		value    string
+		expected string
+	}{
+		{
+			desc:     "replace a member",
+			document: `{"user":{"name":"foo"}}`,
+			path:     "$.user.name",
+			value:    `"bar"`,
+			expected: `{"user":{"name":"bar"}}`,
+		},
+		{
+			desc:     "create the missing members",
+			document: `{}`,
+			path:     "$.user.roles",
+			value:    `["admin"]`,
+			expected: `{"user":{"roles":["admin"]}}`,
+		},
+		{
+			desc:     "replace an array element",
+			document: `{"roles":["a","b"]}`,
+			path:     "$.roles[1]",
+			value:    `"c"`,
+			expected: `{"roles":["a","c"]}`,
+		},
+		{
+			desc:     "append an array element",
+			document: `{"roles":["a"]}`,
+			path:     "$.roles[1]",
+			value:    `"b"`,
+			expected: `{"roles":["a","b"]}`,
+		},
+		{
+			desc:     "index out of range",
+			document: `{"roles":["a"]}`,
+			path:     "$.roles[3]",
+			value:    `"b"`,
+			expected: `{"roles":["a"]}`,
+		},
+		{
+			desc:     "wildcard",
+			document: `{"items":[{"price":1},{"price":2}]}`,
+			path:     "$.items[*].meta",
+			value:    `{"currency":"EUR"}`,
+			expected: `{"items":[{"meta":{"currency":"EUR"},"price":1},{"meta":{"currency":"EUR"},"price":2}]}`,
+		},
+		{
+			desc:     "type mismatch",
+			document: `{"user":"foo"}`,
+			path:     "$.user.name",
+			value:    `"bar"`,
+			expected: `{"user":"foo"}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := parseJSONPath(test.path)
+			require.NoError(t, err)
+
+			var document any
+			require.NoError(t, json.Unmarshal([]byte(test.document), &document))
+
+			op := jsonOperation{value: json.RawMessage(test.value)}
+			result, err := json.Marshal(path.set(document, op.decodeValue))
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expected, string(result))
+		})
+	}
+}
+
+func TestJSONPath_delete(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		document string
+		path     string
+		expected string
+	}{
+		{
+			desc:     "member",
+			document: `{"user":{"name":"foo","password":"bar"}}`,
+			path:     "$.user.password",
+			expected: `{"user":{"name":"foo"}}`,
+		},
+		{
+			desc:     "array element",
+			document: `{"roles":["a","b","c"]}`,
+			path:     "$.roles[1]",
+			expected: `{"roles":["a","c"]}`,
+		},
+		{
+			desc:     "wildcard",
+			document: `{"items":[{"id":1,"secret":"a"},{"id":2,"secret":"b"}]}`,
+			path:     "$.items[*].secret",
+			expected: `{"items":[{"id":1},{"id":2}]}`,
+		},
+		{
+			desc:     "all the elements",
+			document: `{"roles":["a","b"]}`,
+			path:     "$.roles[*]",
+			expected: `{"roles":[]}`,
+		},
+		{
+			desc:     "missing member",
+			document: `{"user":{"name":"foo"}}`,
+			path:     "$.account.password",
+			expected: `{"user":{"name":"foo"}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := parseJSONPath(test.path)
+			require.NoError(t, err)
+
+			var document any
+			require.NoError(t, json.Unmarshal([]byte(test.document), &document))
+
+			result, err := json.Marshal(path.delete(document))
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expected, string(result))
+		})
+	}
+}
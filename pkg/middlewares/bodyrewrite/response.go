@@ -0,0 +1,169 @@
+package bodyrewrite
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog"
+)
+
+type writeMode int
+
+const (
+	modeUndecided writeMode = iota
+	modePassThrough
+	modeStream
+	modeBuffer
+)
+
+// responseWriter rewrites the response body.
+// Whether the body is forwarded as is, rewritten line by line, or buffered to be rewritten as a whole,
+// is decided when the headers are written.
+type responseWriter struct {
+	rw       http.ResponseWriter
+	logger   *zerolog.Logger
+	method   string
+	rules    *rules
+	maxBytes int64
+
+	mode       writeMode
+	statusCode int
+	lines      *lineWriter
+	buf        bytes.Buffer
+	tooLarge   bool
+	hijacked   bool
+}
+
+func (r *responseWriter) Header() http.Header {
+	return r.rw.Header()
+}
+
+func (r *responseWriter) WriteHeader(statusCode int) {
+	// Informational responses are forwarded as is.
+	if statusCode >= 100 && statusCode <= 199 {
+		r.rw.WriteHeader(statusCode)
+		return
+	}
+
+	if r.mode != modeUndecided {
+		return
+	}
+
+	header := r.rw.Header()
+
+	switch {
+	case statusCode == http.StatusNoContent || statusCode == http.StatusNotModified || r.method == http.MethodHead,
+		!isIdentity(header), !r.rules.matches(header):
+		r.mode = modePassThrough
+		r.rw.WriteHeader(statusCode)
+
+	case r.rules.streamable:
+		r.mode = modeStream
+		header.Del("Content-Length")
+		r.rw.WriteHeader(statusCode)
+		r.lines = &lineWriter{w: r.rw, rules: r.rules}
+
+	default:
+		r.mode = modeBuffer
+		r.statusCode = statusCode
+	}
+}
+
+func (r *responseWriter) Write(p []byte) (int, error) {
+	if r.mode == modeUndecided {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	switch r.mode {
+	case modePassThrough:
+		return r.rw.Write(p)
+
+	case modeStream:
+		return r.lines.Write(p)
+
+	default:
+		if r.tooLarge {
+			return len(p), nil
+		}
+
+		if r.maxBytes > 0 && int64(r.buf.Len()+len(p)) > r.maxBytes {
+			// The body is discarded, and the error response is sent once the service is done writing.
+			r.tooLarge = true
+			r.buf.Reset()
+			return len(p), nil
+		}
+
+		return r.buf.Write(p)
+	}
+}
+
+// Flush sends the headers and the rewritten complete lines when streaming.
+// It has no effect when the body is buffered.
+func (r *responseWriter) Flush() {
+	if r.mode == modeUndecided {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if r.mode == modeBuffer {
+		return
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+
+	r.hijacked = true
+	return hijacker.Hijack()
+}
+
+// close writes what remains of the rewritten body.
+func (r *responseWriter) close() error {
+	if r.hijacked {
+		return nil
+	}
+
+	switch r.mode {
+	case modeStream:
+		return r.lines.flush()
+
+	case modeBuffer:
+		if r.tooLarge {
+			r.logger.Debug().Msgf("Response body larger than %d bytes", r.maxBytes)
+
+			r.rw.Header().Del("Content-Length")
+			http.Error(r.rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return nil
+		}
+
+		body, err := r.rules.rewrite(r.buf.Bytes())
+		if err != nil {
+			r.logger.Debug().Err(err).Msg("Response body not rewritten as JSON")
+		}
+
+		r.rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		r.rw.WriteHeader(r.statusCode)
+
+		_, err = r.rw.Write(body)
+		return err
+
+	default:
+		return nil
+	}
+}
+
+// isIdentity reports whether the body is not encoded, e.g. compressed, according to the given headers.
+func isIdentity(header http.Header) bool {
+	encoding := header.Get("Content-Encoding")
+	return encoding == "" || encoding == "identity"
+}
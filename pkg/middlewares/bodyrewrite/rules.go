@@ -0,0 +1,231 @@
+package bodyrewrite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// rules are the compiled rewriting rules of the request or response bodies.
+type rules struct {
+	contentTypes []string
+	operations   []jsonOperation
+	replacements []replacement
+
+	// streamable reports whether the bodies can be rewritten line by line, without being buffered.
+	streamable bool
+}
+
+type jsonOperation struct {
+	path   jsonPath
+	value  json.RawMessage
+	delete bool
+}
+
+type replacement struct {
+	regex       *regexp.Regexp
+	replacement []byte
+}
+
+func newRules(config *dynamic.BodyRewriteRules) (*rules, error) {
+	if len(config.JSON) == 0 && len(config.Replacements) == 0 {
+		return nil, errors.New("at least one JSON operation or replacement must be defined")
+	}
+
+	r := &rules{}
+
+	for _, contentType := range config.ContentTypes {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("parsing content type %q: %w", contentType, err)
+		}
+
+		r.contentTypes = append(r.contentTypes, mediaType)
+	}
+
+	for _, op := range config.JSON {
+		path, err := parseJSONPath(op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case op.Delete && op.Value != "":
+			return nil, fmt.Errorf("JSON operation on %q cannot both set a value and delete", op.Path)
+
+		case !op.Delete && op.Value == "":
+			return nil, fmt.Errorf("JSON operation on %q must either set a value or delete", op.Path)
+
+		case !op.Delete && !json.Valid([]byte(op.Value)):
+			return nil, fmt.Errorf("JSON operation on %q: invalid JSON value %q", op.Path, op.Value)
+		}
+
+		r.operations = append(r.operations, jsonOperation{path: path, value: json.RawMessage(op.Value), delete: op.Delete})
+	}
+
+	r.streamable = len(r.operations) == 0
+
+	for _, rep := range config.Replacements {
+		if rep.Regex == "" {
+			return nil, errors.New("replacement regex must be defined")
+		}
+
+		regex, err := regexp.Compile(rep.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("compiling replacement regex %q: %w", rep.Regex, err)
+		}
+
+		r.replacements = append(r.replacements, replacement{regex: regex, replacement: []byte(rep.Replacement)})
+
+		if r.streamable {
+			r.streamable, err = lineLocal(rep.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("parsing replacement regex %q: %w", rep.Regex, err)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// matches reports whether the body with the given headers has to be rewritten, according to its content type.
+func (r *rules) matches(header http.Header) bool {
+	if len(r.contentTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	for _, contentType := range r.contentTypes {
+		if contentType == "*/*" || contentType == mediaType {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(contentType, "*"); ok && strings.HasSuffix(prefix, "/") && strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rewrite applies the JSON operations, then the replacements, to the body.
+// When the body is not a valid JSON document, the JSON operations are skipped and an error is returned,
+// alongside the body on which the replacements are still applied.
+func (r *rules) rewrite(body []byte) ([]byte, error) {
+	var err error
+	if len(r.operations) > 0 {
+		var rewritten []byte
+		rewritten, err = r.rewriteJSON(body)
+		if err == nil {
+			body = rewritten
+		}
+	}
+
+	return r.replace(body), err
+}
+
+func (r *rules) rewriteJSON(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("parsing JSON body: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("parsing JSON body: unexpected data after the JSON document")
+	}
+
+	for _, op := range r.operations {
+		if op.delete {
+			document = op.path.delete(document)
+			continue
+		}
+
+		document = op.path.set(document, op.decodeValue)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("encoding JSON body: %w", err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (op jsonOperation) decodeValue() any {
+	decoder := json.NewDecoder(bytes.NewReader(op.value))
+	decoder.UseNumber()
+
+	// The value has been validated when creating the rules.
+	var value any
+	_ = decoder.Decode(&value)
+
+	return value
+}
+
+func (r *rules) replace(data []byte) []byte {
+	for _, rep := range r.replacements {
+		data = rep.regex.ReplaceAll(data, rep.replacement)
+	}
+
+	return data
+}
+
+// lineLocal reports whether the matches of the regular expression are the same,
+// whether it is applied to a whole body or to each of its lines separately.
+// It is the case when it cannot match a line feed, nor the beginning or the end of the whole text.
+func lineLocal(expr string) (bool, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return false, err
+	}
+
+	return !matchesAcrossLines(re), nil
+}
+
+func matchesAcrossLines(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpBeginText, syntax.OpEndText:
+		return true
+
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r == '\n' {
+				return true
+			}
+		}
+		return false
+
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= '\n' && '\n' <= re.Rune[i+1] {
+				return true
+			}
+		}
+		return false
+
+	default:
+		for _, sub := range re.Sub {
+			if matchesAcrossLines(sub) {
+				return true
+			}
+		}
+		return false
+	}
+}
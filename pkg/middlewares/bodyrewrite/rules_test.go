@@ -0,0 +1,101 @@
+package bodyrewrite
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestLineLocal(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected bool
+	}{
+		{expr: `http://backend\.local`, expected: true},
+		{expr: `(?i)</head>`, expected: true},
+		{expr: `(?m)^\s*#.*$`, expected: false},
+		{expr: `(?m)^[ \t]*#.*$`, expected: true},
+		{expr: `foo.bar`, expected: true},
+		{expr: `(?s)foo.bar`, expected: false},
+		{expr: `[^"]+`, expected: false},
+		{expr: `foo\nbar`, expected: false},
+		{expr: `^foo`, expected: false},
+		{expr: `foo$`, expected: false},
+		{expr: `\bfoo\b`, expected: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.expr, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := lineLocal(test.expr)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, ok)
+		})
+	}
+}
+
+func TestRules_matches(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		contentTypes []string
+		contentType  string
+		expected     bool
+	}{
+		{
+			desc:        "no filter",
+			contentType: "image/png",
+			expected:    true,
+		},
+		{
+			desc:         "same media type",
+			contentTypes: []string{"text/html"},
+			contentType:  "text/html; charset=utf-8",
+			expected:     true,
+		},
+		{
+			desc:         "different media type",
+			contentTypes: []string{"text/html"},
+			contentType:  "text/plain",
+		},
+		{
+			desc:         "wildcard subtype",
+			contentTypes: []string{"text/css", "application/*"},
+			contentType:  "application/problem+json",
+			expected:     true,
+		},
+		{
+			desc:         "wildcard",
+			contentTypes: []string{"*/*"},
+			contentType:  "image/png",
+			expected:     true,
+		},
+		{
+			desc:         "missing content type",
+			contentTypes: []string{"text/html"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newRules(&dynamic.BodyRewriteRules{
+				ContentTypes: test.contentTypes,
+				Replacements: []dynamic.BodyRewriteReplacement{{Regex: "foo"}},
+			})
+			require.NoError(t, err)
+
+			header := http.Header{}
+			if test.contentType != "" {
+				header.Set("Content-Type", test.contentType)
+			}
+
+			assert.Equal(t, test.expected, r.matches(header))
+		})
+	}
+}
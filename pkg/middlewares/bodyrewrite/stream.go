@@ -0,0 +1,113 @@
+package bodyrewrite
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// maxLineBytes is the length from which a line is rewritten without waiting for its end, when streaming.
+const maxLineBytes = 64 * 1024
+
+// lineWriter applies the replacements to each line of the written data, and writes the result to the underlying writer.
+// Only the complete lines are written, the last incomplete one is written on flush.
+type lineWriter struct {
+	w       io.Writer
+	rules   *rules
+	pending []byte
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.pending = append(l.pending, p...)
+
+	end := bytes.LastIndexByte(l.pending, '\n') + 1
+	if end == 0 && len(l.pending) >= maxLineBytes {
+		end = len(l.pending)
+	}
+	if end == 0 {
+		return len(p), nil
+	}
+
+	if err := l.writeLines(l.pending[:end]); err != nil {
+		return 0, err
+	}
+
+	l.pending = l.pending[:copy(l.pending, l.pending[end:])]
+
+	return len(p), nil
+}
+
+// flush writes the last incomplete line.
+func (l *lineWriter) flush() error {
+	if len(l.pending) == 0 {
+		return nil
+	}
+
+	err := l.writeLines(l.pending)
+	l.pending = l.pending[:0]
+
+	return err
+}
+
+func (l *lineWriter) writeLines(data []byte) error {
+	var out []byte
+	for len(data) > 0 {
+		line, rest, found := bytes.Cut(data, []byte("\n"))
+
+		out = append(out, l.rules.replace(line)...)
+		if found {
+			out = append(out, '\n')
+		}
+
+		data = rest
+	}
+
+	_, err := l.w.Write(out)
+	return err
+}
+
+// lineReader applies the replacements to each line of the underlying reader.
+type lineReader struct {
+	src   io.ReadCloser
+	lines *lineWriter
+	out   bytes.Buffer
+	buf   []byte
+	err   error
+}
+
+func newLineReader(src io.ReadCloser, r *rules) *lineReader {
+	l := &lineReader{
+		src: src,
+		buf: make([]byte, 32*1024),
+	}
+	l.lines = &lineWriter{w: &l.out, rules: r}
+
+	return l
+}
+
+func (l *lineReader) Read(p []byte) (int, error) {
+	for l.out.Len() == 0 && l.err == nil {
+		n, err := l.src.Read(l.buf)
+		if n > 0 {
+			// Writing to a bytes.Buffer never fails.
+			_, _ = l.lines.Write(l.buf[:n])
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				_ = l.lines.flush()
+			}
+			l.err = err
+		}
+	}
+
+	if l.out.Len() > 0 {
+		return l.out.Read(p)
+	}
+
+	return 0, l.err
+}
+
+func (l *lineReader) Close() error {
+	return l.src.Close()
+}
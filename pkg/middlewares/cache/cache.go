@@ -0,0 +1,743 @@
+// Package cache implements a middleware storing the responses of the services,
+// and serving them back following the HTTP caching semantics (RFC 9111).
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/observability/metrics"
+	"github.com/traefik/traefik/v3/pkg/safe"
+)
+
+const (
+	typeName = "Cache"
+
+	cacheStatusHeader = "Cache-Status"
+	cacheStatusName   = "Traefik"
+)
+
+// ErrNotFound is returned by Purge when there is no cache middleware with the given name.
+var ErrNotFound = errors.New("cache middleware not found")
+
+// storages holds the storages of the cache middlewares indexed by middleware name,
+// so that the stored responses survive configuration reloads and can be purged through the API.
+var storages = &storageRegistry{storages: make(map[string]*registeredStorage)}
+
+type registeredStorage struct {
+	config  dynamic.Cache
+	storage storage
+}
+
+type storageRegistry struct {
+	mu       sync.Mutex
+	storages map[string]*registeredStorage
+}
+
+// getOrCreate returns the storage of the named middleware, creating it if the middleware is new or if its configuration changed.
+func (r *storageRegistry) getOrCreate(name string, config dynamic.Cache) (storage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if registered, ok := r.storages[name]; ok && reflect.DeepEqual(registered.config, config) {
+		return registered.storage, nil
+	}
+
+	var s storage
+	if config.Disk != nil {
+		var err error
+		s, err = newDiskStorage(config.Disk.Path, config.MaxSize)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		s = newMemoryStorage(config.MaxSize)
+	}
+
+	if registered, ok := r.storages[name]; ok {
+		// The handlers built with the previous configuration may still be serving requests,
+		// and get a closed storage, which does not store anything anymore.
+		if err := registered.storage.Close(); err != nil {
+			log.Error().Err(err).Str("middlewareName", name).Msg("Unable to close cache storage")
+		}
+	}
+
+	r.storages[name] = &registeredStorage{config: config, storage: s}
+
+	return s, nil
+}
+
+// prune closes and removes the storages of the middlewares which are not in the given names.
+func (r *storageRegistry) prune(names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, registered := range r.storages {
+		if slices.Contains(names, name) {
+			continue
+		}
+
+		if err := registered.storage.Close(); err != nil {
+			log.Error().Err(err).Str("middlewareName", name).Msg("Unable to close cache storage")
+		}
+
+		delete(r.storages, name)
+	}
+}
+
+func (r *storageRegistry) get(name string) (storage, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	registered, ok := r.storages[name]
+	if !ok {
+		return nil, false
+	}
+
+	return registered.storage, true
+}
+
+// Purge removes the responses stored under the given key by the named cache middleware.
+// The key of a response is the host and the request URI of the request, e.g. "example.com/foo?bar=baz".
+// An empty key removes all the responses stored by the middleware.
+func Purge(middlewareName, key string) error {
+	s, ok := storages.get(middlewareName)
+	if !ok {
+		return ErrNotFound
+	}
+
+	if key == "" {
+		return s.Flush()
+	}
+
+	return s.Delete(key)
+}
+
+// Prune closes and removes the storages of the cache middlewares which are not in the given names,
+// i.e. the ones removed from the dynamic configuration.
+// The responses stored on disk are kept, to be served again if the middleware is added back.
+func Prune(middlewareNames []string) {
+	storages.prune(middlewareNames)
+}
+
+type cache struct {
+	name         string
+	next         http.Handler
+	storage      storage
+	maxEntrySize int64
+	defaultTTL   time.Duration
+
+	hits   gokitmetrics.Counter
+	misses gokitmetrics.Counter
+
+	// revalidating holds the keys of the responses being revalidated in the background.
+	revalidating sync.Map
+}
+
+// Validate checks the configuration of a cache middleware, without creating or replacing its storage.
+func Validate(config dynamic.Cache) error {
+	if config.MaxSize <= 0 {
+		return fmt.Errorf("invalid maxSize: %d", config.MaxSize)
+	}
+
+	if config.MaxEntrySize <= 0 {
+		return fmt.Errorf("invalid maxEntrySize: %d", config.MaxEntrySize)
+	}
+
+	if config.DefaultTTL < 0 {
+		return fmt.Errorf("negative value not valid for defaultTTL: %v", time.Duration(config.DefaultTTL))
+	}
+
+	if config.Disk != nil && config.Disk.Path == "" {
+		return errors.New("empty disk storage path")
+	}
+
+	return nil
+}
+
+// New creates a cache middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Cache, metricsRegistry metrics.Registry, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if err := Validate(config); err != nil {
+		return nil, err
+	}
+
+	s, err := storages.getOrCreate(name, config)
+	if err != nil {
+		return nil, fmt.Errorf("creating storage: %w", err)
+	}
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &cache{
+		name:         name,
+		next:         next,
+		storage:      s,
+		maxEntrySize: config.MaxEntrySize,
+		defaultTTL:   time.Duration(config.DefaultTTL),
+		hits:         metricsRegistry.CacheHitsCounter().With("middleware", name),
+		misses:       metricsRegistry.CacheMissesCounter().With("middleware", name),
+	}, nil
+}
+
+func (c *cache) GetTracingInformation() (string, string) {
+	return c.name, typeName
+}
+
+func (c *cache) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), c.name, typeName)
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodOptions, http.MethodTrace, http.MethodConnect:
+		c.next.ServeHTTP(rw, req)
+		return
+	default:
+		c.serveUnsafe(logger, rw, req)
+		return
+	}
+
+	key := cacheKey(req)
+	reqCC := parseCacheControl(req.Header)
+
+	if reqCC.has(directiveNoStore) {
+		c.misses.Add(1)
+		c.forward(logger, rw, req, key, nil, nil, "fwd=bypass")
+		return
+	}
+
+	entries, err := c.storage.Get(key)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get stored response")
+	}
+
+	var stored *entry
+	for _, e := range entries {
+		if e.matches(req) {
+			stored = e
+			break
+		}
+	}
+
+	if stored == nil {
+		if reqCC.has(directiveOnlyIfCached) {
+			c.misses.Add(1)
+			rw.Header().Set(cacheStatusHeader, cacheStatusName+"; fwd=miss")
+			rw.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+
+		reason := "fwd=uri-miss"
+		if len(entries) > 0 {
+			reason = "fwd=vary-miss"
+		}
+
+		c.misses.Add(1)
+		c.forward(logger, rw, req, key, entries, nil, reason)
+		return
+	}
+
+	now := time.Now()
+	age := stored.age(now)
+	lifetime := stored.freshnessLifetime(c.defaultTTL)
+
+	if isFresh(stored, reqCC, age, lifetime) {
+		c.hits.Add(1)
+		serve(rw, req, stored, age, "hit")
+		return
+	}
+
+	if canServeStale(stored, reqCC, age, lifetime) {
+		c.hits.Add(1)
+		serve(rw, req, stored, age, "hit")
+		c.revalidateInBackground(logger, req, key, stored)
+		return
+	}
+
+	if reqCC.has(directiveOnlyIfCached) {
+		c.misses.Add(1)
+		rw.Header().Set(cacheStatusHeader, cacheStatusName+"; fwd=stale")
+		rw.WriteHeader(http.StatusGatewayTimeout)
+		return
+	}
+
+	reason := "fwd=stale"
+	if reqCC.has(directiveNoCache) {
+		reason = "fwd=request"
+	}
+
+	// HEAD requests are not used to revalidate, as their responses would not refresh the stored body.
+	if req.Method == http.MethodHead || !hasValidators(stored) {
+		c.misses.Add(1)
+		c.forward(logger, rw, req, key, entries, nil, reason)
+		return
+	}
+
+	if c.forward(logger, rw, req, key, entries, stored, reason) {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// forward forwards the request to the next handler, and stores the response when possible.
+// When a stored response is given, the request is made conditional to revalidate it,
+// and forward reports whether the stored response has been validated and served.
+func (c *cache) forward(logger *zerolog.Logger, rw http.ResponseWriter, req *http.Request, key string, entries []*entry, stored *entry, reason string) bool {
+	outReq := req
+	crw := &responseWriter{
+		rw:          rw,
+		req:         req,
+		cacheStatus: cacheStatusName + "; " + reason,
+		isStorable: func(statusCode int, header http.Header) bool {
+			return c.isStorable(req, statusCode, header)
+		},
+		maxEntrySize: c.maxEntrySize,
+	}
+
+	if stored != nil {
+		outReq = conditionalRequest(req, stored)
+		crw.revalidating = true
+		crw.header = make(http.Header)
+	}
+
+	requestTime := time.Now()
+	c.next.ServeHTTP(crw, outReq)
+	responseTime := time.Now()
+
+	if crw.notModified {
+		updated := stored.freshen(crw.header, requestTime, responseTime)
+		c.store(logger, key, entries, updated)
+
+		serve(rw, req, updated, updated.age(responseTime), reason+"; fwd-status=304")
+		return true
+	}
+
+	if crw.storable && crw.wroteHeader {
+		c.store(logger, key, entries, crw.entry(requestTime, responseTime))
+	}
+
+	return false
+}
+
+// revalidateInBackground revalidates the stored response without blocking the request,
+// as permitted by the stale-while-revalidate directive (RFC 5861).
+func (c *cache) revalidateInBackground(logger *zerolog.Logger, req *http.Request, key string, stored *entry) {
+	if _, loaded := c.revalidating.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	outReq := req.Clone(context.WithoutCancel(req.Context()))
+	outReq.Method = http.MethodGet
+
+	safe.Go(func() {
+		defer c.revalidating.Delete(key)
+
+		entries, err := c.storage.Get(key)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to get stored response")
+		}
+
+		_ = c.forward(logger, newDiscardResponseWriter(), outReq, key, entries, stored, "fwd=stale")
+	})
+}
+
+// serveUnsafe forwards the requests with an unsafe method,
+// and invalidates the responses stored for the target URI when the request succeeds (RFC 9111 section 4.4).
+func (c *cache) serveUnsafe(logger *zerolog.Logger, rw http.ResponseWriter, req *http.Request) {
+	crw := &responseWriter{
+		rw:           rw,
+		req:          req,
+		maxEntrySize: c.maxEntrySize,
+	}
+
+	c.next.ServeHTTP(crw, req)
+
+	if crw.statusCode < http.StatusOK || crw.statusCode >= http.StatusBadRequest {
+		return
+	}
+
+	keys := []string{cacheKey(req)}
+	for _, name := range []string{"Location", "Content-Location"} {
+		value := rw.Header().Get(name)
+		if value == "" {
+			continue
+		}
+
+		location, err := req.URL.Parse(value)
+		if err != nil || location.Host != "" && location.Host != req.Host {
+			continue
+		}
+
+		keys = append(keys, req.Host+location.RequestURI())
+	}
+
+	for _, key := range keys {
+		if err := c.storage.Delete(key); err != nil {
+			logger.Error().Err(err).Str("key", key).Msg("Unable to invalidate stored response")
+		}
+	}
+}
+
+func (c *cache) store(logger *zerolog.Logger, key string, entries []*entry, e *entry) {
+	updated := make([]*entry, 0, len(entries)+1)
+	updated = append(updated, e)
+	for _, existing := range entries {
+		if !existing.sameVariant(e) {
+			updated = append(updated, existing)
+		}
+	}
+
+	if err := c.storage.Set(key, updated); err != nil {
+		logger.Error().Err(err).Str("key", key).Msg("Unable to store response")
+	}
+}
+
+// isStorable reports whether the response can be stored by a shared cache (RFC 9111 section 3).
+func (c *cache) isStorable(req *http.Request, statusCode int, header http.Header) bool {
+	if req.Method != http.MethodGet || parseCacheControl(req.Header).has(directiveNoStore) {
+		return false
+	}
+
+	// Partial content is not supported.
+	if statusCode < http.StatusOK || statusCode == http.StatusPartialContent || statusCode == http.StatusNotModified {
+		return false
+	}
+
+	cc := parseCacheControl(header)
+	if cc.has(directiveNoStore) || cc.has(directivePrivate) {
+		return false
+	}
+
+	if req.Header.Get("Authorization") != "" &&
+		!cc.has(directiveMustRevalidate) && !cc.has(directivePublic) && !cc.has(directiveSMaxAge) {
+		return false
+	}
+
+	// Responses setting cookies are specific to a client.
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+
+	if _, ok := varyValues(req, header.Values("Vary")); !ok {
+		return false
+	}
+
+	if header.Get("Expires") != "" || cc.has(directiveMaxAge) || cc.has(directiveSMaxAge) || cc.has(directivePublic) {
+		return true
+	}
+
+	return heuristicallyCacheable(statusCode) &&
+		(c.defaultTTL > 0 || header.Get("ETag") != "" || header.Get("Last-Modified") != "")
+}
+
+// isFresh reports whether the stored response can be served without revalidation (RFC 9111 section 4.2).
+func isFresh(stored *entry, reqCC cacheControl, age, lifetime time.Duration) bool {
+	if reqCC.has(directiveNoCache) || parseCacheControl(stored.Header).has(directiveNoCache) {
+		return false
+	}
+
+	if maxAge, ok := reqCC.duration(directiveMaxAge); ok && age > maxAge {
+		return false
+	}
+
+	if minFresh, ok := reqCC.duration(directiveMinFresh); ok && lifetime-age < minFresh {
+		return false
+	}
+
+	if lifetime > age {
+		return true
+	}
+
+	if !reqCC.has(directiveMaxStale) || stored.mustRevalidate() {
+		return false
+	}
+
+	// A max-stale directive without value means that the client accepts a stale response of any age.
+	if reqCC[directiveMaxStale] == "" {
+		return true
+	}
+
+	maxStale, _ := reqCC.duration(directiveMaxStale)
+	return age-lifetime <= maxStale
+}
+
+// canServeStale reports whether the stale response can be served while being revalidated in the background.
+func canServeStale(stored *entry, reqCC cacheControl, age, lifetime time.Duration) bool {
+	if reqCC.has(directiveNoCache) || reqCC.has(directiveMaxAge) || reqCC.has(directiveMinFresh) ||
+		stored.mustRevalidate() || !hasValidators(stored) || age < lifetime {
+		return false
+	}
+
+	staleWhileRevalidate, ok := parseCacheControl(stored.Header).duration(directiveStaleWhileRevalidate)
+	if !ok {
+		return false
+	}
+
+	return age-lifetime <= staleWhileRevalidate
+}
+
+func hasValidators(stored *entry) bool {
+	return stored.Header.Get("ETag") != "" || stored.Header.Get("Last-Modified") != ""
+}
+
+// conditionalRequest returns a copy of the request, made conditional with the validators of the stored response.
+func conditionalRequest(req *http.Request, stored *entry) *http.Request {
+	outReq := req.Clone(req.Context())
+
+	outReq.Header.Del("If-None-Match")
+	outReq.Header.Del("If-Modified-Since")
+	outReq.Header.Del("If-Match")
+	outReq.Header.Del("If-Unmodified-Since")
+	outReq.Header.Del("If-Range")
+
+	if etag := stored.Header.Get("ETag"); etag != "" {
+		outReq.Header.Set("If-None-Match", etag)
+	}
+
+	if lastModified := stored.Header.Get("Last-Modified"); lastModified != "" {
+		outReq.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	return outReq
+}
+
+// serve writes the stored response, or a 304 (Not Modified) response if the request is conditional and the stored response matches it.
+func serve(rw http.ResponseWriter, req *http.Request, stored *entry, age time.Duration, status string) {
+	header := rw.Header()
+	for name, values := range stored.Header {
+		header[name] = append([]string(nil), values...)
+	}
+
+	header.Set("Age", strconv.FormatInt(int64(age.Seconds()), 10))
+	header.Set(cacheStatusHeader, cacheStatusName+"; "+status)
+
+	if notModified(req, stored) {
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(stored.Body)))
+	rw.WriteHeader(stored.StatusCode)
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = rw.Write(stored.Body)
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since preconditions of the request against the stored response (RFC 9110 section 13.2.2).
+func notModified(req *http.Request, stored *entry) bool {
+	if stored.StatusCode != http.StatusOK {
+		return false
+	}
+
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		etag := strings.TrimPrefix(stored.Header.Get("ETag"), "W/")
+		if etag == "" {
+			return false
+		}
+
+		for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	lastModified, err := http.ParseTime(stored.Header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.After(ifModifiedSince)
+}
+
+// cacheKey returns the key under which the responses to the given request are stored.
+func cacheKey(req *http.Request) string {
+	return req.Host + req.URL.RequestURI()
+}
+
+var _ middlewares.Stateful = &responseWriter{}
+
+// responseWriter forwards the response of the service to the client, while recording it to be stored.
+type responseWriter struct {
+	rw           http.ResponseWriter
+	req          *http.Request
+	cacheStatus  string
+	isStorable   func(statusCode int, header http.Header) bool
+	maxEntrySize int64
+
+	// revalidating is set when the forwarded request is a revalidation,
+	// in which case a 304 (Not Modified) response is not forwarded to the client,
+	// and the response header fields are written to header until the status code is known.
+	revalidating bool
+	notModified  bool
+	header       http.Header
+
+	statusCode  int
+	wroteHeader bool
+	storable    bool
+	body        bytes.Buffer
+}
+
+func (r *responseWriter) Header() http.Header {
+	if r.revalidating {
+		return r.header
+	}
+
+	return r.rw.Header()
+}
+
+func (r *responseWriter) WriteHeader(statusCode int) {
+	if r.wroteHeader {
+		return
+	}
+
+	// Informational responses are forwarded as is.
+	if statusCode < http.StatusOK {
+		r.rw.WriteHeader(statusCode)
+		return
+	}
+
+	r.statusCode = statusCode
+	r.wroteHeader = true
+
+	if r.revalidating {
+		if statusCode == http.StatusNotModified {
+			r.notModified = true
+			return
+		}
+
+		r.revalidating = false
+		for name, values := range r.header {
+			r.rw.Header()[name] = values
+		}
+	}
+
+	r.storable = r.isStorable != nil && r.isStorable(statusCode, r.rw.Header())
+
+	if r.cacheStatus != "" {
+		r.rw.Header().Set(cacheStatusHeader, r.cacheStatus)
+	}
+
+	r.rw.WriteHeader(statusCode)
+}
+
+func (r *responseWriter) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if r.notModified {
+		return len(b), nil
+	}
+
+	n, err := r.rw.Write(b)
+	if err != nil {
+		// The client did not get the whole body, so neither would the stored response.
+		r.storable = false
+		r.body = bytes.Buffer{}
+	}
+
+	if r.storable {
+		if int64(r.body.Len()+n) > r.maxEntrySize {
+			r.storable = false
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(b[:n])
+		}
+	}
+
+	return n, err
+}
+
+func (r *responseWriter) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+
+	r.storable = false
+
+	return hijacker.Hijack()
+}
+
+// entry returns the recorded response.
+func (r *responseWriter) entry(requestTime, responseTime time.Time) *entry {
+	header := r.rw.Header().Clone()
+	header.Del(cacheStatusHeader)
+
+	if header.Get("Date") == "" {
+		header.Set("Date", responseTime.UTC().Format(http.TimeFormat))
+	}
+
+	values, _ := varyValues(r.req, header.Values("Vary"))
+
+	return &entry{
+		StatusCode:   r.statusCode,
+		Header:       header,
+		Body:         bytes.Clone(r.body.Bytes()),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+		VaryValues:   values,
+	}
+}
+
+// discardResponseWriter is the response writer used for the background revalidations.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func newDiscardResponseWriter() *discardResponseWriter {
+	return &discardResponseWriter{header: make(http.Header)}
+}
+
+func (d *discardResponseWriter) Header() http.Header {
+	return d.header
+}
+
+func (d *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (d *discardResponseWriter) WriteHeader(int) {}
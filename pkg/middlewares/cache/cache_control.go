@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache-Control directives, as defined in RFC 9111 section 5.2.
+const (
+	directiveMaxAge               = "max-age"
+	directiveMaxStale             = "max-stale"
+	directiveMinFresh             = "min-fresh"
+	directiveMustRevalidate       = "must-revalidate"
+	directiveNoCache              = "no-cache"
+	directiveNoStore              = "no-store"
+	directiveOnlyIfCached         = "only-if-cached"
+	directivePrivate              = "private"
+	directiveProxyRevalidate      = "proxy-revalidate"
+	directivePublic               = "public"
+	directiveSMaxAge              = "s-maxage"
+	directiveStaleWhileRevalidate = "stale-while-revalidate"
+)
+
+// cacheControl holds the directives of Cache-Control header fields, indexed by their lower-cased name.
+type cacheControl map[string]string
+
+// parseCacheControl parses the Cache-Control header fields of the given header.
+// When the request does not define any Cache-Control directive,
+// the "Pragma: no-cache" header field is interpreted as a "no-cache" directive.
+func parseCacheControl(header http.Header) cacheControl {
+	cc := cacheControl{}
+
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range splitDirectives(value) {
+			name, arg, _ := strings.Cut(directive, "=")
+
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+
+			cc[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+
+	if len(cc) == 0 && strings.EqualFold(strings.TrimSpace(header.Get("Pragma")), directiveNoCache) {
+		cc[directiveNoCache] = ""
+	}
+
+	return cc
+}
+
+func (c cacheControl) has(directive string) bool {
+	_, ok := c[directive]
+	return ok
+}
+
+// duration returns the value of a delta-seconds directive.
+// An invalid value is interpreted as zero, which makes the response stale (RFC 9111 section 1.2.2).
+func (c cacheControl) duration(directive string) (time.Duration, bool) {
+	value, ok := c[directive]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, true
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// splitDirectives splits a Cache-Control header field value on commas, ignoring the ones within quoted strings.
+func splitDirectives(value string) []string {
+	var directives []string
+
+	var quoted bool
+	var start int
+	for i := range len(value) {
+		switch value[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				directives = append(directives, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(directives, value[start:])
+}
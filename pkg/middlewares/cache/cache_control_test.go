@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseCacheControl(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		header   http.Header
+		expected cacheControl
+	}{
+		{
+			desc:     "no directive",
+			header:   http.Header{},
+			expected: cacheControl{},
+		},
+		{
+			desc:     "directives with and without argument",
+			header:   http.Header{"Cache-Control": {"Public, max-age=60", `s-maxage="120"`}},
+			expected: cacheControl{"public": "", "max-age": "60", "s-maxage": "120"},
+		},
+		{
+			desc:     "quoted argument containing a comma",
+			header:   http.Header{"Cache-Control": {`private="Set-Cookie, Foo", no-cache`}},
+			expected: cacheControl{"private": "Set-Cookie, Foo", "no-cache": ""},
+		},
+		{
+			desc:     "Pragma no-cache",
+			header:   http.Header{"Pragma": {"no-cache"}},
+			expected: cacheControl{"no-cache": ""},
+		},
+		{
+			desc:     "Pragma ignored when Cache-Control is present",
+			header:   http.Header{"Pragma": {"no-cache"}, "Cache-Control": {"max-age=60"}},
+			expected: cacheControl{"max-age": "60"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, parseCacheControl(test.header))
+		})
+	}
+}
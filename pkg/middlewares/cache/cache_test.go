@@ -0,0 +1,436 @@
+package cache
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+type testRequest struct {
+	method              string
+	header              http.Header
+	expectedStatusCode  int
+	expectedBody        string
+	expectedCacheStatus string
+}
+
+func TestCache(t *testing.T) {
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	testCases := []struct {
+		desc          string
+		config        dynamic.Cache
+		handler       func(rw http.ResponseWriter, req *http.Request)
+		requests      []testRequest
+		expectedCalls int64
+	}{
+		{
+			desc: "fresh response is served from the cache",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=uri-miss"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; hit"},
+				{method: http.MethodHead, expectedStatusCode: http.StatusOK, expectedCacheStatus: "Traefik; hit"},
+			},
+			expectedCalls: 1,
+		},
+		{
+			desc: "no-store response is not stored",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "no-store, max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=uri-miss"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=uri-miss"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "private response is not stored",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "private, max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "response setting a cookie is not stored",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("Set-Cookie", "foo=bar")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "response to an authorized request is not stored without public directive",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{header: http.Header{"Authorization": {"Bearer foo"}}, expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{header: http.Header{"Authorization": {"Bearer foo"}}, expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "response to an authorized request is stored with public directive",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "public, max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{header: http.Header{"Authorization": {"Bearer foo"}}, expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{header: http.Header{"Authorization": {"Bearer foo"}}, expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; hit"},
+			},
+			expectedCalls: 1,
+		},
+		{
+			desc: "response larger than maxEntrySize is not stored",
+			config: dynamic.Cache{
+				MaxEntrySize: 2,
+			},
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=uri-miss"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "expired response is served from the cache when Expires is in the future",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Expires", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; hit"},
+			},
+			expectedCalls: 1,
+		},
+		{
+			desc: "response with a past Expires is revalidated",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Expires", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+				rw.Header().Set("ETag", `"v1"`)
+				if req.Header.Get("If-None-Match") == `"v1"` {
+					rw.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=stale; fwd-status=304"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "request no-cache directive forces a revalidation with Last-Modified",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("Last-Modified", lastModified)
+				if req.Header.Get("If-Modified-Since") == lastModified {
+					rw.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{header: http.Header{"Cache-Control": {"no-cache"}}, expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=request; fwd-status=304"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; hit"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "revalidation replaces the stored response when it changed",
+			handler: func() func(rw http.ResponseWriter, req *http.Request) {
+				var count atomic.Int64
+				return func(rw http.ResponseWriter, req *http.Request) {
+					rw.Header().Set("Cache-Control", "max-age=0")
+					rw.Header().Set("ETag", `"v`+string(rune('0'+count.Add(1)))+`"`)
+					_, _ = rw.Write([]byte("foo"))
+				}
+			}(),
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=stale"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "conditional request is answered from the cache",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("ETag", `W/"v1"`)
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{header: http.Header{"If-None-Match": {`"v0", "v1"`}}, expectedStatusCode: http.StatusNotModified, expectedCacheStatus: "Traefik; hit"},
+			},
+			expectedCalls: 1,
+		},
+		{
+			desc: "variants are selected with the Vary header",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("Vary", "Accept-Language")
+				_, _ = rw.Write([]byte(req.Header.Get("Accept-Language")))
+			},
+			requests: []testRequest{
+				{header: http.Header{"Accept-Language": {"fr"}}, expectedStatusCode: http.StatusOK, expectedBody: "fr", expectedCacheStatus: "Traefik; fwd=uri-miss"},
+				{header: http.Header{"Accept-Language": {"en"}}, expectedStatusCode: http.StatusOK, expectedBody: "en", expectedCacheStatus: "Traefik; fwd=vary-miss"},
+				{header: http.Header{"Accept-Language": {"fr"}}, expectedStatusCode: http.StatusOK, expectedBody: "fr", expectedCacheStatus: "Traefik; hit"},
+				{header: http.Header{"Accept-Language": {"en"}}, expectedStatusCode: http.StatusOK, expectedBody: "en", expectedCacheStatus: "Traefik; hit"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "Vary: * response is not stored",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				rw.Header().Set("Vary", "*")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=uri-miss"},
+			},
+			expectedCalls: 2,
+		},
+		{
+			desc: "unsafe request invalidates the stored response",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Cache-Control", "max-age=60")
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{method: http.MethodPost, expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=uri-miss"},
+			},
+			expectedCalls: 3,
+		},
+		{
+			desc: "only-if-cached request is answered with a 504 on miss",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{header: http.Header{"Cache-Control": {"only-if-cached"}}, expectedStatusCode: http.StatusGatewayTimeout, expectedCacheStatus: "Traefik; fwd=miss"},
+			},
+			expectedCalls: 0,
+		},
+		{
+			desc: "response without freshness information is stored with defaultTTL",
+			config: dynamic.Cache{
+				DefaultTTL: ptypes.Duration(time.Minute),
+			},
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; hit"},
+			},
+			expectedCalls: 1,
+		},
+		{
+			desc: "response without freshness information nor validators is not stored",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("foo"))
+			},
+			requests: []testRequest{
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo"},
+				{expectedStatusCode: http.StatusOK, expectedBody: "foo", expectedCacheStatus: "Traefik; fwd=uri-miss"},
+			},
+			expectedCalls: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int64
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls.Add(1)
+				test.handler(rw, req)
+			})
+
+			config := dynamic.Cache{}
+			config.SetDefaults()
+			if test.config.MaxEntrySize > 0 {
+				config.MaxEntrySize = test.config.MaxEntrySize
+			}
+			config.DefaultTTL = test.config.DefaultTTL
+
+			handler, err := New(t.Context(), next, config, nil, t.Name())
+			require.NoError(t, err)
+
+			// The storages outlive the middlewares, flush them for the test to be repeatable.
+			t.Cleanup(func() { _ = Purge(t.Name(), "") })
+
+			for i, r := range test.requests {
+				method := r.method
+				if method == "" {
+					method = http.MethodGet
+				}
+
+				req := httptest.NewRequest(method, "http://localhost/foo?bar=baz", nil)
+				for name, values := range r.header {
+					req.Header[name] = values
+				}
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+
+				assert.Equal(t, r.expectedStatusCode, recorder.Code, "request %d", i)
+				assert.Equal(t, r.expectedBody, recorder.Body.String(), "request %d", i)
+				if r.expectedCacheStatus != "" {
+					assert.Equal(t, r.expectedCacheStatus, recorder.Header().Get(cacheStatusHeader), "request %d", i)
+				}
+			}
+
+			assert.Equal(t, test.expectedCalls, calls.Load())
+		})
+	}
+}
+
+func TestCache_staleWhileRevalidate(t *testing.T) {
+	var calls atomic.Int64
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+
+		rw.Header().Set("Cache-Control", "max-age=1, stale-while-revalidate=60")
+		rw.Header().Set("Age", "5")
+		rw.Header().Set("ETag", `"v1"`)
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	config := dynamic.Cache{}
+	config.SetDefaults()
+
+	handler, err := New(t.Context(), next, config, nil, t.Name())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = Purge(t.Name(), "") })
+
+	for range 2 {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "foo", recorder.Body.String())
+	}
+
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+type failingResponseWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (f failingResponseWriter) Write(b []byte) (int, error) {
+	n, _ := f.ResponseRecorder.Write(b[:len(b)/2])
+	return n, errors.New("connection reset by peer")
+}
+
+func TestCache_writeError(t *testing.T) {
+	var calls atomic.Int64
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foobar"))
+	})
+
+	config := dynamic.Cache{}
+	config.SetDefaults()
+
+	handler, err := New(t.Context(), next, config, nil, t.Name())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = Purge(t.Name(), "") })
+
+	handler.ServeHTTP(failingResponseWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+	// The truncated response has not been stored.
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil))
+
+	assert.Equal(t, "foobar", recorder.Body.String())
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+func TestPurge(t *testing.T) {
+	var calls atomic.Int64
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls.Add(1)
+
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	config := dynamic.Cache{}
+	config.SetDefaults()
+
+	handler, err := New(t.Context(), next, config, nil, t.Name())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = Purge(t.Name(), "") })
+
+	serve := func(target string) string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder.Header().Get(cacheStatusHeader)
+	}
+
+	serve("http://localhost/foo")
+	serve("http://localhost/bar")
+	assert.Equal(t, "Traefik; hit", serve("http://localhost/foo"))
+
+	require.NoError(t, Purge(t.Name(), "localhost/foo"))
+	assert.True(t, strings.HasPrefix(serve("http://localhost/foo"), "Traefik; fwd="))
+	assert.Equal(t, "Traefik; hit", serve("http://localhost/bar"))
+
+	require.NoError(t, Purge(t.Name(), ""))
+	assert.True(t, strings.HasPrefix(serve("http://localhost/bar"), "Traefik; fwd="))
+
+	assert.ErrorIs(t, Purge("unknown", ""), ErrNotFound)
+	assert.Equal(t, int64(4), calls.Load())
+}
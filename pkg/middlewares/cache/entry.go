@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry is a response stored by the cache.
+// Its fields are exported to be encoded by the disk storage.
+type entry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// RequestTime is the time at which the request which produced the response was forwarded.
+	RequestTime time.Time
+	// ResponseTime is the time at which the response was received.
+	ResponseTime time.Time
+
+	// VaryValues holds the values of the request header fields nominated by the Vary header field of the response.
+	VaryValues map[string]string
+}
+
+func (e *entry) size() int64 {
+	size := int64(len(e.Body))
+	for name, values := range e.Header {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+
+	return size
+}
+
+// date returns the value of the Date header field, or the response time if it is missing or invalid.
+func (e *entry) date() time.Time {
+	date, err := http.ParseTime(e.Header.Get("Date"))
+	if err != nil {
+		return e.ResponseTime
+	}
+
+	return date
+}
+
+// age returns the current age of the stored response, as defined in RFC 9111 section 4.2.3.
+func (e *entry) age(now time.Time) time.Duration {
+	apparentAge := max(0, e.ResponseTime.Sub(e.date()))
+
+	var ageValue time.Duration
+	if seconds, err := strconv.ParseInt(strings.TrimSpace(e.Header.Get("Age")), 10, 64); err == nil && seconds > 0 {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+
+	responseDelay := e.ResponseTime.Sub(e.RequestTime)
+	correctedInitialAge := max(apparentAge, ageValue+responseDelay)
+	residentTime := now.Sub(e.ResponseTime)
+
+	return correctedInitialAge + residentTime
+}
+
+// freshnessLifetime returns the freshness lifetime of the stored response, as defined in RFC 9111 section 4.2.1.
+func (e *entry) freshnessLifetime(defaultTTL time.Duration) time.Duration {
+	cc := parseCacheControl(e.Header)
+
+	if lifetime, ok := cc.duration(directiveSMaxAge); ok {
+		return lifetime
+	}
+
+	if lifetime, ok := cc.duration(directiveMaxAge); ok {
+		return lifetime
+	}
+
+	if expires := e.Header.Get("Expires"); expires != "" {
+		expiresTime, err := http.ParseTime(expires)
+		if err != nil {
+			// An invalid Expires value represents a time in the past.
+			return 0
+		}
+
+		return expiresTime.Sub(e.date())
+	}
+
+	// Heuristic freshness: 10% of the time since the last modification (RFC 9111 section 4.2.2).
+	if lastModified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil && heuristicallyCacheable(e.StatusCode) {
+		if lifetime := e.date().Sub(lastModified) / 10; lifetime > 0 {
+			return lifetime
+		}
+	}
+
+	return defaultTTL
+}
+
+// mustRevalidate reports whether the stored response must not be served stale.
+func (e *entry) mustRevalidate() bool {
+	cc := parseCacheControl(e.Header)
+	return cc.has(directiveMustRevalidate) || cc.has(directiveProxyRevalidate) || cc.has(directiveSMaxAge) || cc.has(directiveNoCache)
+}
+
+// matches reports whether the stored response can be used for the given request,
+// according to the Vary header field of the response (RFC 9111 section 4.1).
+func (e *entry) matches(req *http.Request) bool {
+	for name, value := range e.VaryValues {
+		if strings.Join(req.Header.Values(name), ",") != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameVariant reports whether both stored responses were selected by the same request header field values.
+func (e *entry) sameVariant(other *entry) bool {
+	if len(e.VaryValues) != len(other.VaryValues) {
+		return false
+	}
+
+	for name, value := range e.VaryValues {
+		if otherValue, ok := other.VaryValues[name]; !ok || otherValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// freshen updates the stored response with the header fields of a 304 (Not Modified) response (RFC 9111 section 4.3.4).
+func (e *entry) freshen(header http.Header, requestTime, responseTime time.Time) *entry {
+	updated := &entry{
+		StatusCode:   e.StatusCode,
+		Header:       e.Header.Clone(),
+		Body:         e.Body,
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+		VaryValues:   e.VaryValues,
+	}
+
+	for name, values := range header {
+		switch name {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding", cacheStatusHeader:
+			continue
+		}
+
+		updated.Header[name] = values
+	}
+
+	return updated
+}
+
+// heuristicallyCacheable reports whether a response with the given status code can be stored without explicit freshness information
+// (RFC 9110 section 15.1).
+func heuristicallyCacheable(statusCode int) bool {
+	switch statusCode {
+	case http.StatusOK,
+		http.StatusNonAuthoritativeInfo,
+		http.StatusNoContent,
+		http.StatusMultipleChoices,
+		http.StatusMovedPermanently,
+		http.StatusPermanentRedirect,
+		http.StatusNotFound,
+		http.StatusMethodNotAllowed,
+		http.StatusGone,
+		http.StatusRequestURITooLong,
+		http.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// varyValues returns the values of the request header fields nominated by the given Vary header field values,
+// and whether the response can be stored at all ("Vary: *" never matches).
+func varyValues(req *http.Request, vary []string) (map[string]string, bool) {
+	values := make(map[string]string)
+	for _, field := range vary {
+		for name := range strings.SplitSeq(field, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			if name == "*" {
+				return nil, false
+			}
+
+			name = http.CanonicalHeaderKey(name)
+			values[name] = strings.Join(req.Header.Values(name), ",")
+		}
+	}
+
+	return values, true
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// storage stores the responses of a cache middleware.
+// A key holds all the variants (see the Vary header field) of the response to a request target.
+type storage interface {
+	Get(key string) ([]*entry, error)
+	Set(key string, entries []*entry) error
+	Delete(key string) error
+	Flush() error
+	// Close releases the storage, which does not store anything anymore.
+	Close() error
+}
+
+type memoryRecord struct {
+	key     string
+	entries []*entry
+	size    int64
+}
+
+// memoryStorage is an in-memory storage, evicting the least recently used responses when its maximum size is reached.
+type memoryStorage struct {
+	mu      sync.Mutex
+	closed  bool
+	maxSize int64
+	size    int64
+	lru     *list.List
+	records map[string]*list.Element
+}
+
+func newMemoryStorage(maxSize int64) *memoryStorage {
+	return &memoryStorage{
+		maxSize: maxSize,
+		lru:     list.New(),
+		records: make(map[string]*list.Element),
+	}
+}
+
+func (m *memoryStorage) Get(key string) ([]*entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elt, ok := m.records[key]
+	if !ok {
+		return nil, nil
+	}
+
+	m.lru.MoveToFront(elt)
+
+	return elt.Value.(*memoryRecord).entries, nil
+}
+
+func (m *memoryStorage) Set(key string, entries []*entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(key)
+
+	if m.closed {
+		return nil
+	}
+
+	record := &memoryRecord{key: key, entries: entries}
+	for _, e := range entries {
+		record.size += e.size()
+	}
+
+	if record.size > m.maxSize {
+		return nil
+	}
+
+	m.records[key] = m.lru.PushFront(record)
+	m.size += record.size
+
+	for m.size > m.maxSize {
+		m.remove(m.lru.Back().Value.(*memoryRecord).key)
+	}
+
+	return nil
+}
+
+func (m *memoryStorage) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(key)
+
+	return nil
+}
+
+func (m *memoryStorage) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.size = 0
+	m.lru.Init()
+	clear(m.records)
+
+	return nil
+}
+
+func (m *memoryStorage) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	m.size = 0
+	m.lru.Init()
+	clear(m.records)
+
+	return nil
+}
+
+func (m *memoryStorage) remove(key string) {
+	elt, ok := m.records[key]
+	if !ok {
+		return
+	}
+
+	m.lru.Remove(elt)
+	delete(m.records, key)
+	m.size -= elt.Value.(*memoryRecord).size
+}
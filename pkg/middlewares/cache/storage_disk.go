@@ -0,0 +1,249 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type diskRecord struct {
+	name string
+	size int64
+}
+
+// diskStorage stores the responses in files, named after the hash of their key,
+// and evicts the least recently used ones when its maximum size is reached.
+// The files found in the directory at creation time are indexed, so that the stored responses survive a restart.
+type diskStorage struct {
+	mu      sync.Mutex
+	closed  bool
+	path    string
+	maxSize int64
+	size    int64
+	lru     *list.List
+	records map[string]*list.Element
+}
+
+func newDiskStorage(path string, maxSize int64) (*diskStorage, error) {
+	if path == "" {
+		return nil, errors.New("empty disk storage path")
+	}
+
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, fmt.Errorf("creating disk storage directory: %w", err)
+	}
+
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading disk storage directory: %w", err)
+	}
+
+	var infos []fs.FileInfo
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.Type().IsRegular() {
+			continue
+		}
+
+		// Leftover of an interrupted write.
+		if strings.HasPrefix(dirEntry.Name(), ".") {
+			_ = os.Remove(filepath.Join(path, dirEntry.Name()))
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		infos = append(infos, info)
+	}
+
+	// Index the files from the most recently modified to the least recently modified one.
+	slices.SortFunc(infos, func(a, b fs.FileInfo) int {
+		return b.ModTime().Compare(a.ModTime())
+	})
+
+	d := &diskStorage{
+		path:    path,
+		maxSize: maxSize,
+		lru:     list.New(),
+		records: make(map[string]*list.Element),
+	}
+
+	for _, info := range infos {
+		d.records[info.Name()] = d.lru.PushBack(&diskRecord{name: info.Name(), size: info.Size()})
+		d.size += info.Size()
+	}
+
+	if err := d.evict(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *diskStorage) Get(key string) ([]*entry, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	name := fileName(key)
+
+	elt, ok := d.records[name]
+	if !ok {
+		return nil, nil
+	}
+
+	file, err := os.Open(filepath.Join(d.path, name))
+	if err != nil {
+		d.lru.Remove(elt)
+		delete(d.records, name)
+		d.size -= elt.Value.(*diskRecord).size
+
+		return nil, fmt.Errorf("opening stored response: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []*entry
+	if err := gob.NewDecoder(file).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decoding stored response: %w", err)
+	}
+
+	d.lru.MoveToFront(elt)
+
+	return entries, nil
+}
+
+func (d *diskStorage) Set(key string, entries []*entry) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return nil
+	}
+
+	name := fileName(key)
+
+	file, err := os.CreateTemp(d.path, "."+name)
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+
+	if err := gob.NewEncoder(file).Encode(entries); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("encoding response: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("getting file information: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("closing temporary file: %w", err)
+	}
+
+	if info.Size() > d.maxSize {
+		_ = os.Remove(file.Name())
+
+		return d.remove(name)
+	}
+
+	if err := os.Rename(file.Name(), filepath.Join(d.path, name)); err != nil {
+		_ = os.Remove(file.Name())
+
+		return fmt.Errorf("renaming temporary file: %w", err)
+	}
+
+	if elt, ok := d.records[name]; ok {
+		d.lru.Remove(elt)
+		d.size -= elt.Value.(*diskRecord).size
+	}
+
+	d.records[name] = d.lru.PushFront(&diskRecord{name: name, size: info.Size()})
+	d.size += info.Size()
+
+	return d.evict()
+}
+
+func (d *diskStorage) Delete(key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.remove(fileName(key))
+}
+
+func (d *diskStorage) Flush() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var errs []error
+	for name := range d.records {
+		if err := d.remove(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops indexing the files of the directory, which are left in place
+// for another storage on the same directory to serve them.
+func (d *diskStorage) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	d.size = 0
+	d.lru.Init()
+	clear(d.records)
+
+	return nil
+}
+
+func (d *diskStorage) evict() error {
+	for d.size > d.maxSize {
+		if err := d.remove(d.lru.Back().Value.(*diskRecord).name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *diskStorage) remove(name string) error {
+	elt, ok := d.records[name]
+	if !ok {
+		return nil
+	}
+
+	d.lru.Remove(elt)
+	delete(d.records, name)
+	d.size -= elt.Value.(*diskRecord).size
+
+	if err := os.Remove(filepath.Join(d.path, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("removing stored response: %w", err)
+	}
+
+	return nil
+}
+
+func fileName(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestStorage(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		newStorage func(t *testing.T, maxSize int64) storage
+	}{
+		{
+			desc: "memory",
+			newStorage: func(t *testing.T, maxSize int64) storage {
+				t.Helper()
+
+				return newMemoryStorage(maxSize)
+			},
+		},
+		{
+			desc: "disk",
+			newStorage: func(t *testing.T, maxSize int64) storage {
+				t.Helper()
+
+				s, err := newDiskStorage(t.TempDir(), maxSize)
+				require.NoError(t, err)
+
+				return s
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			foo := []*entry{{StatusCode: http.StatusOK, Header: http.Header{}, Body: make([]byte, 512)}}
+
+			// Big enough for two responses, whatever their encoding is.
+			s := test.newStorage(t, 1500)
+
+			entries, err := s.Get("foo")
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			require.NoError(t, s.Set("foo", foo))
+			require.NoError(t, s.Set("bar", foo))
+
+			// Use foo, so that bar is the least recently used response.
+			entries, err = s.Get("foo")
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, foo[0].Body, entries[0].Body)
+
+			require.NoError(t, s.Set("baz", foo))
+
+			entries, err = s.Get("bar")
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			entries, err = s.Get("foo")
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+
+			require.NoError(t, s.Delete("foo"))
+
+			entries, err = s.Get("foo")
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			require.NoError(t, s.Flush())
+
+			entries, err = s.Get("baz")
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			// A response larger than the storage is not stored.
+			require.NoError(t, s.Set("foo", []*entry{{StatusCode: http.StatusOK, Body: make([]byte, 2048)}}))
+
+			entries, err = s.Get("foo")
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			// A closed storage does not store anything anymore.
+			require.NoError(t, s.Set("foo", foo))
+			require.NoError(t, s.Close())
+			require.NoError(t, s.Set("bar", foo))
+
+			entries, err = s.Get("foo")
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			entries, err = s.Get("bar")
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestStorageRegistry(t *testing.T) {
+	registry := &storageRegistry{storages: make(map[string]*registeredStorage)}
+
+	config := dynamic.Cache{}
+	config.SetDefaults()
+
+	foo, err := registry.getOrCreate("foo", config)
+	require.NoError(t, err)
+	require.NoError(t, foo.Set("key", []*entry{{StatusCode: http.StatusOK, Body: []byte("foo")}}))
+
+	same, err := registry.getOrCreate("foo", config)
+	require.NoError(t, err)
+	assert.Same(t, foo, same)
+
+	bar, err := registry.getOrCreate("bar", config)
+	require.NoError(t, err)
+
+	// The storage replaced by a configuration change is closed.
+	config.MaxSize *= 2
+	replaced, err := registry.getOrCreate("foo", config)
+	require.NoError(t, err)
+	assert.NotSame(t, foo, replaced)
+
+	entries, err := foo.Get("key")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, replaced.Set("key", []*entry{{StatusCode: http.StatusOK, Body: []byte("foo")}}))
+	require.NoError(t, bar.Set("key", []*entry{{StatusCode: http.StatusOK, Body: []byte("bar")}}))
+
+	registry.prune([]string{"foo"})
+
+	_, ok := registry.get("bar")
+	assert.False(t, ok)
+
+	entries, err = bar.Get("key")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	s, ok := registry.get("foo")
+	require.True(t, ok)
+
+	entries, err = s.Get("key")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestDiskStorage_restart(t *testing.T) {
+	path := t.TempDir()
+
+	s, err := newDiskStorage(path, 1024)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Set("foo", []*entry{{StatusCode: http.StatusOK, Body: []byte("foo")}}))
+
+	// Leftover of an interrupted write.
+	require.NoError(t, os.WriteFile(filepath.Join(path, ".leftover"), []byte("foo"), 0o600))
+
+	s, err = newDiskStorage(path, 1024)
+	require.NoError(t, err)
+
+	entries, err := s.Get("foo")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []byte("foo"), entries[0].Body)
+
+	assert.NoFileExists(t, filepath.Join(path, ".leftover"))
+}
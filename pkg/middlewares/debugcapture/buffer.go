@@ -0,0 +1,164 @@
+package debugcapture
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Record is a request captured by a debug capture middleware, along with its response.
+type Record struct {
+	ID         uint64        `json:"id"`
+	Date       time.Time     `json:"date"`
+	Middleware string        `json:"middleware"`
+	RemoteAddr string        `json:"remoteAddr,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Request    Message       `json:"request"`
+	Response   Message       `json:"response"`
+}
+
+// Message is the captured request or response of a Record.
+type Message struct {
+	Method     string      `json:"method,omitempty"`
+	Host       string      `json:"host,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	Proto      string      `json:"proto,omitempty"`
+	StatusCode int         `json:"statusCode,omitempty"`
+	Header     http.Header `json:"header,omitempty"`
+	// Body holds the first bytes of the body, up to the MaxBodyBytes option of the middleware.
+	Body string `json:"body,omitempty"`
+	// BodySize is the number of bytes of the body read from the client or written to it.
+	BodySize int64 `json:"bodySize"`
+}
+
+// ErrNotFound is returned when there is no debug capture middleware with the given name.
+var ErrNotFound = errors.New("debug capture middleware not found")
+
+// buffers holds the records of the debug capture middlewares indexed by middleware name,
+// so that they survive configuration reloads and can be read through the API.
+var buffers = &bufferRegistry{buffers: make(map[string]*buffer)}
+
+type bufferRegistry struct {
+	mu      sync.Mutex
+	buffers map[string]*buffer
+}
+
+// getOrCreate returns the buffer of the named middleware, creating it if the middleware is new or if its size changed.
+func (r *bufferRegistry) getOrCreate(name string, size int) *buffer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if b, ok := r.buffers[name]; ok && len(b.records) == size {
+		return b
+	}
+
+	b := newBuffer(size)
+	r.buffers[name] = b
+
+	return b
+}
+
+// prune removes the buffers of the middlewares which are not in the given names.
+func (r *bufferRegistry) prune(names []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name := range r.buffers {
+		if !slices.Contains(names, name) {
+			delete(r.buffers, name)
+		}
+	}
+}
+
+func (r *bufferRegistry) get(name string) (*buffer, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.buffers[name]
+	return b, ok
+}
+
+// Records returns the records of the named debug capture middleware, the most recent first.
+func Records(middlewareName string) ([]Record, error) {
+	b, ok := buffers.get(middlewareName)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return b.all(), nil
+}
+
+// Clear removes the records of the named debug capture middleware.
+func Clear(middlewareName string) error {
+	b, ok := buffers.get(middlewareName)
+	if !ok {
+		return ErrNotFound
+	}
+
+	b.clear()
+
+	return nil
+}
+
+// Prune removes the records of the debug capture middlewares which are not in the given names,
+// i.e. the ones removed from the dynamic configuration.
+func Prune(middlewareNames []string) {
+	buffers.prune(middlewareNames)
+}
+
+// buffer is a bounded ring buffer of records, where the oldest record is overwritten when it is full.
+type buffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+	lastID  uint64
+}
+
+func newBuffer(size int) *buffer {
+	return &buffer{records: make([]Record, size)}
+}
+
+// add adds a record to the buffer, and assigns its ID.
+func (b *buffer) add(record Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	record.ID = b.lastID
+
+	b.records[b.next] = record
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// all returns the records of the buffer, the most recent first.
+func (b *buffer) all() []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	count := b.next
+	if b.full {
+		count = len(b.records)
+	}
+
+	records := make([]Record, 0, count)
+	for i := 1; i <= count; i++ {
+		records = append(records, b.records[(b.next-i+len(b.records))%len(b.records)])
+	}
+
+	return records
+}
+
+func (b *buffer) clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	clear(b.records)
+	b.next = 0
+	b.full = false
+}
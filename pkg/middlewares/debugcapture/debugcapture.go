@@ -0,0 +1,276 @@
+// Package debugcapture implements a middleware recording the headers and the beginning of the bodies
+// of the requests and responses into a ring buffer, to troubleshoot the exchanges with a client from the API.
+//
+// Unlike the capture package, which only measures the requests and responses,
+// it is meant to be enabled temporarily on the routes being investigated.
+package debugcapture
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	httpmuxer "github.com/traefik/traefik/v3/pkg/muxer/http"
+	"github.com/traefik/traefik/v3/pkg/redactor"
+)
+
+const typeName = "DebugCapture"
+
+type debugCapture struct {
+	next          http.Handler
+	name          string
+	buffer        *buffer
+	maxBodyBytes  int64
+	redactHeaders []string
+	redactQuery   []string
+}
+
+// New creates a debug capture middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.DebugCapture, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	handler, d, err := newHandler(next, config, name)
+	if err != nil {
+		return nil, err
+	}
+
+	d.buffer = buffers.getOrCreate(name, config.MaxRecords)
+
+	return handler, nil
+}
+
+// Validate checks the configuration of a debug capture middleware, without creating or replacing its records buffer.
+func Validate(config dynamic.DebugCapture) error {
+	_, _, err := newHandler(http.NotFoundHandler(), config, "")
+	return err
+}
+
+// newHandler returns the handler of the middleware, and the debug capture it routes the matching requests to,
+// which has no buffer yet.
+func newHandler(next http.Handler, config dynamic.DebugCapture, name string) (http.Handler, *debugCapture, error) {
+	if config.MaxBodyBytes < 0 {
+		return nil, nil, errors.New("maxBodyBytes must be greater than or equal to zero")
+	}
+
+	if config.MaxRecords <= 0 {
+		return nil, nil, fmt.Errorf("invalid maxRecords: %d", config.MaxRecords)
+	}
+
+	d := &debugCapture{
+		next:          next,
+		name:          name,
+		maxBodyBytes:  config.MaxBodyBytes,
+		redactHeaders: config.RedactHeaders,
+		redactQuery:   config.RedactQueryParams,
+	}
+
+	if config.Rule == "" {
+		return d, d, nil
+	}
+
+	parser, err := httpmuxer.NewSyntaxParser()
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating rule parser: %w", err)
+	}
+
+	// The requests not matching the rule go straight to the next handler.
+	muxer := httpmuxer.NewMuxer(parser, nil)
+	muxer.SetDefaultHandler(next)
+
+	if err := muxer.AddRoute(config.Rule, "v3", 0, "", d); err != nil {
+		return nil, nil, fmt.Errorf("invalid rule: %w", err)
+	}
+
+	return muxer, d, nil
+}
+
+func (d *debugCapture) GetTracingInformation() (string, string) {
+	return d.name, typeName
+}
+
+func (d *debugCapture) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	record := Record{
+		Date:       time.Now(),
+		Middleware: d.name,
+		RemoteAddr: req.RemoteAddr,
+		Request: Message{
+			Method: req.Method,
+			Host:   req.Host,
+			URL:    d.requestURI(req.URL),
+			Proto:  req.Proto,
+			Header: redactor.Headers(req.Header, d.redactHeaders...),
+		},
+	}
+
+	reqBody := &bodyRecorder{max: d.maxBodyBytes}
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &recordingReader{source: req.Body, recorder: reqBody}
+	}
+
+	crw := &recordingResponseWriter{rw: rw, recorder: &bodyRecorder{max: d.maxBodyBytes}}
+
+	d.next.ServeHTTP(crw, req)
+
+	record.Duration = time.Since(record.Date)
+	record.Request.Body, record.Request.BodySize = reqBody.snapshot()
+	if isForm(req.Header) {
+		record.Request.Body = redactor.Query(record.Request.Body, d.redactQuery...)
+	}
+
+	crw.mu.Lock()
+	header := crw.header
+	record.Response.StatusCode = crw.status
+	crw.mu.Unlock()
+
+	if header == nil {
+		// The handler did not write anything.
+		header = rw.Header()
+	}
+
+	record.Response.Header = redactor.Headers(header, d.redactHeaders...)
+	record.Response.Body, record.Response.BodySize = crw.recorder.snapshot()
+
+	d.buffer.add(record)
+}
+
+// requestURI returns the request URI of the given URL, with the credentials of its query redacted.
+func (d *debugCapture) requestURI(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+
+	redacted := *u
+	redacted.RawQuery = redactor.Query(u.RawQuery, d.redactQuery...)
+
+	return redacted.RequestURI()
+}
+
+// isForm reports whether the body of a request is an URL-encoded form, whose fields are redacted as the query parameters.
+func isForm(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == "application/x-www-form-urlencoded"
+}
+
+// bodyRecorder records the first bytes of a body, and counts all of them.
+// It is safe for concurrent use, as the request body may still be read by the transport after the handler returns.
+type bodyRecorder struct {
+	mu   sync.Mutex
+	max  int64
+	buf  bytes.Buffer
+	size int64
+}
+
+func (b *bodyRecorder) record(p []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.size += int64(len(p))
+
+	if remaining := b.max - int64(b.buf.Len()); remaining > 0 {
+		b.buf.Write(p[:min(int64(len(p)), remaining)])
+	}
+}
+
+func (b *bodyRecorder) snapshot() (string, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String(), b.size
+}
+
+type recordingReader struct {
+	source   io.ReadCloser
+	recorder *bodyRecorder
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.source.Read(p)
+	r.recorder.record(p[:n])
+	return n, err
+}
+
+func (r *recordingReader) Close() error {
+	return r.source.Close()
+}
+
+var _ middlewares.Stateful = &recordingResponseWriter{}
+
+// recordingResponseWriter is a http.ResponseWriter recording the status, headers and body of the response.
+type recordingResponseWriter struct {
+	rw       http.ResponseWriter
+	recorder *bodyRecorder
+
+	mu     sync.Mutex
+	status int
+	// header is a copy of the headers when they are written.
+	header http.Header
+}
+
+func (r *recordingResponseWriter) Header() http.Header {
+	return r.rw.Header()
+}
+
+func (r *recordingResponseWriter) WriteHeader(status int) {
+	r.mu.Lock()
+	// Informational responses are sent before the final one.
+	if r.status == 0 && status >= http.StatusOK {
+		r.status = status
+		r.header = r.rw.Header().Clone()
+	}
+	r.mu.Unlock()
+
+	r.rw.WriteHeader(status)
+}
+
+func (r *recordingResponseWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	if r.status == 0 {
+		r.status = http.StatusOK
+		r.header = r.rw.Header().Clone()
+	}
+	r.mu.Unlock()
+
+	n, err := r.rw.Write(p)
+	r.recorder.record(p[:n])
+
+	return n, err
+}
+
+func (r *recordingResponseWriter) Flush() {
+	if f, ok := r.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *recordingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("not a hijacker: %T", r.rw)
+	}
+
+	r.mu.Lock()
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+		r.header = r.rw.Header().Clone()
+	}
+	r.mu.Unlock()
+
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter, enabling http.ResponseController.
+func (r *recordingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.rw
+}
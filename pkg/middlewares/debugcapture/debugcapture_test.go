@@ -0,0 +1,264 @@
+package debugcapture
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestDebugCapture(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		config           dynamic.DebugCapture
+		path             string
+		expectedCaptured bool
+		expectedRequest  Message
+		expectedResponse Message
+	}{
+		{
+			desc:             "captures headers and bodies",
+			config:           dynamic.DebugCapture{MaxBodyBytes: 4096},
+			path:             "/foo",
+			expectedCaptured: true,
+			expectedRequest: Message{
+				Method:   http.MethodPost,
+				Host:     "localhost",
+				URL:      "/foo",
+				Proto:    "HTTP/1.1",
+				Header:   http.Header{"Authorization": {"xxxx"}, "X-Secret": {"s3cr3t"}},
+				Body:     "request body",
+				BodySize: 12,
+			},
+			expectedResponse: Message{
+				StatusCode: http.StatusCreated,
+				Header:     http.Header{"Content-Type": {"text/plain"}, "Set-Cookie": {"xxxx"}},
+				Body:       "response body",
+				BodySize:   13,
+			},
+		},
+		{
+			desc:             "truncates bodies and redacts extra headers",
+			config:           dynamic.DebugCapture{MaxBodyBytes: 4, RedactHeaders: []string{"x-secret"}},
+			path:             "/foo",
+			expectedCaptured: true,
+			expectedRequest: Message{
+				Method:   http.MethodPost,
+				Host:     "localhost",
+				URL:      "/foo",
+				Proto:    "HTTP/1.1",
+				Header:   http.Header{"Authorization": {"xxxx"}, "X-Secret": {"xxxx"}},
+				Body:     "requ",
+				BodySize: 12,
+			},
+			expectedResponse: Message{
+				StatusCode: http.StatusCreated,
+				Header:     http.Header{"Content-Type": {"text/plain"}, "Set-Cookie": {"xxxx"}},
+				Body:       "resp",
+				BodySize:   13,
+			},
+		},
+		{
+			desc:             "headers only",
+			config:           dynamic.DebugCapture{},
+			path:             "/foo",
+			expectedCaptured: true,
+			expectedRequest: Message{
+				Method:   http.MethodPost,
+				Host:     "localhost",
+				URL:      "/foo",
+				Proto:    "HTTP/1.1",
+				Header:   http.Header{"Authorization": {"xxxx"}, "X-Secret": {"s3cr3t"}},
+				BodySize: 12,
+			},
+			expectedResponse: Message{
+				StatusCode: http.StatusCreated,
+				Header:     http.Header{"Content-Type": {"text/plain"}, "Set-Cookie": {"xxxx"}},
+				BodySize:   13,
+			},
+		},
+		{
+			desc:             "redacts query parameters",
+			config:           dynamic.DebugCapture{RedactQueryParams: []string{"sig"}},
+			path:             "/foo?page=2&access_token=secret&sig=secret",
+			expectedCaptured: true,
+			expectedRequest: Message{
+				Method:   http.MethodPost,
+				Host:     "localhost",
+				URL:      "/foo?page=2&access_token=xxxx&sig=xxxx",
+				Proto:    "HTTP/1.1",
+				Header:   http.Header{"Authorization": {"xxxx"}, "X-Secret": {"s3cr3t"}},
+				BodySize: 12,
+			},
+			expectedResponse: Message{
+				StatusCode: http.StatusCreated,
+				Header:     http.Header{"Content-Type": {"text/plain"}, "Set-Cookie": {"xxxx"}},
+				BodySize:   13,
+			},
+		},
+		{
+			desc:             "request matching the rule",
+			config:           dynamic.DebugCapture{Rule: "PathPrefix(`/foo`) && Method(`POST`)"},
+			path:             "/foo/bar",
+			expectedCaptured: true,
+			expectedRequest: Message{
+				Method:   http.MethodPost,
+				Host:     "localhost",
+				URL:      "/foo/bar",
+				Proto:    "HTTP/1.1",
+				Header:   http.Header{"Authorization": {"xxxx"}, "X-Secret": {"s3cr3t"}},
+				BodySize: 12,
+			},
+			expectedResponse: Message{
+				StatusCode: http.StatusCreated,
+				Header:     http.Header{"Content-Type": {"text/plain"}, "Set-Cookie": {"xxxx"}},
+				BodySize:   13,
+			},
+		},
+		{
+			desc:   "request not matching the rule",
+			config: dynamic.DebugCapture{Rule: "PathPrefix(`/foo`)"},
+			path:   "/bar",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "request body", string(body))
+
+				rw.Header().Set("Content-Type", "text/plain")
+				rw.Header().Set("Set-Cookie", "session=foo")
+				rw.WriteHeader(http.StatusCreated)
+				_, _ = rw.Write([]byte("response body"))
+			})
+
+			test.config.MaxRecords = 10
+			handler, err := New(t.Context(), next, test.config, t.Name())
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost"+test.path, strings.NewReader("request body"))
+			req.Header.Set("Authorization", "Bearer token")
+			req.Header.Set("X-Secret", "s3cr3t")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusCreated, recorder.Code)
+			assert.Equal(t, "response body", recorder.Body.String())
+			assert.Equal(t, "session=foo", recorder.Header().Get("Set-Cookie"))
+
+			records, err := Records(t.Name())
+			require.NoError(t, err)
+
+			if !test.expectedCaptured {
+				assert.Empty(t, records)
+				return
+			}
+
+			require.Len(t, records, 1)
+			assert.Equal(t, uint64(1), records[0].ID)
+			assert.Equal(t, t.Name(), records[0].Middleware)
+			assert.Equal(t, test.expectedRequest, records[0].Request)
+			assert.Equal(t, test.expectedResponse, records[0].Response)
+		})
+	}
+}
+
+func TestDebugCapture_formBody(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+		assert.Equal(t, "s3cr3t", req.PostForm.Get("password"))
+
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	config := dynamic.DebugCapture{MaxRecords: 10, MaxBodyBytes: 4096, RedactQueryParams: []string{"otp"}}
+	handler, err := New(t.Context(), next, config, t.Name())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost/login", strings.NewReader("user=foo&password=s3cr3t&otp=123456"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	records, err := Records(t.Name())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	assert.Equal(t, "user=foo&password=xxxx&otp=xxxx", records[0].Request.Body)
+	assert.Equal(t, int64(35), records[0].Request.BodySize)
+}
+
+func TestDebugCapture_invalidConfig(t *testing.T) {
+	_, err := New(t.Context(), http.NotFoundHandler(), dynamic.DebugCapture{MaxRecords: 10, Rule: "Foo(`bar`)"}, t.Name())
+	assert.Error(t, err)
+
+	_, err = New(t.Context(), http.NotFoundHandler(), dynamic.DebugCapture{MaxRecords: 0}, t.Name())
+	assert.Error(t, err)
+
+	_, err = New(t.Context(), http.NotFoundHandler(), dynamic.DebugCapture{MaxRecords: 10, MaxBodyBytes: -1}, t.Name())
+	assert.Error(t, err)
+}
+
+func TestBuffer(t *testing.T) {
+	handler, err := New(t.Context(), http.NotFoundHandler(), dynamic.DebugCapture{MaxRecords: 3}, t.Name())
+	require.NoError(t, err)
+
+	for _, path := range []string{"/1", "/2", "/3", "/4", "/5"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	records, err := Records(t.Name())
+	require.NoError(t, err)
+
+	var urls []string
+	for _, record := range records {
+		urls = append(urls, record.Request.URL)
+	}
+	assert.Equal(t, []string{"/5", "/4", "/3"}, urls)
+	assert.Equal(t, uint64(5), records[0].ID)
+
+	// The records survive a configuration reload.
+	_, err = New(t.Context(), http.NotFoundHandler(), dynamic.DebugCapture{MaxRecords: 3, Rule: "Path(`/foo`)"}, t.Name())
+	require.NoError(t, err)
+
+	records, err = Records(t.Name())
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	require.NoError(t, Clear(t.Name()))
+
+	records, err = Records(t.Name())
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = Records("unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, Clear("unknown"), ErrNotFound)
+}
+
+func TestBufferRegistry_prune(t *testing.T) {
+	registry := &bufferRegistry{buffers: make(map[string]*buffer)}
+
+	foo := registry.getOrCreate("foo", 3)
+	registry.getOrCreate("bar", 3)
+
+	registry.prune([]string{"foo"})
+
+	_, ok := registry.get("bar")
+	assert.False(t, ok)
+
+	b, ok := registry.get("foo")
+	require.True(t, ok)
+	assert.Same(t, foo, b)
+}
@@ -0,0 +1,148 @@
+package grpctranscoder
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// findField returns the fields along a dot-separated field path, e.g. book.author.name.
+// The field names can be the protobuf or the JSON ones.
+func findField(desc protoreflect.MessageDescriptor, fieldPath string) ([]protoreflect.FieldDescriptor, error) {
+	var fields []protoreflect.FieldDescriptor
+
+	names := strings.Split(fieldPath, ".")
+	for i, name := range names {
+		field := desc.Fields().ByName(protoreflect.Name(name))
+		if field == nil {
+			field = desc.Fields().ByJSONName(name)
+		}
+		if field == nil {
+			return nil, fmt.Errorf("unknown field %q in %s", fieldPath, desc.FullName())
+		}
+
+		fields = append(fields, field)
+
+		if i == len(names)-1 {
+			break
+		}
+
+		if field.Message() == nil || field.IsList() || field.IsMap() {
+			return nil, fmt.Errorf("field %q in %s is not a message", fieldPath, desc.FullName())
+		}
+		desc = field.Message()
+	}
+
+	if last := fields[len(fields)-1]; last.IsMap() {
+		return nil, fmt.Errorf("map field %q in %s cannot be bound", fieldPath, desc.FullName())
+	}
+
+	return fields, nil
+}
+
+// setField sets the field at the given path from its string representation, as found in a path or a query.
+// The values of the repeated fields are appended.
+func setField(msg protoreflect.Message, fieldPath, value string) error {
+	fields, err := findField(msg.Descriptor(), fieldPath)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields[:len(fields)-1] {
+		msg = msg.Mutable(field).Message()
+	}
+
+	field := fields[len(fields)-1]
+
+	if field.Message() != nil {
+		// The well-known types, such as google.protobuf.Timestamp or google.protobuf.StringValue,
+		// are parsed from their JSON string representation.
+		if field.IsList() {
+			elem := msg.Mutable(field).List().AppendMutable()
+			return unmarshalString(elem.Message(), fieldPath, value)
+		}
+
+		return unmarshalString(msg.Mutable(field).Message(), fieldPath, value)
+	}
+
+	v, err := parseScalar(field, value)
+	if err != nil {
+		return fmt.Errorf("invalid value %q for field %q: %w", value, fieldPath, err)
+	}
+
+	if field.IsList() {
+		msg.Mutable(field).List().Append(v)
+		return nil
+	}
+
+	msg.Set(field, v)
+	return nil
+}
+
+func unmarshalString(msg protoreflect.Message, fieldPath, value string) error {
+	if err := protojson.Unmarshal([]byte(strconv.Quote(value)), msg.Interface()); err != nil {
+		return fmt.Errorf("invalid value %q for field %q: %w", value, fieldPath, err)
+	}
+
+	return nil
+}
+
+func parseScalar(field protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(v), err
+
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(v), err
+
+	case protoreflect.BytesKind:
+		v, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			v, err = base64.URLEncoding.DecodeString(value)
+		}
+		return protoreflect.ValueOfBytes(v), err
+
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByName(protoreflect.Name(value)); enumValue != nil {
+			return protoreflect.ValueOfEnum(enumValue.Number()), nil
+		}
+
+		v, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown value of enum %s", field.Enum().FullName())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), nil
+
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", field.Kind())
+	}
+}
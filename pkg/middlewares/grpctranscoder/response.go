@@ -0,0 +1,353 @@
+package grpctranscoder
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	contentTypeJSON   = "application/json"
+	contentTypeNDJSON = "application/x-ndjson"
+
+	// frameHeaderLen is the length of the header of the gRPC messages: the compression flag, and the message length.
+	frameHeaderLen = 5
+)
+
+// responseWriter converts the gRPC response of the service to a JSON response.
+// The messages of the server streaming methods are written as newline-delimited JSON, as they are received.
+// The responses which are not gRPC responses, e.g. when the service is unavailable, are forwarded as is.
+type responseWriter struct {
+	rw      http.ResponseWriter
+	logger  *zerolog.Logger
+	route   *route
+	marshal protojson.MarshalOptions
+
+	// header holds the headers and trailers written by the service, which are not forwarded as is.
+	header      http.Header
+	wroteHeader bool
+	passThrough bool
+	headersSent bool
+
+	pending  []byte
+	messages [][]byte
+	err      error
+}
+
+func newResponseWriter(rw http.ResponseWriter, logger *zerolog.Logger, r *route, marshal protojson.MarshalOptions) *responseWriter {
+	return &responseWriter{
+		rw:      rw,
+		logger:  logger,
+		route:   r,
+		marshal: marshal,
+		header:  make(http.Header),
+	}
+}
+
+func (w *responseWriter) Header() http.Header {
+	if w.passThrough {
+		return w.rw.Header()
+	}
+
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if strings.HasPrefix(w.header.Get("Content-Type"), "application/grpc") {
+		return
+	}
+
+	w.passThrough = true
+	copyHeader(w.rw.Header(), w.header)
+	w.rw.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.passThrough {
+		return w.rw.Write(p)
+	}
+
+	w.pending = append(w.pending, p...)
+
+	for w.err == nil && len(w.pending) >= frameHeaderLen {
+		length := int(binary.BigEndian.Uint32(w.pending[1:frameHeaderLen]))
+		if len(w.pending) < frameHeaderLen+length {
+			break
+		}
+
+		if w.pending[0]&1 != 0 {
+			w.err = errors.New("compressed messages are not supported")
+			break
+		}
+
+		message := w.pending[frameHeaderLen : frameHeaderLen+length]
+		w.pending = w.pending[frameHeaderLen+length:]
+
+		if !w.route.method.IsStreamingServer() {
+			w.messages = append(w.messages, message)
+			continue
+		}
+
+		if err := w.writeStreamMessage(message); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.passThrough && !w.headersSent {
+		return
+	}
+
+	if flusher, ok := w.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// writeStreamMessage writes a message of a server stream as a JSON line.
+func (w *responseWriter) writeStreamMessage(message []byte) error {
+	body, err := w.convert(message)
+	if err != nil {
+		w.err = err
+		return nil
+	}
+
+	if !w.headersSent {
+		w.sendHeaders(http.StatusOK, contentTypeNDJSON, -1)
+	}
+
+	if _, err := w.rw.Write(append(body, '\n')); err != nil {
+		return err
+	}
+
+	if flusher, ok := w.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+// close writes the response once the service is done, according to the gRPC status.
+func (w *responseWriter) close() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.passThrough {
+		return
+	}
+
+	code, message := w.status()
+	if code == codes.OK && w.err != nil {
+		code, message = codes.Internal, w.err.Error()
+	}
+
+	if code != codes.OK {
+		w.writeError(code, message)
+		return
+	}
+
+	if w.route.method.IsStreamingServer() {
+		if !w.headersSent {
+			w.sendHeaders(http.StatusOK, contentTypeNDJSON, 0)
+		}
+		return
+	}
+
+	if len(w.messages) != 1 {
+		w.writeError(codes.Internal, fmt.Sprintf("expected one response message, got %d", len(w.messages)))
+		return
+	}
+
+	body, err := w.convert(w.messages[0])
+	if err != nil {
+		w.writeError(codes.Internal, err.Error())
+		return
+	}
+
+	w.sendHeaders(http.StatusOK, contentTypeJSON, len(body))
+	if _, err := w.rw.Write(body); err != nil {
+		w.logger.Debug().Err(err).Msg("Error while writing response")
+	}
+}
+
+// status returns the gRPC status of the response, sent in the trailers, or in the headers of a trailers-only response.
+func (w *responseWriter) status() (codes.Code, string) {
+	value := w.header.Get("Grpc-Status")
+	message := w.header.Get("Grpc-Message")
+	if value == "" {
+		value = w.header.Get(http.TrailerPrefix + "Grpc-Status")
+		message = w.header.Get(http.TrailerPrefix + "Grpc-Message")
+	}
+
+	if value == "" {
+		return codes.Unknown, "missing gRPC status"
+	}
+
+	code, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return codes.Unknown, fmt.Sprintf("invalid gRPC status %q", value)
+	}
+
+	// The gRPC messages are percent-encoded.
+	if decoded, err := url.PathUnescape(message); err == nil {
+		message = decoded
+	}
+
+	return codes.Code(code), message
+}
+
+// convert converts a response message to JSON.
+func (w *responseWriter) convert(data []byte) ([]byte, error) {
+	msg := dynamicpb.NewMessage(w.route.method.Output())
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("parsing response message: %w", err)
+	}
+
+	if w.route.responseBody == "" {
+		return w.marshal.Marshal(msg)
+	}
+
+	field := msg.Descriptor().Fields().ByName(protoreflect.Name(w.route.responseBody))
+	if field.Message() != nil && !field.IsList() && !field.IsMap() {
+		return w.marshal.Marshal(msg.Get(field).Message().Interface())
+	}
+
+	// The other fields are marshaled within their message, to be extracted from it.
+	only := dynamicpb.NewMessage(w.route.method.Output())
+	only.Set(field, msg.Get(field))
+
+	marshal := w.marshal
+	marshal.EmitUnpopulated = true
+
+	content, err := marshal.Marshal(only)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(content, &members); err != nil {
+		return nil, err
+	}
+
+	if marshal.UseProtoNames {
+		return members[string(field.Name())], nil
+	}
+	return members[field.JSONName()], nil
+}
+
+// writeError writes a gRPC error as a JSON object, or as the last JSON line of a stream whose headers have been sent.
+func (w *responseWriter) writeError(code codes.Code, message string) {
+	w.logger.Debug().Msgf("gRPC error %s: %s", code, message)
+
+	status := errorBody{Code: int(code), Message: message}
+
+	if w.headersSent {
+		body, _ := json.Marshal(streamError{Error: status})
+		if _, err := w.rw.Write(append(body, '\n')); err != nil {
+			w.logger.Debug().Err(err).Msg("Error while writing response")
+		}
+		return
+	}
+
+	body, _ := json.Marshal(status)
+	w.sendHeaders(httpStatus(code), contentTypeJSON, len(body))
+	if _, err := w.rw.Write(body); err != nil {
+		w.logger.Debug().Err(err).Msg("Error while writing response")
+	}
+}
+
+// sendHeaders sends the headers of the service, as metadata, with the given status and content.
+func (w *responseWriter) sendHeaders(statusCode int, contentType string, contentLength int) {
+	header := w.rw.Header()
+	copyHeader(header, w.header)
+
+	for name := range header {
+		if strings.HasPrefix(name, "Grpc-") || strings.HasPrefix(name, http.TrailerPrefix) {
+			header.Del(name)
+		}
+	}
+	header.Del("Trailer")
+	header.Set("Content-Type", contentType)
+
+	if contentLength >= 0 {
+		header.Set("Content-Length", strconv.Itoa(contentLength))
+	} else {
+		header.Del("Content-Length")
+	}
+
+	w.rw.WriteHeader(statusCode)
+	w.headersSent = true
+}
+
+type errorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type streamError struct {
+	Error errorBody `json:"error"`
+}
+
+// httpStatus returns the HTTP status corresponding to a gRPC status code.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func copyHeader(dst, src http.Header) {
+	for name, values := range src {
+		dst[name] = append([]string(nil), values...)
+	}
+}
@@ -0,0 +1,177 @@
+package grpctranscoder
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// route is an HTTP route, bound to a gRPC method by a google.api.http rule.
+type route struct {
+	httpMethod string
+	template   *pathTemplate
+	method     protoreflect.MethodDescriptor
+	// grpcPath is the path of the gRPC method, e.g. /helloworld.Greeter/SayHello.
+	grpcPath string
+	// body is the field of the request message bound to the request body.
+	// It is empty when the request has no body, and "*" when the body is the whole message.
+	body string
+	// responseBody is the field of the response message bound to the response body.
+	// It is empty when the body is the whole message.
+	responseBody string
+}
+
+// match reports whether the request matches the route, and returns the values of the path variables.
+func (r *route) match(req *http.Request) (map[string]string, bool) {
+	if req.Method != r.httpMethod {
+		return nil, false
+	}
+
+	return r.template.match(req.URL.EscapedPath())
+}
+
+// loadDescriptorSet loads the file descriptors of a protobuf descriptor set file.
+func loadDescriptorSet(path string) (*protoregistry.Files, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading descriptor set: %w", err)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(content, set); err != nil {
+		return nil, fmt.Errorf("parsing descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("loading descriptor set: %w", err)
+	}
+
+	return files, nil
+}
+
+// buildRoutes builds the routes of the methods of the given services, or of all the services when none is given.
+func buildRoutes(logger *zerolog.Logger, files *protoregistry.Files, services []string) ([]*route, error) {
+	var descriptors []protoreflect.ServiceDescriptor
+
+	if len(services) == 0 {
+		files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+			for i := range file.Services().Len() {
+				descriptors = append(descriptors, file.Services().Get(i))
+			}
+			return true
+		})
+	}
+
+	for _, name := range services {
+		desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", name, err)
+		}
+
+		service, ok := desc.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a service", name)
+		}
+
+		descriptors = append(descriptors, service)
+	}
+
+	// The routes are matched in the order of the services and methods names.
+	slices.SortFunc(descriptors, func(a, b protoreflect.ServiceDescriptor) int {
+		return cmp.Compare(a.FullName(), b.FullName())
+	})
+
+	var routes []*route
+	for _, service := range descriptors {
+		for i := range service.Methods().Len() {
+			method := service.Methods().Get(i)
+
+			rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if !ok || rule == nil {
+				continue
+			}
+
+			if method.IsStreamingClient() {
+				logger.Warn().Msgf("Client streaming method %s cannot be transcoded", method.FullName())
+				continue
+			}
+
+			for _, binding := range append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...) {
+				r, err := newRoute(method, binding)
+				if err != nil {
+					return nil, fmt.Errorf("method %s: %w", method.FullName(), err)
+				}
+
+				routes = append(routes, r)
+			}
+		}
+	}
+
+	// The routes with a verb are matched first, as a wildcard segment could match their verb.
+	slices.SortStableFunc(routes, func(a, b *route) int {
+		return cmp.Compare(len(b.template.verb), len(a.template.verb))
+	})
+
+	return routes, nil
+}
+
+func newRoute(method protoreflect.MethodDescriptor, rule *annotations.HttpRule) (*route, error) {
+	r := &route{
+		method:       method,
+		grpcPath:     fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name()),
+		body:         rule.GetBody(),
+		responseBody: rule.GetResponseBody(),
+	}
+
+	var path string
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		r.httpMethod, path = http.MethodGet, pattern.Get
+	case *annotations.HttpRule_Put:
+		r.httpMethod, path = http.MethodPut, pattern.Put
+	case *annotations.HttpRule_Post:
+		r.httpMethod, path = http.MethodPost, pattern.Post
+	case *annotations.HttpRule_Delete:
+		r.httpMethod, path = http.MethodDelete, pattern.Delete
+	case *annotations.HttpRule_Patch:
+		r.httpMethod, path = http.MethodPatch, pattern.Patch
+	case *annotations.HttpRule_Custom:
+		r.httpMethod, path = pattern.Custom.GetKind(), pattern.Custom.GetPath()
+	default:
+		return nil, errors.New("missing HTTP pattern")
+	}
+
+	var err error
+	r.template, err = parseTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range r.template.variables {
+		if _, err := findField(method.Input(), v.fieldPath); err != nil {
+			return nil, fmt.Errorf("path variable: %w", err)
+		}
+	}
+
+	if r.body != "" && r.body != "*" && method.Input().Fields().ByName(protoreflect.Name(r.body)) == nil {
+		return nil, fmt.Errorf("unknown body field %q", r.body)
+	}
+
+	if r.responseBody != "" && method.Output().Fields().ByName(protoreflect.Name(r.responseBody)) == nil {
+		return nil, fmt.Errorf("unknown response body field %q", r.responseBody)
+	}
+
+	return r, nil
+}
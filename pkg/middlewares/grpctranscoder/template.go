@@ -0,0 +1,163 @@
+package grpctranscoder
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	wildcard     = "*"
+	deepWildcard = "**"
+)
+
+// pathTemplate is a path template of a google.api.http rule, e.g. /v1/{name=shelves/*/books/*}:publish.
+type pathTemplate struct {
+	// segments are the literal segments, and the * and ** wildcards.
+	segments  []string
+	variables []variable
+	verb      string
+}
+
+// variable binds the path segments from start to end (excluded) to a field of the request message.
+type variable struct {
+	fieldPath string
+	start     int
+	end       int
+}
+
+func parseTemplate(raw string) (*pathTemplate, error) {
+	rest, ok := strings.CutPrefix(raw, "/")
+	if !ok {
+		return nil, fmt.Errorf("path template %q must start with /", raw)
+	}
+
+	tmpl := &pathTemplate{}
+
+	if i := strings.LastIndexByte(rest, ':'); i > strings.LastIndexByte(rest, '/') && i > strings.LastIndexByte(rest, '}') {
+		rest, tmpl.verb = rest[:i], rest[i+1:]
+	}
+
+	for {
+		var err error
+		rest, err = tmpl.parseSegment(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path template %q: %w", raw, err)
+		}
+
+		if rest == "" {
+			break
+		}
+
+		rest, ok = strings.CutPrefix(rest, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid path template %q: unexpected %q", raw, rest)
+		}
+	}
+
+	for i, segment := range tmpl.segments {
+		if segment == deepWildcard && i != len(tmpl.segments)-1 {
+			return nil, fmt.Errorf("invalid path template %q: ** must be the last segment", raw)
+		}
+	}
+
+	return tmpl, nil
+}
+
+// parseSegment parses a segment, or a variable, and returns the rest of the template.
+func (t *pathTemplate) parseSegment(s string) (string, error) {
+	if !strings.HasPrefix(s, "{") {
+		segment, rest, _ := strings.Cut(s, "/")
+		if segment == "" {
+			return "", errors.New("empty segment")
+		}
+
+		t.segments = append(t.segments, segment)
+
+		if rest == "" && len(segment) < len(s) {
+			return "", errors.New("trailing /")
+		}
+		return s[len(segment):], nil
+	}
+
+	end := strings.IndexByte(s, '}')
+	if end < 0 {
+		return "", errors.New("unterminated variable")
+	}
+
+	fieldPath, pattern, ok := strings.Cut(s[1:end], "=")
+	if fieldPath == "" {
+		return "", errors.New("empty variable name")
+	}
+	if !ok {
+		pattern = wildcard
+	}
+
+	start := len(t.segments)
+	for segment := range strings.SplitSeq(pattern, "/") {
+		if segment == "" || strings.ContainsAny(segment, "{}") {
+			return "", fmt.Errorf("invalid pattern %q of variable %q", pattern, fieldPath)
+		}
+
+		t.segments = append(t.segments, segment)
+	}
+
+	t.variables = append(t.variables, variable{fieldPath: fieldPath, start: start, end: len(t.segments)})
+
+	return s[end+1:], nil
+}
+
+// match matches the escaped path of a request against the template, and returns the values of the variables.
+func (t *pathTemplate) match(escapedPath string) (map[string]string, bool) {
+	path, ok := strings.CutPrefix(escapedPath, "/")
+	if !ok {
+		return nil, false
+	}
+
+	if t.verb != "" {
+		path, ok = strings.CutSuffix(path, ":"+t.verb)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	parts := strings.Split(path, "/")
+
+	deep := len(t.segments) > 0 && t.segments[len(t.segments)-1] == deepWildcard
+	if deep && len(parts) < len(t.segments)-1 || !deep && len(parts) != len(t.segments) {
+		return nil, false
+	}
+
+	for i, segment := range t.segments {
+		switch segment {
+		case deepWildcard:
+			// Matches the remaining segments.
+		case wildcard:
+			if parts[i] == "" {
+				return nil, false
+			}
+		default:
+			if parts[i] != segment {
+				return nil, false
+			}
+		}
+	}
+
+	values := make(map[string]string, len(t.variables))
+	for _, v := range t.variables {
+		end := v.end
+		if deep && end == len(t.segments) {
+			end = len(parts)
+		}
+
+		value, err := url.PathUnescape(strings.Join(parts[v.start:end], "/"))
+		if err != nil {
+			return nil, false
+		}
+
+		values[v.fieldPath] = value
+	}
+
+	return values, true
+}
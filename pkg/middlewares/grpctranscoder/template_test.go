@@ -0,0 +1,171 @@
+package grpctranscoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		template  string
+		expected  *pathTemplate
+		expectErr bool
+	}{
+		{
+			desc:     "literal segments",
+			template: "/v1/books",
+			expected: &pathTemplate{segments: []string{"v1", "books"}},
+		},
+		{
+			desc:     "variable",
+			template: "/v1/books/{name}",
+			expected: &pathTemplate{
+				segments:  []string{"v1", "books", "*"},
+				variables: []variable{{fieldPath: "name", start: 2, end: 3}},
+			},
+		},
+		{
+			desc:     "variable with pattern and verb",
+			template: "/v1/{name=shelves/*/books/*}:publish",
+			expected: &pathTemplate{
+				segments:  []string{"v1", "shelves", "*", "books", "*"},
+				variables: []variable{{fieldPath: "name", start: 1, end: 5}},
+				verb:      "publish",
+			},
+		},
+		{
+			desc:     "nested field and deep wildcard",
+			template: "/v1/{book.name=**}",
+			expected: &pathTemplate{
+				segments:  []string{"v1", "**"},
+				variables: []variable{{fieldPath: "book.name", start: 1, end: 2}},
+			},
+		},
+		{
+			desc:      "missing leading slash",
+			template:  "v1/books",
+			expectErr: true,
+		},
+		{
+			desc:      "trailing slash",
+			template:  "/v1/books/",
+			expectErr: true,
+		},
+		{
+			desc:      "unterminated variable",
+			template:  "/v1/{name",
+			expectErr: true,
+		},
+		{
+			desc:      "deep wildcard not last",
+			template:  "/v1/**/books",
+			expectErr: true,
+		},
+		{
+			desc:      "empty variable name",
+			template:  "/v1/{=books/*}",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := parseTemplate(test.template)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, tmpl)
+		})
+	}
+}
+
+func TestPathTemplate_match(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		template string
+		path     string
+		expected map[string]string
+		noMatch  bool
+	}{
+		{
+			desc:     "literal segments",
+			template: "/v1/books",
+			path:     "/v1/books",
+			expected: map[string]string{},
+		},
+		{
+			desc:     "variable",
+			template: "/v1/books/{name}",
+			path:     "/v1/books/moby%20dick",
+			expected: map[string]string{"name": "moby dick"},
+		},
+		{
+			desc:     "variable with pattern",
+			template: "/v1/{name=shelves/*/books/*}",
+			path:     "/v1/shelves/1/books/2",
+			expected: map[string]string{"name": "shelves/1/books/2"},
+		},
+		{
+			desc:     "verb",
+			template: "/v1/{name=books/*}:publish",
+			path:     "/v1/books/2:publish",
+			expected: map[string]string{"name": "books/2"},
+		},
+		{
+			desc:     "deep wildcard",
+			template: "/v1/{name=files/**}",
+			path:     "/v1/files/a/b/c",
+			expected: map[string]string{"name": "files/a/b/c"},
+		},
+		{
+			desc:     "missing verb",
+			template: "/v1/{name=books/*}:publish",
+			path:     "/v1/books/2",
+			noMatch:  true,
+		},
+		{
+			desc:     "different literal",
+			template: "/v1/books/{name}",
+			path:     "/v1/shelves/1",
+			noMatch:  true,
+		},
+		{
+			desc:     "too many segments",
+			template: "/v1/books/{name}",
+			path:     "/v1/books/1/2",
+			noMatch:  true,
+		},
+		{
+			desc:     "empty wildcard segment",
+			template: "/v1/books/{name}",
+			path:     "/v1/books/",
+			noMatch:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := parseTemplate(test.template)
+			require.NoError(t, err)
+
+			values, ok := tmpl.match(test.path)
+			if test.noMatch {
+				assert.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, test.expected, values)
+		})
+	}
+}
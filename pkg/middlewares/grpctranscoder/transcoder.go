@@ -0,0 +1,276 @@
+// Package grpctranscoder implements a middleware converting HTTP/JSON requests to gRPC requests,
+// according to the google.api.http annotations of the gRPC methods, and the gRPC responses back to JSON.
+package grpctranscoder
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const typeName = "GRPCTranscoder"
+
+var errRequestTooLarge = errors.New("request body too large")
+
+type transcoder struct {
+	next                http.Handler
+	name                string
+	routes              []*route
+	unmarshal           protojson.UnmarshalOptions
+	marshal             protojson.MarshalOptions
+	maxRequestBodyBytes int64
+}
+
+// New creates a gRPC-JSON transcoder middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.GrpcTranscoder, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.DescriptorSetFile == "" {
+		return nil, errors.New("descriptor set file must be defined")
+	}
+
+	if config.MaxRequestBodyBytes < 0 {
+		return nil, errors.New("maxRequestBodyBytes must be greater than or equal to zero")
+	}
+
+	files, err := loadDescriptorSet(config.DescriptorSetFile)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := buildRoutes(logger, files, config.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(routes) == 0 {
+		return nil, errors.New("no gRPC method with a google.api.http annotation")
+	}
+
+	logger.Debug().Msgf("Transcoding %d HTTP routes", len(routes))
+
+	types := dynamicpb.NewTypes(files)
+
+	return &transcoder{
+		next:      next,
+		name:      name,
+		routes:    routes,
+		unmarshal: protojson.UnmarshalOptions{Resolver: types},
+		marshal: protojson.MarshalOptions{
+			Resolver:        types,
+			UseProtoNames:   config.UseProtoNames,
+			EmitUnpopulated: config.EmitUnpopulated,
+		},
+		maxRequestBodyBytes: config.MaxRequestBodyBytes,
+	}, nil
+}
+
+func (t *transcoder) GetTracingInformation() (string, string) {
+	return t.name, typeName
+}
+
+func (t *transcoder) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), t.name, typeName)
+
+	// The gRPC requests are forwarded as is.
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+		t.next.ServeHTTP(rw, req)
+		return
+	}
+
+	r, variables := t.match(req)
+	if r == nil {
+		t.next.ServeHTTP(rw, req)
+		return
+	}
+
+	writer := newResponseWriter(rw, logger, r, t.marshal)
+
+	message, err := t.buildMessage(req, r, variables)
+	if errors.Is(err, errRequestTooLarge) {
+		logger.Debug().Msgf("Request body larger than %d bytes", t.maxRequestBodyBytes)
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		writer.writeError(codes.InvalidArgument, err.Error())
+		return
+	}
+
+	t.next.ServeHTTP(writer, newGRPCRequest(req, r, message))
+
+	writer.close()
+}
+
+func (t *transcoder) match(req *http.Request) (*route, map[string]string) {
+	for _, r := range t.routes {
+		if variables, ok := r.match(req); ok {
+			return r, variables
+		}
+	}
+
+	return nil, nil
+}
+
+// buildMessage builds the request message from the request body, the query parameters and the path variables.
+func (t *transcoder) buildMessage(req *http.Request, r *route, variables map[string]string) ([]byte, error) {
+	msg := dynamicpb.NewMessage(r.method.Input())
+
+	if r.body != "" {
+		body, err := t.readBody(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := t.unmarshalBody(msg, r.body, body); err != nil {
+			return nil, fmt.Errorf("parsing request body: %w", err)
+		}
+	}
+
+	// When the body is the whole message, the query parameters are not bound.
+	if r.body != "*" {
+		for name, values := range req.URL.Query() {
+			if _, err := findField(msg.Descriptor(), name); err != nil {
+				// The query parameters which are not fields, e.g. an API key, are ignored.
+				continue
+			}
+
+			for _, value := range values {
+				if err := setField(msg, name, value); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// The path variables take precedence over the body and the query parameters.
+	for fieldPath, value := range variables {
+		if err := clearField(msg, fieldPath); err != nil {
+			return nil, err
+		}
+
+		if err := setField(msg, fieldPath, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return proto.Marshal(msg)
+}
+
+// readBody reads the request body, up to the maximum request body size.
+func (t *transcoder) readBody(req *http.Request) ([]byte, error) {
+	if t.maxRequestBodyBytes <= 0 {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+
+		return body, nil
+	}
+
+	if req.ContentLength > t.maxRequestBodyBytes {
+		return nil, errRequestTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, t.maxRequestBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+
+	if int64(len(body)) > t.maxRequestBodyBytes {
+		return nil, errRequestTooLarge
+	}
+
+	return body, nil
+}
+
+func (t *transcoder) unmarshalBody(msg *dynamicpb.Message, bodyField string, body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	if bodyField == "*" {
+		return t.unmarshal.Unmarshal(body, msg)
+	}
+
+	field := msg.Descriptor().Fields().ByName(protoreflect.Name(bodyField))
+	if field.Message() != nil && !field.IsList() && !field.IsMap() {
+		return t.unmarshal.Unmarshal(body, msg.Mutable(field).Message().Interface())
+	}
+
+	// The other fields are unmarshaled within their message.
+	wrapped := make([]byte, 0, len(body)+len(bodyField)+5)
+	wrapped = append(wrapped, `{"`...)
+	wrapped = append(wrapped, bodyField...)
+	wrapped = append(wrapped, `":`...)
+	wrapped = append(wrapped, body...)
+	wrapped = append(wrapped, '}')
+
+	return t.unmarshal.Unmarshal(wrapped, msg)
+}
+
+// clearField clears the field at the given path, if set, so that a path variable replaces the values of a repeated field.
+func clearField(msg protoreflect.Message, fieldPath string) error {
+	fields, err := findField(msg.Descriptor(), fieldPath)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields[:len(fields)-1] {
+		if !msg.Has(field) {
+			return nil
+		}
+		msg = msg.Mutable(field).Message()
+	}
+
+	msg.Clear(fields[len(fields)-1])
+
+	return nil
+}
+
+// newGRPCRequest builds the gRPC request of the method of the route, with the given message.
+// The headers of the original request are forwarded as metadata.
+func newGRPCRequest(req *http.Request, r *route, message []byte) *http.Request {
+	frame := make([]byte, frameHeaderLen+len(message))
+	binary.BigEndian.PutUint32(frame[1:frameHeaderLen], uint32(len(message)))
+	copy(frame[frameHeaderLen:], message)
+
+	grpcReq := req.Clone(req.Context())
+	grpcReq.Method = http.MethodPost
+	grpcReq.URL.Path = r.grpcPath
+	grpcReq.URL.RawPath = ""
+	grpcReq.URL.RawQuery = ""
+	grpcReq.RequestURI = r.grpcPath
+	grpcReq.Proto = "HTTP/2.0"
+	grpcReq.ProtoMajor = 2
+	grpcReq.ProtoMinor = 0
+
+	grpcReq.Body = io.NopCloser(bytes.NewReader(frame))
+	grpcReq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(frame)), nil
+	}
+	grpcReq.ContentLength = int64(len(frame))
+
+	grpcReq.Header.Set("Content-Type", "application/grpc")
+	grpcReq.Header.Set("Content-Length", strconv.Itoa(len(frame)))
+	grpcReq.Header.Set("Te", "trailers")
+	grpcReq.Header.Del("Accept")
+	grpcReq.Header.Del("Accept-Encoding")
+
+	return grpcReq
+}
@@ -0,0 +1,528 @@
+package grpctranscoder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestNew(t *testing.T) {
+	descriptorSetFile := writeDescriptorSet(t)
+
+	testCases := []struct {
+		desc      string
+		config    dynamic.GrpcTranscoder
+		expectErr bool
+	}{
+		{
+			desc:   "all services",
+			config: dynamic.GrpcTranscoder{DescriptorSetFile: descriptorSetFile},
+		},
+		{
+			desc: "named service",
+			config: dynamic.GrpcTranscoder{
+				DescriptorSetFile: descriptorSetFile,
+				Services:          []string{"library.Library"},
+			},
+		},
+		{
+			desc:      "missing descriptor set file",
+			config:    dynamic.GrpcTranscoder{},
+			expectErr: true,
+		},
+		{
+			desc:      "unreadable descriptor set file",
+			config:    dynamic.GrpcTranscoder{DescriptorSetFile: filepath.Join(t.TempDir(), "missing.pb")},
+			expectErr: true,
+		},
+		{
+			desc: "unknown service",
+			config: dynamic.GrpcTranscoder{
+				DescriptorSetFile: descriptorSetFile,
+				Services:          []string{"library.Unknown"},
+			},
+			expectErr: true,
+		},
+		{
+			desc: "not a service",
+			config: dynamic.GrpcTranscoder{
+				DescriptorSetFile: descriptorSetFile,
+				Services:          []string{"library.Book"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(t.Context(), next, test.config, "grpcTranscoder")
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestTranscoder_ServeHTTP(t *testing.T) {
+	descriptorSetFile := writeDescriptorSet(t)
+
+	testCases := []struct {
+		desc            string
+		config          dynamic.GrpcTranscoder
+		method          string
+		target          string
+		body            string
+		contentType     string
+		expectedStatus  int
+		expectedType    string
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:           "get with path variable and query parameter",
+			method:         http.MethodGet,
+			target:         "/v1/shelves/1/books/2?full=true&apiKey=secret",
+			expectedStatus: http.StatusOK,
+			expectedType:   contentTypeJSON,
+			expectedBody:   `{"name":"shelves/1/books/2","title":"full"}`,
+			expectedHeaders: map[string]string{
+				"X-Library": "open",
+			},
+		},
+		{
+			desc:           "use proto names and emit unpopulated",
+			config:         dynamic.GrpcTranscoder{UseProtoNames: true, EmitUnpopulated: true},
+			method:         http.MethodGet,
+			target:         "/v1/shelves/1/books/2",
+			expectedStatus: http.StatusOK,
+			expectedType:   contentTypeJSON,
+			expectedBody:   `{"name":"shelves/1/books/2","title":"","page_count":0,"tags":[]}`,
+		},
+		{
+			desc:           "invalid query parameter",
+			method:         http.MethodGet,
+			target:         "/v1/shelves/1/books/2?full=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   contentTypeJSON,
+			expectedBody:   `{"code":3,"message":"invalid value \"maybe\" for field \"full\": strconv.ParseBool: parsing \"maybe\": invalid syntax"}`,
+		},
+		{
+			desc:           "gRPC error",
+			method:         http.MethodGet,
+			target:         "/v1/shelves/1/books/missing",
+			expectedStatus: http.StatusNotFound,
+			expectedType:   contentTypeJSON,
+			expectedBody:   `{"code":5,"message":"book shelves/1/books/missing not found"}`,
+		},
+		{
+			desc:           "post with field body",
+			method:         http.MethodPost,
+			target:         "/v1/shelves/1/books",
+			body:           `{"title":"Moby Dick","pageCount":635,"tags":["whale"]}`,
+			expectedStatus: http.StatusOK,
+			expectedType:   contentTypeJSON,
+			expectedBody:   `{"name":"shelves/1/books/1","title":"Moby Dick","pageCount":635,"tags":["whale"]}`,
+		},
+		{
+			desc:           "patch with whole body, overridden by the path variable",
+			method:         http.MethodPatch,
+			target:         "/v1/books/moby",
+			body:           `{"name":"other","title":"Moby Dick"}`,
+			expectedStatus: http.StatusOK,
+			expectedType:   contentTypeJSON,
+			expectedBody:   `{"name":"moby","title":"Moby Dick"}`,
+		},
+		{
+			desc:           "body too large",
+			config:         dynamic.GrpcTranscoder{MaxRequestBodyBytes: 16},
+			method:         http.MethodPost,
+			target:         "/v1/shelves/1/books",
+			body:           `{"title":"Moby Dick","pageCount":635,"tags":["whale"]}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:           "invalid body",
+			method:         http.MethodPatch,
+			target:         "/v1/books/moby",
+			body:           `{"unknown":true}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedType:   contentTypeJSON,
+		},
+		{
+			desc:           "response body",
+			method:         http.MethodGet,
+			target:         "/v1/shelves/1/books/2:title",
+			expectedStatus: http.StatusOK,
+			expectedType:   contentTypeJSON,
+			expectedBody:   `""`,
+		},
+		{
+			desc:           "server streaming",
+			method:         http.MethodGet,
+			target:         "/v1/shelves/1/books?count=3",
+			expectedStatus: http.StatusOK,
+			expectedType:   contentTypeNDJSON,
+			expectedBody:   "{\"name\":\"shelves/1/books/0\"}\n{\"name\":\"shelves/1/books/1\"}\n{\"name\":\"shelves/1/books/2\"}\n",
+		},
+		{
+			desc:           "server streaming error after messages",
+			method:         http.MethodGet,
+			target:         "/v1/shelves/1/books?count=12",
+			expectedStatus: http.StatusOK,
+			expectedType:   contentTypeNDJSON,
+			expectedBody:   "{\"name\":\"shelves/1/books/0\"}\n{\"error\":{\"code\":8,\"message\":\"too many books\"}}\n",
+		},
+		{
+			desc:           "empty server stream",
+			method:         http.MethodGet,
+			target:         "/v1/shelves/1/books",
+			expectedStatus: http.StatusOK,
+			expectedType:   contentTypeNDJSON,
+		},
+		{
+			desc:           "unmatched route",
+			method:         http.MethodDelete,
+			target:         "/v1/shelves/1/books/2",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			// The request is forwarded as is, and the gRPC server only accepts HTTP/2 requests.
+			desc:           "gRPC request",
+			method:         http.MethodPost,
+			target:         "/v1/shelves/1/books",
+			contentType:    "application/grpc",
+			expectedStatus: http.StatusHTTPVersionNotSupported,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := test.config
+			config.DescriptorSetFile = descriptorSetFile
+
+			handler, err := New(t.Context(), newLibraryServer(t), config, "grpcTranscoder")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+
+			if test.expectedType == "" {
+				return
+			}
+
+			assert.Equal(t, test.expectedType, recorder.Header().Get("Content-Type"))
+			assert.Empty(t, recorder.Header().Get("Grpc-Status"))
+			assert.Empty(t, recorder.Header().Get("Trailer"))
+
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, recorder.Header().Get(name))
+			}
+
+			if test.expectedBody != "" {
+				// The output of protojson is not stable, so the JSON lines are compared semantically.
+				expectedLines := strings.SplitAfter(test.expectedBody, "\n")
+				lines := strings.SplitAfter(recorder.Body.String(), "\n")
+				require.Len(t, lines, len(expectedLines))
+
+				for i, line := range lines {
+					if line == "" {
+						assert.Empty(t, expectedLines[i])
+						continue
+					}
+					assert.JSONEq(t, expectedLines[i], line)
+				}
+			}
+
+			if test.expectedType == contentTypeJSON {
+				assert.Equal(t, strconv.Itoa(recorder.Body.Len()), recorder.Header().Get("Content-Length"))
+			}
+		})
+	}
+}
+
+// writeDescriptorSet writes the descriptor set of a library service, annotated with google.api.http rules.
+func writeDescriptorSet(t *testing.T) string {
+	t.Helper()
+
+	httpRule := func(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_Http, rule)
+		return opts
+	}
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    label.Enum(),
+			JsonName: proto.String(jsonName(name)),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+
+	const (
+		typeString  = descriptorpb.FieldDescriptorProto_TYPE_STRING
+		typeInt32   = descriptorpb.FieldDescriptorProto_TYPE_INT32
+		typeBool    = descriptorpb.FieldDescriptorProto_TYPE_BOOL
+		typeMessage = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("library.proto"),
+		Package:    proto.String("library"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/api/annotations.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Book"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, typeString, "", false),
+					field("title", 2, typeString, "", false),
+					field("page_count", 3, typeInt32, "", false),
+					field("tags", 4, typeString, "", true),
+				},
+			},
+			{
+				Name: proto.String("GetBookRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, typeString, "", false),
+					field("full", 2, typeBool, "", false),
+				},
+			},
+			{
+				Name: proto.String("CreateBookRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("parent", 1, typeString, "", false),
+					field("book", 2, typeMessage, ".library.Book", false),
+				},
+			},
+			{
+				Name: proto.String("ListBooksRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("parent", 1, typeString, "", false),
+					field("count", 2, typeInt32, "", false),
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Library"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{
+					Name:       proto.String("GetBook"),
+					InputType:  proto.String(".library.GetBookRequest"),
+					OutputType: proto.String(".library.Book"),
+					Options: httpRule(&annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=shelves/*/books/*}"},
+						AdditionalBindings: []*annotations.HttpRule{{
+							Pattern:      &annotations.HttpRule_Get{Get: "/v1/{name=shelves/*/books/*}:title"},
+							ResponseBody: "title",
+						}},
+					}),
+				},
+				{
+					Name:       proto.String("CreateBook"),
+					InputType:  proto.String(".library.CreateBookRequest"),
+					OutputType: proto.String(".library.Book"),
+					Options: httpRule(&annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Post{Post: "/v1/{parent=shelves/*}/books"},
+						Body:    "book",
+					}),
+				},
+				{
+					Name:       proto.String("UpdateBook"),
+					InputType:  proto.String(".library.Book"),
+					OutputType: proto.String(".library.Book"),
+					Options: httpRule(&annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Patch{Patch: "/v1/books/{name}"},
+						Body:    "*",
+					}),
+				},
+				{
+					Name:            proto.String("ListBooks"),
+					InputType:       proto.String(".library.ListBooksRequest"),
+					OutputType:      proto.String(".library.Book"),
+					ServerStreaming: proto.Bool(true),
+					Options: httpRule(&annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Get{Get: "/v1/{parent=shelves/*}/books"},
+					}),
+				},
+			},
+		}},
+	}
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_http_proto),
+			protodesc.ToFileDescriptorProto(annotations.File_google_api_annotations_proto),
+			file,
+		},
+	}
+
+	content, err := proto.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "library.pb")
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+
+	return path
+}
+
+func jsonName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// newLibraryServer returns a gRPC server implementing the library service with dynamic messages.
+func newLibraryServer(t *testing.T) *grpc.Server {
+	t.Helper()
+
+	files, err := loadDescriptorSet(writeDescriptorSet(t))
+	require.NoError(t, err)
+
+	desc, err := files.FindDescriptorByName("library.Library")
+	require.NoError(t, err)
+
+	methods := desc.(protoreflect.ServiceDescriptor).Methods()
+	newMessage := func(method protoreflect.Name, output bool) *dynamicpb.Message {
+		if output {
+			return dynamicpb.NewMessage(methods.ByName(method).Output())
+		}
+		return dynamicpb.NewMessage(methods.ByName(method).Input())
+	}
+
+	set := func(msg *dynamicpb.Message, name protoreflect.Name, value protoreflect.Value) {
+		msg.Set(msg.Descriptor().Fields().ByName(name), value)
+	}
+	get := func(msg *dynamicpb.Message, name protoreflect.Name) protoreflect.Value {
+		return msg.Get(msg.Descriptor().Fields().ByName(name))
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "library.Library",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "GetBook",
+				Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+					req := newMessage("GetBook", false)
+					if err := dec(req); err != nil {
+						return nil, err
+					}
+
+					name := get(req, "name").String()
+					if strings.HasSuffix(name, "/missing") {
+						return nil, status.Errorf(codes.NotFound, "book %s not found", name)
+					}
+
+					if err := grpc.SetHeader(ctx, map[string][]string{"x-library": {"open"}}); err != nil {
+						return nil, err
+					}
+
+					book := newMessage("GetBook", true)
+					set(book, "name", protoreflect.ValueOfString(name))
+					if get(req, "full").Bool() {
+						set(book, "title", protoreflect.ValueOfString("full"))
+					}
+					return book, nil
+				},
+			},
+			{
+				MethodName: "CreateBook",
+				Handler: func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+					req := newMessage("CreateBook", false)
+					if err := dec(req); err != nil {
+						return nil, err
+					}
+
+					book := get(req, "book").Message()
+					book.Set(book.Descriptor().Fields().ByName("name"), protoreflect.ValueOfString(get(req, "parent").String()+"/books/1"))
+					return book.Interface(), nil
+				},
+			},
+			{
+				MethodName: "UpdateBook",
+				Handler: func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+					req := newMessage("UpdateBook", false)
+					if err := dec(req); err != nil {
+						return nil, err
+					}
+					return req, nil
+				},
+			},
+		},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "ListBooks",
+			ServerStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				req := newMessage("ListBooks", false)
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+
+				for i := range get(req, "count").Int() {
+					if i == 1 && get(req, "count").Int() > 10 {
+						return status.Error(codes.ResourceExhausted, "too many books")
+					}
+
+					book := newMessage("ListBooks", true)
+					set(book, "name", protoreflect.ValueOfString(get(req, "parent").String()+"/books/"+strconv.FormatInt(i, 10)))
+					if err := stream.SendMsg(book); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		}},
+	}, struct{}{})
+
+	return server
+}
@@ -0,0 +1,234 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vulcand/predicate"
+)
+
+// claimsPredicate reports whether the claims of a token match an expression.
+type claimsPredicate func(claims map[string]any) bool
+
+// parseClaimsExpression parses an expression combining claim checks, in the same way as the circuit breaker expression.
+// For instance: Equals(`grp`, `admin`) && !Contains(`roles`, `guest`).
+func parseClaimsExpression(expression string) (claimsPredicate, error) {
+	parser, err := predicate.NewParser(predicate.Def{
+		Operators: predicate.Operators{
+			AND: andClaims,
+			OR:  orClaims,
+			NOT: notClaims,
+		},
+		Functions: map[string]any{
+			"Equals":        equals,
+			"Prefix":        prefix,
+			"Contains":      contains,
+			"SplitContains": splitContains,
+			"OneOf":         oneOf,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := parser.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, ok := out.(claimsPredicate)
+	if !ok {
+		return nil, fmt.Errorf("expected claims predicate, got %T", out)
+	}
+
+	return pr, nil
+}
+
+func andClaims(left, right claimsPredicate) claimsPredicate {
+	return func(claims map[string]any) bool {
+		return left(claims) && right(claims)
+	}
+}
+
+func orClaims(left, right claimsPredicate) claimsPredicate {
+	return func(claims map[string]any) bool {
+		return left(claims) || right(claims)
+	}
+}
+
+func notClaims(pr claimsPredicate) claimsPredicate {
+	return func(claims map[string]any) bool {
+		return !pr(claims)
+	}
+}
+
+// equals checks that the claim value is equal to the given value.
+func equals(key, value string) claimsPredicate {
+	path := claimPath(key)
+
+	return func(claims map[string]any) bool {
+		claim, ok := lookupClaim(claims, path)
+		return ok && claimString(claim) == value
+	}
+}
+
+// prefix checks that the claim value starts with the given value.
+func prefix(key, value string) claimsPredicate {
+	path := claimPath(key)
+
+	return func(claims map[string]any) bool {
+		claim, ok := lookupClaim(claims, path)
+		return ok && strings.HasPrefix(claimString(claim), value)
+	}
+}
+
+// contains checks that the claim array contains the given value, or that the claim string contains it.
+func contains(key, value string) claimsPredicate {
+	path := claimPath(key)
+
+	return func(claims map[string]any) bool {
+		claim, ok := lookupClaim(claims, path)
+		if !ok {
+			return false
+		}
+
+		if values, ok := claim.([]any); ok {
+			return slices.ContainsFunc(values, func(v any) bool {
+				return claimString(v) == value
+			})
+		}
+
+		return strings.Contains(claimString(claim), value)
+	}
+}
+
+// splitContains checks that the claim string, split by the given separator, contains the given value.
+// It is mostly useful for the space-delimited scope claim.
+func splitContains(key, separator, value string) claimsPredicate {
+	path := claimPath(key)
+
+	return func(claims map[string]any) bool {
+		claim, ok := lookupClaim(claims, path)
+		if !ok {
+			return false
+		}
+
+		s, ok := claim.(string)
+		return ok && slices.Contains(strings.Split(s, separator), value)
+	}
+}
+
+// oneOf checks that the claim value, or one of the claim array values, is one of the given values.
+func oneOf(key string, values ...string) claimsPredicate {
+	path := claimPath(key)
+
+	return func(claims map[string]any) bool {
+		claim, ok := lookupClaim(claims, path)
+		if !ok {
+			return false
+		}
+
+		claimValues, ok := claim.([]any)
+		if !ok {
+			claimValues = []any{claim}
+		}
+
+		for _, v := range claimValues {
+			if slices.Contains(values, claimString(v)) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// ClaimHeaders associates request headers with the claims used as their values.
+type ClaimHeaders map[string][]string
+
+// NewClaimHeaders creates ClaimHeaders from claim keys indexed by header name.
+// Nested claims are selected by separating their keys with dots.
+func NewClaimHeaders(headers map[string]string) ClaimHeaders {
+	claimHeaders := make(ClaimHeaders, len(headers))
+	for header, key := range headers {
+		claimHeaders[http.CanonicalHeaderKey(header)] = claimPath(key)
+	}
+
+	return claimHeaders
+}
+
+// Set sets the headers with the values of their claims.
+// A header whose claim is missing is removed, so that it cannot be forged by the client.
+func (c ClaimHeaders) Set(header http.Header, claims map[string]any) {
+	for name, path := range c {
+		header.Del(name)
+
+		if claim, ok := lookupClaim(claims, path); ok {
+			header.Set(name, claimString(claim))
+		}
+	}
+}
+
+// claimPath splits a claim key into the keys of the nested claims.
+// The dots separate the nested keys, unless escaped with a backslash.
+func claimPath(key string) []string {
+	var path []string
+
+	var current strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\' && i+1 < len(key):
+			i++
+			current.WriteByte(key[i])
+		case key[i] == '.':
+			path = append(path, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(key[i])
+		}
+	}
+
+	return append(path, current.String())
+}
+
+func lookupClaim(claims map[string]any, path []string) (any, bool) {
+	var value any = claims
+	for _, key := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// claimString returns the string representation of a claim value.
+// Strings are returned as is, while other values are JSON encoded.
+func claimString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+
+		return string(b)
+	}
+}
@@ -0,0 +1,132 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseClaimsExpression(t *testing.T) {
+	claims := map[string]any{
+		"active":   true,
+		"grp":      "admin",
+		"level":    float64(42),
+		"scope":    "reader writer deploy",
+		"referrer": "http://example.com/foo/bar",
+		"areas":    []any{"office", "home"},
+		"user": map[string]any{
+			"name": "John Snow",
+		},
+		"dotted.key": "foo",
+	}
+
+	testCases := []struct {
+		desc        string
+		expression  string
+		expected    bool
+		expectedErr bool
+	}{
+		{
+			desc:       "Equals",
+			expression: "Equals(`grp`, `admin`)",
+			expected:   true,
+		},
+		{
+			desc:       "Equals not matching",
+			expression: "Equals(`grp`, `user`)",
+		},
+		{
+			desc:       "Equals on a boolean",
+			expression: "Equals(`active`, `true`)",
+			expected:   true,
+		},
+		{
+			desc:       "Equals on a number",
+			expression: "Equals(`level`, `42`)",
+			expected:   true,
+		},
+		{
+			desc:       "Equals on a missing claim",
+			expression: "Equals(`missing`, ``)",
+		},
+		{
+			desc:       "Prefix",
+			expression: "Prefix(`referrer`, `http://example.com`)",
+			expected:   true,
+		},
+		{
+			desc:       "Contains on a string",
+			expression: "Contains(`referrer`, `/foo/`)",
+			expected:   true,
+		},
+		{
+			desc:       "Contains on an array",
+			expression: "Contains(`areas`, `home`)",
+			expected:   true,
+		},
+		{
+			desc:       "Contains on an array not matching",
+			expression: "Contains(`areas`, `hom`)",
+		},
+		{
+			desc:       "SplitContains",
+			expression: "SplitContains(`scope`, ` `, `writer`)",
+			expected:   true,
+		},
+		{
+			desc:       "SplitContains not matching",
+			expression: "SplitContains(`scope`, ` `, `write`)",
+		},
+		{
+			desc:       "OneOf",
+			expression: "OneOf(`areas`, `lab`, `office`)",
+			expected:   true,
+		},
+		{
+			desc:       "OneOf on a string",
+			expression: "OneOf(`grp`, `dev`, `admin`)",
+			expected:   true,
+		},
+		{
+			desc:       "nested claim",
+			expression: "Equals(`user.name`, `John Snow`)",
+			expected:   true,
+		},
+		{
+			desc:       "escaped dot",
+			expression: "Equals(`dotted\\.key`, `foo`)",
+			expected:   true,
+		},
+		{
+			desc:       "operators",
+			expression: "Equals(`grp`, `admin`) && (Equals(`active`, `false`) || !Contains(`areas`, `lab`))",
+			expected:   true,
+		},
+		{
+			desc:        "unknown function",
+			expression:  "Foo(`grp`, `admin`)",
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid expression",
+			expression:  "Equals(`grp`, `admin`) &&",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			pr, err := parseClaimsExpression(test.expression)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, pr(claims))
+		})
+	}
+}
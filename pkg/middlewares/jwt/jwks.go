@@ -0,0 +1,161 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxJWKSSize is the maximum size of a JSON Web Key Set document.
+const maxJWKSSize = 1024 * 1024
+
+// minRefetchInterval is the minimum time between two fetches of a key set,
+// to prevent tokens forged with random key IDs, or an unreachable endpoint, from hammering the JWKS endpoint.
+const minRefetchInterval = 10 * time.Second
+
+// keySet fetches a JSON Web Key Set, and caches it for the configured refresh interval.
+type keySet struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	group singleflight.Group
+
+	mu          sync.RWMutex
+	keys        *jose.JSONWebKeySet
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	// fetchErr is the error of the last failed fetch.
+	fetchErr error
+}
+
+func newKeySet(url string, client *http.Client, refreshInterval time.Duration) *keySet {
+	return &keySet{
+		url:             url,
+		client:          client,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// verificationKeys returns the keys which may have been used to sign a token with the given key ID.
+// When the key ID is empty, all the signature keys of the set are returned.
+func (k *keySet) verificationKeys(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	keys, fetchedAt, attemptedAt, fetchErr := k.cached()
+
+	refetchable := time.Since(attemptedAt) > minRefetchInterval
+
+	// The key set has never been fetched successfully, and the last attempt is too recent to try again.
+	if keys == nil && !refetchable && fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	expired := time.Since(fetchedAt) > k.refreshInterval
+	// The keys may have been rotated since the last fetch.
+	rotated := keys != nil && kid != "" && len(keys.Key(kid)) == 0
+
+	if keys == nil || ((expired || rotated) && refetchable) {
+		fetched, err := k.fetch(ctx)
+		switch {
+		case err == nil:
+			keys = fetched
+		case keys == nil:
+			return nil, err
+		default:
+			// Keep using the previous keys until the JWKS endpoint is reachable again.
+			log.Ctx(ctx).Warn().Err(err).Msg("Unable to refresh JWKS, using the previously fetched keys")
+		}
+	}
+
+	candidates := keys.Keys
+	if kid != "" {
+		candidates = keys.Key(kid)
+	}
+
+	var verificationKeys []jose.JSONWebKey
+	for _, key := range candidates {
+		if key.Use == "" || key.Use == "sig" {
+			verificationKeys = append(verificationKeys, key)
+		}
+	}
+
+	return verificationKeys, nil
+}
+
+func (k *keySet) cached() (*jose.JSONWebKeySet, time.Time, time.Time, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.keys, k.fetchedAt, k.attemptedAt, k.fetchErr
+}
+
+func (k *keySet) fetch(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	// The fetch is detached from the cancellation of the request which triggered it,
+	// as its result is shared with the concurrent requests.
+	keys, err, _ := k.group.Do(k.url, func() (any, error) {
+		keys, err := k.doFetch(context.WithoutCancel(ctx))
+
+		k.mu.Lock()
+		k.fetchErr = err
+		k.mu.Unlock()
+
+		return keys, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys.(*jose.JSONWebKeySet), nil
+}
+
+func (k *keySet) doFetch(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	k.mu.Lock()
+	k.attemptedAt = time.Now()
+	k.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating JWKS request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching JWKS: unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS: %w", err)
+	}
+
+	if len(body) > maxJWKSSize {
+		return nil, errors.New("reading JWKS: document too large")
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(body, &keys); err != nil {
+		return nil, fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	k.mu.Lock()
+	k.keys = &keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+
+	return &keys, nil
+}
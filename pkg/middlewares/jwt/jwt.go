@@ -0,0 +1,154 @@
+// Package jwt implements a middleware validating the JSON Web Tokens given as bearer tokens,
+// with signature keys fetched from a JSON Web Key Set endpoint.
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/observability"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+const typeName = "JWT"
+
+type jwtAuth struct {
+	next                 http.Handler
+	name                 string
+	verifier             *Verifier
+	claims               claimsPredicate
+	forwardHeaders       ClaimHeaders
+	forwardAuthorization bool
+}
+
+// New creates a JWT middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.JWT, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.JWKSURL == "" {
+		return nil, errors.New("jwksUrl must be defined")
+	}
+
+	if _, err := url.ParseRequestURI(config.JWKSURL); err != nil {
+		return nil, fmt.Errorf("parsing jwksUrl: %w", err)
+	}
+
+	if config.JWKSRefreshInterval <= 0 {
+		return nil, errors.New("jwksRefreshInterval must be greater than zero")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	if config.TLS != nil {
+		clientTLS := &types.ClientTLS{
+			CA:                 config.TLS.CA,
+			Cert:               config.TLS.Cert,
+			Key:                config.TLS.Key,
+			InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+		}
+
+		tlsConfig, err := clientTLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create client TLS configuration: %w", err)
+		}
+
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = tlsConfig
+		client.Transport = tr
+	}
+
+	verifier := NewVerifier(config.JWKSURL, client, time.Duration(config.JWKSRefreshInterval), config.Issuer, config.Audience)
+	verifier.allowMissingExpiration = config.AllowMissingExpiration
+
+	j := &jwtAuth{
+		next:                 next,
+		name:                 name,
+		verifier:             verifier,
+		forwardHeaders:       NewClaimHeaders(config.ForwardHeaders),
+		forwardAuthorization: config.ForwardAuthorization,
+	}
+
+	if config.Claims != "" {
+		var err error
+		j.claims, err = parseClaimsExpression(config.Claims)
+		if err != nil {
+			return nil, fmt.Errorf("parsing claims expression %q: %w", config.Claims, err)
+		}
+	}
+
+	return j, nil
+}
+
+func (j *jwtAuth) GetTracingInformation() (string, string) {
+	return j.name, typeName
+}
+
+func (j *jwtAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), j.name, typeName)
+
+	rawToken, ok := bearerToken(req)
+	if !ok {
+		logger.Debug().Msg("Missing bearer token")
+		observability.SetStatusErrorf(req.Context(), "Missing bearer token")
+
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := j.verifier.Verify(req.Context(), rawToken)
+	if err != nil {
+		var keySetErr *KeySetError
+		if errors.As(err, &keySetErr) {
+			logger.Error().Err(err).Msg("Unable to get the token verification keys")
+			observability.SetStatusErrorf(req.Context(), "Unable to get the token verification keys")
+
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		logger.Debug().Err(err).Msg("Invalid token")
+		observability.SetStatusErrorf(req.Context(), "Invalid token")
+
+		rw.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if j.claims != nil && !j.claims(claims) {
+		logger.Debug().Msg("Token claims do not match the claims expression")
+		observability.SetStatusErrorf(req.Context(), "Token claims do not match the claims expression")
+
+		rw.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	j.forwardHeaders.Set(req.Header, claims)
+
+	if !j.forwardAuthorization {
+		req.Header.Del("Authorization")
+	}
+
+	j.next.ServeHTTP(rw, req)
+}
+
+// bearerToken returns the token given in the Authorization header of the request (RFC 6750 section 2.1).
+func bearerToken(req *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
@@ -0,0 +1,336 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	josejwt "github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwksServer := newJWKSServer(t, jose.JSONWebKey{Key: key.Public(), KeyID: "key", Algorithm: string(jose.RS256), Use: "sig"})
+
+	now := time.Now()
+
+	testCases := []struct {
+		desc            string
+		config          dynamic.JWT
+		authorization   string
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "not a bearer token",
+			authorization:  "Basic Zm9vOmJhcg==",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "malformed token",
+			authorization:  "Bearer foo.bar.baz",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "valid token",
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{Subject: "foo", Expiry: josejwt.NewNumericDate(now.Add(time.Hour))}, nil),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Authorization": "",
+			},
+		},
+		{
+			desc:           "valid token with authorization forwarded",
+			config:         dynamic.JWT{ForwardAuthorization: true},
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{Subject: "foo"}, nil),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Authorization": "Bearer " + signToken(t, key, "key", josejwt.Claims{Subject: "foo"}, nil),
+			},
+		},
+		{
+			desc:           "token without key ID",
+			authorization:  "Bearer " + signToken(t, key, "", josejwt.Claims{Subject: "foo"}, nil),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "token signed with an unknown key ID",
+			authorization:  "Bearer " + signToken(t, key, "unknown", josejwt.Claims{Subject: "foo"}, nil),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "token signed with another key",
+			authorization:  "Bearer " + signToken(t, otherKey, "key", josejwt.Claims{Subject: "foo"}, nil),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "expired token",
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{Expiry: josejwt.NewNumericDate(now.Add(-time.Hour))}, nil),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "token without expiration",
+			authorization:  "Bearer " + signClaims(t, key, "key", josejwt.Claims{Subject: "foo"}),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "token without expiration allowed",
+			config:         dynamic.JWT{AllowMissingExpiration: true},
+			authorization:  "Bearer " + signClaims(t, key, "key", josejwt.Claims{Subject: "foo"}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "token not valid yet",
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{NotBefore: josejwt.NewNumericDate(now.Add(time.Hour))}, nil),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "expected issuer and audience",
+			config:         dynamic.JWT{Issuer: "https://issuer.example.com", Audience: []string{"foo", "bar"}},
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{Issuer: "https://issuer.example.com", Audience: josejwt.Audience{"bar"}}, nil),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "unexpected issuer",
+			config:         dynamic.JWT{Issuer: "https://issuer.example.com"},
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{Issuer: "https://other.example.com"}, nil),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "unexpected audience",
+			config:         dynamic.JWT{Audience: []string{"foo"}},
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{Audience: josejwt.Audience{"bar"}}, nil),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "claims matching the expression",
+			config:         dynamic.JWT{Claims: "Equals(`grp`, `admin`)"},
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{}, map[string]any{"grp": "admin"}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "claims not matching the expression",
+			config:         dynamic.JWT{Claims: "Equals(`grp`, `admin`)"},
+			authorization:  "Bearer " + signToken(t, key, "key", josejwt.Claims{}, map[string]any{"grp": "user"}),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc: "forwarded claims",
+			config: dynamic.JWT{ForwardHeaders: map[string]string{
+				"X-Subject": "sub",
+				"X-Name":    "user.name",
+				"X-Areas":   "areas",
+				"X-Missing": "missing",
+			}},
+			authorization: "Bearer " + signToken(t, key, "key", josejwt.Claims{Subject: "foo"}, map[string]any{
+				"user":  map[string]any{"name": "John Snow"},
+				"areas": []string{"office", "home"},
+			}),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Subject": "foo",
+				"X-Name":    "John Snow",
+				"X-Areas":   `["office","home"]`,
+				"X-Missing": "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := test.config
+			config.SetDefaults()
+			config.JWKSURL = jwksServer.URL
+
+			var forwarded *http.Request
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req
+			})
+
+			handler, err := New(t.Context(), next, config, "jwt")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			req.Header.Set("X-Missing", "spoofed")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+
+			if test.expectedStatus != http.StatusOK {
+				assert.NotEmpty(t, recorder.Header().Get("WWW-Authenticate"))
+				assert.Nil(t, forwarded)
+				return
+			}
+
+			require.NotNil(t, forwarded)
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, forwarded.Header.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestJWT_keyRotation(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var keys atomic.Value
+	keys.Store(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: "key"}}})
+
+	var fetches atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(rw).Encode(keys.Load())
+	}))
+	t.Cleanup(server.Close)
+
+	config := dynamic.JWT{JWKSURL: server.URL, JWKSRefreshInterval: ptypes.Duration(time.Hour)}
+
+	handler, err := New(t.Context(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), config, "jwt")
+	require.NoError(t, err)
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(signToken(t, key, "key", josejwt.Claims{}, nil)))
+	assert.Equal(t, http.StatusOK, serve(signToken(t, key, "key", josejwt.Claims{}, nil)))
+	assert.Equal(t, int64(1), fetches.Load())
+
+	keys.Store(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: newKey.Public(), KeyID: "new"}}})
+
+	// The key set has been fetched too recently to be fetched again.
+	assert.Equal(t, http.StatusUnauthorized, serve(signToken(t, newKey, "new", josejwt.Claims{}, nil)))
+	assert.Equal(t, int64(1), fetches.Load())
+
+	handler.(*jwtAuth).verifier.keySet.attemptedAt = time.Now().Add(-minRefetchInterval)
+
+	assert.Equal(t, http.StatusOK, serve(signToken(t, newKey, "new", josejwt.Claims{}, nil)))
+	assert.Equal(t, int64(2), fetches.Load())
+}
+
+func TestJWT_unreachableJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	config := dynamic.JWT{JWKSURL: server.URL, JWKSRefreshInterval: ptypes.Duration(time.Hour)}
+
+	handler, err := New(t.Context(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), config, "jwt")
+	require.NoError(t, err)
+
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, key, "key", josejwt.Claims{}, nil))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	for range 10 {
+		assert.Equal(t, http.StatusServiceUnavailable, serve())
+	}
+
+	// The key set has been fetched too recently to be fetched again.
+	assert.Equal(t, int64(1), fetches.Load())
+
+	handler.(*jwtAuth).verifier.keySet.attemptedAt = time.Now().Add(-minRefetchInterval)
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve())
+	assert.Equal(t, int64(2), fetches.Load())
+}
+
+func newJWKSServer(t *testing.T, keys ...jose.JSONWebKey) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: keys})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// signToken signs a token with the given claims, expiring in an hour unless another expiration is given.
+func signToken(t *testing.T, key any, kid string, claims josejwt.Claims, privateClaims map[string]any) string {
+	t.Helper()
+
+	if claims.Expiry == nil {
+		claims.Expiry = josejwt.NewNumericDate(time.Now().Add(time.Hour))
+	}
+
+	return signClaims(t, key, kid, claims, privateClaims)
+}
+
+func signClaims(t *testing.T, key any, kid string, claims ...any) string {
+	t.Helper()
+
+	alg := jose.RS256
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		alg = jose.ES256
+	}
+
+	opts := &jose.SignerOptions{}
+	if kid != "" {
+		opts = opts.WithHeader(jose.HeaderKey("kid"), kid)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, opts.WithType("JWT"))
+	require.NoError(t, err)
+
+	builder := josejwt.Signed(signer)
+	for _, c := range claims {
+		builder = builder.Claims(c)
+	}
+
+	token, err := builder.Serialize()
+	require.NoError(t, err)
+
+	return token
+}
@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	josejwt "github.com/go-jose/go-jose/v4/jwt"
+)
+
+// leeway is the clock skew tolerated when validating the exp, nbf and iat claims.
+const leeway = time.Minute
+
+// signatureAlgorithms are the accepted signature algorithms.
+// Symmetric algorithms are excluded, as the keys are published in a JWKS.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// KeySetError is returned when the keys needed to verify a token cannot be fetched.
+type KeySetError struct {
+	err error
+}
+
+func (e *KeySetError) Error() string {
+	return e.err.Error()
+}
+
+func (e *KeySetError) Unwrap() error {
+	return e.err
+}
+
+// Verifier verifies the signature of JSON Web Tokens with the keys of a JSON Web Key Set,
+// and validates their registered claims.
+type Verifier struct {
+	keySet   *keySet
+	expected josejwt.Expected
+	// allowMissingExpiration accepts the tokens without an exp claim, which are otherwise rejected.
+	allowMissingExpiration bool
+}
+
+// NewVerifier creates a Verifier fetching the keys from the given JWKS URL.
+// When not empty, the issuer and audience are the expected values of the iss and aud claims.
+func NewVerifier(jwksURL string, client *http.Client, refreshInterval time.Duration, issuer string, audience []string) *Verifier {
+	return &Verifier{
+		keySet: newKeySet(jwksURL, client, refreshInterval),
+		expected: josejwt.Expected{
+			Issuer:      issuer,
+			AnyAudience: audience,
+		},
+	}
+}
+
+// Verify verifies the given token and returns all its claims.
+func (v *Verifier) Verify(ctx context.Context, rawToken string) (map[string]any, error) {
+	token, err := josejwt.ParseSigned(rawToken, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("parsing token: %w", err)
+	}
+
+	keys, err := v.keySet.verificationKeys(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, &KeySetError{err: err}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no verification key found for key ID %q", token.Headers[0].KeyID)
+	}
+
+	var registered josejwt.Claims
+	var claims map[string]any
+
+	for _, key := range keys {
+		if err = token.Claims(key.Key, &registered, &claims); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("verifying token signature: %w", err)
+	}
+
+	if registered.Expiry == nil && !v.allowMissingExpiration {
+		return nil, errors.New("validating token claims: missing exp claim")
+	}
+
+	if err := registered.ValidateWithLeeway(v.expected.WithTime(time.Now()), leeway); err != nil {
+		return nil, fmt.Errorf("validating token claims: %w", err)
+	}
+
+	return claims, nil
+}
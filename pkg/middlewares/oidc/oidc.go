@@ -0,0 +1,442 @@
+// Package oidc implements a middleware acting as an OpenID Connect relying party:
+// it authenticates the users with the authorization code flow, and keeps their session in an encrypted cookie.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/jwt"
+	"github.com/traefik/traefik/v3/pkg/middlewares/observability"
+	"github.com/traefik/traefik/v3/pkg/server/cookie"
+	"github.com/traefik/traefik/v3/pkg/types"
+	"golang.org/x/oauth2"
+)
+
+const typeName = "OIDC"
+
+// stateMaxAge is the maximum duration, in seconds, of an authentication with the provider.
+const stateMaxAge = 10 * 60
+
+type oidc struct {
+	next               http.Handler
+	name               string
+	clientID           string
+	clientSecret       string
+	scopes             []string
+	redirectURL        *url.URL
+	logoutPath         string
+	discovery          *discovery
+	client             *http.Client
+	cookies            *cookieStore
+	sessionName        string
+	stateName          string
+	sessionMaxAge      int
+	forwardHeaders     jwt.ClaimHeaders
+	forwardAccessToken bool
+}
+
+// New creates an OpenID Connect middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.OIDC, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.Issuer == "" {
+		return nil, errors.New("issuer must be defined")
+	}
+
+	if config.ClientID == "" {
+		return nil, errors.New("clientID must be defined")
+	}
+
+	redirectURL, err := url.Parse(config.RedirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing redirectURL: %w", err)
+	}
+
+	if redirectURL.Path == "" {
+		return nil, errors.New("redirectURL must define a path")
+	}
+
+	sessionConfig := dynamic.OIDCSession{}
+	sessionConfig.SetDefaults()
+	if config.Session != nil {
+		sessionConfig = *config.Session
+	}
+
+	cookies, err := newCookieStore(sessionConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	if config.TLS != nil {
+		clientTLS := &types.ClientTLS{
+			CA:                 config.TLS.CA,
+			Cert:               config.TLS.Cert,
+			Key:                config.TLS.Key,
+			InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+		}
+
+		tlsConfig, err := clientTLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create client TLS configuration: %w", err)
+		}
+
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = tlsConfig
+		client.Transport = tr
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range config.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	sessionName := cookie.GetName(sessionConfig.Name, name)
+
+	return &oidc{
+		next:               next,
+		name:               name,
+		clientID:           config.ClientID,
+		clientSecret:       config.ClientSecret,
+		scopes:             scopes,
+		redirectURL:        redirectURL,
+		logoutPath:         config.LogoutPath,
+		discovery:          newDiscovery(config.Issuer, config.ClientID, client),
+		client:             client,
+		cookies:            cookies,
+		sessionName:        sessionName,
+		stateName:          sessionName + "_state",
+		sessionMaxAge:      sessionConfig.MaxAge,
+		forwardHeaders:     jwt.NewClaimHeaders(config.ForwardHeaders),
+		forwardAccessToken: config.ForwardAccessToken,
+	}, nil
+}
+
+func (o *oidc) GetTracingInformation() (string, string) {
+	return o.name, typeName
+}
+
+func (o *oidc) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), o.name, typeName)
+
+	p, err := o.discovery.getProvider(req.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to discover the OpenID Connect provider")
+		observability.SetStatusErrorf(req.Context(), "Unable to discover the OpenID Connect provider")
+
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	switch req.URL.Path {
+	case o.redirectURL.Path:
+		o.handleCallback(logger, rw, req, p)
+		return
+	case o.logoutPath:
+		if o.logoutPath != "" {
+			o.handleLogout(rw, req, p)
+			return
+		}
+	}
+
+	var sess session
+	if err := o.cookies.load(req, o.sessionName, &sess); err != nil {
+		if !errors.Is(err, errNoCookie) {
+			logger.Debug().Err(err).Msg("Invalid session cookie")
+		}
+
+		o.authenticate(logger, rw, req, p)
+		return
+	}
+
+	now := time.Now()
+
+	if now.After(sess.ExpiresAt) {
+		logger.Debug().Msg("Session expired")
+		o.authenticate(logger, rw, req, p)
+		return
+	}
+
+	if !sess.TokenExpiry.IsZero() && now.After(sess.TokenExpiry) {
+		if sess.RefreshToken == "" {
+			logger.Debug().Msg("Tokens expired, and cannot be refreshed")
+			o.authenticate(logger, rw, req, p)
+			return
+		}
+
+		if err := o.refresh(req, p, &sess); err != nil {
+			logger.Debug().Err(err).Msg("Unable to refresh the tokens")
+			o.authenticate(logger, rw, req, p)
+			return
+		}
+
+		if err := o.saveSession(rw, req, sess); err != nil {
+			logger.Error().Err(err).Msg("Unable to save the session")
+			observability.SetStatusErrorf(req.Context(), "Unable to save the session")
+
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	o.forwardHeaders.Set(req.Header, sess.Claims)
+
+	if o.forwardAccessToken {
+		req.Header.Set("Authorization", "Bearer "+sess.AccessToken)
+	}
+
+	o.removeCookies(req)
+
+	o.next.ServeHTTP(rw, req)
+}
+
+// authenticate redirects the user to the provider authorization endpoint,
+// in the same way ForwardAuth redirects to its sign-in URL.
+func (o *oidc) authenticate(logger *zerolog.Logger, rw http.ResponseWriter, req *http.Request, p *provider) {
+	// A request with side effects cannot be replayed after the authentication.
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		observability.SetStatusErrorf(req.Context(), "Unauthenticated request")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	state := authState{
+		State:        randomString(),
+		Nonce:        randomString(),
+		CodeVerifier: oauth2.GenerateVerifier(),
+		RedirectURI:  req.URL.RequestURI(),
+		ExpiresAt:    time.Now().Add(stateMaxAge * time.Second),
+	}
+
+	// The state cookie must be sent along the redirection from the provider, which is a cross-site navigation.
+	if err := o.cookies.save(rw, req, o.stateName, state, stateMaxAge, http.SameSiteLaxMode); err != nil {
+		logger.Error().Err(err).Msg("Unable to save the authentication state")
+		observability.SetStatusErrorf(req.Context(), "Unable to save the authentication state")
+
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	authCodeURL := o.oauth2Config(req, p).AuthCodeURL(state.State,
+		oauth2.S256ChallengeOption(state.CodeVerifier),
+		oauth2.SetAuthURLParam("nonce", state.Nonce),
+	)
+
+	logger.Debug().Msgf("Redirecting to the authorization endpoint: %s", p.metadata.AuthorizationEndpoint)
+
+	http.Redirect(rw, req, authCodeURL, http.StatusFound)
+}
+
+// handleCallback handles the redirection from the provider, once the user is authenticated.
+func (o *oidc) handleCallback(logger *zerolog.Logger, rw http.ResponseWriter, req *http.Request, p *provider) {
+	unauthorized := func(err error) {
+		logger.Debug().Err(err).Msg("Authentication failed")
+		observability.SetStatusErrorf(req.Context(), "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	}
+
+	var state authState
+	if err := o.cookies.load(req, o.stateName, &state); err != nil {
+		unauthorized(fmt.Errorf("loading authentication state: %w", err))
+		return
+	}
+
+	o.cookies.clear(rw, req, o.stateName)
+
+	query := req.URL.Query()
+
+	if time.Now().After(state.ExpiresAt) || query.Get("state") != state.State {
+		unauthorized(errors.New("invalid authentication state"))
+		return
+	}
+
+	if errCode := query.Get("error"); errCode != "" {
+		unauthorized(fmt.Errorf("provider error %q: %s", errCode, query.Get("error_description")))
+		return
+	}
+
+	token, err := o.oauth2Config(req, p).Exchange(o.clientContext(req.Context()), query.Get("code"), oauth2.VerifierOption(state.CodeVerifier))
+	if err != nil {
+		unauthorized(fmt.Errorf("exchanging authorization code: %w", err))
+		return
+	}
+
+	claims, err := o.verifyIDToken(req.Context(), p, token)
+	if err != nil {
+		unauthorized(err)
+		return
+	}
+
+	if claims["nonce"] != state.Nonce {
+		unauthorized(errors.New("invalid ID token nonce"))
+		return
+	}
+
+	sess := session{
+		Claims:       claims,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenExpiry:  token.Expiry,
+		ExpiresAt:    time.Now().Add(time.Duration(o.sessionMaxAge) * time.Second),
+	}
+
+	if err := o.saveSession(rw, req, sess); err != nil {
+		logger.Error().Err(err).Msg("Unable to save the session")
+		observability.SetStatusErrorf(req.Context(), "Unable to save the session")
+
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(rw, req, state.RedirectURI, http.StatusFound)
+}
+
+// handleLogout removes the session, and redirects the user to the provider end session endpoint when there is one.
+func (o *oidc) handleLogout(rw http.ResponseWriter, req *http.Request, p *provider) {
+	o.cookies.clear(rw, req, o.sessionName)
+
+	if p.metadata.EndSessionEndpoint == "" {
+		http.Redirect(rw, req, "/", http.StatusFound)
+		return
+	}
+
+	endSessionURL, err := url.Parse(p.metadata.EndSessionEndpoint)
+	if err != nil {
+		http.Redirect(rw, req, "/", http.StatusFound)
+		return
+	}
+
+	query := endSessionURL.Query()
+	query.Set("client_id", o.clientID)
+	endSessionURL.RawQuery = query.Encode()
+
+	http.Redirect(rw, req, endSessionURL.String(), http.StatusFound)
+}
+
+// refresh refreshes the tokens of the session.
+func (o *oidc) refresh(req *http.Request, p *provider, sess *session) error {
+	expired := &oauth2.Token{RefreshToken: sess.RefreshToken, Expiry: time.Now().Add(-time.Second)}
+
+	token, err := o.oauth2Config(req, p).TokenSource(o.clientContext(req.Context()), expired).Token()
+	if err != nil {
+		return fmt.Errorf("refreshing tokens: %w", err)
+	}
+
+	// The provider may not issue a new ID token when refreshing the tokens (OpenID Connect Core 1.0 section 12.2).
+	if _, ok := token.Extra("id_token").(string); ok {
+		claims, err := o.verifyIDToken(req.Context(), p, token)
+		if err != nil {
+			return err
+		}
+
+		sess.Claims = claims
+	}
+
+	sess.AccessToken = token.AccessToken
+	sess.RefreshToken = token.RefreshToken
+	sess.TokenExpiry = token.Expiry
+
+	return nil
+}
+
+func (o *oidc) verifyIDToken(ctx context.Context, p *provider, token *oauth2.Token) (map[string]any, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("missing ID token in token response")
+	}
+
+	claims, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("verifying ID token: %w", err)
+	}
+
+	return claims, nil
+}
+
+func (o *oidc) saveSession(rw http.ResponseWriter, req *http.Request, sess session) error {
+	maxAge := int(time.Until(sess.ExpiresAt).Seconds())
+
+	return o.cookies.save(rw, req, o.sessionName, sess, maxAge, o.cookies.sameSite)
+}
+
+// removeCookies removes the middleware cookies from the forwarded request, so that the session does not leak to the service.
+func (o *oidc) removeCookies(req *http.Request) {
+	cookies := req.Cookies()
+	if len(cookies) == 0 {
+		return
+	}
+
+	req.Header.Del("Cookie")
+
+	for _, c := range cookies {
+		if isChunkOf(c.Name, o.sessionName) || isChunkOf(c.Name, o.stateName) {
+			continue
+		}
+
+		req.AddCookie(c)
+	}
+}
+
+func (o *oidc) oauth2Config(req *http.Request, p *provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     o.clientID,
+		ClientSecret: o.clientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.metadata.AuthorizationEndpoint,
+			TokenURL: p.metadata.TokenEndpoint,
+		},
+		RedirectURL: o.callbackURL(req),
+		Scopes:      o.scopes,
+	}
+}
+
+// callbackURL returns the absolute URL of the callback, resolved against the requested URL when it is a path.
+func (o *oidc) callbackURL(req *http.Request) string {
+	if o.redirectURL.IsAbs() {
+		return o.redirectURL.String()
+	}
+
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.ToLower(proto)
+	}
+
+	u := *o.redirectURL
+	u.Scheme = scheme
+	u.Host = req.Host
+
+	return u.String()
+}
+
+func (o *oidc) clientContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, o.client)
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
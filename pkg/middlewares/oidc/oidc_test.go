@@ -0,0 +1,413 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	josejwt "github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+const testSecret = "01234567890123456789012345678901"
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.OIDC
+		expectedErr bool
+	}{
+		{
+			desc: "valid configuration",
+			config: dynamic.OIDC{
+				Issuer:   "https://issuer.example.com",
+				ClientID: "client",
+				Session:  &dynamic.OIDCSession{Secret: testSecret},
+			},
+		},
+		{
+			desc: "missing issuer",
+			config: dynamic.OIDC{
+				ClientID: "client",
+				Session:  &dynamic.OIDCSession{Secret: testSecret},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "missing client ID",
+			config: dynamic.OIDC{
+				Issuer:  "https://issuer.example.com",
+				Session: &dynamic.OIDCSession{Secret: testSecret},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "short session secret",
+			config: dynamic.OIDC{
+				Issuer:   "https://issuer.example.com",
+				ClientID: "client",
+				Session:  &dynamic.OIDCSession{Secret: "secret"},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "missing session secret",
+			config: dynamic.OIDC{
+				Issuer:   "https://issuer.example.com",
+				ClientID: "client",
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.OIDC{}
+			config.SetDefaults()
+			config.Issuer = test.config.Issuer
+			config.ClientID = test.config.ClientID
+			config.Session = test.config.Session
+
+			_, err := New(t.Context(), http.NotFoundHandler(), config, "oidc")
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOIDC_authorizationCodeFlow(t *testing.T) {
+	idp := newFakeProvider(t)
+
+	config := dynamic.OIDC{}
+	config.SetDefaults()
+	config.Issuer = idp.server.URL
+	config.ClientID = "client"
+	config.ClientSecret = "secret"
+	config.LogoutPath = "/logout"
+	config.Session.Secret = testSecret
+	config.ForwardHeaders = map[string]string{"X-Email": "email", "X-Missing": "missing"}
+	config.ForwardAccessToken = true
+
+	var forwarded *http.Request
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req
+	})
+
+	handler, err := New(t.Context(), next, config, "oidc")
+	require.NoError(t, err)
+
+	jar := newCookieJar()
+
+	// Unauthenticated requests are redirected to the provider.
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/foo?bar=baz", nil)
+	rw := jar.serve(handler, req)
+
+	require.Equal(t, http.StatusFound, rw.Code)
+	assert.Nil(t, forwarded)
+
+	authURL, err := url.Parse(rw.Header().Get("Location"))
+	require.NoError(t, err)
+
+	query := authURL.Query()
+	assert.Equal(t, idp.server.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "client", query.Get("client_id"))
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "openid profile email", query.Get("scope"))
+	assert.Equal(t, "http://localhost/oidc/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+	assert.NotEmpty(t, query.Get("nonce"))
+
+	// Requests other than GET cannot be replayed after authentication.
+	req = httptest.NewRequest(http.MethodPost, "http://localhost/foo", nil)
+	assert.Equal(t, http.StatusUnauthorized, jar.serve(handler, req).Code)
+
+	// The callback with an unknown state is rejected.
+	code := idp.authorize(query.Get("nonce"))
+
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/oidc/callback?code="+code+"&state=invalid", nil)
+	assert.Equal(t, http.StatusUnauthorized, jar.copy().serve(handler, req).Code)
+
+	// The callback exchanges the code, and redirects to the requested URL.
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/oidc/callback?code="+code+"&state="+query.Get("state"), nil)
+	rw = jar.serve(handler, req)
+
+	require.Equal(t, http.StatusFound, rw.Code)
+	assert.Equal(t, "/foo?bar=baz", rw.Header().Get("Location"))
+
+	// Authenticated requests are forwarded.
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/foo?bar=baz", nil)
+	req.Header.Set("X-Missing", "spoofed")
+	req.AddCookie(&http.Cookie{Name: "app", Value: "value"})
+	rw = jar.serve(handler, req)
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NotNil(t, forwarded)
+	assert.Equal(t, "john@example.com", forwarded.Header.Get("X-Email"))
+	assert.Empty(t, forwarded.Header.Get("X-Missing"))
+	assert.Equal(t, "Bearer access-token", forwarded.Header.Get("Authorization"))
+	assert.Equal(t, "app=value", forwarded.Header.Get("Cookie"))
+
+	// The logout removes the session, and redirects to the provider.
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/logout", nil)
+	rw = jar.serve(handler, req)
+
+	require.Equal(t, http.StatusFound, rw.Code)
+	assert.Equal(t, idp.server.URL+"/logout?client_id=client", rw.Header().Get("Location"))
+
+	forwarded = nil
+	req = httptest.NewRequest(http.MethodGet, "http://localhost/foo", nil)
+	assert.Equal(t, http.StatusFound, jar.serve(handler, req).Code)
+	assert.Nil(t, forwarded)
+}
+
+func TestOIDC_invalidNonce(t *testing.T) {
+	idp := newFakeProvider(t)
+
+	config := dynamic.OIDC{}
+	config.SetDefaults()
+	config.Issuer = idp.server.URL
+	config.ClientID = "client"
+	config.Session.Secret = testSecret
+
+	handler, err := New(t.Context(), http.NotFoundHandler(), config, "oidc")
+	require.NoError(t, err)
+
+	jar := newCookieJar()
+
+	rw := jar.serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+	require.Equal(t, http.StatusFound, rw.Code)
+
+	authURL, err := url.Parse(rw.Header().Get("Location"))
+	require.NoError(t, err)
+
+	code := idp.authorize("another nonce")
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/oidc/callback?code="+code+"&state="+authURL.Query().Get("state"), nil)
+	assert.Equal(t, http.StatusUnauthorized, jar.serve(handler, req).Code)
+}
+
+func TestOIDC_refresh(t *testing.T) {
+	idp := newFakeProvider(t)
+
+	config := dynamic.OIDC{}
+	config.SetDefaults()
+	config.Issuer = idp.server.URL
+	config.ClientID = "client"
+	config.Session.Secret = testSecret
+	config.ForwardAccessToken = true
+
+	var forwarded *http.Request
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req
+	})
+
+	handler, err := New(t.Context(), next, config, "oidc")
+	require.NoError(t, err)
+
+	o := handler.(*oidc)
+
+	// Create a session whose access token has expired.
+	rw := httptest.NewRecorder()
+	err = o.saveSession(rw, httptest.NewRequest(http.MethodGet, "http://localhost/", nil), session{
+		Claims:       map[string]any{"sub": "john"},
+		AccessToken:  "expired-token",
+		RefreshToken: "refresh-token",
+		TokenExpiry:  time.Now().Add(-time.Minute),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	jar := newCookieJar()
+	jar.store(rw.Result().Cookies())
+
+	rw = jar.serve(handler, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NotNil(t, forwarded)
+	assert.Equal(t, "Bearer access-token", forwarded.Header.Get("Authorization"))
+	assert.NotEmpty(t, rw.Result().Cookies())
+}
+
+func TestOIDC_unreachableProvider(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	config := dynamic.OIDC{}
+	config.SetDefaults()
+	config.Issuer = server.URL
+	config.ClientID = "client"
+	config.Session.Secret = testSecret
+
+	handler, err := New(t.Context(), http.NotFoundHandler(), config, "oidc")
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+}
+
+// fakeProvider is a minimal OpenID Provider.
+type fakeProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu     sync.Mutex
+	nonces map[string]string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &fakeProvider{t: t, key: key, nonces: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(providerMetadata{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/jwks",
+			EndSessionEndpoint:    p.server.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: "key", Algorithm: string(jose.RS256), Use: "sig"}}})
+	})
+	mux.HandleFunc("/token", p.token)
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+// authorize returns an authorization code, for which an ID token with the given nonce is issued.
+func (p *fakeProvider) authorize(nonce string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	code := randomString()
+	p.nonces[code] = nonce
+
+	return code
+}
+
+func (p *fakeProvider) token(rw http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	claims := map[string]any{"email": "john@example.com"}
+
+	switch req.Form.Get("grant_type") {
+	case "authorization_code":
+		if req.Form.Get("code_verifier") == "" {
+			http.Error(rw, `{"error":"invalid_request"}`, http.StatusBadRequest)
+			return
+		}
+
+		p.mu.Lock()
+		nonce, ok := p.nonces[req.Form.Get("code")]
+		p.mu.Unlock()
+
+		if !ok {
+			http.Error(rw, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		claims["nonce"] = nonce
+
+	case "refresh_token":
+		if req.Form.Get("refresh_token") != "refresh-token" {
+			http.Error(rw, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+	default:
+		http.Error(rw, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: p.key}, (&jose.SignerOptions{}).WithHeader(jose.HeaderKey("kid"), "key"))
+	require.NoError(p.t, err)
+
+	idToken, err := josejwt.Signed(signer).Claims(josejwt.Claims{
+		Issuer:   p.server.URL,
+		Subject:  "john",
+		Audience: josejwt.Audience{"client"},
+		Expiry:   josejwt.NewNumericDate(time.Now().Add(time.Hour)),
+		IssuedAt: josejwt.NewNumericDate(time.Now()),
+	}).Claims(claims).Serialize()
+	require.NoError(p.t, err)
+
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(map[string]any{
+		"access_token":  "access-token",
+		"token_type":    "Bearer",
+		"refresh_token": "refresh-token",
+		"expires_in":    3600,
+		"id_token":      idToken,
+	})
+}
+
+// cookieJar keeps the cookies set by the middleware, in the same way a browser does.
+type cookieJar map[string]*http.Cookie
+
+func newCookieJar() cookieJar {
+	return make(cookieJar)
+}
+
+func (j cookieJar) serve(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	for _, c := range j {
+		req.AddCookie(c)
+	}
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	j.store(rw.Result().Cookies())
+
+	return rw
+}
+
+func (j cookieJar) store(cookies []*http.Cookie) {
+	for _, c := range cookies {
+		if c.MaxAge < 0 {
+			delete(j, c.Name)
+			continue
+		}
+
+		j[c.Name] = &http.Cookie{Name: c.Name, Value: c.Value}
+	}
+}
+
+func (j cookieJar) copy() cookieJar {
+	c := make(cookieJar, len(j))
+	for name, cookie := range j {
+		c[name] = cookie
+	}
+
+	return c
+}
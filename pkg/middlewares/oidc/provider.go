@@ -0,0 +1,124 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/middlewares/jwt"
+	"golang.org/x/sync/singleflight"
+)
+
+// maxDiscoverySize is the maximum size of a provider metadata document.
+const maxDiscoverySize = 1024 * 1024
+
+// jwksRefreshInterval is the interval at which the provider keys are fetched again.
+const jwksRefreshInterval = 10 * time.Minute
+
+// providerMetadata holds the OpenID Provider metadata used by the middleware (OpenID Connect Discovery 1.0 section 3).
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// provider is an OpenID Provider, whose metadata has been discovered.
+type provider struct {
+	metadata providerMetadata
+	verifier *jwt.Verifier
+}
+
+// discovery discovers the metadata of an OpenID Provider on first use.
+// When the discovery fails, it is attempted again on the next use.
+type discovery struct {
+	issuer   string
+	clientID string
+	client   *http.Client
+
+	group singleflight.Group
+
+	mu       sync.RWMutex
+	provider *provider
+}
+
+func newDiscovery(issuer, clientID string, client *http.Client) *discovery {
+	return &discovery{
+		issuer:   issuer,
+		clientID: clientID,
+		client:   client,
+	}
+}
+
+func (d *discovery) getProvider(ctx context.Context) (*provider, error) {
+	d.mu.RLock()
+	p := d.provider
+	d.mu.RUnlock()
+
+	if p != nil {
+		return p, nil
+	}
+
+	// The discovery is detached from the cancellation of the request which triggered it,
+	// as its result is shared with the concurrent requests.
+	result, err, _ := d.group.Do(d.issuer, func() (any, error) {
+		return d.discover(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(*provider), nil
+}
+
+func (d *discovery) discover(ctx context.Context) (*provider, error) {
+	discoveryURL := strings.TrimSuffix(d.issuer, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating discovery request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching provider metadata: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching provider metadata: unexpected status code %d", resp.StatusCode)
+	}
+
+	var metadata providerMetadata
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDiscoverySize)).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("decoding provider metadata: %w", err)
+	}
+
+	// The issuer must be identical to the one used to discover the metadata (OpenID Connect Discovery 1.0 section 4.3).
+	if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(d.issuer, "/") {
+		return nil, fmt.Errorf("provider metadata issuer %q does not match %q", metadata.Issuer, d.issuer)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("provider metadata is missing required endpoints: %+v", metadata)
+	}
+
+	p := &provider{
+		metadata: metadata,
+		verifier: jwt.NewVerifier(metadata.JWKSURI, d.client, jwksRefreshInterval, metadata.Issuer, []string{d.clientID}),
+	}
+
+	d.mu.Lock()
+	d.provider = p
+	d.mu.Unlock()
+
+	return p, nil
+}
@@ -0,0 +1,225 @@
+package oidc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// maxChunkSize is the maximum size of a cookie value.
+// Browsers limit the size of a cookie, including its name and attributes, to 4096 bytes,
+// so larger values are split in several cookies.
+const maxChunkSize = 3800
+
+// maxChunks is the maximum number of cookies a value can be split in.
+const maxChunks = 10
+
+var errNoCookie = errors.New("cookie not found")
+
+// session is the content of the session cookie.
+type session struct {
+	// Claims are the claims of the ID token.
+	Claims       map[string]any `json:"claims"`
+	AccessToken  string         `json:"accessToken,omitempty"`
+	RefreshToken string         `json:"refreshToken,omitempty"`
+	// TokenExpiry is the expiry of the access token, after which the tokens are refreshed.
+	TokenExpiry time.Time `json:"tokenExpiry,omitzero"`
+	// ExpiresAt is the time at which the session expires, whether its tokens can be refreshed or not.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// authState is the content of the cookie holding the state of an authentication in progress.
+type authState struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"codeVerifier"`
+	RedirectURI  string    `json:"redirectURI"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// cookieStore stores values in encrypted cookies.
+type cookieStore struct {
+	aead     cipher.AEAD
+	path     string
+	domain   string
+	secure   bool
+	sameSite http.SameSite
+}
+
+func newCookieStore(config dynamic.OIDCSession) (*cookieStore, error) {
+	if len(config.Secret) < 32 {
+		return nil, errors.New("session secret must be at least 32 characters long")
+	}
+
+	key := sha256.Sum256([]byte(config.Secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("creating session cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating session cipher: %w", err)
+	}
+
+	return &cookieStore{
+		aead:     aead,
+		path:     config.Path,
+		domain:   config.Domain,
+		secure:   config.Secure,
+		sameSite: convertSameSite(config.SameSite),
+	}, nil
+}
+
+// save encrypts the value and sets it in the named cookie, split in several cookies when needed.
+func (c *cookieStore) save(rw http.ResponseWriter, req *http.Request, name string, value any, maxAge int, sameSite http.SameSite) error {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding cookie value: %w", err)
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generating cookie nonce: %w", err)
+	}
+
+	// The cookie name is authenticated, so that the content of a cookie cannot be used in another one.
+	encoded := base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, plaintext, []byte(name)))
+
+	var chunks []string
+	for len(encoded) > maxChunkSize {
+		chunks = append(chunks, encoded[:maxChunkSize])
+		encoded = encoded[maxChunkSize:]
+	}
+	chunks = append(chunks, encoded)
+
+	if len(chunks) > maxChunks {
+		return fmt.Errorf("cookie value too large: %d bytes", len(plaintext))
+	}
+
+	for i, chunk := range chunks {
+		http.SetCookie(rw, c.cookie(chunkName(name, i), chunk, maxAge, sameSite))
+	}
+
+	// Remove the chunks of a previous larger value.
+	for i := len(chunks); i < maxChunks; i++ {
+		if _, err := req.Cookie(chunkName(name, i)); err != nil {
+			break
+		}
+
+		http.SetCookie(rw, c.cookie(chunkName(name, i), "", -1, sameSite))
+	}
+
+	return nil
+}
+
+// load decrypts the value of the named cookie.
+func (c *cookieStore) load(req *http.Request, name string, value any) error {
+	var encoded strings.Builder
+	for i := range maxChunks {
+		cookie, err := req.Cookie(chunkName(name, i))
+		if err != nil {
+			break
+		}
+
+		encoded.WriteString(cookie.Value)
+	}
+
+	if encoded.Len() == 0 {
+		return errNoCookie
+	}
+
+	ciphertext, err := base64.RawURLEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return fmt.Errorf("decoding cookie: %w", err)
+	}
+
+	if len(ciphertext) < c.aead.NonceSize() {
+		return errors.New("decoding cookie: value too short")
+	}
+
+	nonce, ciphertext := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return fmt.Errorf("decrypting cookie: %w", err)
+	}
+
+	if err := json.Unmarshal(plaintext, value); err != nil {
+		return fmt.Errorf("decoding cookie value: %w", err)
+	}
+
+	return nil
+}
+
+// clear removes the named cookie, and all its chunks.
+func (c *cookieStore) clear(rw http.ResponseWriter, req *http.Request, name string) {
+	for i := range maxChunks {
+		if _, err := req.Cookie(chunkName(name, i)); err != nil {
+			return
+		}
+
+		http.SetCookie(rw, c.cookie(chunkName(name, i), "", -1, c.sameSite))
+	}
+}
+
+func (c *cookieStore) cookie(name, value string, maxAge int, sameSite http.SameSite) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     c.path,
+		Domain:   c.domain,
+		MaxAge:   maxAge,
+		Secure:   c.secure,
+		HttpOnly: true,
+		SameSite: sameSite,
+	}
+}
+
+func chunkName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+
+	return name + "_" + strconv.Itoa(i)
+}
+
+// isChunkOf reports whether the given cookie name is the one of a chunk of the named cookie.
+func isChunkOf(cookieName, name string) bool {
+	if cookieName == name {
+		return true
+	}
+
+	suffix, ok := strings.CutPrefix(cookieName, name+"_")
+	if !ok {
+		return false
+	}
+
+	i, err := strconv.Atoi(suffix)
+	return err == nil && i > 0 && i < maxChunks
+}
+
+func convertSameSite(sameSite string) http.SameSite {
+	switch strings.ToLower(sameSite) {
+	case "none":
+		return http.SameSiteNoneMode
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	default:
+		return http.SameSiteDefaultMode
+	}
+}
@@ -0,0 +1,115 @@
+package oidc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestCookieStore(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		value          map[string]string
+		expectedChunks int
+	}{
+		{
+			desc:           "small value",
+			value:          map[string]string{"foo": "bar"},
+			expectedChunks: 1,
+		},
+		{
+			desc:           "large value",
+			value:          map[string]string{"foo": strings.Repeat("a", 2*maxChunkSize)},
+			expectedChunks: 3,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := newCookieStore(dynamic.OIDCSession{Secret: testSecret, Path: "/"})
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			err = store.save(rw, httptest.NewRequest(http.MethodGet, "/", nil), "session", test.value, 60, http.SameSiteLaxMode)
+			require.NoError(t, err)
+
+			cookies := rw.Result().Cookies()
+			require.Len(t, cookies, test.expectedChunks)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, c := range cookies {
+				assert.True(t, c.HttpOnly)
+				assert.LessOrEqual(t, len(c.Value), maxChunkSize)
+
+				req.AddCookie(c)
+			}
+
+			var value map[string]string
+			require.NoError(t, store.load(req, "session", &value))
+			assert.Equal(t, test.value, value)
+
+			// The value cannot be loaded under another name.
+			renamed := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, c := range cookies {
+				renamed.AddCookie(&http.Cookie{Name: strings.Replace(c.Name, "session", "other", 1), Value: c.Value})
+			}
+			assert.Error(t, store.load(renamed, "other", &value))
+
+			// The value cannot be loaded with another secret.
+			otherStore, err := newCookieStore(dynamic.OIDCSession{Secret: strings.Repeat("b", 32)})
+			require.NoError(t, err)
+			assert.Error(t, otherStore.load(req, "session", &value))
+		})
+	}
+}
+
+func TestCookieStore_removesStaleChunks(t *testing.T) {
+	store, err := newCookieStore(dynamic.OIDCSession{Secret: testSecret})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "a"})
+	req.AddCookie(&http.Cookie{Name: "session_1", Value: "b"})
+	req.AddCookie(&http.Cookie{Name: "session_2", Value: "c"})
+
+	rw := httptest.NewRecorder()
+	require.NoError(t, store.save(rw, req, "session", "value", 60, http.SameSiteLaxMode))
+
+	maxAges := map[string]int{}
+	for _, c := range rw.Result().Cookies() {
+		maxAges[c.Name] = c.MaxAge
+	}
+
+	assert.Equal(t, map[string]int{"session": 60, "session_1": -1, "session_2": -1}, maxAges)
+}
+
+func TestIsChunkOf(t *testing.T) {
+	testCases := []struct {
+		cookieName string
+		expected   bool
+	}{
+		{cookieName: "session", expected: true},
+		{cookieName: "session_1", expected: true},
+		{cookieName: "session_9", expected: true},
+		{cookieName: "session_10"},
+		{cookieName: "session_0"},
+		{cookieName: "session_state"},
+		{cookieName: "sessions"},
+		{cookieName: "other"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.cookieName, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, isChunkOf(test.cookieName, "session"))
+		})
+	}
+}
@@ -42,7 +42,11 @@ func newInMemoryRateLimiter(rl rate.Limit, burst int64, maxDelay time.Duration,
 	}, nil
 }
 
-func (i *inMemoryRateLimiter) Allow(_ context.Context, source string) (*time.Duration, error) {
+func (i *inMemoryRateLimiter) Allow(ctx context.Context, source string) (*time.Duration, error) {
+	return i.reserve(ctx, source, 1, i.maxDelay)
+}
+
+func (i *inMemoryRateLimiter) reserve(_ context.Context, source string, n int64, maxDelay time.Duration) (*time.Duration, error) {
 	// Get bucket which contains limiter information.
 	bucket, exists := i.buckets.Get(source)
 	if !exists {
@@ -56,13 +60,13 @@ func (i *inMemoryRateLimiter) Allow(_ context.Context, source string) (*time.Dur
 		return nil, fmt.Errorf("setting buckets: %w", err)
 	}
 
-	res := bucket.Reserve()
+	res := bucket.ReserveN(time.Now(), int(n))
 	if !res.OK() {
 		return nil, nil
 	}
 
 	delay := res.Delay()
-	if delay > i.maxDelay {
+	if delay > maxDelay {
 		res.Cancel()
 	}
 
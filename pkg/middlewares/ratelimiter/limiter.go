@@ -0,0 +1,126 @@
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"golang.org/x/time/rate"
+)
+
+// Limiter is a set of token buckets, one for each traffic source, sharing the same parameters.
+// The buckets are kept in memory, or in Redis so that several Traefik instances enforce the same budget.
+type Limiter struct {
+	limiter limiter
+	rate    rate.Limit // tokens/s
+	// maxDelay is the maximum duration we're willing to wait for a bucket reservation to become effective.
+	maxDelay time.Duration
+}
+
+// NewLimiter creates a Limiter with the Average, Period, Burst, and Redis options of the given configuration.
+// Its SourceCriterion is ignored, as the source of the traffic is given to each reservation.
+func NewLimiter(ctx context.Context, config dynamic.RateLimit, logger *zerolog.Logger) (*Limiter, error) {
+	burst := max(config.Burst, 1)
+
+	period := time.Duration(config.Period)
+	if period < 0 {
+		return nil, fmt.Errorf("negative value not valid for period: %v", period)
+	}
+	if period == 0 {
+		period = time.Second
+	}
+
+	// Initialized at rate.Inf to enforce no rate limiting when config.Average == 0
+	rtl := float64(rate.Inf)
+	// No need to set any particular value for maxDelay as the reservation's delay
+	// will be <= 0 in the Inf case (i.e. the average == 0 case).
+	var maxDelay time.Duration
+
+	if config.Average > 0 {
+		rtl = float64(config.Average*int64(time.Second)) / float64(period)
+		// maxDelay does not scale well for rates below 1,
+		// so we just cap it to the corresponding value, i.e. 0.5s, in order to keep the effective rate predictable.
+		// One alternative would be to switch to a no-reservation mode (Allow() method) whenever we are in such a low rate regime.
+		if rtl < 1 {
+			maxDelay = 500 * time.Millisecond
+		} else {
+			maxDelay = time.Second / (time.Duration(rtl) * 2)
+		}
+	}
+
+	// Make the ttl inversely proportional to how often a rate limiter is supposed to see any activity (when maxed out),
+	// for low rate limiters.
+	// Otherwise just make it a second for all the high rate limiters.
+	// Add an extra second in both cases for continuity between the two cases.
+	ttl := 1
+	if rtl >= 1 {
+		ttl++
+	} else if rtl > 0 {
+		ttl += int(1 / rtl)
+	}
+
+	var (
+		limiter limiter
+		err     error
+	)
+	if config.Redis != nil {
+		limiter, err = newRedisLimiter(ctx, rate.Limit(rtl), burst, maxDelay, ttl, config, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating redis limiter: %w", err)
+		}
+	} else {
+		limiter, err = newInMemoryRateLimiter(rate.Limit(rtl), burst, maxDelay, ttl, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating in-memory limiter: %w", err)
+		}
+	}
+
+	return &Limiter{
+		limiter:  limiter,
+		rate:     rate.Limit(rtl),
+		maxDelay: maxDelay,
+	}, nil
+}
+
+// Allow reserves a token in the bucket of the given source.
+// It returns the delay to wait before using the token,
+// or false when the token cannot be obtained without waiting for longer than the maximum delay.
+func (l *Limiter) Allow(ctx context.Context, source string) (time.Duration, bool, error) {
+	return l.reserve(ctx, source, 1, l.maxDelay)
+}
+
+// ReserveN reserves n tokens in the bucket of the given source, whatever the delay,
+// and returns the delay to wait before using them.
+// It fails when n is greater than the burst, as the tokens could never be obtained.
+func (l *Limiter) ReserveN(ctx context.Context, source string, n int64) (time.Duration, error) {
+	delay, ok, err := l.reserve(ctx, source, n, math.MaxInt64)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return 0, fmt.Errorf("cannot reserve %d tokens, which is more than the burst", n)
+	}
+
+	return delay, nil
+}
+
+func (l *Limiter) reserve(ctx context.Context, source string, n int64, maxDelay time.Duration) (time.Duration, bool, error) {
+	if l.rate == rate.Inf {
+		return 0, true, nil
+	}
+
+	delay, err := l.limiter.reserve(ctx, source, n, maxDelay)
+	if err != nil {
+		return 0, false, fmt.Errorf("could not insert/update bucket: %w", err)
+	}
+
+	if delay == nil || *delay > maxDelay {
+		return 0, false, nil
+	}
+
+	return *delay, true, nil
+}
@@ -23,6 +23,7 @@ var AllowTokenBucketRaw = `
 local key = KEYS[1]
 local limit, burst, ttl, t, max_delay = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3]), tonumber(ARGV[4]),
     tonumber(ARGV[5])
+local amount = tonumber(ARGV[6]) or 1
 
 local bucket = {
     limit = limit,
@@ -48,13 +49,13 @@ local elapsed = t - last
 local delta = bucket.limit * elapsed
 local tokens = bucket.tokens + delta
 tokens = math.min(tokens, bucket.burst)
-tokens = tokens - 1
+tokens = tokens - amount
 
 local wait_duration = 0
 if tokens < 0 then
     wait_duration = (tokens * -1) / bucket.limit
     if wait_duration > max_delay then
-        tokens = tokens + 1
+        tokens = tokens + amount
         tokens = math.min(tokens, burst)
     end
 end
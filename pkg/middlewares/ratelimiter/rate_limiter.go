@@ -24,6 +24,9 @@ const (
 
 type limiter interface {
 	Allow(ctx context.Context, token string) (*time.Duration, error)
+	// reserve reserves n tokens, and cancels the reservation when its delay is greater than maxDelay.
+	// It returns a nil delay when the tokens cannot be reserved at all.
+	reserve(ctx context.Context, token string, n int64, maxDelay time.Duration) (*time.Duration, error)
 }
 
 // rateLimiter implements rate limiting and traffic shaping with a set of token buckets;
@@ -61,65 +64,19 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		return nil, fmt.Errorf("getting source extractor: %w", err)
 	}
 
-	burst := max(config.Burst, 1)
-
-	period := time.Duration(config.Period)
-	if period < 0 {
-		return nil, fmt.Errorf("negative value not valid for period: %v", period)
-	}
-	if period == 0 {
-		period = time.Second
-	}
-
-	// Initialized at rate.Inf to enforce no rate limiting when config.Average == 0
-	rtl := float64(rate.Inf)
-	// No need to set any particular value for maxDelay as the reservation's delay
-	// will be <= 0 in the Inf case (i.e. the average == 0 case).
-	var maxDelay time.Duration
-
-	if config.Average > 0 {
-		rtl = float64(config.Average*int64(time.Second)) / float64(period)
-		// maxDelay does not scale well for rates below 1,
-		// so we just cap it to the corresponding value, i.e. 0.5s, in order to keep the effective rate predictable.
-		// One alternative would be to switch to a no-reservation mode (Allow() method) whenever we are in such a low rate regime.
-		if rtl < 1 {
-			maxDelay = 500 * time.Millisecond
-		} else {
-			maxDelay = time.Second / (time.Duration(rtl) * 2)
-		}
-	}
-
-	// Make the ttl inversely proportional to how often a rate limiter is supposed to see any activity (when maxed out),
-	// for low rate limiters.
-	// Otherwise just make it a second for all the high rate limiters.
-	// Add an extra second in both cases for continuity between the two cases.
-	ttl := 1
-	if rtl >= 1 {
-		ttl++
-	} else if rtl > 0 {
-		ttl += int(1 / rtl)
-	}
-	var limiter limiter
-	if config.Redis != nil {
-		limiter, err = newRedisLimiter(ctx, rate.Limit(rtl), burst, maxDelay, ttl, config, logger)
-		if err != nil {
-			return nil, fmt.Errorf("creating redis limiter: %w", err)
-		}
-	} else {
-		limiter, err = newInMemoryRateLimiter(rate.Limit(rtl), burst, maxDelay, ttl, logger)
-		if err != nil {
-			return nil, fmt.Errorf("creating in-memory limiter: %w", err)
-		}
+	limiter, err := NewLimiter(ctx, config, logger)
+	if err != nil {
+		return nil, err
 	}
 
 	return &rateLimiter{
 		logger:        logger,
 		name:          name,
-		rate:          rate.Limit(rtl),
-		maxDelay:      maxDelay,
+		rate:          limiter.rate,
+		maxDelay:      limiter.maxDelay,
 		next:          next,
 		sourceMatcher: sourceMatcher,
-		limiter:       limiter,
+		limiter:       limiter.limiter,
 	}, nil
 }
 
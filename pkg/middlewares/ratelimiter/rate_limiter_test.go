@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ptypes "github.com/traefik/paerser/types"
@@ -680,3 +681,76 @@ func computeMinCount(wantCount int) int {
 
 	return wantCount * 95 / 100
 }
+
+func TestLimiter(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		redis bool
+	}{
+		{
+			desc: "in-memory",
+		},
+		{
+			desc:  "redis",
+			redis: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.RateLimit{
+				Average: 100,
+				Burst:   100,
+			}
+			if test.redis {
+				config.Redis = &dynamic.Redis{Endpoints: []string{"localhost:6379"}}
+			}
+
+			logger := zerolog.Nop()
+			l, err := NewLimiter(t.Context(), config, &logger)
+			require.NoError(t, err)
+
+			if test.redis {
+				limiter := l.limiter.(*redisLimiter)
+				limiter.client = newMockRedisClient(limiter.ttl)
+			}
+
+			source := strconv.Itoa(rand.Int())
+
+			// The whole burst can be reserved at once.
+			delay, err := l.ReserveN(t.Context(), source, 100)
+			require.NoError(t, err)
+			assert.Zero(t, delay)
+
+			// Once the bucket is empty, the tokens are reserved in advance.
+			delay, err = l.ReserveN(t.Context(), source, 50)
+			require.NoError(t, err)
+			assert.InDelta(t, 500*time.Millisecond, delay, float64(50*time.Millisecond))
+
+			// A token cannot be allowed while the bucket is in debt for longer than the maximum delay.
+			_, ok, err := l.Allow(t.Context(), source)
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			// Another source has its own bucket.
+			delay, ok, err = l.Allow(t.Context(), source+"-other")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Zero(t, delay)
+		})
+	}
+}
+
+func TestLimiter_noRateLimit(t *testing.T) {
+	logger := zerolog.Nop()
+	l, err := NewLimiter(t.Context(), dynamic.RateLimit{}, &logger)
+	require.NoError(t, err)
+
+	for range 100 {
+		delay, err := l.ReserveN(t.Context(), "source", 1000)
+		require.NoError(t, err)
+		assert.Zero(t, delay)
+	}
+}
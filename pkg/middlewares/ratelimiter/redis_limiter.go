@@ -83,7 +83,11 @@ func newRedisLimiter(ctx context.Context, rate rate.Limit, burst int64, maxDelay
 }
 
 func (r *redisLimiter) Allow(ctx context.Context, source string) (*time.Duration, error) {
-	ok, delay, err := r.evaluateScript(ctx, source)
+	return r.reserve(ctx, source, 1, r.maxDelay)
+}
+
+func (r *redisLimiter) reserve(ctx context.Context, source string, n int64, maxDelay time.Duration) (*time.Duration, error) {
+	ok, delay, err := r.evaluateScript(ctx, source, n, maxDelay)
 	if err != nil {
 		return nil, fmt.Errorf("evaluating script: %w", err)
 	}
@@ -93,7 +97,7 @@ func (r *redisLimiter) Allow(ctx context.Context, source string) (*time.Duration
 	return delay, nil
 }
 
-func (r *redisLimiter) evaluateScript(ctx context.Context, key string) (bool, *time.Duration, error) {
+func (r *redisLimiter) evaluateScript(ctx context.Context, key string, n int64, maxDelay time.Duration) (bool, *time.Duration, error) {
 	if r.rate == rate.Inf {
 		return true, nil, nil
 	}
@@ -103,7 +107,8 @@ func (r *redisLimiter) evaluateScript(ctx context.Context, key string) (bool, *t
 		r.burst,
 		r.ttl,
 		time.Now().UnixMicro(),
-		r.maxDelay.Microseconds(),
+		maxDelay.Microseconds(),
+		n,
 	}
 	v, err := r.script.Run(ctx, r.client, []string{redisPrefix + key}, params...).Result()
 	if err != nil {
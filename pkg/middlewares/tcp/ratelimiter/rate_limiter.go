@@ -0,0 +1,204 @@
+// Package ratelimiter implements a TCP middleware limiting the rate of the connections and the bandwidth of a client IP.
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/ratelimiter"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+const typeName = "RateLimiterTCP"
+
+var errConnClosed = errors.New("connection closed while waiting for the rate limiter")
+
+type rateLimiter struct {
+	name string
+	next tcp.Handler
+
+	connections *ratelimiter.Limiter
+
+	bytes      *ratelimiter.Limiter
+	bytesBurst int64
+}
+
+// New creates a TCP rate limiter middleware.
+// The connections are identified and grouped by remote IP.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPRateLimit, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	connections, err := ratelimiter.NewLimiter(ctx, dynamic.RateLimit{
+		Average: config.Average,
+		Period:  config.Period,
+		Burst:   config.Burst,
+		Redis:   config.Redis,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("creating connection rate limiter: %w", err)
+	}
+
+	rl := &rateLimiter{
+		name:        name,
+		next:        next,
+		connections: connections,
+	}
+
+	if config.Bytes != nil && config.Bytes.Average > 0 {
+		rl.bytesBurst = config.Bytes.Burst
+		if rl.bytesBurst <= 0 {
+			rl.bytesBurst = config.Bytes.Average
+		}
+
+		rl.bytes, err = ratelimiter.NewLimiter(ctx, dynamic.RateLimit{
+			Average: config.Bytes.Average,
+			Period:  config.Bytes.Period,
+			Burst:   rl.bytesBurst,
+			Redis:   config.Redis,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating byte rate limiter: %w", err)
+		}
+	}
+
+	return rl, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (r *rateLimiter) ServeTCP(conn tcp.WriteCloser) {
+	logger := middlewares.GetLogger(context.Background(), r.name, typeName)
+	ctx := logger.WithContext(context.Background())
+
+	ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		logger.Error().Err(err).Msg("Cannot parse IP from remote addr")
+		conn.Close()
+		return
+	}
+
+	// Each rate limiter has its own source space, as the buckets may be shared through Redis.
+	delay, ok, err := r.connections.Allow(ctx, r.name+":"+ip)
+	if err != nil {
+		logger.Error().Err(err).Msg("Connection rejected")
+		conn.Close()
+		return
+	}
+
+	if !ok {
+		logger.Debug().Msgf("Connection rate limit reached for %s", ip)
+		conn.Close()
+		return
+	}
+
+	time.Sleep(delay)
+
+	if r.bytes == nil {
+		r.next.ServeTCP(conn)
+		return
+	}
+
+	r.next.ServeTCP(&limitedConn{
+		WriteCloser: conn,
+		ctx:         ctx,
+		logger:      logger,
+		limiter:     r.bytes,
+		source:      r.name + ":bytes:" + ip,
+		burst:       r.bytesBurst,
+		closeCh:     make(chan struct{}),
+	})
+}
+
+// limitedConn is a connection whose reads and writes are delayed to respect the byte rate of its client IP.
+type limitedConn struct {
+	tcp.WriteCloser
+
+	ctx     context.Context
+	logger  *zerolog.Logger
+	limiter *ratelimiter.Limiter
+	source  string
+	// burst is the maximum number of bytes transferred by a single read or write on the underlying connection.
+	burst int64
+
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+// Read reads at most burst bytes from the connection, and waits for the bytes to be allowed before returning.
+func (c *limitedConn) Read(p []byte) (int, error) {
+	if int64(len(p)) > c.burst {
+		p = p[:c.burst]
+	}
+
+	n, err := c.WriteCloser.Read(p)
+	if n > 0 {
+		if waitErr := c.wait(n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
+
+// Write writes the bytes to the connection by chunks of at most burst bytes, once they are allowed.
+func (c *limitedConn) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		chunk := p[:min(int64(len(p)), c.burst)]
+
+		if err := c.wait(len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := c.WriteCloser.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		p = p[n:]
+	}
+
+	return written, nil
+}
+
+// Close closes the connection, and interrupts the pending waits.
+func (c *limitedConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closeCh) })
+
+	return c.WriteCloser.Close()
+}
+
+// Unwrap returns the rate limited connection.
+func (c *limitedConn) Unwrap() tcp.WriteCloser {
+	return c.WriteCloser
+}
+
+func (c *limitedConn) wait(n int) error {
+	delay, err := c.limiter.ReserveN(c.ctx, c.source, int64(n))
+	if err != nil {
+		c.logger.Error().Err(err).Msg("Could not rate limit the connection bytes")
+		return err
+	}
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.closeCh:
+		return errConnClosed
+	}
+}
@@ -0,0 +1,165 @@
+package ratelimiter
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+func TestRateLimiter_connections(t *testing.T) {
+	proceedCh := make(chan struct{}, 10)
+
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		proceedCh <- struct{}{}
+	})
+
+	// One connection per hour, so that the bucket is not refilled during the test.
+	middleware, err := New(t.Context(), next, dynamic.TCPRateLimit{
+		Average: 1,
+		Period:  ptypes.Duration(time.Hour),
+		Burst:   2,
+	}, "foo")
+	require.NoError(t, err)
+
+	// The burst connections are allowed.
+	middleware.ServeTCP(fakeConn{addr: "127.0.0.1:9000"})
+	middleware.ServeTCP(fakeConn{addr: "127.0.0.1:9001"})
+	require.Len(t, proceedCh, 2)
+
+	// The next connection from the same IP is closed.
+	closeCh := make(chan struct{})
+	middleware.ServeTCP(fakeConn{addr: "127.0.0.1:9002", closeCh: closeCh})
+	requireMessage(t, closeCh)
+	require.Len(t, proceedCh, 2)
+
+	// The connection from another IP is allowed.
+	middleware.ServeTCP(fakeConn{addr: "127.0.0.2:9000"})
+	require.Len(t, proceedCh, 3)
+}
+
+func TestRateLimiter_bytes(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		size        int
+		minDuration time.Duration
+	}{
+		{
+			desc: "within burst",
+			size: 100,
+		},
+		{
+			desc:        "exceeding burst",
+			size:        300,
+			minDuration: 150 * time.Millisecond,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			received := make(chan []byte, 1)
+			next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+				defer conn.Close()
+
+				b, err := io.ReadAll(io.LimitReader(conn, int64(test.size)))
+				require.NoError(t, err)
+				received <- b
+			})
+
+			// 1000 bytes per second, with a burst of 100 bytes.
+			middleware, err := New(t.Context(), next, dynamic.TCPRateLimit{
+				Average: 1000,
+				Period:  ptypes.Duration(time.Second),
+				Burst:   1,
+				Bytes: &dynamic.TCPByteRateLimit{
+					Average: 1000,
+					Period:  ptypes.Duration(time.Second),
+					Burst:   100,
+				},
+			}, "foo")
+			require.NoError(t, err)
+
+			server, client := net.Pipe()
+			t.Cleanup(func() { _ = client.Close() })
+
+			go middleware.ServeTCP(pipeConn{Conn: server})
+
+			start := time.Now()
+
+			go func() {
+				_, _ = client.Write(make([]byte, test.size))
+			}()
+
+			select {
+			case b := <-received:
+				assert.Len(t, b, test.size)
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timeout waiting for the bytes")
+			}
+
+			assert.GreaterOrEqual(t, time.Since(start), test.minDuration)
+		})
+	}
+}
+
+func requireMessage(t *testing.T, c chan struct{}) {
+	t.Helper()
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for message")
+	}
+}
+
+type fakeConn struct {
+	net.Conn
+
+	addr    string
+	closeCh chan struct{}
+}
+
+func (c fakeConn) RemoteAddr() net.Addr {
+	return fakeAddr{addr: c.addr}
+}
+
+func (c fakeConn) Close() error {
+	close(c.closeCh)
+	return nil
+}
+
+func (c fakeConn) CloseWrite() error {
+	panic("implement me")
+}
+
+type fakeAddr struct {
+	addr string
+}
+
+func (a fakeAddr) Network() string {
+	return "tcp"
+}
+
+func (a fakeAddr) String() string {
+	return a.addr
+}
+
+// pipeConn is a net.Pipe connection with an IP remote address.
+type pipeConn struct {
+	net.Conn
+}
+
+func (c pipeConn) RemoteAddr() net.Addr {
+	return fakeAddr{addr: "127.0.0.1:9000"}
+}
+
+func (c pipeConn) CloseWrite() error {
+	return c.Close()
+}
@@ -0,0 +1,91 @@
+package inflightsession
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/udp"
+)
+
+const typeName = "InFlightSessionUDP"
+
+type inFlightSession struct {
+	name        string
+	next        udp.Handler
+	maxSessions int64
+
+	mu       sync.Mutex
+	sessions map[string]int64 // current number of sessions by remote IP.
+}
+
+// New creates a max sessions middleware.
+// The sessions are identified and grouped by remote IP.
+func New(ctx context.Context, next udp.Handler, config dynamic.UDPInFlightSession, name string) (udp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	return &inFlightSession{
+		name:        name,
+		next:        next,
+		sessions:    make(map[string]int64),
+		maxSessions: config.Amount,
+	}, nil
+}
+
+// ServeUDP serves the given UDP session.
+func (i *inFlightSession) ServeUDP(conn *udp.Conn) {
+	logger := middlewares.GetLogger(context.Background(), i.name, typeName)
+
+	ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		logger.Error().Err(err).Msg("Cannot parse IP from remote addr")
+		conn.Close()
+		return
+	}
+
+	if err = i.increment(ip); err != nil {
+		logger.Error().Err(err).Msg("Session rejected")
+		conn.Close()
+		return
+	}
+
+	defer i.decrement(ip)
+
+	i.next.ServeUDP(conn)
+}
+
+// increment increases the counter for the number of sessions tracked for the
+// given IP.
+// It returns an error if the counter would go above the max allowed number of
+// sessions.
+func (i *inFlightSession) increment(ip string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.sessions[ip] >= i.maxSessions {
+		return fmt.Errorf("max number of sessions reached for %s", ip)
+	}
+
+	i.sessions[ip]++
+
+	return nil
+}
+
+// decrement decreases the counter for the number of sessions tracked for the
+// given IP.
+// The IP is forgotten once it has no session left.
+func (i *inFlightSession) decrement(ip string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.sessions[ip] <= 1 {
+		delete(i.sessions, ip)
+		return
+	}
+
+	i.sessions[ip]--
+}
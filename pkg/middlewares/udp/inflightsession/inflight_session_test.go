@@ -0,0 +1,84 @@
+package inflightsession
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/udp"
+)
+
+func TestInFlightSession_ServeUDP(t *testing.T) {
+	closeCh := make(chan struct{})
+
+	next := udp.HandlerFunc(func(conn *udp.Conn) {
+		b := make([]byte, 2048)
+		n, err := conn.Read(b)
+		if err != nil {
+			return
+		}
+
+		_, _ = conn.Write(b[:n])
+
+		// The session is kept until the test ends it.
+		<-closeCh
+	})
+
+	middleware, err := New(t.Context(), next, dynamic.UDPInFlightSession{Amount: 1}, "foo")
+	require.NoError(t, err)
+
+	ln, err := udp.Listen(net.ListenConfig{}, "udp", "127.0.0.1:0", 3*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go middleware.ServeUDP(conn)
+		}
+	}()
+
+	// The first session should succeed and stay open.
+	client, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	assert.NoError(t, echo(client, "TEST"))
+
+	// The second session from the same IP should be closed as the maximum number of sessions is exceeded.
+	otherClient, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = otherClient.Close() })
+
+	assert.ErrorIs(t, echo(otherClient, "TEST"), os.ErrDeadlineExceeded)
+
+	// Once the first session is closed, a new session from the same IP should succeed.
+	close(closeCh)
+
+	assert.Eventually(t, func() bool {
+		return echo(otherClient, "TEST") == nil
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func echo(conn net.Conn, data string) error {
+	if _, err := conn.Write([]byte(data)); err != nil {
+		return err
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+		return err
+	}
+
+	b := make([]byte, 2048)
+	_, err := conn.Read(b)
+
+	return err
+}
@@ -0,0 +1,131 @@
+package ipallowlist
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/udp"
+)
+
+func TestNewIPAllowLister(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		allowList     dynamic.UDPIPAllowList
+		expectedError bool
+	}{
+		{
+			desc:          "Empty config",
+			allowList:     dynamic.UDPIPAllowList{},
+			expectedError: true,
+		},
+		{
+			desc: "invalid IP",
+			allowList: dynamic.UDPIPAllowList{
+				SourceRange: []string{"foo"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "valid IP",
+			allowList: dynamic.UDPIPAllowList{
+				SourceRange: []string{"10.10.10.10"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := udp.HandlerFunc(func(conn *udp.Conn) {})
+			allowLister, err := New(t.Context(), next, test.allowList, "traefikTest")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, allowLister)
+			}
+		})
+	}
+}
+
+func TestIPAllowLister_ServeUDP(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		allowList     dynamic.UDPIPAllowList
+		expectedError error
+	}{
+		{
+			desc: "authorized with remote address",
+			allowList: dynamic.UDPIPAllowList{
+				SourceRange: []string{"127.0.0.1"},
+			},
+		},
+		{
+			desc: "non authorized with remote address",
+			allowList: dynamic.UDPIPAllowList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			expectedError: os.ErrDeadlineExceeded,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := udp.HandlerFunc(func(conn *udp.Conn) {
+				b := make([]byte, 2048)
+				n, err := conn.Read(b)
+				if err != nil {
+					return
+				}
+
+				_, _ = conn.Write(b[:n])
+			})
+
+			allowLister, err := New(t.Context(), next, test.allowList, "traefikTest")
+			require.NoError(t, err)
+
+			ln, err := udp.Listen(net.ListenConfig{}, "udp", "127.0.0.1:0", 3*time.Second)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = ln.Close() })
+
+			go func() {
+				for {
+					conn, err := ln.Accept()
+					if err != nil {
+						return
+					}
+
+					go allowLister.ServeUDP(conn)
+				}
+			}()
+
+			client, err := net.Dial("udp", ln.Addr().String())
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = client.Close() })
+
+			_, err = client.Write([]byte("OK"))
+			require.NoError(t, err)
+
+			require.NoError(t, client.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+
+			b := make([]byte, 2048)
+			n, err := client.Read(b)
+			if test.expectedError != nil {
+				assert.ErrorIs(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "OK", string(b[:n]))
+		})
+	}
+}
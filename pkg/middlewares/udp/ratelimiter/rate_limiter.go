@@ -0,0 +1,119 @@
+// Package ratelimiter implements a UDP middleware limiting the rate of the sessions and of the datagrams of a client IP.
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/ratelimiter"
+	"github.com/traefik/traefik/v3/pkg/udp"
+)
+
+const typeName = "RateLimiterUDP"
+
+type rateLimiter struct {
+	name string
+	next udp.Handler
+
+	sessions *ratelimiter.Limiter
+
+	packets           *ratelimiter.Limiter
+	packetsPerSession bool
+}
+
+// New creates a UDP rate limiter middleware.
+// The sessions are identified and grouped by remote IP.
+func New(ctx context.Context, next udp.Handler, config dynamic.UDPRateLimit, name string) (udp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	sessions, err := ratelimiter.NewLimiter(ctx, dynamic.RateLimit{
+		Average: config.Average,
+		Period:  config.Period,
+		Burst:   config.Burst,
+		Redis:   config.Redis,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("creating session rate limiter: %w", err)
+	}
+
+	rl := &rateLimiter{
+		name:     name,
+		next:     next,
+		sessions: sessions,
+	}
+
+	if config.Packets != nil && config.Packets.Average > 0 {
+		rl.packetsPerSession = config.Packets.PerSession
+		rl.packets, err = ratelimiter.NewLimiter(ctx, dynamic.RateLimit{
+			Average: config.Packets.Average,
+			Period:  config.Packets.Period,
+			Burst:   config.Packets.Burst,
+			Redis:   config.Redis,
+		}, logger)
+		if err != nil {
+			return nil, fmt.Errorf("creating datagram rate limiter: %w", err)
+		}
+	}
+
+	return rl, nil
+}
+
+// ServeUDP serves the given UDP session.
+func (r *rateLimiter) ServeUDP(conn *udp.Conn) {
+	logger := middlewares.GetLogger(context.Background(), r.name, typeName)
+	ctx := logger.WithContext(context.Background())
+
+	ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		logger.Error().Err(err).Msg("Cannot parse IP from remote addr")
+		conn.Close()
+		return
+	}
+
+	// Each rate limiter has its own source space, as the buckets may be shared through Redis.
+	delay, ok, err := r.sessions.Allow(ctx, r.name+":"+ip)
+	if err != nil {
+		logger.Error().Err(err).Msg("Session rejected")
+		conn.Close()
+		return
+	}
+
+	if !ok {
+		logger.Debug().Msgf("Session rate limit reached for %s", ip)
+		conn.Close()
+		return
+	}
+
+	time.Sleep(delay)
+
+	if r.packets != nil {
+		source := r.name + ":packets:" + ip
+		if r.packetsPerSession {
+			source = r.name + ":packets:" + conn.RemoteAddr().String()
+		}
+
+		// The datagrams exceeding the rate are dropped, as UDP does not provide any flow control.
+		conn.AddReadFilter(func([]byte) bool {
+			delay, ok, err := r.packets.Allow(ctx, source)
+			if err != nil {
+				logger.Error().Err(err).Msg("Datagram dropped")
+				return false
+			}
+
+			if !ok {
+				return false
+			}
+
+			time.Sleep(delay)
+
+			return true
+		})
+	}
+
+	r.next.ServeUDP(conn)
+}
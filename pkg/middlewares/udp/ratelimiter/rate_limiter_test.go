@@ -0,0 +1,154 @@
+package ratelimiter
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/udp"
+)
+
+func TestRateLimiter_ServeUDP(t *testing.T) {
+	next := udp.HandlerFunc(func(conn *udp.Conn) {
+		b := make([]byte, 2048)
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				return
+			}
+
+			if _, err = conn.Write(b[:n]); err != nil {
+				return
+			}
+		}
+	})
+
+	// The buckets are not refilled during the test.
+	middleware, err := New(t.Context(), next, dynamic.UDPRateLimit{
+		Average: 1,
+		Period:  ptypes.Duration(time.Hour),
+		Burst:   1,
+		Packets: &dynamic.UDPPacketRateLimit{
+			Average: 1,
+			Period:  ptypes.Duration(time.Hour),
+			Burst:   2,
+		},
+	}, "foo")
+	require.NoError(t, err)
+
+	ln, err := udp.Listen(net.ListenConfig{}, "udp", "127.0.0.1:0", 3*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go middleware.ServeUDP(conn)
+		}
+	}()
+
+	client, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	// The datagrams of the burst are forwarded.
+	assert.NoError(t, echo(client, "TEST1"))
+	assert.NoError(t, echo(client, "TEST2"))
+
+	// The next datagram of the session is dropped.
+	assert.ErrorIs(t, echo(client, "TEST3"), os.ErrDeadlineExceeded)
+
+	// A new session from the same IP is rejected.
+	otherClient, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = otherClient.Close() })
+
+	assert.ErrorIs(t, echo(otherClient, "TEST"), os.ErrDeadlineExceeded)
+}
+
+func echo(conn net.Conn, data string) error {
+	if _, err := conn.Write([]byte(data)); err != nil {
+		return err
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+		return err
+	}
+
+	b := make([]byte, 2048)
+	n, err := conn.Read(b)
+	if err != nil {
+		return err
+	}
+
+	if string(b[:n]) != data {
+		return errors.New("unexpected echo: " + string(b[:n]))
+	}
+
+	return nil
+}
+
+func TestRateLimiter_packetsPerSession(t *testing.T) {
+	next := udp.HandlerFunc(func(conn *udp.Conn) {
+		b := make([]byte, 2048)
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				return
+			}
+
+			if _, err = conn.Write(b[:n]); err != nil {
+				return
+			}
+		}
+	})
+
+	middleware, err := New(t.Context(), next, dynamic.UDPRateLimit{
+		Packets: &dynamic.UDPPacketRateLimit{
+			Average:    1,
+			Period:     ptypes.Duration(time.Hour),
+			Burst:      1,
+			PerSession: true,
+		},
+	}, "foo")
+	require.NoError(t, err)
+
+	ln, err := udp.Listen(net.ListenConfig{}, "udp", "127.0.0.1:0", 3*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go middleware.ServeUDP(conn)
+		}
+	}()
+
+	client, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	assert.NoError(t, echo(client, "TEST1"))
+	assert.ErrorIs(t, echo(client, "TEST2"), os.ErrDeadlineExceeded)
+
+	// Another session from the same IP has its own budget.
+	otherClient, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = otherClient.Close() })
+
+	assert.NoError(t, echo(otherClient, "TEST"))
+}
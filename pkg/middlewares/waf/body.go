@@ -0,0 +1,108 @@
+package waf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseBody parses the arguments of the URL-encoded, multipart and JSON request bodies into ARGS_POST and FILES.
+// The JSON values are named after their path, e.g. json.user.roles.0.
+func parseBody(t *transaction) error {
+	contentType := t.req.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("parsing Content-Type: %w", err)
+	}
+
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		t.argsPost = parseArgs(string(t.body))
+		return nil
+
+	case mediaType == "multipart/form-data":
+		return parseMultipart(t, params["boundary"])
+
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return parseJSON(t)
+
+	default:
+		return nil
+	}
+}
+
+func parseMultipart(t *transaction, boundary string) error {
+	if boundary == "" {
+		return errors.New("missing multipart boundary")
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(t.body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading multipart body: %w", err)
+		}
+
+		if filename := part.FileName(); filename != "" {
+			t.files = append(t.files, keyValue{key: part.FormName(), value: filename})
+			continue
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return fmt.Errorf("reading multipart body: %w", err)
+		}
+
+		t.argsPost = append(t.argsPost, keyValue{key: part.FormName(), value: string(value)})
+	}
+}
+
+func parseJSON(t *transaction) error {
+	decoder := json.NewDecoder(bytes.NewReader(t.body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("parsing JSON body: %w", err)
+	}
+
+	t.argsPost = flattenJSON(t.argsPost, "json", value)
+
+	return nil
+}
+
+func flattenJSON(args []keyValue, key string, value any) []keyValue {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range slices.Sorted(maps.Keys(v)) {
+			args = flattenJSON(args, key+"."+name, v[name])
+		}
+	case []any:
+		for i, child := range v {
+			args = flattenJSON(args, key+"."+strconv.Itoa(i), child)
+		}
+	case nil:
+		args = append(args, keyValue{key: key})
+	case string:
+		args = append(args, keyValue{key: key, value: v})
+	default:
+		args = append(args, keyValue{key: key, value: fmt.Sprint(v)})
+	}
+
+	return args
+}
@@ -0,0 +1,231 @@
+package waf
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/traefik/v3/pkg/ip"
+)
+
+// maxCaptures is the number of regular expression groups captured in the TX:0 to TX:9 variables.
+const maxCaptures = 10
+
+// operator is the operator of a rule, e.g. @rx or !@streq.
+type operator struct {
+	name    string
+	negated bool
+	fn      operatorFunc
+}
+
+// operatorFunc reports whether the input matches, and returns the captured values, if any.
+type operatorFunc func(t *transaction, input string) (bool, []string)
+
+func (o *operator) match(t *transaction, input string) (bool, []string) {
+	ok, captures := o.fn(t, input)
+	if o.negated {
+		return !ok, nil
+	}
+
+	return ok, captures
+}
+
+// operators are the supported operators, by lower-case name.
+var operators = map[string]func(param string) (operatorFunc, error){
+	"rx":                 newRx,
+	"streq":              expandedOperator(func(param, input string) bool { return input == param }),
+	"contains":           expandedOperator(func(param, input string) bool { return strings.Contains(input, param) }),
+	"containsword":       expandedOperator(containsWord),
+	"beginswith":         expandedOperator(func(param, input string) bool { return strings.HasPrefix(input, param) }),
+	"endswith":           expandedOperator(func(param, input string) bool { return strings.HasSuffix(input, param) }),
+	"within":             expandedOperator(func(param, input string) bool { return strings.Contains(param, input) }),
+	"eq":                 numericOperator(func(param, input int) bool { return input == param }),
+	"ge":                 numericOperator(func(param, input int) bool { return input >= param }),
+	"gt":                 numericOperator(func(param, input int) bool { return input > param }),
+	"le":                 numericOperator(func(param, input int) bool { return input <= param }),
+	"lt":                 numericOperator(func(param, input int) bool { return input < param }),
+	"pm":                 newPm,
+	"ipmatch":            newIPMatch,
+	"validatebyterange":  newValidateByteRange,
+	"unconditionalmatch": func(string) (operatorFunc, error) { return constantOperator(true), nil },
+	"nomatch":            func(string) (operatorFunc, error) { return constantOperator(false), nil },
+}
+
+// parseOperator parses the operator of a rule.
+// An operator without name, e.g. "^admin", is a regular expression.
+func parseOperator(raw string) (*operator, error) {
+	raw = strings.TrimSpace(raw)
+
+	op := &operator{name: "rx"}
+	if rest, ok := strings.CutPrefix(raw, "!"); ok {
+		op.negated = true
+		raw = rest
+	}
+
+	param := raw
+	if rest, ok := strings.CutPrefix(raw, "@"); ok {
+		op.name, param, _ = strings.Cut(rest, " ")
+		param = strings.TrimSpace(param)
+	}
+
+	build, ok := operators[strings.ToLower(op.name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported operator @%s", op.name)
+	}
+
+	var err error
+	op.fn, err = build(param)
+	if err != nil {
+		return nil, fmt.Errorf("operator @%s: %w", op.name, err)
+	}
+
+	return op, nil
+}
+
+func newRx(param string) (operatorFunc, error) {
+	re, err := regexp.Compile(param)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(_ *transaction, input string) (bool, []string) {
+		captures := re.FindStringSubmatch(input)
+		if captures == nil {
+			return false, nil
+		}
+
+		return true, captures[:min(len(captures), maxCaptures)]
+	}, nil
+}
+
+// expandedOperator returns an operator comparing the input with its parameter, in which the macros are expanded.
+func expandedOperator(fn func(param, input string) bool) func(string) (operatorFunc, error) {
+	return func(param string) (operatorFunc, error) {
+		return func(t *transaction, input string) (bool, []string) {
+			return fn(t.expand(param), input), nil
+		}, nil
+	}
+}
+
+// numericOperator returns an operator comparing the input with its parameter as integers.
+// As for ModSecurity, the values which are not integers are considered to be 0.
+func numericOperator(fn func(param, input int) bool) func(string) (operatorFunc, error) {
+	return func(param string) (operatorFunc, error) {
+		return func(t *transaction, input string) (bool, []string) {
+			p, _ := strconv.Atoi(strings.TrimSpace(t.expand(param)))
+			i, _ := strconv.Atoi(strings.TrimSpace(input))
+			return fn(p, i), nil
+		}, nil
+	}
+}
+
+func constantOperator(result bool) operatorFunc {
+	return func(*transaction, string) (bool, []string) {
+		return result, nil
+	}
+}
+
+// containsWord reports whether the input contains the param, delimited by non-word characters.
+func containsWord(param, input string) bool {
+	if param == "" {
+		return true
+	}
+
+	for offset := 0; offset <= len(input)-len(param); {
+		i := strings.Index(input[offset:], param)
+		if i < 0 {
+			return false
+		}
+
+		start, end := offset+i, offset+i+len(param)
+		if (start == 0 || !isWordChar(input[start-1])) && (end == len(input) || !isWordChar(input[end])) {
+			return true
+		}
+
+		offset = start + 1
+	}
+
+	return false
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// newPm returns an operator matching the inputs containing one of the space-separated phrases, case-insensitively.
+func newPm(param string) (operatorFunc, error) {
+	phrases := strings.Fields(strings.ToLower(param))
+	if len(phrases) == 0 {
+		return nil, errors.New("no phrases defined")
+	}
+
+	return func(_ *transaction, input string) (bool, []string) {
+		input = strings.ToLower(input)
+		for _, phrase := range phrases {
+			if strings.Contains(input, phrase) {
+				return true, []string{phrase}
+			}
+		}
+
+		return false, nil
+	}, nil
+}
+
+// newIPMatch returns an operator matching the inputs which are one of the comma-separated IPs or CIDR ranges.
+func newIPMatch(param string) (operatorFunc, error) {
+	var ips []string
+	for value := range strings.SplitSeq(param, ",") {
+		ips = append(ips, strings.TrimSpace(value))
+	}
+
+	checker, err := ip.NewChecker(ips)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(_ *transaction, input string) (bool, []string) {
+		ok, err := checker.Contains(input)
+		return err == nil && ok, nil
+	}, nil
+}
+
+// newValidateByteRange returns an operator matching the inputs containing a byte out of the allowed ranges,
+// e.g. "9,10,13,32-126".
+func newValidateByteRange(param string) (operatorFunc, error) {
+	var allowed [256]bool
+
+	for item := range strings.SplitSeq(param, ",") {
+		item = strings.TrimSpace(item)
+
+		from, to, isRange := strings.Cut(item, "-")
+		if !isRange {
+			to = from
+		}
+
+		start, err := strconv.ParseUint(strings.TrimSpace(from), 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte range %q", item)
+		}
+
+		end, err := strconv.ParseUint(strings.TrimSpace(to), 10, 8)
+		if err != nil || end < start {
+			return nil, fmt.Errorf("invalid byte range %q", item)
+		}
+
+		for b := start; b <= end; b++ {
+			allowed[b] = true
+		}
+	}
+
+	return func(_ *transaction, input string) (bool, []string) {
+		for i := range len(input) {
+			if !allowed[input[i]] {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}, nil
+}
@@ -0,0 +1,370 @@
+package waf
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// disruptiveAction is the action taken on the request when a rule matches.
+type disruptiveAction int
+
+const (
+	disruptivePass disruptiveAction = iota
+	disruptiveDeny
+	disruptiveBlock
+	disruptiveAllow
+)
+
+// rule is a SecRule or SecAction, or a SecMarker when marker is set.
+type rule struct {
+	id     int
+	phase  int
+	marker string
+
+	variables       []*variable
+	exclusions      []*variable
+	operator        *operator
+	transformations []transformation
+
+	msg      string
+	logData  string
+	severity string
+	tags     []string
+	log      bool
+
+	disruptive disruptiveAction
+	status     int
+	capture    bool
+	setVars    []setVar
+	skipAfter  string
+
+	// chain is whether the rule starts or continues a chain, whose next rule is chained.
+	chain   bool
+	chained *rule
+
+	explicitPhase      bool
+	explicitDisruptive bool
+}
+
+func newRule() *rule {
+	return &rule{phase: phaseRequestBody, log: true}
+}
+
+func (r *rule) applyActions(actions []action) error {
+	for _, a := range actions {
+		switch a.name {
+		case "id":
+			id, err := strconv.Atoi(a.value)
+			if err != nil || id <= 0 {
+				return fmt.Errorf("invalid rule id %q", a.value)
+			}
+			r.id = id
+
+		case "phase":
+			switch strings.ToLower(a.value) {
+			case "1":
+				r.phase = phaseRequestHeaders
+			case "2", "request":
+				r.phase = phaseRequestBody
+			default:
+				return fmt.Errorf("unsupported phase %q, only the request phases 1 and 2 are supported", a.value)
+			}
+			r.explicitPhase = true
+
+		case "msg":
+			r.msg = a.value
+		case "logdata":
+			r.logData = a.value
+		case "severity":
+			r.severity = a.value
+		case "tag":
+			r.tags = append(r.tags, a.value)
+		case "log":
+			r.log = true
+		case "nolog":
+			r.log = false
+		case "ver", "rev", "maturity", "accuracy", "auditlog", "noauditlog":
+			// Only informative.
+
+		case "t":
+			if strings.EqualFold(a.value, "none") {
+				r.transformations = nil
+				continue
+			}
+
+			fn, ok := transformations[strings.ToLower(a.value)]
+			if !ok {
+				return fmt.Errorf("unsupported transformation %q", a.value)
+			}
+			r.transformations = append(r.transformations, transformation{name: a.value, fn: fn})
+
+		case "pass":
+			r.disruptive, r.explicitDisruptive = disruptivePass, true
+		case "deny":
+			r.disruptive, r.explicitDisruptive = disruptiveDeny, true
+		case "block":
+			r.disruptive, r.explicitDisruptive = disruptiveBlock, true
+		case "allow":
+			r.disruptive, r.explicitDisruptive = disruptiveAllow, true
+
+		case "status":
+			status, err := strconv.Atoi(a.value)
+			if err != nil || http.StatusText(status) == "" {
+				return fmt.Errorf("invalid status %q", a.value)
+			}
+			r.status = status
+
+		case "capture":
+			r.capture = true
+		case "chain":
+			r.chain = true
+		case "skipafter":
+			r.skipAfter = a.value
+
+		case "setvar":
+			sv, err := parseSetVar(a.value)
+			if err != nil {
+				return err
+			}
+			r.setVars = append(r.setVars, sv)
+
+		default:
+			return fmt.Errorf("unsupported action %q", a.name)
+		}
+	}
+
+	return nil
+}
+
+// matches reports whether the rule, and all the rules of its chain, match the transaction.
+func (r *rule) matches(t *transaction) bool {
+	for link := r; link != nil; link = link.chained {
+		if !link.matchesOwn(t) {
+			return false
+		}
+	}
+
+	for link := r; link != nil; link = link.chained {
+		for _, sv := range link.setVars {
+			sv.apply(t)
+		}
+	}
+
+	return true
+}
+
+// matchesOwn reports whether the rule, not considering its chain, matches the transaction.
+// A rule without operator, i.e. a SecAction, always matches.
+func (r *rule) matchesOwn(t *transaction) bool {
+	if r.operator == nil {
+		return true
+	}
+
+	for _, v := range r.values(t) {
+		input := v.value
+		for _, tr := range r.transformations {
+			input = tr.fn(input)
+		}
+
+		ok, captures := r.operator.match(t, input)
+		if !ok {
+			continue
+		}
+
+		t.matchedVar, t.matchedVarName = v.value, v.name
+		if r.capture {
+			for i, capture := range captures {
+				t.tx[strconv.Itoa(i)] = capture
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (r *rule) values(t *transaction) []variableValue {
+	var values []variableValue
+	for _, v := range r.variables {
+		values = append(values, v.values(t, r.exclusions)...)
+	}
+
+	return values
+}
+
+// setVar is a setvar action, which sets, increments, decrements or deletes a TX variable.
+type setVar struct {
+	name  string
+	op    byte
+	value string
+}
+
+func parseSetVar(raw string) (setVar, error) {
+	if name, ok := strings.CutPrefix(raw, "!"); ok {
+		key, err := txKey(name)
+		return setVar{name: key, op: '!'}, err
+	}
+
+	name, value, ok := strings.Cut(raw, "=")
+	if !ok {
+		value = "1"
+	}
+
+	key, err := txKey(name)
+	if err != nil {
+		return setVar{}, err
+	}
+
+	sv := setVar{name: key, op: '=', value: value}
+	if len(value) > 0 && (value[0] == '+' || value[0] == '-') {
+		sv.op, sv.value = value[0], value[1:]
+	}
+
+	return sv, nil
+}
+
+func txKey(name string) (string, error) {
+	collection, key, ok := strings.Cut(strings.TrimSpace(name), ".")
+	if !ok || key == "" {
+		return "", fmt.Errorf("invalid setvar variable %q", name)
+	}
+
+	if !strings.EqualFold(collection, "tx") {
+		return "", fmt.Errorf("unsupported setvar collection %q, only tx is supported", collection)
+	}
+
+	return strings.ToLower(key), nil
+}
+
+func (s setVar) apply(t *transaction) {
+	switch s.op {
+	case '!':
+		delete(t.tx, s.name)
+	case '+', '-':
+		current, _ := strconv.Atoi(t.tx[s.name])
+		delta, _ := strconv.Atoi(t.expand(s.value))
+		if s.op == '-' {
+			delta = -delta
+		}
+		t.tx[s.name] = strconv.Itoa(current + delta)
+	default:
+		t.tx[s.name] = t.expand(s.value)
+	}
+}
+
+var macroRegexp = regexp.MustCompile(`%\{([^}]+)\}`)
+
+// expand replaces the macros, e.g. %{tx.anomaly_score} or %{MATCHED_VAR}, with the values of the variables.
+func (t *transaction) expand(s string) string {
+	if !strings.Contains(s, "%{") {
+		return s
+	}
+
+	return macroRegexp.ReplaceAllStringFunc(s, func(macro string) string {
+		name, key, _ := strings.Cut(macro[2:len(macro)-1], ".")
+		return t.lookup(name, key)
+	})
+}
+
+// match holds the information of a rule matching a request.
+type match struct {
+	id       int
+	msg      string
+	logData  string
+	severity string
+	variable string
+}
+
+// interruption is the blocking of a request by a rule.
+type interruption struct {
+	ruleID int
+	status int
+	msg    string
+}
+
+// evaluate evaluates the rules of the given phase against the transaction.
+func (rs *ruleSet) evaluate(t *transaction, phase int) {
+	var skipTo string
+
+	for _, r := range rs.rules {
+		if t.interruption != nil || t.allowed {
+			return
+		}
+
+		if r.marker != "" {
+			if r.marker == skipTo {
+				skipTo = ""
+			}
+			continue
+		}
+
+		if skipTo != "" || r.phase != phase || !r.matches(t) {
+			continue
+		}
+
+		msg := t.expand(r.msg)
+		if r.log {
+			t.matches = append(t.matches, match{
+				id:       r.id,
+				msg:      msg,
+				logData:  t.expand(r.logData),
+				severity: r.severity,
+				variable: t.matchedVarName,
+			})
+		}
+
+		skipTo = r.skipAfter
+
+		// In detection-only mode, the matches are only logged.
+		if rs.engine != engineOn {
+			continue
+		}
+
+		switch r.disruptive {
+		case disruptiveDeny:
+			status := r.status
+			if status == 0 {
+				status = http.StatusForbidden
+			}
+			t.interruption = &interruption{ruleID: r.id, status: status, msg: msg}
+		case disruptiveAllow:
+			t.allowed = true
+		}
+	}
+}
+
+// hasPhase reports whether a rule is evaluated in the given phase.
+func (rs *ruleSet) hasPhase(phase int) bool {
+	for _, r := range rs.rules {
+		if r.marker == "" && r.phase == phase {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkMarkers checks that the markers referenced by the skipAfter actions are defined after the rules referencing them.
+func (rs *ruleSet) checkMarkers() error {
+	pending := make(map[string]int)
+	for _, r := range rs.rules {
+		if r.marker != "" {
+			delete(pending, r.marker)
+			continue
+		}
+
+		if r.skipAfter != "" {
+			pending[r.skipAfter] = r.id
+		}
+	}
+
+	for marker, id := range pending {
+		return fmt.Errorf("rule %d skips after the marker %q, which is not defined after it", id, marker)
+	}
+
+	return nil
+}
@@ -0,0 +1,432 @@
+package waf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule engine modes, set with the SecRuleEngine directive.
+const (
+	engineOn            = "On"
+	engineOff           = "Off"
+	engineDetectionOnly = "DetectionOnly"
+)
+
+// Request body limit actions, set with the SecRequestBodyLimitAction directive.
+const (
+	bodyLimitReject         = "Reject"
+	bodyLimitProcessPartial = "ProcessPartial"
+)
+
+const defaultBodyLimit = 128 * 1024
+
+// Phases of the requests processing, only the request ones are supported.
+const (
+	phaseRequestHeaders = 1
+	phaseRequestBody    = 2
+)
+
+// ruleSet holds the rules and the engine settings parsed from the SecLang directives.
+type ruleSet struct {
+	engine          string
+	bodyAccess      bool
+	bodyLimit       int64
+	bodyLimitAction string
+	rules           []*rule
+}
+
+// parser holds the state of the SecLang directives parsing.
+type parser struct {
+	ruleSet *ruleSet
+
+	// defaultActions are the actions set with SecDefaultAction, by phase.
+	defaultActions map[int][]action
+	ids            map[int]struct{}
+	// chain is the last rule of the chain being parsed, if any.
+	chain *rule
+}
+
+// parseDirectives parses the given SecLang directives.
+// A directive can be continued on the next line by ending it with a backslash,
+// and lines starting with a # are comments.
+func parseDirectives(directives []string) (*ruleSet, error) {
+	p := &parser{
+		ruleSet: &ruleSet{
+			engine:          engineOn,
+			bodyAccess:      true,
+			bodyLimit:       defaultBodyLimit,
+			bodyLimitAction: bodyLimitReject,
+		},
+		defaultActions: make(map[int][]action),
+		ids:            make(map[int]struct{}),
+	}
+
+	for _, directive := range splitDirectives(directives) {
+		if err := p.parseDirective(directive); err != nil {
+			return nil, fmt.Errorf("parsing directive %q: %w", directive, err)
+		}
+	}
+
+	if p.chain != nil {
+		return nil, errors.New("the last rule starts a chain which is never completed")
+	}
+
+	if err := p.ruleSet.checkMarkers(); err != nil {
+		return nil, err
+	}
+
+	return p.ruleSet, nil
+}
+
+// splitDirectives returns the directives, one per logical line, without the comments and empty lines.
+func splitDirectives(directives []string) []string {
+	var lines []string
+	var current strings.Builder
+	for _, line := range strings.Split(strings.Join(directives, "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if current.Len() == 0 && (line == "" || strings.HasPrefix(line, "#")) {
+			continue
+		}
+
+		if continued, ok := strings.CutSuffix(line, `\`); ok {
+			current.WriteString(continued)
+			current.WriteString(" ")
+			continue
+		}
+
+		current.WriteString(line)
+		lines = append(lines, current.String())
+		current.Reset()
+	}
+
+	if current.Len() > 0 {
+		lines = append(lines, strings.TrimSpace(current.String()))
+	}
+
+	return lines
+}
+
+func (p *parser) parseDirective(directive string) error {
+	args, err := splitArguments(directive)
+	if err != nil {
+		return err
+	}
+
+	name, args := args[0], args[1:]
+
+	if p.chain != nil && !strings.EqualFold(name, "SecRule") {
+		return errors.New("a chained rule must be followed by a SecRule directive")
+	}
+
+	switch strings.ToLower(name) {
+	case "secruleengine":
+		if len(args) != 1 {
+			return errors.New("expected one argument")
+		}
+		switch {
+		case strings.EqualFold(args[0], engineOn):
+			p.ruleSet.engine = engineOn
+		case strings.EqualFold(args[0], engineOff):
+			p.ruleSet.engine = engineOff
+		case strings.EqualFold(args[0], engineDetectionOnly):
+			p.ruleSet.engine = engineDetectionOnly
+		default:
+			return fmt.Errorf("unknown rule engine mode %q", args[0])
+		}
+
+	case "secrequestbodyaccess":
+		if len(args) != 1 {
+			return errors.New("expected one argument")
+		}
+		switch {
+		case strings.EqualFold(args[0], "On"):
+			p.ruleSet.bodyAccess = true
+		case strings.EqualFold(args[0], "Off"):
+			p.ruleSet.bodyAccess = false
+		default:
+			return fmt.Errorf("unexpected value %q, expected On or Off", args[0])
+		}
+
+	case "secrequestbodylimit":
+		if len(args) != 1 {
+			return errors.New("expected one argument")
+		}
+		limit, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("invalid request body limit %q", args[0])
+		}
+		p.ruleSet.bodyLimit = limit
+
+	case "secrequestbodylimitaction":
+		if len(args) != 1 {
+			return errors.New("expected one argument")
+		}
+		switch {
+		case strings.EqualFold(args[0], bodyLimitReject):
+			p.ruleSet.bodyLimitAction = bodyLimitReject
+		case strings.EqualFold(args[0], bodyLimitProcessPartial):
+			p.ruleSet.bodyLimitAction = bodyLimitProcessPartial
+		default:
+			return fmt.Errorf("unknown request body limit action %q", args[0])
+		}
+
+	case "secdefaultaction":
+		if len(args) != 1 {
+			return errors.New("expected one argument")
+		}
+		return p.parseDefaultAction(args[0])
+
+	case "secrule":
+		if len(args) != 2 && len(args) != 3 {
+			return errors.New("expected variables, operator and optional actions arguments")
+		}
+		var actions string
+		if len(args) == 3 {
+			actions = args[2]
+		}
+		return p.parseRule(args[0], args[1], actions)
+
+	case "secaction":
+		if len(args) != 1 {
+			return errors.New("expected one argument")
+		}
+		return p.parseRule("", "", args[0])
+
+	case "secmarker":
+		if len(args) != 1 {
+			return errors.New("expected one argument")
+		}
+		p.ruleSet.rules = append(p.ruleSet.rules, &rule{marker: args[0]})
+
+	case "seccomponentsignature":
+		// Only informative.
+
+	default:
+		return fmt.Errorf("unsupported directive %q", name)
+	}
+
+	return nil
+}
+
+func (p *parser) parseDefaultAction(raw string) error {
+	actions, err := parseActions(raw)
+	if err != nil {
+		return err
+	}
+
+	r := newRule()
+	if err := r.applyActions(actions); err != nil {
+		return err
+	}
+
+	if !r.explicitPhase {
+		return errors.New("the phase must be defined")
+	}
+
+	if !r.explicitDisruptive {
+		return errors.New("a disruptive action must be defined")
+	}
+
+	if r.disruptive == disruptiveBlock {
+		return errors.New("the block action cannot be a default action")
+	}
+
+	if r.id != 0 || r.chain || r.skipAfter != "" || len(r.setVars) > 0 {
+		return errors.New("only phase, disruptive, logging, status and transformation actions are allowed")
+	}
+
+	p.defaultActions[r.phase] = actions
+
+	return nil
+}
+
+func (p *parser) parseRule(rawVariables, rawOperator, rawActions string) error {
+	actions, err := parseActions(rawActions)
+	if err != nil {
+		return err
+	}
+
+	r := newRule()
+
+	// The phase is needed to select the default actions to apply before the rule ones.
+	if err := r.applyActions(actions); err != nil {
+		return err
+	}
+	phase := r.phase
+	if p.chain != nil {
+		phase = p.chain.phase
+	}
+
+	r = newRule()
+	r.phase = phase
+	if err := r.applyActions(p.defaultActions[phase]); err != nil {
+		return err
+	}
+
+	defaultDisruptive, defaultStatus := r.disruptive, r.status
+	r.explicitPhase, r.explicitDisruptive = false, false
+
+	if err := r.applyActions(actions); err != nil {
+		return err
+	}
+
+	if r.disruptive == disruptiveBlock {
+		r.disruptive, r.status = defaultDisruptive, defaultStatus
+	}
+
+	if rawVariables != "" {
+		r.variables, r.exclusions, err = parseVariables(rawVariables)
+		if err != nil {
+			return fmt.Errorf("parsing variables: %w", err)
+		}
+
+		r.operator, err = parseOperator(rawOperator)
+		if err != nil {
+			return fmt.Errorf("parsing operator: %w", err)
+		}
+	}
+
+	if p.chain != nil {
+		if r.id != 0 || r.explicitPhase || r.explicitDisruptive || r.skipAfter != "" {
+			return errors.New("a chained rule cannot define id, phase, disruptive or skipAfter actions")
+		}
+
+		p.chain.chained = r
+		p.chain = nil
+		if r.chain {
+			p.chain = r
+		}
+
+		return nil
+	}
+
+	if r.id == 0 {
+		return errors.New("the rule id must be defined")
+	}
+
+	if _, ok := p.ids[r.id]; ok {
+		return fmt.Errorf("duplicated rule id %d", r.id)
+	}
+	p.ids[r.id] = struct{}{}
+
+	p.ruleSet.rules = append(p.ruleSet.rules, r)
+	if r.chain {
+		p.chain = r
+	}
+
+	return nil
+}
+
+// splitArguments splits a directive into its whitespace-separated arguments.
+// Arguments can be enclosed in double quotes, in which \" is an escaped double quote.
+func splitArguments(directive string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var inArg, quoted bool
+
+	for i := 0; i < len(directive); i++ {
+		c := directive[i]
+
+		switch {
+		case quoted && c == '\\' && i+1 < len(directive) && directive[i+1] == '"':
+			current.WriteByte('"')
+			i++
+		case quoted && c == '"':
+			quoted = false
+			args = append(args, current.String())
+			current.Reset()
+			inArg = false
+		case quoted:
+			current.WriteByte(c)
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '"' && !inArg:
+			quoted = true
+			inArg = true
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if quoted {
+		return nil, errors.New("unterminated quoted argument")
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	if len(args) == 0 {
+		return nil, errors.New("empty directive")
+	}
+
+	return args, nil
+}
+
+// action is a rule action, e.g. "id:1" or "deny".
+type action struct {
+	name  string
+	value string
+}
+
+// parseActions parses a comma-separated list of actions.
+// Action values can be enclosed in single quotes, in which \' is an escaped single quote.
+func parseActions(raw string) ([]action, error) {
+	var actions []action
+	var current strings.Builder
+	var quoted bool
+
+	flush := func() error {
+		item := strings.TrimSpace(current.String())
+		current.Reset()
+		if item == "" {
+			return nil
+		}
+
+		name, value, _ := strings.Cut(item, ":")
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = strings.ReplaceAll(value[1:len(value)-1], `\'`, `'`)
+		}
+
+		actions = append(actions, action{name: strings.ToLower(strings.TrimSpace(name)), value: value})
+
+		return nil
+	}
+
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+
+		switch {
+		case quoted && c == '\\' && i+1 < len(raw) && raw[i+1] == '\'':
+			current.WriteString(`\'`)
+			i++
+		case c == '\'':
+			quoted = !quoted
+			current.WriteByte(c)
+		case c == ',' && !quoted:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	if quoted {
+		return nil, errors.New("unterminated quoted action value")
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
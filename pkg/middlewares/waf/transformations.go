@@ -0,0 +1,212 @@
+package waf
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"html"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// transformation is a t: action, applied to the variable values before the operator.
+type transformation struct {
+	name string
+	fn   func(string) string
+}
+
+// transformations are the supported transformations, by lower-case name.
+var transformations = map[string]func(string) string{
+	"lowercase":          strings.ToLower,
+	"uppercase":          strings.ToUpper,
+	"trim":               strings.TrimSpace,
+	"trimleft":           func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) },
+	"trimright":          func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) },
+	"urldecode":          func(s string) string { return urlDecode(s, false) },
+	"urldecodeuni":       urlDecodeUni,
+	"htmlentitydecode":   html.UnescapeString,
+	"base64decode":       base64Decode,
+	"hexdecode":          hexDecode,
+	"compresswhitespace": compressWhitespace,
+	"removewhitespace":   func(s string) string { return strings.Join(strings.Fields(s), "") },
+	"removenulls":        func(s string) string { return strings.ReplaceAll(s, "\x00", "") },
+	"replacenulls":       func(s string) string { return strings.ReplaceAll(s, "\x00", " ") },
+	"normalizepath":      normalizePath,
+	"normalisepath":      normalizePath,
+	"normalizepathwin":   func(s string) string { return normalizePath(strings.ReplaceAll(s, `\`, "/")) },
+	"normalisepathwin":   func(s string) string { return normalizePath(strings.ReplaceAll(s, `\`, "/")) },
+	"cmdline":            cmdLine,
+	"length":             func(s string) string { return strconv.Itoa(len(s)) },
+}
+
+// urlDecode decodes the %XX sequences, and the + characters when plus is true.
+// Unlike url.QueryUnescape, invalid sequences are kept as is.
+func urlDecode(s string, plus bool) string {
+	if !strings.ContainsAny(s, "%+") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+		case s[i] == '+' && plus:
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+// urlDecodeUni decodes the %XX and %uXXXX sequences, and the + characters.
+func urlDecodeUni(s string) string {
+	if !strings.Contains(s, "%u") && !strings.Contains(s, "%U") {
+		return urlDecode(s, true)
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+5 < len(s) && (s[i+1] == 'u' || s[i+1] == 'U') {
+			if code, err := strconv.ParseUint(s[i+2:i+6], 16, 16); err == nil {
+				b.WriteRune(rune(code))
+				i += 5
+				continue
+			}
+		}
+
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+			continue
+		}
+
+		if s[i] == '+' {
+			b.WriteByte(' ')
+			continue
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// base64Decode decodes the padded or unpadded base64 value, which is kept as is when invalid.
+func base64Decode(s string) string {
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+		if err != nil {
+			return s
+		}
+	}
+
+	return string(decoded)
+}
+
+// hexDecode decodes the hexadecimal value, which is kept as is when invalid.
+func hexDecode(s string) string {
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return s
+	}
+
+	return string(decoded)
+}
+
+// compressWhitespace replaces the sequences of whitespace characters with a single space.
+func compressWhitespace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	var space bool
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+
+	if space && b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+
+	return b.String()
+}
+
+// normalizePath removes the multiple slashes and the self and parent directory references, keeping the trailing slash.
+func normalizePath(s string) string {
+	if s == "" {
+		return s
+	}
+
+	cleaned := path.Clean(s)
+	if strings.HasSuffix(s, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+
+	return cleaned
+}
+
+// cmdLine normalizes a command line the way ModSecurity does, to defeat the command evasion techniques:
+// it deletes the \ " ' and ^ characters, the spaces before / and (, replaces the , and ; with spaces,
+// compresses the whitespaces and lower-cases the result.
+func cmdLine(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	var space bool
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == '\\' || r == '"' || r == '\'' || r == '^':
+			continue
+		case r == ',' || r == ';' || unicode.IsSpace(r):
+			space = true
+			continue
+		case r == '/' || r == '(':
+			space = false
+		}
+
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+
+	if space {
+		b.WriteByte(' ')
+	}
+
+	return b.String()
+}
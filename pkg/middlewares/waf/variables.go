@@ -0,0 +1,330 @@
+package waf
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// keyValue is an entry of a collection, e.g. a request header.
+type keyValue struct {
+	key   string
+	value string
+}
+
+// variableValue is a value of a rule variable, with its full name, e.g. ARGS:id.
+type variableValue struct {
+	name  string
+	value string
+}
+
+// transaction holds the data of a request evaluated by the rules.
+type transaction struct {
+	req        *http.Request
+	requestURI string
+
+	argsGet  []keyValue
+	argsPost []keyValue
+	headers  []keyValue
+	cookies  []keyValue
+	files    []keyValue
+
+	body          []byte
+	bodyProcessed bool
+	bodyError     string
+
+	tx                         map[string]string
+	matchedVar, matchedVarName string
+
+	matches      []match
+	interruption *interruption
+	allowed      bool
+}
+
+func newTransaction(req *http.Request) *transaction {
+	t := &transaction{
+		req:        req,
+		requestURI: req.RequestURI,
+		tx:         make(map[string]string),
+	}
+
+	if t.requestURI == "" {
+		t.requestURI = req.URL.RequestURI()
+	}
+
+	t.argsGet = parseArgs(req.URL.RawQuery)
+
+	t.headers = append(t.headers, keyValue{key: "Host", value: req.Host})
+	for _, name := range slices.Sorted(maps.Keys(req.Header)) {
+		for _, value := range req.Header[name] {
+			t.headers = append(t.headers, keyValue{key: name, value: value})
+		}
+	}
+
+	for _, cookie := range req.Cookies() {
+		t.cookies = append(t.cookies, keyValue{key: cookie.Name, value: cookie.Value})
+	}
+
+	return t
+}
+
+// parseArgs parses URL-encoded arguments, keeping their order and the malformed ones.
+func parseArgs(raw string) []keyValue {
+	var args []keyValue
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(pair, "=")
+		args = append(args, keyValue{key: urlDecode(key, true), value: urlDecode(value, true)})
+	}
+
+	return args
+}
+
+// collections are the variables holding several values, selectable by key, e.g. ARGS:id.
+var collections = map[string]func(t *transaction) []keyValue{
+	"ARGS":                  func(t *transaction) []keyValue { return slices.Concat(t.argsGet, t.argsPost) },
+	"ARGS_GET":              func(t *transaction) []keyValue { return t.argsGet },
+	"ARGS_POST":             func(t *transaction) []keyValue { return t.argsPost },
+	"ARGS_NAMES":            func(t *transaction) []keyValue { return names(slices.Concat(t.argsGet, t.argsPost)) },
+	"ARGS_GET_NAMES":        func(t *transaction) []keyValue { return names(t.argsGet) },
+	"ARGS_POST_NAMES":       func(t *transaction) []keyValue { return names(t.argsPost) },
+	"REQUEST_HEADERS":       func(t *transaction) []keyValue { return t.headers },
+	"REQUEST_HEADERS_NAMES": func(t *transaction) []keyValue { return names(t.headers) },
+	"REQUEST_COOKIES":       func(t *transaction) []keyValue { return t.cookies },
+	"REQUEST_COOKIES_NAMES": func(t *transaction) []keyValue { return names(t.cookies) },
+	"FILES":                 func(t *transaction) []keyValue { return t.files },
+	"FILES_NAMES":           func(t *transaction) []keyValue { return names(t.files) },
+	"TX": func(t *transaction) []keyValue {
+		var values []keyValue
+		for _, key := range slices.Sorted(maps.Keys(t.tx)) {
+			values = append(values, keyValue{key: key, value: t.tx[key]})
+		}
+		return values
+	},
+}
+
+func names(values []keyValue) []keyValue {
+	result := make([]keyValue, 0, len(values))
+	for _, v := range values {
+		result = append(result, keyValue{key: v.key, value: v.key})
+	}
+
+	return result
+}
+
+// scalars are the variables holding a single value.
+// The returned boolean is false when the variable is not available.
+var scalars = map[string]func(t *transaction) (string, bool){
+	"REQUEST_URI":     func(t *transaction) (string, bool) { return t.requestURI, true },
+	"REQUEST_URI_RAW": func(t *transaction) (string, bool) { return t.requestURI, true },
+	"REQUEST_FILENAME": func(t *transaction) (string, bool) {
+		filename, _, _ := strings.Cut(t.requestURI, "?")
+		return filename, true
+	},
+	"REQUEST_BASENAME": func(t *transaction) (string, bool) {
+		filename, _, _ := strings.Cut(t.requestURI, "?")
+		return filename[strings.LastIndex(filename, "/")+1:], true
+	},
+	"REQUEST_LINE": func(t *transaction) (string, bool) {
+		return t.req.Method + " " + t.requestURI + " " + t.req.Proto, true
+	},
+	"REQUEST_METHOD":   func(t *transaction) (string, bool) { return t.req.Method, true },
+	"REQUEST_PROTOCOL": func(t *transaction) (string, bool) { return t.req.Proto, true },
+	"QUERY_STRING":     func(t *transaction) (string, bool) { return t.req.URL.RawQuery, true },
+	"SERVER_NAME": func(t *transaction) (string, bool) {
+		host, _, err := net.SplitHostPort(t.req.Host)
+		if err != nil {
+			return t.req.Host, true
+		}
+		return host, true
+	},
+	"REMOTE_ADDR": func(t *transaction) (string, bool) {
+		host, _, err := net.SplitHostPort(t.req.RemoteAddr)
+		if err != nil {
+			return t.req.RemoteAddr, true
+		}
+		return host, true
+	},
+	"REMOTE_PORT": func(t *transaction) (string, bool) {
+		_, port, err := net.SplitHostPort(t.req.RemoteAddr)
+		return port, err == nil
+	},
+	"REQUEST_BODY":        func(t *transaction) (string, bool) { return string(t.body), t.bodyProcessed },
+	"REQUEST_BODY_LENGTH": func(t *transaction) (string, bool) { return strconv.Itoa(len(t.body)), t.bodyProcessed },
+	"REQBODY_ERROR": func(t *transaction) (string, bool) {
+		if t.bodyError != "" {
+			return "1", t.bodyProcessed
+		}
+		return "0", t.bodyProcessed
+	},
+	"REQBODY_ERROR_MSG": func(t *transaction) (string, bool) { return t.bodyError, t.bodyProcessed },
+	"MATCHED_VAR":       func(t *transaction) (string, bool) { return t.matchedVar, t.matchedVarName != "" },
+	"MATCHED_VAR_NAME":  func(t *transaction) (string, bool) { return t.matchedVarName, t.matchedVarName != "" },
+}
+
+// lookup returns the value of a variable, or of the given key of a collection, used in macros.
+func (t *transaction) lookup(name, key string) string {
+	name = strings.ToUpper(name)
+
+	if scalar, ok := scalars[name]; ok {
+		value, _ := scalar(t)
+		return value
+	}
+
+	if collection, ok := collections[name]; ok {
+		for _, kv := range collection(t) {
+			if strings.EqualFold(kv.key, key) {
+				return kv.value
+			}
+		}
+	}
+
+	return ""
+}
+
+// variable is a variable of a rule, e.g. ARGS, ARGS:id, ARGS:/^id_/ or &ARGS.
+type variable struct {
+	name      string
+	key       string
+	keyRegexp *regexp.Regexp
+	count     bool
+}
+
+// parseVariables parses the pipe-separated variables of a rule,
+// and returns the variables and the exclusions, i.e. the ones prefixed with a !.
+func parseVariables(raw string) ([]*variable, []*variable, error) {
+	var variables, exclusions []*variable
+
+	for _, item := range splitVariables(raw) {
+		item = strings.TrimSpace(item)
+
+		exclusion := strings.HasPrefix(item, "!")
+		item = strings.TrimPrefix(item, "!")
+
+		count := strings.HasPrefix(item, "&")
+		item = strings.TrimPrefix(item, "&")
+
+		name, selector, hasSelector := strings.Cut(item, ":")
+		v := &variable{name: strings.ToUpper(name), count: count}
+
+		_, isCollection := collections[v.name]
+		if _, isScalar := scalars[v.name]; !isScalar && !isCollection {
+			return nil, nil, fmt.Errorf("unsupported variable %q", name)
+		}
+
+		if hasSelector {
+			if !isCollection {
+				return nil, nil, fmt.Errorf("variable %q is not a collection", name)
+			}
+
+			switch {
+			case len(selector) >= 2 && selector[0] == '/' && selector[len(selector)-1] == '/':
+				re, err := regexp.Compile(selector[1 : len(selector)-1])
+				if err != nil {
+					return nil, nil, fmt.Errorf("compiling selector of variable %q: %w", name, err)
+				}
+				v.keyRegexp = re
+			case len(selector) >= 2 && selector[0] == '\'' && selector[len(selector)-1] == '\'':
+				v.key = selector[1 : len(selector)-1]
+			default:
+				v.key = selector
+			}
+		}
+
+		if exclusion {
+			if count || !hasSelector {
+				return nil, nil, fmt.Errorf("invalid exclusion %q, a collection key is expected", item)
+			}
+			exclusions = append(exclusions, v)
+			continue
+		}
+
+		variables = append(variables, v)
+	}
+
+	if len(variables) == 0 {
+		return nil, nil, errors.New("no variables defined")
+	}
+
+	return variables, exclusions, nil
+}
+
+// splitVariables splits the variables on the pipes which are not in a quoted or regular expression selector.
+func splitVariables(raw string) []string {
+	var items []string
+	var quote byte
+	start := 0
+
+	for i := range len(raw) {
+		c := raw[i]
+		switch {
+		case quote != 0:
+			if c == quote && raw[i-1] != '\\' {
+				quote = 0
+			}
+		case (c == '/' || c == '\'') && i > 0 && raw[i-1] == ':':
+			quote = c
+		case c == '|':
+			items = append(items, raw[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(items, raw[start:])
+}
+
+func (v *variable) values(t *transaction, exclusions []*variable) []variableValue {
+	if scalar, ok := scalars[v.name]; ok {
+		value, ok := scalar(t)
+		switch {
+		case v.count && ok:
+			return []variableValue{{name: "&" + v.name, value: "1"}}
+		case v.count:
+			return []variableValue{{name: "&" + v.name, value: "0"}}
+		case ok:
+			return []variableValue{{name: v.name, value: value}}
+		default:
+			return nil
+		}
+	}
+
+	var values []variableValue
+	for _, kv := range collections[v.name](t) {
+		if !v.selects(kv.key) || slices.ContainsFunc(exclusions, func(e *variable) bool { return e.name == v.name && e.selects(kv.key) }) {
+			continue
+		}
+
+		values = append(values, variableValue{name: v.name + ":" + kv.key, value: kv.value})
+	}
+
+	if v.count {
+		name := "&" + v.name
+		if v.key != "" {
+			name += ":" + v.key
+		}
+		return []variableValue{{name: name, value: strconv.Itoa(len(values))}}
+	}
+
+	return values
+}
+
+// selects reports whether the collection key is selected by the variable.
+func (v *variable) selects(key string) bool {
+	switch {
+	case v.keyRegexp != nil:
+		return v.keyRegexp.MatchString(key)
+	case v.key != "":
+		return strings.EqualFold(v.key, key)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,202 @@
+// Package waf implements a web application firewall middleware,
+// evaluating rules written in a subset of the ModSecurity rule language (SecLang) against the requests.
+package waf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v3/pkg/middlewares/observability"
+)
+
+const typeName = "WAF"
+
+// Values of the WAFAction access log field.
+const (
+	actionBlocked  = "blocked"
+	actionDetected = "detected"
+)
+
+type waf struct {
+	next  http.Handler
+	name  string
+	rules *ruleSet
+}
+
+// New creates a WAF middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.WAF, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if len(config.Directives) == 0 {
+		return nil, errors.New("directives must be defined")
+	}
+
+	rules, err := parseDirectives(config.Directives)
+	if err != nil {
+		return nil, err
+	}
+
+	if rules.engine == engineOff {
+		logger.Debug().Msg("Rule engine is off, requests are not evaluated")
+		return next, nil
+	}
+
+	return &waf{
+		next:  next,
+		name:  name,
+		rules: rules,
+	}, nil
+}
+
+func (w *waf) GetTracingInformation() (string, string) {
+	return w.name, typeName
+}
+
+func (w *waf) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), w.name, typeName)
+
+	t := newTransaction(req)
+
+	w.rules.evaluate(t, phaseRequestHeaders)
+
+	if t.interruption == nil && !t.allowed && w.rules.bodyAccess && w.rules.hasPhase(phaseRequestBody) {
+		if err := w.readBody(t); err != nil {
+			var limitErr *bodyLimitError
+			if !errors.As(err, &limitErr) {
+				logger.Debug().Err(err).Msg("Error while reading request body")
+				observability.SetStatusErrorf(req.Context(), "Error while reading request body")
+
+				http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+
+			logger.Debug().Err(err).Msg("Request body rejected")
+			observability.SetStatusErrorf(req.Context(), "Request body rejected")
+
+			setLogData(req, nil, &interruption{status: http.StatusRequestEntityTooLarge, msg: err.Error()})
+
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+	}
+
+	if t.interruption == nil && !t.allowed {
+		w.rules.evaluate(t, phaseRequestBody)
+	}
+
+	for _, m := range t.matches {
+		logger.Debug().
+			Int("ruleId", m.id).
+			Str("msg", m.msg).
+			Str("data", m.logData).
+			Str("severity", m.severity).
+			Str("variable", m.variable).
+			Msg("Rule matched")
+	}
+
+	setLogData(req, t.matches, t.interruption)
+
+	if t.interruption != nil {
+		observability.SetStatusErrorf(req.Context(), "Request blocked by rule %d", t.interruption.ruleID)
+
+		http.Error(rw, http.StatusText(t.interruption.status), t.interruption.status)
+		return
+	}
+
+	w.next.ServeHTTP(rw, req)
+}
+
+// bodyLimitError is returned when the request body is larger than the limit and the limit action is Reject.
+type bodyLimitError struct {
+	limit int64
+}
+
+func (e *bodyLimitError) Error() string {
+	return fmt.Sprintf("request body larger than %d bytes", e.limit)
+}
+
+// readBody reads the request body, up to the body limit, and parses its arguments.
+// The request body is then restored to be forwarded as is.
+func (w *waf) readBody(t *transaction) error {
+	req := t.req
+	if req.Body == nil || req.Body == http.NoBody {
+		t.bodyProcessed = true
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, w.rules.bodyLimit+1))
+	if err != nil {
+		return err
+	}
+
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: io.MultiReader(bytes.NewReader(body), req.Body),
+		Closer: req.Body,
+	}
+
+	if int64(len(body)) > w.rules.bodyLimit {
+		// In detection-only mode, the requests are never rejected.
+		if w.rules.bodyLimitAction == bodyLimitReject && w.rules.engine == engineOn {
+			return &bodyLimitError{limit: w.rules.bodyLimit}
+		}
+
+		body = body[:w.rules.bodyLimit]
+	}
+
+	t.body = body
+	t.bodyProcessed = true
+
+	if err := parseBody(t); err != nil {
+		t.bodyError = err.Error()
+	}
+
+	return nil
+}
+
+// setLogData adds the matched rules and the blocking of the request, if any, to the access log data.
+// The data of several WAF middlewares handling the same request are merged.
+func setLogData(req *http.Request, matches []match, interrupt *interruption) {
+	if len(matches) == 0 && interrupt == nil {
+		return
+	}
+
+	logData := accesslog.GetLogData(req)
+	if logData == nil {
+		return
+	}
+
+	var ids []string
+	if previous, ok := logData.Core[accesslog.WAFMatchedRules].(string); ok && previous != "" {
+		ids = append(ids, previous)
+	}
+	for _, m := range matches {
+		ids = append(ids, strconv.Itoa(m.id))
+	}
+	if len(ids) > 0 {
+		logData.Core[accesslog.WAFMatchedRules] = strings.Join(ids, ",")
+	}
+
+	if interrupt != nil {
+		logData.Core[accesslog.WAFAction] = actionBlocked
+		logData.Core[accesslog.WAFMessage] = interrupt.msg
+		return
+	}
+
+	if _, ok := logData.Core[accesslog.WAFAction]; !ok {
+		logData.Core[accesslog.WAFAction] = actionDetected
+	}
+	logData.Core[accesslog.WAFMessage] = matches[len(matches)-1].msg
+}
@@ -0,0 +1,348 @@
+package waf
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		directives []string
+		expectErr  bool
+	}{
+		{
+			desc:      "no directives",
+			expectErr: true,
+		},
+		{
+			desc:       "valid rule",
+			directives: []string{`SecRule ARGS "@contains foo" "id:1,phase:1,deny"`},
+		},
+		{
+			desc:       "unsupported directive",
+			directives: []string{`SecAuditEngine On`},
+			expectErr:  true,
+		},
+		{
+			desc:       "invalid regular expression",
+			directives: []string{`SecRule ARGS "@rx (?=foo)" "id:1,deny"`},
+			expectErr:  true,
+		},
+		{
+			desc:       "rule without id",
+			directives: []string{`SecRule ARGS "@contains foo" "phase:1,deny"`},
+			expectErr:  true,
+		},
+		{
+			desc: "duplicated rule id",
+			directives: []string{
+				`SecRule ARGS "@contains foo" "id:1,deny"`,
+				`SecRule ARGS "@contains bar" "id:1,deny"`,
+			},
+			expectErr: true,
+		},
+		{
+			desc:       "unsupported response phase",
+			directives: []string{`SecRule ARGS "@contains foo" "id:1,phase:3,deny"`},
+			expectErr:  true,
+		},
+		{
+			desc:       "unsupported action",
+			directives: []string{`SecRule ARGS "@contains foo" "id:1,deny,ctl:ruleEngine=Off"`},
+			expectErr:  true,
+		},
+		{
+			desc:       "incomplete chain",
+			directives: []string{`SecRule ARGS "@contains foo" "id:1,deny,chain"`},
+			expectErr:  true,
+		},
+		{
+			desc:       "unknown marker",
+			directives: []string{`SecRule ARGS "@contains foo" "id:1,pass,skipAfter:END"`},
+			expectErr:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+			_, err := New(t.Context(), next, dynamic.WAF{Directives: test.directives}, "waf")
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWAF(t *testing.T) {
+	anomalyScoring := []string{
+		`SecDefaultAction "phase:1,log,pass"`,
+		`SecDefaultAction "phase:2,log,deny,status:403"`,
+		`SecAction "id:900000,phase:1,nolog,pass,setvar:tx.anomaly_score=0,setvar:tx.critical_score=5"`,
+		`SecRule ARGS|REQUEST_HEADERS:User-Agent "@pm sqlmap nikto" "id:913100,phase:1,capture,msg:'Scanner detected: %{TX.0}',setvar:tx.anomaly_score=+%{tx.critical_score}"`,
+		`SecRule ARGS "@rx (?i)union\s+select" "id:942100,phase:2,pass,t:urlDecodeUni,t:compressWhitespace,msg:'SQL injection',setvar:tx.anomaly_score=+%{tx.critical_score}"`,
+		`SecRule TX:ANOMALY_SCORE "@ge 5" "id:949110,phase:2,block,msg:'Inbound anomaly score exceeded (score: %{tx.anomaly_score})'"`,
+	}
+
+	testCases := []struct {
+		desc               string
+		directives         []string
+		method             string
+		target             string
+		headers            map[string]string
+		body               string
+		expectedStatus     int
+		expectedBody       string
+		expectedLogData    map[string]any
+		expectedNotLogData []string
+	}{
+		{
+			desc:               "no match",
+			directives:         anomalyScoring,
+			target:             "/search?q=traefik",
+			expectedStatus:     http.StatusOK,
+			expectedNotLogData: []string{accesslog.WAFAction, accesslog.WAFMatchedRules},
+		},
+		{
+			desc:           "blocked on query argument",
+			directives:     anomalyScoring,
+			target:         "/search?q=1%20UNION%20%20SELECT%20password",
+			expectedStatus: http.StatusForbidden,
+			expectedLogData: map[string]any{
+				accesslog.WAFAction:       "blocked",
+				accesslog.WAFMatchedRules: "942100,949110",
+				accesslog.WAFMessage:      "Inbound anomaly score exceeded (score: 5)",
+			},
+		},
+		{
+			desc:           "blocked on URL-encoded body",
+			directives:     anomalyScoring,
+			method:         http.MethodPost,
+			target:         "/login",
+			headers:        map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:           "user=admin&password=1+union+select+1",
+			expectedStatus: http.StatusForbidden,
+			expectedLogData: map[string]any{
+				accesslog.WAFAction:       "blocked",
+				accesslog.WAFMatchedRules: "942100,949110",
+			},
+		},
+		{
+			desc:           "blocked on JSON body",
+			directives:     anomalyScoring,
+			method:         http.MethodPost,
+			target:         "/api",
+			headers:        map[string]string{"Content-Type": "application/json"},
+			body:           `{"filter":{"name":"x' UNION SELECT 1"}}`,
+			expectedStatus: http.StatusForbidden,
+			expectedLogData: map[string]any{
+				accesslog.WAFMatchedRules: "942100,949110",
+			},
+		},
+		{
+			desc:           "blocked on header, with capture and macro",
+			directives:     anomalyScoring,
+			target:         "/",
+			headers:        map[string]string{"User-Agent": "sqlmap/1.7"},
+			expectedStatus: http.StatusForbidden,
+			expectedLogData: map[string]any{
+				accesslog.WAFMatchedRules: "913100,949110",
+			},
+		},
+		{
+			desc:           "detection only",
+			directives:     append([]string{"SecRuleEngine DetectionOnly"}, anomalyScoring...),
+			target:         "/search?q=1%20UNION%20SELECT%20password",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "1 UNION SELECT password",
+			expectedLogData: map[string]any{
+				accesslog.WAFAction:       "detected",
+				accesslog.WAFMatchedRules: "942100,949110",
+				accesslog.WAFMessage:      "Inbound anomaly score exceeded (score: 5)",
+			},
+		},
+		{
+			desc:           "engine off",
+			directives:     append([]string{"SecRuleEngine Off"}, anomalyScoring...),
+			target:         "/search?q=1%20UNION%20SELECT%20password",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "deny with status in phase 1",
+			directives: []string{
+				`SecRule REQUEST_FILENAME "@beginsWith /admin" "id:1,phase:1,t:lowercase,t:normalizePath,deny,status:404,msg:'Admin access'"`,
+			},
+			target:         "/foo/../ADMIN/users",
+			expectedStatus: http.StatusNotFound,
+			expectedLogData: map[string]any{
+				accesslog.WAFAction:       "blocked",
+				accesslog.WAFMatchedRules: "1",
+				accesslog.WAFMessage:      "Admin access",
+			},
+		},
+		{
+			desc: "nolog rule",
+			directives: []string{
+				`SecRule REQUEST_METHOD "@streq DELETE" "id:1,phase:1,deny,nolog"`,
+			},
+			method:             http.MethodDelete,
+			target:             "/",
+			expectedStatus:     http.StatusForbidden,
+			expectedNotLogData: []string{accesslog.WAFMatchedRules},
+			expectedLogData: map[string]any{
+				accesslog.WAFAction: "blocked",
+			},
+		},
+		{
+			desc: "chained rule",
+			directives: []string{
+				`SecRule REQUEST_METHOD "@streq POST" "id:1,phase:1,deny,chain"`,
+				`SecRule &REQUEST_HEADERS:Content-Type "@eq 0"`,
+			},
+			method:         http.MethodPost,
+			target:         "/",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc: "chained rule not matching",
+			directives: []string{
+				`SecRule REQUEST_METHOD "@streq POST" "id:1,phase:1,deny,chain"`,
+				`SecRule &REQUEST_HEADERS:Content-Type "@eq 0"`,
+			},
+			method:         http.MethodPost,
+			target:         "/",
+			headers:        map[string]string{"Content-Type": "text/plain"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "excluded argument",
+			directives: []string{
+				`SecRule ARGS|!ARGS:comment "@contains <script" "id:1,phase:1,deny"`,
+			},
+			target:         "/?comment=%3Cscript%3E",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "allow stops the evaluation",
+			directives: []string{
+				`SecRule REMOTE_ADDR "@ipMatch 192.0.2.0/24" "id:1,phase:1,allow,nolog"`,
+				`SecRule ARGS "@contains <script" "id:2,phase:1,deny"`,
+			},
+			target:         "/?comment=%3Cscript%3E",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "skip after marker",
+			directives: []string{
+				`SecRule REQUEST_FILENAME "@streq /health" "id:1,phase:1,pass,nolog,skipAfter:END_CHECKS"`,
+				`SecRule REQUEST_METHOD "!@within GET POST" "id:2,phase:1,deny"`,
+				`SecMarker END_CHECKS`,
+			},
+			method:         http.MethodOptions,
+			target:         "/health",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "request body too large",
+			directives: []string{
+				`SecRequestBodyLimit 8`,
+				`SecRule REQUEST_BODY "@contains foo" "id:1,deny"`,
+			},
+			method:         http.MethodPost,
+			target:         "/",
+			body:           "0123456789",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc: "request body partially processed",
+			directives: []string{
+				`SecRequestBodyLimit 8`,
+				`SecRequestBodyLimitAction ProcessPartial`,
+				`SecRule REQUEST_BODY "@contains 9" "id:1,deny"`,
+			},
+			method:         http.MethodPost,
+			target:         "/",
+			body:           "0123456789",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "0123456789",
+		},
+		{
+			desc: "invalid JSON body",
+			directives: []string{
+				`SecRule REQBODY_ERROR "!@eq 0" "id:1,deny,msg:'Failed to parse request body: %{REQBODY_ERROR_MSG}'"`,
+			},
+			method:         http.MethodPost,
+			target:         "/",
+			headers:        map[string]string{"Content-Type": "application/json"},
+			body:           `{"foo":`,
+			expectedStatus: http.StatusForbidden,
+			expectedLogData: map[string]any{
+				accesslog.WAFMessage: "Failed to parse request body: parsing JSON body: unexpected EOF",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Body != nil {
+					body, err := io.ReadAll(req.Body)
+					require.NoError(t, err)
+					_, _ = rw.Write(body)
+				}
+
+				if q := req.URL.Query().Get("q"); q != "" {
+					_, _ = rw.Write([]byte(q))
+				}
+			})
+
+			handler, err := New(t.Context(), next, dynamic.WAF{Directives: test.directives}, "waf")
+			require.NoError(t, err)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, test.target, strings.NewReader(test.body))
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			logData := &accesslog.LogData{Core: accesslog.CoreLogData{}}
+			req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, rw.Body.String())
+			}
+
+			for key, value := range test.expectedLogData {
+				assert.Equal(t, value, logData.Core[key], key)
+			}
+			for _, key := range test.expectedNotLogData {
+				assert.NotContains(t, logData.Core, key)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package tcp
 
 import (
+	"bytes"
 	"fmt"
+	"net"
+	"net/url"
 	"regexp"
 	"slices"
 	"strings"
@@ -14,11 +17,38 @@ import (
 
 var tcpFuncs = map[string]func(*matchersTree, ...string) error{
 	"ALPN":          expect1Parameter(alpn),
+	"ClientCertSAN": expect1Parameter(clientCertSAN),
 	"ClientIP":      expect1Parameter(clientIP),
 	"HostSNI":       expect1Parameter(hostSNI),
 	"HostSNIRegexp": expect1Parameter(hostSNIRegexp),
+	"PayloadPrefix": expect1Parameter(payloadPrefix),
+	"Protocol":      expect1Parameter(protocol),
 }
 
+// clientCertMatchers are the matchers of the client certificate, which is only known once the TLS handshake is over.
+var clientCertMatchers = []string{"ClientCertSAN"}
+
+// protocolMatchers are the functions detecting the protocols of the Protocol matcher from the first bytes sent by the client.
+var protocolMatchers = map[string]func(payload []byte) bool{
+	"http":  isHTTP,
+	"redis": isRedis,
+	"ssh":   isSSH,
+}
+
+// protocolPayloadLengths are the numbers of bytes needed to detect the protocols of the Protocol matcher,
+// which are sent by any client of these protocols.
+var protocolPayloadLengths = map[string]int{
+	// A request line followed by the Host header name.
+	"http":  len("GET / HTTP/1.0\r\nHost:"),
+	"redis": len("*1\r\n$"),
+	"ssh":   len("SSH-"),
+}
+
+var (
+	httpRequestLine  = regexp.MustCompile(`^[A-Z]+ \S+ HTTP/1\.[01]\r?\n`)
+	redisArrayHeader = regexp.MustCompile(`^\*[0-9]+\r\n\$`)
+)
+
 func expect1Parameter(fn func(*matchersTree, ...string) error) func(*matchersTree, ...string) error {
 	return func(route *matchersTree, s ...string) error {
 		if len(s) != 1 {
@@ -44,6 +74,39 @@ func alpn(tree *matchersTree, protos ...string) error {
 	return nil
 }
 
+// clientCertSAN checks if a Subject Alternative Name of the verified client certificate is equal to the matcher value.
+// Before the TLS handshake, it matches any connection.
+func clientCertSAN(tree *matchersTree, sans ...string) error {
+	san := sans[0]
+	sanIP := net.ParseIP(san)
+
+	tree.matcher = func(meta ConnData) bool {
+		if meta.tlsState == nil {
+			return true
+		}
+
+		// The client certificate is only trusted once it has been verified.
+		if len(meta.tlsState.VerifiedChains) == 0 || len(meta.tlsState.PeerCertificates) == 0 {
+			return false
+		}
+
+		cert := meta.tlsState.PeerCertificates[0]
+
+		if slices.ContainsFunc(cert.DNSNames, func(name string) bool { return strings.EqualFold(name, san) }) ||
+			slices.Contains(cert.EmailAddresses, san) {
+			return true
+		}
+
+		if sanIP != nil && slices.ContainsFunc(cert.IPAddresses, sanIP.Equal) {
+			return true
+		}
+
+		return slices.ContainsFunc(cert.URIs, func(uri *url.URL) bool { return uri.String() == san })
+	}
+
+	return nil
+}
+
 func clientIP(tree *matchersTree, clientIP ...string) error {
 	checker, err := ip.NewChecker(clientIP)
 	if err != nil {
@@ -111,3 +174,67 @@ func hostSNIRegexp(tree *matchersTree, templates ...string) error {
 
 	return nil
 }
+
+// payloadPrefix checks if the first bytes sent by the client start with the matcher prefix.
+func payloadPrefix(tree *matchersTree, prefixes ...string) error {
+	prefix := []byte(prefixes[0])
+
+	tree.matcher = func(meta ConnData) bool {
+		return bytes.HasPrefix(meta.payload, prefix)
+	}
+
+	return nil
+}
+
+// protocol checks if the first bytes sent by the client are the ones of the matcher protocol.
+func protocol(tree *matchersTree, protocols ...string) error {
+	isProtocol, ok := protocolMatchers[strings.ToLower(protocols[0])]
+	if !ok {
+		return fmt.Errorf("invalid value for Protocol matcher, %q is not a supported protocol", protocols[0])
+	}
+
+	tree.matcher = func(meta ConnData) bool {
+		return isProtocol(meta.payload)
+	}
+
+	return nil
+}
+
+// isHTTP checks if the payload is the beginning of an HTTP/1 request with a Host header.
+func isHTTP(payload []byte) bool {
+	if !httpRequestLine.Match(payload) {
+		return false
+	}
+
+	_, headers, _ := bytes.Cut(payload, []byte("\n"))
+	for {
+		var line []byte
+		var ok bool
+		line, headers, ok = bytes.Cut(headers, []byte("\n"))
+		if !ok {
+			// The end of the line is not part of the payload.
+			return false
+		}
+
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(line) == 0 {
+			// End of the headers.
+			return false
+		}
+
+		name, _, _ := bytes.Cut(line, []byte(":"))
+		if strings.EqualFold(string(name), "Host") {
+			return true
+		}
+	}
+}
+
+// isRedis checks if the payload is the beginning of a Redis command, sent as an array of bulk strings.
+func isRedis(payload []byte) bool {
+	return redisArrayHeader.Match(payload)
+}
+
+// isSSH checks if the payload is the beginning of the SSH protocol version exchange.
+func isSSH(payload []byte) bool {
+	return bytes.HasPrefix(payload, []byte("SSH-"))
+}
@@ -1,6 +1,10 @@
 package tcp
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -383,3 +387,340 @@ func Test_ALPN(t *testing.T) {
 		})
 	}
 }
+
+func Test_Protocol(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rule     string
+		expected map[string]bool
+		buildErr bool
+	}{
+		{
+			desc:     "Invalid Protocol matcher (unsupported protocol)",
+			rule:     "Protocol(`ftp`)",
+			buildErr: true,
+		},
+		{
+			desc:     "Invalid Protocol matcher (too many parameters)",
+			rule:     "Protocol(`ssh`, `http`)",
+			buildErr: true,
+		},
+		{
+			desc: "Valid SSH Protocol matcher",
+			rule: "Protocol(`ssh`)",
+			expected: map[string]bool{
+				"SSH-2.0-OpenSSH_9.6\r\n":         true,
+				"SSH-":                            true,
+				"GET / HTTP/1.1\r\nHost: foo\r\n": false,
+				"*1\r\n$4\r\nPING\r\n":            false,
+				"ssh-2.0":                         false,
+				"":                                false,
+			},
+		},
+		{
+			desc: "Valid SSH Protocol matcher with alternative case",
+			rule: "Protocol(`SSH`)",
+			expected: map[string]bool{
+				"SSH-2.0-OpenSSH_9.6\r\n": true,
+			},
+		},
+		{
+			desc: "Valid HTTP Protocol matcher",
+			rule: "Protocol(`http`)",
+			expected: map[string]bool{
+				"GET / HTTP/1.1\r\nHost: foo\r\n\r\n":                    true,
+				"POST /foo HTTP/1.0\r\nUser-Agent: bar\r\nhost: foo\r\n": true,
+				"GET / HTTP/1.1\nHost: foo\n\n":                          true,
+				"GET / HTTP/1.1\r\n\r\nHost: foo\r\n":                    false,
+				"GET / HTTP/1.1\r\nUser-Agent: bar\r\n\r\n":              false,
+				"GET / HTTP/1.1\r\nHost: foo":                            false,
+				"GET / HTTP/2.0\r\nHost: foo\r\n\r\n":                    false,
+				"PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n":                       false,
+				"get / HTTP/1.1\r\nHost: foo\r\n\r\n":                    false,
+				"SSH-2.0-OpenSSH_9.6\r\n":                                false,
+				"":                                                       false,
+			},
+		},
+		{
+			desc: "Valid Redis Protocol matcher",
+			rule: "Protocol(`redis`)",
+			expected: map[string]bool{
+				"*1\r\n$4\r\nPING\r\n":                          true,
+				"*3\r\n$3\r\nSET\r\n$3\r\nfoo\r\n$3\r\nbar\r\n": true,
+				"*1\r\n":                  false,
+				"PING\r\n":                false,
+				"SSH-2.0-OpenSSH_9.6\r\n": false,
+				"":                        false,
+			},
+		},
+		{
+			desc: "Valid negative Protocol matcher",
+			rule: "!Protocol(`ssh`)",
+			expected: map[string]bool{
+				"SSH-2.0-OpenSSH_9.6\r\n": false,
+				"*1\r\n$4\r\nPING\r\n":    true,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer(nil)
+			require.NoError(t, err)
+
+			err = muxer.AddRoute(test.rule, "", 0, "", tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+			if test.buildErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, muxer.HasPayloadRoutes())
+
+			for payload, match := range test.expected {
+				meta := ConnData{
+					payload: []byte(payload),
+				}
+
+				handler, _ := muxer.Match(meta)
+				assert.Equal(t, match, handler != nil, payload)
+			}
+		})
+	}
+}
+
+func Test_PayloadPrefix(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rule     string
+		expected map[string]bool
+		buildErr bool
+	}{
+		{
+			desc:     "Invalid PayloadPrefix matcher (empty parameters)",
+			rule:     "PayloadPrefix(``)",
+			buildErr: true,
+		},
+		{
+			desc:     "Invalid PayloadPrefix matcher (too many parameters)",
+			rule:     "PayloadPrefix(`foo`, `bar`)",
+			buildErr: true,
+		},
+		{
+			desc: "Valid PayloadPrefix matcher",
+			rule: "PayloadPrefix(`MQTT`)",
+			expected: map[string]bool{
+				"MQTT":    true,
+				"MQTTfoo": true,
+				"mqtt":    false,
+				"MQT":     false,
+				"":        false,
+			},
+		},
+		{
+			desc: "Valid PayloadPrefix matcher with escape sequences",
+			rule: "PayloadPrefix(\"\\x10\\x00\")",
+			expected: map[string]bool{
+				"\x10\x00\x04MQTT": true,
+				"\x10\x01":         false,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer(nil)
+			require.NoError(t, err)
+
+			err = muxer.AddRoute(test.rule, "", 0, "", tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+			if test.buildErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, muxer.HasPayloadRoutes())
+
+			for payload, match := range test.expected {
+				meta := ConnData{
+					payload: []byte(payload),
+				}
+
+				handler, _ := muxer.Match(meta)
+				assert.Equal(t, match, handler != nil, payload)
+			}
+		})
+	}
+}
+
+func Test_ClientCertSAN(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://example.org/client")
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{
+		DNSNames:       []string{"client.example.com"},
+		EmailAddresses: []string{"client@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("2001:db8::1")},
+		URIs:           []*url.URL{spiffeID},
+	}
+
+	verified := tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+
+	unverified := tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+	}
+
+	testCases := []struct {
+		desc     string
+		rule     string
+		tlsState *tls.ConnectionState
+		expected bool
+		buildErr bool
+	}{
+		{
+			desc:     "Invalid ClientCertSAN matcher (empty parameters)",
+			rule:     "ClientCertSAN(``)",
+			buildErr: true,
+		},
+		{
+			desc:     "Invalid ClientCertSAN matcher (too many parameters)",
+			rule:     "ClientCertSAN(`client.example.com`, `client@example.com`)",
+			buildErr: true,
+		},
+		{
+			desc:     "Matching before the TLS handshake",
+			rule:     "ClientCertSAN(`other.example.com`)",
+			expected: true,
+		},
+		{
+			desc:     "Negated matching before the TLS handshake",
+			rule:     "!ClientCertSAN(`client.example.com`)",
+			expected: true,
+		},
+		{
+			desc:     "Matching DNS name",
+			rule:     "ClientCertSAN(`client.example.com`)",
+			tlsState: &verified,
+			expected: true,
+		},
+		{
+			desc:     "Matching DNS name with alternative case",
+			rule:     "ClientCertSAN(`Client.Example.com`)",
+			tlsState: &verified,
+			expected: true,
+		},
+		{
+			desc:     "Matching email address",
+			rule:     "ClientCertSAN(`client@example.com`)",
+			tlsState: &verified,
+			expected: true,
+		},
+		{
+			desc:     "Matching IP address",
+			rule:     "ClientCertSAN(`2001:db8:0::1`)",
+			tlsState: &verified,
+			expected: true,
+		},
+		{
+			desc:     "Matching SPIFFE ID",
+			rule:     "ClientCertSAN(`spiffe://example.org/client`)",
+			tlsState: &verified,
+			expected: true,
+		},
+		{
+			desc:     "Not matching SAN",
+			rule:     "ClientCertSAN(`other.example.com`)",
+			tlsState: &verified,
+		},
+		{
+			desc:     "Negated matching SAN",
+			rule:     "!ClientCertSAN(`client.example.com`)",
+			tlsState: &verified,
+		},
+		{
+			desc:     "Unverified client certificate",
+			rule:     "ClientCertSAN(`client.example.com`)",
+			tlsState: &unverified,
+		},
+		{
+			desc:     "No client certificate",
+			rule:     "ClientCertSAN(`client.example.com`)",
+			tlsState: &tls.ConnectionState{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer(nil)
+			require.NoError(t, err)
+
+			err = muxer.AddRoute(test.rule, "", 0, "", tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+			if test.buildErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, muxer.HasClientCertRoutes())
+
+			var meta ConnData
+			if test.tlsState != nil {
+				meta.SetTLSConnectionState(*test.tlsState)
+			}
+
+			handler, _ := muxer.Match(meta)
+			assert.Equal(t, test.expected, handler != nil)
+		})
+	}
+}
+
+func TestMuxer_PayloadLength(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rules    []string
+		expected int
+	}{
+		{
+			desc:  "No payload matcher",
+			rules: []string{"HostSNI(`*`)"},
+		},
+		{
+			desc:     "PayloadPrefix matcher",
+			rules:    []string{"PayloadPrefix(`MQTT`)"},
+			expected: 4,
+		},
+		{
+			desc:     "Protocol matcher",
+			rules:    []string{"Protocol(`redis`)"},
+			expected: 5,
+		},
+		{
+			desc:     "Longest prefix of several rules",
+			rules:    []string{"PayloadPrefix(`MQTT`) || !Protocol(`HTTP`)", "ClientIP(`10.0.0.1`) && Protocol(`ssh`)"},
+			expected: 21,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer(nil)
+			require.NoError(t, err)
+
+			for _, rule := range test.rules {
+				err = muxer.AddRoute(rule, "", 0, "", tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.expected, muxer.PayloadLength())
+			assert.Equal(t, test.expected > 0, muxer.HasPayloadRoutes())
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package tcp
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"slices"
@@ -19,6 +20,10 @@ type ConnData struct {
 	serverName string
 	remoteIP   string
 	alpnProtos []string
+	// payload holds the first bytes sent by a non-TLS client.
+	payload []byte
+	// tlsState holds the state of the terminated TLS connection, once the TLS handshake is over.
+	tlsState *tls.ConnectionState
 }
 
 // NewConnData builds a connData struct from the given parameters.
@@ -38,9 +43,26 @@ func NewConnData(serverName string, remoteAddr net.Addr, alpnProtos []string) (C
 	}, nil
 }
 
+// SetPayload sets the first bytes sent by the client, matched by the payload matchers (Protocol and PayloadPrefix).
+func (c *ConnData) SetPayload(payload []byte) {
+	c.payload = payload
+}
+
+// SetTLSConnectionState sets the state of the terminated TLS connection, matched by the client certificate matchers (ClientCertSAN).
+// Before it is set, the client certificate matchers match any connection.
+func (c *ConnData) SetTLSConnectionState(state tls.ConnectionState) {
+	c.tlsState = &state
+}
+
 // Muxer defines a muxer that handles TCP routing with rules.
 type Muxer struct {
 	routes routes
+	// payloadLength is the number of bytes sent by the client needed by the payload matchers of the route rules,
+	// zero when no rule uses a payload matcher.
+	payloadLength int
+	// clientCertRoutes is whether a route rule uses a client certificate matcher,
+	// which requires routing the connection again once the TLS handshake is over.
+	clientCertRoutes bool
 
 	parser              predicate.Parser
 	parserV2            predicate.Parser
@@ -165,6 +187,18 @@ func (m *Muxer) AddRoute(rule string, syntax string, priority int, providerName
 		catchAll = ruleTree.Value[0] == "*" && strings.EqualFold(ruleTree.Matcher, "HostSNI")
 	}
 
+	for _, prefix := range ruleTree.ParseMatchers([]string{"PayloadPrefix"}) {
+		m.payloadLength = max(m.payloadLength, len(prefix))
+	}
+
+	for _, proto := range ruleTree.ParseMatchers([]string{"Protocol"}) {
+		m.payloadLength = max(m.payloadLength, protocolPayloadLengths[proto])
+	}
+
+	if len(ruleTree.ParseMatchers(clientCertMatchers)) > 0 {
+		m.clientCertRoutes = true
+	}
+
 	newRoute := &route{
 		handler:          handler,
 		matchers:         matchers,
@@ -184,9 +218,40 @@ func (m *Muxer) HasRoutes() bool {
 	return len(m.routes) > 0
 }
 
+// HasPayloadRoutes returns whether a route rule of the muxer uses a payload matcher.
+func (m *Muxer) HasPayloadRoutes() bool {
+	return m.payloadLength > 0
+}
+
+// PayloadLength returns the number of bytes sent by the client needed by the payload matchers,
+// that is to say the length of the longest prefix they match.
+func (m *Muxer) PayloadLength() int {
+	return m.payloadLength
+}
+
+// HasClientCertRoutes returns whether a route rule of the muxer uses a client certificate matcher.
+func (m *Muxer) HasClientCertRoutes() bool {
+	return m.clientCertRoutes
+}
+
 // ParseHostSNI extracts the HostSNIs declared in a rule.
 // This is a first naive implementation used in TCP routing.
 func ParseHostSNI(rule string) ([]string, error) {
+	return parseMatcherValues(rule, "HostSNI")
+}
+
+// ParseHostSNIRegexps extracts the HostSNIRegexp regular expressions declared in a rule.
+func ParseHostSNIRegexps(rule string) ([]string, error) {
+	return parseMatcherValues(rule, "HostSNIRegexp")
+}
+
+// ParseClientCertSANs extracts the client certificate SANs declared in a rule.
+func ParseClientCertSANs(rule string) ([]string, error) {
+	return parseMatcherValues(rule, "ClientCertSAN")
+}
+
+// parseMatcherValues extracts the values of the given matcher declared in a rule.
+func parseMatcherValues(rule, matcher string) ([]string, error) {
 	var matchers []string
 	for matcher := range tcpFuncs {
 		matchers = append(matchers, matcher)
@@ -210,7 +275,7 @@ func ParseHostSNI(rule string) ([]string, error) {
 		return nil, fmt.Errorf("error while parsing rule %s", rule)
 	}
 
-	return buildTree().ParseMatchers([]string{"HostSNI"}), nil
+	return buildTree().ParseMatchers([]string{matcher}), nil
 }
 
 // routes implements sort.Interface.
@@ -308,7 +373,13 @@ func (m *matchersTree) addRule(rule *rules.Tree, funcs matcherFuncs) error {
 
 		if rule.Not {
 			matcherFunc := m.matcher
+			clientCert := slices.Contains(clientCertMatchers, rule.Matcher)
 			m.matcher = func(meta ConnData) bool {
+				// Negated or not, a client certificate matcher matches any connection before the TLS handshake.
+				if clientCert && meta.tlsState == nil {
+					return true
+				}
+
 				return !matcherFunc(meta)
 			}
 		}
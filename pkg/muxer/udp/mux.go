@@ -0,0 +1,245 @@
+package udp
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/rules"
+	"github.com/traefik/traefik/v3/pkg/types"
+	"github.com/traefik/traefik/v3/pkg/udp"
+	"github.com/vulcand/predicate"
+)
+
+// ConnData contains UDP session metadata.
+type ConnData struct {
+	serverName string
+	remoteIP   string
+	alpnProtos []string
+}
+
+// NewConnData builds a connData struct from the given parameters.
+// The serverName and alpnProtos are the ones of the TLS ClientHello carried by the first datagram of a DTLS or QUIC session, if any.
+func NewConnData(serverName string, remoteAddr net.Addr, alpnProtos []string) (ConnData, error) {
+	remoteIP, _, err := net.SplitHostPort(remoteAddr.String())
+	if err != nil {
+		return ConnData{}, fmt.Errorf("parsing remote address %q: %w", remoteAddr.String(), err)
+	}
+
+	return ConnData{
+		serverName: types.CanonicalDomain(serverName),
+		remoteIP:   remoteIP,
+		alpnProtos: alpnProtos,
+	}, nil
+}
+
+// Muxer defines a muxer that handles UDP routing with rules.
+type Muxer struct {
+	routes routes
+	parser predicate.Parser
+}
+
+// NewMuxer returns a UDP muxer.
+func NewMuxer() (*Muxer, error) {
+	var matcherNames []string
+	for matcherName := range udpFuncs {
+		matcherNames = append(matcherNames, matcherName)
+	}
+
+	parser, err := rules.NewParser(matcherNames)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating rules parser: %w", err)
+	}
+
+	return &Muxer{parser: parser}, nil
+}
+
+// Match returns the handler of the first route matching the session metadata.
+func (m *Muxer) Match(meta ConnData) udp.Handler {
+	for _, route := range m.routes {
+		if route.matchers.match(meta) {
+			return route.handler
+		}
+	}
+
+	return nil
+}
+
+// GetRulePriority computes the priority for a given rule.
+// The priority is calculated using the length of rule.
+// There is a special case where the HostSNI(`*`) has a priority of -1.
+func GetRulePriority(rule string) int {
+	if isCatchAll(rule) {
+		return -1
+	}
+
+	return len(rule)
+}
+
+// AddRoute adds a new route, associated to the given handler, at the given
+// priority, to the muxer.
+func (m *Muxer) AddRoute(rule string, priority int, handler udp.Handler) error {
+	parse, err := m.parser.Parse(rule)
+	if err != nil {
+		return fmt.Errorf("error while parsing rule %s: %w", rule, err)
+	}
+
+	buildTree, ok := parse.(rules.TreeBuilder)
+	if !ok {
+		return fmt.Errorf("error while parsing rule %s", rule)
+	}
+
+	var matchers matchersTree
+	err = matchers.addRule(buildTree(), udpFuncs)
+	if err != nil {
+		return fmt.Errorf("error while adding rule %s: %w", rule, err)
+	}
+
+	m.routes = append(m.routes, &route{
+		handler:  handler,
+		matchers: matchers,
+		catchAll: isCatchAll(rule),
+		priority: priority,
+	})
+
+	sort.Stable(m.routes)
+
+	return nil
+}
+
+// CatchAllHandler returns the handler of the only route of the muxer,
+// if this route matches all the sessions, i.e. if routing does not need to look at the datagrams.
+func (m *Muxer) CatchAllHandler() (udp.Handler, bool) {
+	if len(m.routes) != 1 || !m.routes[0].catchAll {
+		return nil, false
+	}
+
+	return m.routes[0].handler, true
+}
+
+// HasRoutes returns whether the muxer has routes.
+func (m *Muxer) HasRoutes() bool {
+	return len(m.routes) > 0
+}
+
+// isCatchAll reports whether the rule is exactly HostSNI(`*`).
+func isCatchAll(rule string) bool {
+	catchAllParser, err := rules.NewParser([]string{"HostSNI"})
+	if err != nil {
+		return false
+	}
+
+	parse, err := catchAllParser.Parse(rule)
+	if err != nil {
+		return false
+	}
+
+	buildTree, ok := parse.(rules.TreeBuilder)
+	if !ok {
+		return false
+	}
+
+	ruleTree := buildTree()
+
+	return ruleTree.RuleLeft == nil && ruleTree.RuleRight == nil && !ruleTree.Not &&
+		len(ruleTree.Value) == 1 && ruleTree.Value[0] == "*" && strings.EqualFold(ruleTree.Matcher, "HostSNI")
+}
+
+// routes implements sort.Interface.
+type routes []*route
+
+// Len implements sort.Interface.
+func (r routes) Len() int { return len(r) }
+
+// Swap implements sort.Interface.
+func (r routes) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+
+// Less implements sort.Interface.
+func (r routes) Less(i, j int) bool { return r[i].priority > r[j].priority }
+
+// route holds the matchers to match UDP route,
+// and the handler that will serve the session.
+type route struct {
+	// matchers tree structure reflecting the rule.
+	matchers matchersTree
+	// handler responsible for handling the route.
+	handler udp.Handler
+	// catchAll indicates whether the route rule has exactly the catchAll value (HostSNI(`*`)).
+	catchAll bool
+	// priority is used to disambiguate between two (or more) rules that would
+	// all match for a given session.
+	// Computed from the matching rule length, if not user-set.
+	priority int
+}
+
+// matchersTree represents the matchers tree structure.
+type matchersTree struct {
+	// matcher is a matcher func used to match session properties.
+	// If matcher is not nil, it means that this matcherTree is a leaf of the tree.
+	// It is therefore mutually exclusive with left and right.
+	matcher func(ConnData) bool
+	// operator to combine the evaluation of left and right leaves.
+	operator string
+	// Mutually exclusive with matcher.
+	left  *matchersTree
+	right *matchersTree
+}
+
+func (m *matchersTree) match(meta ConnData) bool {
+	if m == nil {
+		// This should never happen as it should have been detected during parsing.
+		log.Warn().Msg("Rule matcher is nil")
+		return false
+	}
+
+	if m.matcher != nil {
+		return m.matcher(meta)
+	}
+
+	switch m.operator {
+	case "or":
+		return m.left.match(meta) || m.right.match(meta)
+	case "and":
+		return m.left.match(meta) && m.right.match(meta)
+	default:
+		// This should never happen as it should have been detected during parsing.
+		log.Warn().Str("operator", m.operator).Msg("Invalid rule operator")
+		return false
+	}
+}
+
+func (m *matchersTree) addRule(rule *rules.Tree, funcs map[string]func(*matchersTree, ...string) error) error {
+	switch rule.Matcher {
+	case "and", "or":
+		m.operator = rule.Matcher
+		m.left = &matchersTree{}
+		err := m.left.addRule(rule.RuleLeft, funcs)
+		if err != nil {
+			return err
+		}
+
+		m.right = &matchersTree{}
+		return m.right.addRule(rule.RuleRight, funcs)
+	default:
+		err := rules.CheckRule(rule)
+		if err != nil {
+			return err
+		}
+
+		err = funcs[rule.Matcher](m, rule.Value...)
+		if err != nil {
+			return err
+		}
+
+		if rule.Not {
+			matcherFunc := m.matcher
+			m.matcher = func(meta ConnData) bool {
+				return !matcherFunc(meta)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package udp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/udp"
+)
+
+func Test_addRoute(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		rule       string
+		serverName string
+		remoteAddr string
+		protos     []string
+		addErr     bool
+		matches    bool
+	}{
+		{
+			desc:   "no rule",
+			rule:   "",
+			addErr: true,
+		},
+		{
+			desc:   "unknown matcher",
+			rule:   "Host(`example.com`)",
+			addErr: true,
+		},
+		{
+			desc:   "invalid HostSNI",
+			rule:   "HostSNI(`example.com/foo`)",
+			addErr: true,
+		},
+		{
+			desc:   "invalid ClientIP",
+			rule:   "ClientIP(`invalid`)",
+			addErr: true,
+		},
+		{
+			desc:       "catchAll without ClientHello",
+			rule:       "HostSNI(`*`)",
+			remoteAddr: "10.0.0.1:53",
+			matches:    true,
+		},
+		{
+			desc:       "matching ClientIP",
+			rule:       "ClientIP(`10.0.0.0/24`)",
+			remoteAddr: "10.0.0.1:53",
+			matches:    true,
+		},
+		{
+			desc:       "non matching ClientIP",
+			rule:       "ClientIP(`10.0.0.0/24`)",
+			remoteAddr: "10.0.1.1:53",
+		},
+		{
+			desc:       "matching IPv6 ClientIP",
+			rule:       "ClientIP(`fe80::/64`)",
+			remoteAddr: "[fe80::1]:53",
+			matches:    true,
+		},
+		{
+			desc:       "matching HostSNI",
+			rule:       "HostSNI(`example.com`)",
+			serverName: "example.com",
+			remoteAddr: "10.0.0.1:443",
+			matches:    true,
+		},
+		{
+			desc:       "matching HostSNI case insensitively",
+			rule:       "HostSNI(`example.com`)",
+			serverName: "EXAMPLE.com",
+			remoteAddr: "10.0.0.1:443",
+			matches:    true,
+		},
+		{
+			desc:       "HostSNI without ClientHello",
+			rule:       "HostSNI(`example.com`)",
+			remoteAddr: "10.0.0.1:443",
+		},
+		{
+			desc:       "matching wildcard HostSNI",
+			rule:       "HostSNI(`*.example.com`)",
+			serverName: "foo.example.com",
+			remoteAddr: "10.0.0.1:443",
+			matches:    true,
+		},
+		{
+			desc:       "matching HostSNIRegexp",
+			rule:       "HostSNIRegexp(`^[a-z]+\\.example\\.com$`)",
+			serverName: "foo.example.com",
+			remoteAddr: "10.0.0.1:443",
+			matches:    true,
+		},
+		{
+			desc:       "matching ALPN",
+			rule:       "ALPN(`h3`)",
+			protos:     []string{"h3"},
+			remoteAddr: "10.0.0.1:443",
+			matches:    true,
+		},
+		{
+			desc:       "matching HostSNI and ClientIP",
+			rule:       "HostSNI(`example.com`) && ClientIP(`10.0.0.1`)",
+			serverName: "example.com",
+			remoteAddr: "10.0.0.1:443",
+			matches:    true,
+		},
+		{
+			desc:       "negated ClientIP",
+			rule:       "!ClientIP(`10.0.0.1`)",
+			remoteAddr: "10.0.0.1:443",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var served bool
+			handler := udp.HandlerFunc(func(conn *udp.Conn) {
+				served = true
+			})
+
+			muxer, err := NewMuxer()
+			require.NoError(t, err)
+
+			err = muxer.AddRoute(test.rule, 0, handler)
+			if test.addErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			addr, err := net.ResolveUDPAddr("udp", test.remoteAddr)
+			require.NoError(t, err)
+
+			meta, err := NewConnData(test.serverName, addr, test.protos)
+			require.NoError(t, err)
+
+			matchingHandler := muxer.Match(meta)
+			if !test.matches {
+				assert.Nil(t, matchingHandler)
+				return
+			}
+
+			require.NotNil(t, matchingHandler)
+			matchingHandler.ServeUDP(nil)
+			assert.True(t, served)
+		})
+	}
+}
+
+func TestMuxer_priority(t *testing.T) {
+	muxer, err := NewMuxer()
+	require.NoError(t, err)
+
+	var served string
+	handler := func(name string) udp.Handler {
+		return udp.HandlerFunc(func(conn *udp.Conn) {
+			served = name
+		})
+	}
+
+	require.NoError(t, muxer.AddRoute("HostSNI(`*`)", GetRulePriority("HostSNI(`*`)"), handler("catchAll")))
+	require.NoError(t, muxer.AddRoute("ClientIP(`10.0.0.0/8`)", 10, handler("clientIP")))
+	require.NoError(t, muxer.AddRoute("HostSNI(`example.com`)", 20, handler("hostSNI")))
+
+	_, ok := muxer.CatchAllHandler()
+	assert.False(t, ok)
+
+	testCases := []struct {
+		serverName string
+		remoteAddr string
+		expected   string
+	}{
+		{serverName: "example.com", remoteAddr: "10.0.0.1:443", expected: "hostSNI"},
+		{remoteAddr: "10.0.0.1:443", expected: "clientIP"},
+		{remoteAddr: "192.168.0.1:443", expected: "catchAll"},
+	}
+
+	for _, test := range testCases {
+		addr, err := net.ResolveUDPAddr("udp", test.remoteAddr)
+		require.NoError(t, err)
+
+		meta, err := NewConnData(test.serverName, addr, nil)
+		require.NoError(t, err)
+
+		muxer.Match(meta).ServeUDP(nil)
+		assert.Equal(t, test.expected, served)
+	}
+}
+
+func TestMuxer_CatchAllHandler(t *testing.T) {
+	muxer, err := NewMuxer()
+	require.NoError(t, err)
+
+	require.NoError(t, muxer.AddRoute("HostSNI(`*`)", -1, udp.HandlerFunc(func(conn *udp.Conn) {})))
+
+	handler, ok := muxer.CatchAllHandler()
+	assert.True(t, ok)
+	assert.NotNil(t, handler)
+}
@@ -33,6 +33,7 @@ const (
 	ddOpenConnsName               = "open.connections"
 
 	ddTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
+	ddACMECertificateEventsName     = "tls.acme.certificate.events.total"
 
 	ddEntryPointReqsName        = "entrypoint.request.total"
 	ddEntryPointReqsTLSName     = "entrypoint.request.tls.total"
@@ -53,6 +54,9 @@ const (
 	ddServiceServerUpName     = "service.server.up"
 	ddServiceReqsBytesName    = "service.requests.bytes.total"
 	ddServiceRespsBytesName   = "service.responses.bytes.total"
+
+	ddCacheHitsName   = "middleware.cache.hits.total"
+	ddCacheMissesName = "middleware.cache.misses.total"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -75,6 +79,9 @@ func RegisterDatadog(ctx context.Context, config *otypes.Datadog) Registry {
 		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
 		openConnectionsGauge:           datadogClient.NewGauge(ddOpenConnsName),
 		tlsCertsNotAfterTimestampGauge: datadogClient.NewGauge(ddTLSCertsNotAfterTimestampName),
+		acmeCertificateEventsCounter:   datadogClient.NewCounter(ddACMECertificateEventsName, 1.0),
+		cacheHitsCounter:               datadogClient.NewCounter(ddCacheHitsName, 1.0),
+		cacheMissesCounter:             datadogClient.NewCounter(ddCacheMissesName, 1.0),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -30,6 +30,7 @@ const (
 	influxDBOpenConnsName               = "traefik.open.connections"
 
 	influxDBTLSCertsNotAfterTimestampName = "traefik.tls.certs.notAfterTimestamp"
+	influxDBACMECertificateEventsName     = "traefik.tls.acme.certificate.events.total"
 
 	influxDBEntryPointReqsName        = "traefik.entrypoint.requests.total"
 	influxDBEntryPointReqsTLSName     = "traefik.entrypoint.requests.tls.total"
@@ -50,6 +51,9 @@ const (
 	influxDBServiceServerUpName     = "traefik.service.server.up"
 	influxDBServiceReqsBytesName    = "traefik.service.requests.bytes.total"
 	influxDBServiceRespsBytesName   = "traefik.service.responses.bytes.total"
+
+	influxDBCacheHitsName   = "traefik.middleware.cache.hits.total"
+	influxDBCacheMissesName = "traefik.middleware.cache.misses.total"
 )
 
 // RegisterInfluxDB2 creates metrics exporter for InfluxDB2.
@@ -84,6 +88,9 @@ func RegisterInfluxDB2(ctx context.Context, config *otypes.InfluxDB2) Registry {
 		lastConfigReloadSuccessGauge:   influxDB2Store.NewGauge(influxDBLastConfigReloadSuccessName),
 		openConnectionsGauge:           influxDB2Store.NewGauge(influxDBOpenConnsName),
 		tlsCertsNotAfterTimestampGauge: influxDB2Store.NewGauge(influxDBTLSCertsNotAfterTimestampName),
+		acmeCertificateEventsCounter:   influxDB2Store.NewCounter(influxDBACMECertificateEventsName),
+		cacheHitsCounter:               influxDB2Store.NewCounter(influxDBCacheHitsName),
+		cacheMissesCounter:             influxDB2Store.NewCounter(influxDBCacheMissesName),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -28,6 +28,7 @@ type Registry interface {
 	// TLS
 
 	TLSCertsNotAfterTimestampGauge() metrics.Gauge
+	ACMECertificateEventsCounter() metrics.Counter
 
 	// entry point metrics
 
@@ -54,6 +55,11 @@ type Registry interface {
 	ServiceServerUpGauge() metrics.Gauge
 	ServiceReqsBytesCounter() metrics.Counter
 	ServiceRespsBytesCounter() metrics.Counter
+
+	// middleware metrics
+
+	CacheHitsCounter() metrics.Counter
+	CacheMissesCounter() metrics.Counter
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -70,6 +76,7 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var openConnectionsGauge []metrics.Gauge
 	var tlsCertsNotAfterTimestampGauge []metrics.Gauge
+	var acmeCertificateEventsCounter []metrics.Counter
 	var entryPointReqsCounter []CounterWithHeaders
 	var entryPointReqsTLSCounter []metrics.Counter
 	var entryPointReqDurationHistogram []ScalableHistogram
@@ -87,6 +94,8 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var serviceServerUpGauge []metrics.Gauge
 	var serviceReqsBytesCounter []metrics.Counter
 	var serviceRespsBytesCounter []metrics.Counter
+	var cacheHitsCounter []metrics.Counter
+	var cacheMissesCounter []metrics.Counter
 
 	for _, r := range registries {
 		if r.ConfigReloadsCounter() != nil {
@@ -101,6 +110,9 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.TLSCertsNotAfterTimestampGauge() != nil {
 			tlsCertsNotAfterTimestampGauge = append(tlsCertsNotAfterTimestampGauge, r.TLSCertsNotAfterTimestampGauge())
 		}
+		if r.ACMECertificateEventsCounter() != nil {
+			acmeCertificateEventsCounter = append(acmeCertificateEventsCounter, r.ACMECertificateEventsCounter())
+		}
 		if r.EntryPointReqsCounter() != nil {
 			entryPointReqsCounter = append(entryPointReqsCounter, r.EntryPointReqsCounter())
 		}
@@ -152,6 +164,12 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.ServiceRespsBytesCounter() != nil {
 			serviceRespsBytesCounter = append(serviceRespsBytesCounter, r.ServiceRespsBytesCounter())
 		}
+		if r.CacheHitsCounter() != nil {
+			cacheHitsCounter = append(cacheHitsCounter, r.CacheHitsCounter())
+		}
+		if r.CacheMissesCounter() != nil {
+			cacheMissesCounter = append(cacheMissesCounter, r.CacheMissesCounter())
+		}
 	}
 
 	return &standardRegistry{
@@ -162,6 +180,7 @@ func NewMultiRegistry(registries []Registry) Registry {
 		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
 		openConnectionsGauge:           multi.NewGauge(openConnectionsGauge...),
 		tlsCertsNotAfterTimestampGauge: multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
+		acmeCertificateEventsCounter:   multi.NewCounter(acmeCertificateEventsCounter...),
 		entryPointReqsCounter:          NewMultiCounterWithHeaders(entryPointReqsCounter...),
 		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
 		entryPointReqDurationHistogram: MultiHistogram(entryPointReqDurationHistogram),
@@ -179,6 +198,8 @@ func NewMultiRegistry(registries []Registry) Registry {
 		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
 		serviceReqsBytesCounter:        multi.NewCounter(serviceReqsBytesCounter...),
 		serviceRespsBytesCounter:       multi.NewCounter(serviceRespsBytesCounter...),
+		cacheHitsCounter:               multi.NewCounter(cacheHitsCounter...),
+		cacheMissesCounter:             multi.NewCounter(cacheMissesCounter...),
 	}
 }
 
@@ -190,6 +211,7 @@ type standardRegistry struct {
 	lastConfigReloadSuccessGauge   metrics.Gauge
 	openConnectionsGauge           metrics.Gauge
 	tlsCertsNotAfterTimestampGauge metrics.Gauge
+	acmeCertificateEventsCounter   metrics.Counter
 	entryPointReqsCounter          CounterWithHeaders
 	entryPointReqsTLSCounter       metrics.Counter
 	entryPointReqDurationHistogram ScalableHistogram
@@ -207,6 +229,8 @@ type standardRegistry struct {
 	serviceServerUpGauge           metrics.Gauge
 	serviceReqsBytesCounter        metrics.Counter
 	serviceRespsBytesCounter       metrics.Counter
+	cacheHitsCounter               metrics.Counter
+	cacheMissesCounter             metrics.Counter
 }
 
 func (r *standardRegistry) IsEpEnabled() bool {
@@ -237,6 +261,10 @@ func (r *standardRegistry) TLSCertsNotAfterTimestampGauge() metrics.Gauge {
 	return r.tlsCertsNotAfterTimestampGauge
 }
 
+func (r *standardRegistry) ACMECertificateEventsCounter() metrics.Counter {
+	return r.acmeCertificateEventsCounter
+}
+
 func (r *standardRegistry) EntryPointReqsCounter() CounterWithHeaders {
 	return r.entryPointReqsCounter
 }
@@ -305,6 +333,14 @@ func (r *standardRegistry) ServiceRespsBytesCounter() metrics.Counter {
 	return r.serviceRespsBytesCounter
 }
 
+func (r *standardRegistry) CacheHitsCounter() metrics.Counter {
+	return r.cacheHitsCounter
+}
+
+func (r *standardRegistry) CacheMissesCounter() metrics.Counter {
+	return r.cacheMissesCounter
+}
+
 // ScalableHistogram is a Histogram with a predefined time unit,
 // used when producing observations without explicitly setting the observed value.
 type ScalableHistogram interface {
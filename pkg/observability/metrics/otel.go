@@ -126,6 +126,9 @@ func RegisterOpenTelemetry(ctx context.Context, config *otypes.OTLP) Registry {
 		lastConfigReloadSuccessGauge:   newOTLPGaugeFrom(meter, configLastReloadSuccessName, "Last config reload success", "ms"),
 		openConnectionsGauge:           newOTLPGaugeFrom(meter, openConnectionsName, "How many open connections exist, by entryPoint and protocol", "1"),
 		tlsCertsNotAfterTimestampGauge: newOTLPGaugeFrom(meter, tlsCertsNotAfterTimestampName, "Certificate expiration timestamp", "s"),
+		acmeCertificateEventsCounter:   newOTLPCounterFrom(meter, acmeCertificateEventsTotalName, "How many ACME certificate lifecycle events occurred, by resolver and event."),
+		cacheHitsCounter:               newOTLPCounterFrom(meter, cacheHitsTotalName, "How many requests were served from the cache, by middleware."),
+		cacheMissesCounter:             newOTLPCounterFrom(meter, cacheMissesTotalName, "How many requests were forwarded because of a cache miss, by middleware."),
 	}
 
 	if config.AddEntryPointsLabels {
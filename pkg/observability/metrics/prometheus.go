@@ -27,8 +27,9 @@ const (
 	openConnectionsName         = MetricNamePrefix + "open_connections"
 
 	// TLS.
-	metricsTLSPrefix              = MetricNamePrefix + "tls_"
-	tlsCertsNotAfterTimestampName = metricsTLSPrefix + "certs_not_after"
+	metricsTLSPrefix               = MetricNamePrefix + "tls_"
+	tlsCertsNotAfterTimestampName  = metricsTLSPrefix + "certs_not_after"
+	acmeCertificateEventsTotalName = metricsTLSPrefix + "acme_certificate_events_total"
 
 	// entry point.
 	metricEntryPointPrefix        = MetricNamePrefix + "entrypoint_"
@@ -55,6 +56,11 @@ const (
 	serviceServerUpName        = metricServicePrefix + "server_up"
 	serviceReqsBytesTotalName  = metricServicePrefix + "requests_bytes_total"
 	serviceRespsBytesTotalName = metricServicePrefix + "responses_bytes_total"
+
+	// middleware level.
+	metricMiddlewarePrefix = MetricNamePrefix + "middleware_"
+	cacheHitsTotalName     = metricMiddlewarePrefix + "cache_hits_total"
+	cacheMissesTotalName   = metricMiddlewarePrefix + "cache_misses_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -120,16 +126,31 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 		Name: tlsCertsNotAfterTimestampName,
 		Help: "Certificate expiration timestamp",
 	}, []string{"cn", "serial", "sans"})
+	acmeCertificateEvents := newCounterFrom(stdprometheus.CounterOpts{
+		Name: acmeCertificateEventsTotalName,
+		Help: "How many ACME certificate lifecycle events occurred, by resolver and event.",
+	}, []string{"resolver", "event"})
 	openConnections := newGaugeFrom(stdprometheus.GaugeOpts{
 		Name: openConnectionsName,
 		Help: "How many open connections exist, by entryPoint and protocol",
 	}, []string{"entrypoint", "protocol"})
+	cacheHits := newCounterFrom(stdprometheus.CounterOpts{
+		Name: cacheHitsTotalName,
+		Help: "How many requests were served from the cache, by middleware.",
+	}, []string{"middleware"})
+	cacheMisses := newCounterFrom(stdprometheus.CounterOpts{
+		Name: cacheMissesTotalName,
+		Help: "How many requests were forwarded because of a cache miss, by middleware.",
+	}, []string{"middleware"})
 
 	promState.vectors = []vector{
 		configReloads.cv,
 		lastConfigReloadSuccess.gv,
 		tlsCertsNotAfterTimestamp.gv,
+		acmeCertificateEvents.cv,
 		openConnections.gv,
+		cacheHits.cv,
+		cacheMisses.cv,
 	}
 
 	reg := &standardRegistry{
@@ -139,7 +160,10 @@ func initStandardRegistry(config *otypes.Prometheus) Registry {
 		configReloadsCounter:           configReloads,
 		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
 		tlsCertsNotAfterTimestampGauge: tlsCertsNotAfterTimestamp,
+		acmeCertificateEventsCounter:   acmeCertificateEvents,
 		openConnectionsGauge:           openConnections,
+		cacheHitsCounter:               cacheHits,
+		cacheMissesCounter:             cacheMisses,
 	}
 
 	if config.AddEntryPointsLabels {
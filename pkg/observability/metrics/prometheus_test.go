@@ -181,6 +181,20 @@ func TestPrometheus(t *testing.T) {
 		With("service", "service1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http").
 		Add(1)
 
+	prometheusRegistry.
+		CacheHitsCounter().
+		With("middleware", "cache@file").
+		Add(1)
+	prometheusRegistry.
+		CacheMissesCounter().
+		With("middleware", "cache@file").
+		Add(1)
+
+	prometheusRegistry.
+		ACMECertificateEventsCounter().
+		With("resolver", "myresolver", "event", "renewed").
+		Add(1)
+
 	delayForTrackingCompletion()
 
 	metricsFamilies := mustScrape()
@@ -376,6 +390,28 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildCounterAssert(t, serviceRespsBytesTotalName, 1),
 		},
+		{
+			name: cacheHitsTotalName,
+			labels: map[string]string{
+				"middleware": "cache@file",
+			},
+			assert: buildCounterAssert(t, cacheHitsTotalName, 1),
+		},
+		{
+			name: cacheMissesTotalName,
+			labels: map[string]string{
+				"middleware": "cache@file",
+			},
+			assert: buildCounterAssert(t, cacheMissesTotalName, 1),
+		},
+		{
+			name: acmeCertificateEventsTotalName,
+			labels: map[string]string{
+				"resolver": "myresolver",
+				"event":    "renewed",
+			},
+			assert: buildCounterAssert(t, acmeCertificateEventsTotalName, 1),
+		},
 	}
 
 	for _, test := range testCases {
@@ -22,6 +22,7 @@ const (
 	statsdOpenConnectionsName         = "open.connections"
 
 	statsdTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
+	statsdACMECertificateEventsName     = "tls.acme.certificate.events.total"
 
 	statsdEntryPointReqsName        = "entrypoint.request.total"
 	statsdEntryPointReqsTLSName     = "entrypoint.request.tls.total"
@@ -42,6 +43,9 @@ const (
 	statsdServiceServerUpName     = "service.server.up"
 	statsdServiceReqsBytesName    = "service.requests.bytes.total"
 	statsdServiceRespsBytesName   = "service.responses.bytes.total"
+
+	statsdCacheHitsName   = "middleware.cache.hits.total"
+	statsdCacheMissesName = "middleware.cache.misses.total"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -61,7 +65,10 @@ func RegisterStatsd(ctx context.Context, config *otypes.Statsd) Registry {
 		configReloadsCounter:           statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
 		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
 		tlsCertsNotAfterTimestampGauge: statsdClient.NewGauge(statsdTLSCertsNotAfterTimestampName),
+		acmeCertificateEventsCounter:   statsdClient.NewCounter(statsdACMECertificateEventsName, 1.0),
 		openConnectionsGauge:           statsdClient.NewGauge(statsdOpenConnectionsName),
+		cacheHitsCounter:               statsdClient.NewCounter(statsdCacheHitsName, 1.0),
+		cacheMissesCounter:             statsdClient.NewCounter(statsdCacheMissesName, 1.0),
 	}
 
 	if config.AddEntryPointsLabels {
@@ -0,0 +1,38 @@
+package acme
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Certificate lifecycle events.
+const (
+	certificateObtained = "obtained"
+	certificateRenewed  = "renewed"
+	certificateFailed   = "failed"
+	certificateExpiring = "expiring"
+)
+
+// certificateEvent counts the given lifecycle event of the certificate of the domains,
+// and returns the log event reporting it, to be completed and sent by the caller.
+func (p *Provider) certificateEvent(ctx context.Context, event string, domains []string) *zerolog.Event {
+	if p.metricsRegistry != nil {
+		p.metricsRegistry.ACMECertificateEventsCounter().With("resolver", p.ResolverName, "event", event).Add(1)
+	}
+
+	logger := log.Ctx(ctx)
+
+	var logEvent *zerolog.Event
+	switch event {
+	case certificateFailed:
+		logEvent = logger.Error()
+	case certificateExpiring:
+		logEvent = logger.Warn()
+	default:
+		logEvent = logger.Info()
+	}
+
+	return logEvent.Str("certificateEvent", event).Strs("domains", domains)
+}
@@ -0,0 +1,127 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kvtools/valkeyrie/store"
+	"github.com/rs/zerolog/log"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+// LeaderElection holds the configuration of the leader election between the instances sharing a storage.
+type LeaderElection struct {
+	LeaseDuration ptypes.Duration `description:"Duration after which the leadership of an unresponsive leader is taken over." json:"leaseDuration,omitempty" toml:"leaseDuration,omitempty" yaml:"leaseDuration,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (l *LeaderElection) SetDefaults() {
+	l.LeaseDuration = ptypes.Duration(15 * time.Second)
+}
+
+// elector elects a leader among the instances sharing a storage.
+type elector interface {
+	// run takes part in the election of the leader of the resolver until the context is done,
+	// calling onLeadership each time the instance gains or loses the leadership.
+	run(ctx context.Context, resolverName string, onLeadership func(leader bool))
+}
+
+// leaseElector elects the leader with a lease, renewed by the leader a few times per lease duration.
+type leaseElector struct {
+	leases   leaseBackend
+	holder   string
+	duration time.Duration
+}
+
+func (e *leaseElector) run(ctx context.Context, resolverName string, onLeadership func(leader bool)) {
+	key := resolverName + "-leader"
+
+	ticker := time.NewTicker(e.duration / 3)
+	defer ticker.Stop()
+
+	var leader bool
+	for {
+		err := holdLease(ctx, e.leases, key, e.holder, e.duration)
+		if err != nil && !errors.Is(err, ErrLeaseHeld) && ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("Unable to renew the ACME leader lease")
+		}
+
+		if (err == nil) != leader {
+			leader = err == nil
+			onLeadership(leader)
+		}
+
+		select {
+		case <-ctx.Done():
+			if leader {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), sharedStoreTimeout)
+				_ = releaseLease(releaseCtx, e.leases, key, e.holder)
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lockElector elects the leader with the native lock of a KV store,
+// such as a Consul session or a Redis lock, kept by the leader until it stops.
+type lockElector struct {
+	backend *kvBackend
+	holder  string
+	ttl     time.Duration
+}
+
+func (e *lockElector) run(ctx context.Context, resolverName string, onLeadership func(leader bool)) {
+	logger := log.Ctx(ctx)
+
+	for ctx.Err() == nil {
+		lost, unlock, err := e.lock(ctx, resolverName)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn().Err(err).Msg("Unable to acquire the ACME leader lock")
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(e.ttl / 3):
+			}
+			continue
+		}
+
+		onLeadership(true)
+
+		select {
+		case <-ctx.Done():
+		case <-lost:
+			onLeadership(false)
+		}
+
+		unlock()
+	}
+}
+
+// lock blocks until the leader lock is acquired, and returns a channel closed when the lock is lost.
+func (e *lockElector) lock(ctx context.Context, resolverName string) (<-chan struct{}, func(), error) {
+	locker, err := e.backend.client.NewLock(ctx, e.backend.path("leaders/"+resolverName), &store.LockOptions{
+		Value:     []byte(e.holder),
+		TTL:       e.ttl,
+		RenewLock: make(chan struct{}),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lost, err := locker.Lock(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return lost, func() {
+		unlockCtx, cancel := context.WithTimeout(context.Background(), sharedStoreTimeout)
+		defer cancel()
+
+		_ = locker.Unlock(unlockCtx)
+	}, nil
+}
@@ -0,0 +1,77 @@
+package acme
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaseElector(t *testing.T) {
+	backend := newMemoryBackend()
+	leases := valueLeases{backend: backend}
+
+	first := &leaseElector{leases: leases, holder: "first", duration: 300 * time.Millisecond}
+	second := &leaseElector{leases: leases, holder: "second", duration: 300 * time.Millisecond}
+
+	firstLeadership := make(chan bool, 10)
+	secondLeadership := make(chan bool, 10)
+
+	firstCtx, stopFirst := context.WithCancel(t.Context())
+	firstDone := make(chan struct{})
+	go func() {
+		first.run(firstCtx, "test", func(leader bool) { firstLeadership <- leader })
+		close(firstDone)
+	}()
+
+	require.True(t, receive(t, firstLeadership))
+
+	go second.run(t.Context(), "test", func(leader bool) { secondLeadership <- leader })
+
+	// The leadership is kept while the leader renews its lease.
+	select {
+	case leader := <-secondLeadership:
+		t.Fatalf("unexpected leadership change of the second instance: %t", leader)
+	case <-time.After(time.Second):
+	}
+
+	// The leadership is released when the leader stops.
+	stopFirst()
+	<-firstDone
+
+	assert.True(t, receive(t, secondLeadership))
+}
+
+func TestProvider_acquireLease_follower(t *testing.T) {
+	backend := newMemoryBackend()
+
+	p := &Provider{
+		Configuration: &Configuration{},
+		ResolverName:  "test",
+		Store:         newSharedStore(backend, valueLeases{backend: backend}, time.Minute),
+		electing:      true,
+	}
+
+	_, ok := p.acquireLease(t.Context(), []string{"foo.localhost"})
+	assert.False(t, ok)
+
+	p.leader.Store(true)
+
+	release, ok := p.acquireLease(t.Context(), []string{"foo.localhost"})
+	require.True(t, ok)
+	release()
+}
+
+func receive(t *testing.T, leadership <-chan bool) bool {
+	t.Helper()
+
+	select {
+	case leader := <-leadership:
+		return leader
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for a leadership change")
+		return false
+	}
+}
@@ -0,0 +1,276 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/rs/zerolog/log"
+	ptypes "github.com/traefik/paerser/types"
+	traefiktls "github.com/traefik/traefik/v3/pkg/tls"
+	"github.com/traefik/traefik/v3/pkg/types"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
+)
+
+const (
+	onDemandAskTimeout = 5 * time.Second
+	// onDemandDeniedTTL is the duration during which a server name which has not been approved is not submitted again.
+	onDemandDeniedTTL = time.Minute
+	// onDemandAskInterval and onDemandAskBurst bound the calls to the ask URL,
+	// as the handshakes with random server names would otherwise all trigger one.
+	onDemandAskInterval = 100 * time.Millisecond
+	onDemandAskBurst    = 10
+)
+
+var (
+	errOnDemandDenied      = errors.New("server name not approved")
+	errOnDemandRateLimited = errors.New("on-demand certificates rate limit reached")
+	errOnDemandAskLimited  = errors.New("on-demand ask URL rate limit reached")
+
+	// onDemandServerName matches the server names eligible to an on-demand certificate:
+	// fully qualified domain names, without wildcard.
+	onDemandServerName = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)+$`)
+)
+
+// OnDemand holds the configuration of the certificates obtained during the TLS handshakes,
+// for the server names matching none of the certificates.
+type OnDemand struct {
+	Ask                 string          `description:"URL called with the domain query parameter to approve a server name. The name is approved when it responds with a 200 status code." json:"ask,omitempty" toml:"ask,omitempty" yaml:"ask,omitempty"`
+	AllowedDomainsRegex []string        `description:"Regular expressions matching the server names approved without calling the ask URL." json:"allowedDomainsRegex,omitempty" toml:"allowedDomainsRegex,omitempty" yaml:"allowedDomainsRegex,omitempty"`
+	Average             int64           `description:"Maximum number of certificates obtained on demand per period. Zero means no limit." json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	Period              ptypes.Duration `description:"Period of the rate limit of the certificates obtained on demand." json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	Burst               int64           `description:"Maximum number of certificates obtained on demand at once." json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+	Timeout             ptypes.Duration `description:"Maximum duration a TLS handshake waits for its certificate, which is still obtained afterward." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (o *OnDemand) SetDefaults() {
+	o.Average = 10
+	o.Period = ptypes.Duration(time.Minute)
+	o.Burst = 10
+	o.Timeout = ptypes.Duration(30 * time.Second)
+}
+
+// onDemandIssuer approves the server names submitted during the TLS handshakes, and obtains their certificate.
+// Concurrent handshakes for the same server name share the same approval and certificate request.
+type onDemandIssuer struct {
+	ctx     context.Context
+	ask     string
+	allowed []*regexp.Regexp
+	timeout time.Duration
+	client  *http.Client
+	limiter *rate.Limiter
+	denied  *cache.Cache
+	group   singleflight.Group
+
+	// askLimiter bounds the calls to the ask URL, independently of the limiter of the certificates.
+	askLimiter *rate.Limiter
+
+	obtain func(ctx context.Context, serverName string) error
+}
+
+func newOnDemandIssuer(ctx context.Context, config *OnDemand, obtain func(ctx context.Context, serverName string) error) (*onDemandIssuer, error) {
+	if config.Ask == "" && len(config.AllowedDomainsRegex) == 0 {
+		return nil, errors.New("an ask URL or allowed domains regular expressions are required")
+	}
+
+	if config.Ask != "" {
+		if _, err := url.ParseRequestURI(config.Ask); err != nil {
+			return nil, fmt.Errorf("parsing ask URL: %w", err)
+		}
+	}
+
+	var allowed []*regexp.Regexp
+	for _, expr := range config.AllowedDomainsRegex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("compiling allowed domains regular expression %q: %w", expr, err)
+		}
+
+		allowed = append(allowed, re)
+	}
+
+	limit := rate.Inf
+	if config.Average > 0 && config.Period > 0 {
+		limit = rate.Every(time.Duration(config.Period) / time.Duration(config.Average))
+	}
+
+	return &onDemandIssuer{
+		ctx:        ctx,
+		ask:        config.Ask,
+		allowed:    allowed,
+		timeout:    time.Duration(config.Timeout),
+		client:     &http.Client{Timeout: onDemandAskTimeout},
+		limiter:    rate.NewLimiter(limit, int(max(config.Burst, 1))),
+		askLimiter: rate.NewLimiter(rate.Every(onDemandAskInterval), onDemandAskBurst),
+		denied:     cache.New(onDemandDeniedTTL, onDemandDeniedTTL),
+		obtain:     obtain,
+	}, nil
+}
+
+// issue obtains the certificate of the server name if it is approved,
+// and returns whether it has been obtained before the timeout.
+func (o *onDemandIssuer) issue(ctx context.Context, serverName string) bool {
+	results := o.group.DoChan(serverName, func() (any, error) {
+		return nil, o.approveAndObtain(serverName)
+	})
+
+	timer := time.NewTimer(o.timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-results:
+		return result.Err == nil
+	case <-timer.C:
+		log.Ctx(o.ctx).Debug().Str("serverName", serverName).Msg("On-demand certificate not obtained in time for the TLS handshake")
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (o *onDemandIssuer) approveAndObtain(serverName string) error {
+	logger := log.Ctx(o.ctx).With().Str("serverName", serverName).Logger()
+
+	if _, denied := o.denied.Get(serverName); denied {
+		return errOnDemandDenied
+	}
+
+	approved, err := o.approve(serverName)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Server name not submitted to the on-demand ask URL")
+		return err
+	}
+
+	if !approved {
+		logger.Debug().Msg("Server name not approved for an on-demand certificate")
+		o.denied.SetDefault(serverName, struct{}{})
+		return errOnDemandDenied
+	}
+
+	if !o.limiter.Allow() {
+		logger.Warn().Msg("Rate limit of the on-demand certificates reached, the certificate is not requested")
+		return errOnDemandRateLimited
+	}
+
+	return o.obtain(o.ctx, serverName)
+}
+
+// approve returns whether the server name is approved.
+// It returns an error when the ask URL cannot be called yet, in which case the server name is neither approved nor denied.
+func (o *onDemandIssuer) approve(serverName string) (bool, error) {
+	if slices.ContainsFunc(o.allowed, func(re *regexp.Regexp) bool { return re.MatchString(serverName) }) {
+		return true, nil
+	}
+
+	if o.ask == "" {
+		return false, nil
+	}
+
+	if !o.askLimiter.Allow() {
+		return false, errOnDemandAskLimited
+	}
+
+	askURL, err := url.Parse(o.ask)
+	if err != nil {
+		return false, nil
+	}
+
+	query := askURL.Query()
+	query.Set("domain", serverName)
+	askURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(o.ctx, http.MethodGet, askURL.String(), http.NoBody)
+	if err != nil {
+		return false, nil
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		log.Ctx(o.ctx).Error().Err(err).Str("serverName", serverName).Msg("Unable to call the on-demand ask URL")
+		return false, nil
+	}
+	_ = resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// GetOnDemandCertificate returns the certificate of the server name of the TLS handshake,
+// obtained on demand if it is approved.
+func (p *Provider) GetOnDemandCertificate(clientHello *tls.ClientHelloInfo) *tls.Certificate {
+	// The followers of a leader election leave the certificates to the leader.
+	if p.onDemand == nil || p.isFollower() {
+		return nil
+	}
+
+	serverName := types.CanonicalDomain(clientHello.ServerName)
+	if len(serverName) > 253 || !onDemandServerName.MatchString(serverName) {
+		return nil
+	}
+
+	// The certificate may have been obtained but not yet loaded by the TLS manager.
+	if cert := p.getCertificate(serverName); cert != nil {
+		return cert
+	}
+
+	if !p.onDemand.issue(clientHello.Context(), serverName) {
+		return nil
+	}
+
+	return p.getCertificate(serverName)
+}
+
+// obtainOnDemand obtains the certificate of the server name, and adds it to the default TLS store.
+func (p *Provider) obtainOnDemand(ctx context.Context, serverName string) error {
+	domain, cert, err := p.resolveCertificate(ctx, types.Domain{Main: serverName}, traefiktls.DefaultTLSStoreName)
+	if err != nil {
+		return err
+	}
+
+	return p.addCertificateForDomain(domain, cert, traefiktls.DefaultTLSStoreName)
+}
+
+// parsedCertificate is an ACME certificate parsed for the TLS handshakes.
+type parsedCertificate struct {
+	certificate []byte
+	tlsCert     *tls.Certificate
+}
+
+// getCertificate returns the ACME certificate which main domain or SANs contain the server name.
+// The parsed certificates are cached until they are renewed, as they are requested on every TLS handshake reaching the on-demand resolver.
+func (p *Provider) getCertificate(serverName string) *tls.Certificate {
+	p.certificatesMu.RLock()
+	defer p.certificatesMu.RUnlock()
+
+	for _, cert := range p.certificates {
+		if !slices.Contains(cert.Domain.ToStrArray(), serverName) {
+			continue
+		}
+
+		if cached, ok := p.onDemandCertificates.Load(serverName); ok {
+			if parsed := cached.(*parsedCertificate); bytes.Equal(parsed.certificate, cert.Certificate.Certificate) {
+				return parsed.tlsCert
+			}
+		}
+
+		tlsCert, err := tls.X509KeyPair(cert.Certificate.Certificate, cert.Key)
+		if err != nil {
+			return nil
+		}
+
+		p.onDemandCertificates.Store(serverName, &parsedCertificate{certificate: cert.Certificate.Certificate, tlsCert: &tlsCert})
+
+		return &tlsCert
+	}
+
+	return nil
+}
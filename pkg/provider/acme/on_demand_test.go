@@ -0,0 +1,232 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"golang.org/x/time/rate"
+)
+
+func TestNewOnDemandIssuer(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        OnDemand
+		expectedError bool
+	}{
+		{
+			desc:          "no ask URL nor allowed domains",
+			expectedError: true,
+		},
+		{
+			desc:   "ask URL",
+			config: OnDemand{Ask: "http://127.0.0.1:8080/ask"},
+		},
+		{
+			desc:          "invalid ask URL",
+			config:        OnDemand{Ask: "foo"},
+			expectedError: true,
+		},
+		{
+			desc:   "allowed domains",
+			config: OnDemand{AllowedDomainsRegex: []string{`^[a-z]+\.example\.com$`}},
+		},
+		{
+			desc:          "invalid allowed domains",
+			config:        OnDemand{AllowedDomainsRegex: []string{`^[a-z+\.example\.com$`}},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newOnDemandIssuer(t.Context(), &test.config, nil)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOnDemandIssuer_issue(t *testing.T) {
+	var askCalls atomic.Int32
+	ask := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		askCalls.Add(1)
+
+		if req.URL.Query().Get("domain") != "approved.example.org" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}))
+	t.Cleanup(ask.Close)
+
+	config := &OnDemand{}
+	config.SetDefaults()
+	config.Ask = ask.URL
+	config.AllowedDomainsRegex = []string{`^[a-z]+\.example\.com$`}
+
+	var obtained []string
+	issuer, err := newOnDemandIssuer(t.Context(), config, func(_ context.Context, serverName string) error {
+		obtained = append(obtained, serverName)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.True(t, issuer.issue(t.Context(), "foo.example.com"))
+	assert.Equal(t, int32(0), askCalls.Load())
+
+	assert.True(t, issuer.issue(t.Context(), "approved.example.org"))
+	assert.Equal(t, int32(1), askCalls.Load())
+
+	assert.False(t, issuer.issue(t.Context(), "denied.example.org"))
+	assert.Equal(t, int32(2), askCalls.Load())
+
+	// The denied server names are not submitted again to the ask URL.
+	assert.False(t, issuer.issue(t.Context(), "denied.example.org"))
+	assert.Equal(t, int32(2), askCalls.Load())
+
+	assert.Equal(t, []string{"foo.example.com", "approved.example.org"}, obtained)
+}
+
+func TestOnDemandIssuer_issue_concurrent(t *testing.T) {
+	config := &OnDemand{AllowedDomainsRegex: []string{`\.example\.com$`}}
+	config.SetDefaults()
+
+	var obtainCalls atomic.Int32
+	release := make(chan struct{})
+	issuer, err := newOnDemandIssuer(t.Context(), config, func(_ context.Context, _ string) error {
+		obtainCalls.Add(1)
+		<-release
+		return nil
+	})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	results := make([]bool, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = issuer.issue(t.Context(), "foo.example.com")
+		}()
+	}
+
+	// Lets the handshakes join the pending certificate request.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), obtainCalls.Load())
+	for _, result := range results {
+		assert.True(t, result)
+	}
+}
+
+func TestOnDemandIssuer_issue_rateLimit(t *testing.T) {
+	config := &OnDemand{
+		AllowedDomainsRegex: []string{`\.example\.com$`},
+		Average:             1,
+		Period:              ptypes.Duration(time.Hour),
+		Burst:               1,
+		Timeout:             ptypes.Duration(time.Second),
+	}
+
+	var obtained []string
+	issuer, err := newOnDemandIssuer(t.Context(), config, func(_ context.Context, serverName string) error {
+		obtained = append(obtained, serverName)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.True(t, issuer.issue(t.Context(), "foo.example.com"))
+	assert.False(t, issuer.issue(t.Context(), "bar.example.com"))
+
+	assert.Equal(t, []string{"foo.example.com"}, obtained)
+}
+
+func TestOnDemandIssuer_issue_askRateLimit(t *testing.T) {
+	var askCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		askCalls.Add(1)
+		rw.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	config := &OnDemand{Ask: server.URL}
+	config.SetDefaults()
+
+	issuer, err := newOnDemandIssuer(t.Context(), config, func(_ context.Context, _ string) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	issuer.askLimiter = rate.NewLimiter(rate.Every(time.Hour), 5)
+
+	// Handshakes with random server names are not all submitted to the ask URL.
+	for i := range 100 {
+		assert.False(t, issuer.issue(t.Context(), fmt.Sprintf("random-%d.example.org", i)))
+	}
+
+	assert.Equal(t, int32(5), askCalls.Load())
+}
+
+func TestOnDemandIssuer_issue_timeout(t *testing.T) {
+	config := &OnDemand{
+		AllowedDomainsRegex: []string{`\.example\.com$`},
+		Timeout:             ptypes.Duration(10 * time.Millisecond),
+	}
+
+	done := make(chan string, 1)
+	release := make(chan struct{})
+	issuer, err := newOnDemandIssuer(t.Context(), config, func(_ context.Context, serverName string) error {
+		<-release
+		done <- serverName
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.False(t, issuer.issue(t.Context(), "foo.example.com"))
+
+	// The certificate is still obtained after the handshake gave up waiting.
+	close(release)
+
+	select {
+	case serverName := <-done:
+		assert.Equal(t, "foo.example.com", serverName)
+	case <-time.After(time.Second):
+		t.Fatal("certificate not obtained after the timeout")
+	}
+}
+
+func TestProvider_getCertificate(t *testing.T) {
+	cert := newTestCertificate(t, "foo.example.com", time.Now().Add(time.Hour))
+	p := &Provider{certificates: []*CertAndStore{cert}}
+
+	tlsCert := p.getCertificate("foo.example.com")
+	require.NotNil(t, tlsCert)
+
+	// The certificate is only parsed once.
+	assert.Same(t, tlsCert, p.getCertificate("foo.example.com"))
+	assert.Nil(t, p.getCertificate("bar.example.com"))
+
+	// The renewed certificate is parsed again.
+	cert.Certificate = newTestCertificate(t, "foo.example.com", time.Now().Add(2*time.Hour)).Certificate
+
+	renewed := p.getCertificate("foo.example.com")
+	require.NotNil(t, renewed)
+	assert.NotSame(t, tlsCert, renewed)
+	assert.NotEqual(t, tlsCert.Certificate, renewed.Certificate)
+}
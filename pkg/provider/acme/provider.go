@@ -16,9 +16,12 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/acme/api"
+	"github.com/go-acme/lego/v5/certcrypto"
 	"github.com/go-acme/lego/v5/certificate"
 	"github.com/go-acme/lego/v5/challenge"
 	"github.com/go-acme/lego/v5/challenge/dns01"
@@ -33,6 +36,7 @@ import (
 	httpmuxer "github.com/traefik/traefik/v3/pkg/muxer/http"
 	tcpmuxer "github.com/traefik/traefik/v3/pkg/muxer/tcp"
 	"github.com/traefik/traefik/v3/pkg/observability/logs"
+	"github.com/traefik/traefik/v3/pkg/observability/metrics"
 	"github.com/traefik/traefik/v3/pkg/safe"
 	traefiktls "github.com/traefik/traefik/v3/pkg/tls"
 	"github.com/traefik/traefik/v3/pkg/types"
@@ -41,18 +45,26 @@ import (
 
 const resolverSuffix = ".acme"
 
+// errCertificateNotObtained is returned when the CA does not issue a certificate,
+// which failure has already been reported by the certificate lifecycle event.
+var errCertificateNotObtained = errors.New("unable to obtain ACME certificate")
+
 // Configuration holds ACME configuration provided by users.
 type Configuration struct {
-	Email                string   `description:"Email address used for registration." json:"email,omitempty" toml:"email,omitempty" yaml:"email,omitempty"`
-	CAServer             string   `description:"CA server to use." json:"caServer,omitempty" toml:"caServer,omitempty" yaml:"caServer,omitempty"`
-	PreferredChain       string   `description:"Preferred chain to use." json:"preferredChain,omitempty" toml:"preferredChain,omitempty" yaml:"preferredChain,omitempty" export:"true"`
-	Profile              string   `description:"Certificate profile to use." json:"profile,omitempty" toml:"profile,omitempty" yaml:"profile,omitempty" export:"true"`
-	EmailAddresses       []string `description:"CSR email addresses to use." json:"emailAddresses,omitempty" toml:"emailAddresses,omitempty" yaml:"emailAddresses,omitempty"`
-	DisableCommonName    bool     `description:"Disable the common name in the CSR." json:"disableCommonName,omitempty" toml:"disableCommonName,omitempty" yaml:"disableCommonName,omitempty" export:"true"`
-	Storage              string   `description:"Storage to use." json:"storage,omitempty" toml:"storage,omitempty" yaml:"storage,omitempty" export:"true"`
-	KeyType              string   `description:"KeyType used for generating certificate private key. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'." json:"keyType,omitempty" toml:"keyType,omitempty" yaml:"keyType,omitempty" export:"true"`
-	EAB                  *EAB     `description:"External Account Binding to use." json:"eab,omitempty" toml:"eab,omitempty" yaml:"eab,omitempty"`
-	CertificatesDuration int      `description:"Certificates' duration in hours." json:"certificatesDuration,omitempty" toml:"certificatesDuration,omitempty" yaml:"certificatesDuration,omitempty" export:"true"`
+	Email                string         `description:"Email address used for registration." json:"email,omitempty" toml:"email,omitempty" yaml:"email,omitempty"`
+	CAServer             string         `description:"CA server to use." json:"caServer,omitempty" toml:"caServer,omitempty" yaml:"caServer,omitempty"`
+	PreferredChain       string         `description:"Preferred chain to use." json:"preferredChain,omitempty" toml:"preferredChain,omitempty" yaml:"preferredChain,omitempty" export:"true"`
+	Profile              string         `description:"Certificate profile to use." json:"profile,omitempty" toml:"profile,omitempty" yaml:"profile,omitempty" export:"true"`
+	EmailAddresses       []string       `description:"CSR email addresses to use." json:"emailAddresses,omitempty" toml:"emailAddresses,omitempty" yaml:"emailAddresses,omitempty"`
+	DisableCommonName    bool           `description:"Disable the common name in the CSR." json:"disableCommonName,omitempty" toml:"disableCommonName,omitempty" yaml:"disableCommonName,omitempty" export:"true"`
+	Storage              string         `description:"Storage to use." json:"storage,omitempty" toml:"storage,omitempty" yaml:"storage,omitempty" export:"true"`
+	SharedStorage        *SharedStorage `description:"Storage shared by several Traefik instances, used instead of the storage file." json:"sharedStorage,omitempty" toml:"sharedStorage,omitempty" yaml:"sharedStorage,omitempty" export:"true"`
+	OnDemand             *OnDemand      `description:"Obtains certificates during the TLS handshakes, for the approved server names matching none of the certificates." json:"onDemand,omitempty" toml:"onDemand,omitempty" yaml:"onDemand,omitempty" export:"true"`
+	KeyType              string         `description:"KeyType used for generating certificate private key. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'." json:"keyType,omitempty" toml:"keyType,omitempty" yaml:"keyType,omitempty" export:"true"`
+	EAB                  *EAB           `description:"External Account Binding to use." json:"eab,omitempty" toml:"eab,omitempty" yaml:"eab,omitempty"`
+	CertificatesDuration int            `description:"Certificates' duration in hours." json:"certificatesDuration,omitempty" toml:"certificatesDuration,omitempty" yaml:"certificatesDuration,omitempty" export:"true"`
+	RenewalInfo          bool           `description:"Renew the certificates in the renewal window suggested by the CA with the ACME Renewal Information (ARI), instead of based on their duration only." json:"renewalInfo,omitempty" toml:"renewalInfo,omitempty" yaml:"renewalInfo,omitempty" export:"true"`
+	CheckRevocation      bool           `description:"Check the revocation status of the certificates with OCSP, and renew the revoked ones. It has no effect with the CAs not serving OCSP anymore, such as Let's Encrypt." json:"checkRevocation,omitempty" toml:"checkRevocation,omitempty" yaml:"checkRevocation,omitempty" export:"true"`
 
 	ClientTimeout               ptypes.Duration `description:"Timeout for a complete HTTP transaction with the ACME server." json:"clientTimeout,omitempty" toml:"clientTimeout,omitempty" yaml:"clientTimeout,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	ClientResponseHeaderTimeout ptypes.Duration `description:"Timeout for receiving the response headers when communicating with the ACME server." json:"clientResponseHeaderTimeout,omitempty" toml:"clientResponseHeaderTimeout,omitempty" yaml:"clientResponseHeaderTimeout,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
@@ -145,11 +157,21 @@ type Provider struct {
 	client                 *lego.Client
 	configurationChan      chan<- dynamic.Message
 	tlsManager             *traefiktls.Manager
+	metricsRegistry        metrics.Registry
 	clientMutex            sync.Mutex
 	configFromListenerChan chan dynamic.Configuration
 	pool                   *safe.Pool
 	resolvingDomains       map[string]struct{}
 	resolvingDomainsMutex  sync.RWMutex
+	renewalInfos           renewalInfoCache
+	onDemand               *onDemandIssuer
+	onDemandCertificates   sync.Map // server name -> *parsedCertificate
+
+	// electing is true when the instance takes part in a leader election,
+	// in which case only the leader requests certificates to the CA.
+	electing   bool
+	leader     atomic.Bool
+	lastConfig atomic.Pointer[dynamic.Configuration]
 }
 
 // SetTLSManager sets the tls manager to use.
@@ -157,6 +179,11 @@ func (p *Provider) SetTLSManager(tlsManager *traefiktls.Manager) {
 	p.tlsManager = tlsManager
 }
 
+// SetMetricsRegistry sets the metrics registry publishing the certificate lifecycle events.
+func (p *Provider) SetMetricsRegistry(metricsRegistry metrics.Registry) {
+	p.metricsRegistry = metricsRegistry
+}
+
 // SetConfigListenerChan initializes the configFromListenerChan.
 func (p *Provider) SetConfigListenerChan(configFromListenerChan chan dynamic.Configuration) {
 	p.configFromListenerChan = configFromListenerChan
@@ -206,6 +233,13 @@ func (p *Provider) Init() error {
 	// Init the currently resolved domain map
 	p.resolvingDomains = make(map[string]struct{})
 
+	if p.OnDemand != nil {
+		p.onDemand, err = newOnDemandIssuer(logger.WithContext(context.Background()), p.OnDemand, p.obtainOnDemand)
+		if err != nil {
+			return fmt.Errorf("unable to initialize on-demand certificates: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -241,6 +275,9 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 
 	p.pool = pool
 
+	elector, ok := p.Store.(Elector)
+	p.electing = ok && p.SharedStorage != nil && p.SharedStorage.LeaderElection != nil
+
 	p.watchNewDomains(ctx)
 
 	p.configurationChan = configurationChan
@@ -255,14 +292,14 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	logger.Debug().Msgf("Attempt to renew certificates %q before expiry and check every %q",
 		renewPeriod, renewInterval)
 
-	p.renewCertificates(ctx, renewPeriod)
+	p.renewCertificates(ctx, renewPeriod, renewInterval)
 
 	ticker := time.NewTicker(renewInterval)
 	pool.GoCtx(func(ctxPool context.Context) {
 		for {
 			select {
 			case <-ticker.C:
-				p.renewCertificates(ctx, renewPeriod)
+				p.renewCertificates(ctx, renewPeriod, renewInterval)
 			case <-ctxPool.Done():
 				ticker.Stop()
 				return
@@ -270,9 +307,117 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 		}
 	})
 
+	if p.electing {
+		pool.GoCtx(func(ctxPool context.Context) {
+			elector.RunElection(logger.WithContext(ctxPool), p.ResolverName, func(leader bool) {
+				p.onLeadership(ctx, leader, renewPeriod, renewInterval)
+			})
+		})
+	}
+
+	if p.SharedStorage != nil && p.SharedStorage.SyncInterval > 0 {
+		syncTicker := time.NewTicker(time.Duration(p.SharedStorage.SyncInterval))
+		pool.GoCtx(func(ctxPool context.Context) {
+			for {
+				select {
+				case <-syncTicker.C:
+					p.syncCertificates(ctx)
+				case <-ctxPool.Done():
+					syncTicker.Stop()
+					return
+				}
+			}
+		})
+	}
+
 	return nil
 }
 
+// onLeadership is called when the instance becomes, or stops being, the leader of the instances sharing the storage.
+func (p *Provider) onLeadership(ctx context.Context, leader bool, renewPeriod, renewInterval time.Duration) {
+	p.leader.Store(leader)
+
+	logger := log.Ctx(ctx)
+	if !leader {
+		logger.Info().Msg("No longer the ACME leader, certificates are requested by the new leader")
+		return
+	}
+
+	logger.Info().Msg("Elected ACME leader, certificates are requested by this instance")
+
+	// Catch up with the certificates the previous leader did not get.
+	safe.Go(func() {
+		p.syncCertificates(ctx)
+		p.renewCertificates(ctx, renewPeriod, renewInterval)
+
+		if config := p.lastConfig.Load(); config != nil {
+			p.ListenConfiguration(*config)
+		}
+	})
+}
+
+// isFollower returns whether the certificates are requested by another instance, elected as leader.
+func (p *Provider) isFollower() bool {
+	return p.electing && !p.leader.Load()
+}
+
+// syncCertificates loads the certificates obtained or renewed by the other instances sharing the storage.
+func (p *Provider) syncCertificates(ctx context.Context) {
+	certificates, err := p.Store.GetCertificates(p.ResolverName)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to load ACME certificates from the shared storage")
+		return
+	}
+
+	p.certificatesMu.Lock()
+
+	merged, updated := mergeCertificates(p.certificates, certificates)
+	if !updated {
+		p.certificatesMu.Unlock()
+		return
+	}
+
+	p.certificates = merged
+	msg := p.buildMessage()
+
+	p.certificatesMu.Unlock()
+
+	// The message is sent outside the lock, as the configuration channel may block.
+	p.configurationChan <- msg
+}
+
+// acquireLease acquires the lease of the given domains when the storage is shared with other instances,
+// and loads the certificates they may have obtained in the meantime.
+// It returns false if the certificate of the domains is being obtained or renewed by another instance.
+func (p *Provider) acquireLease(ctx context.Context, domains []string) (func(), bool) {
+	if p.isFollower() {
+		log.Ctx(ctx).Debug().Strs("domains", domains).Msg("ACME certificate is handled by the leader")
+		return nil, false
+	}
+
+	leaser, ok := p.Store.(Leaser)
+	if !ok {
+		return func() {}, true
+	}
+
+	sortedDomains := slices.Clone(domains)
+	slices.Sort(sortedDomains)
+
+	release, err := leaser.AcquireLease(ctx, p.ResolverName, strings.Join(sortedDomains, ","))
+	if errors.Is(err, ErrLeaseHeld) {
+		log.Ctx(ctx).Debug().Strs("domains", domains).Msg("ACME certificate is handled by another instance")
+		return nil, false
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Strs("domains", domains).Msg("Unable to acquire ACME lease")
+		return nil, false
+	}
+
+	p.syncCertificates(ctx)
+
+	return release, true
+}
+
 func (p *Provider) getClient() (*lego.Client, error) {
 	p.clientMutex.Lock()
 	defer p.clientMutex.Unlock()
@@ -500,7 +645,9 @@ func (p *Provider) resolveDomains(ctx context.Context, domains []string, tlsStor
 		safe.Go(func() {
 			dom, cert, err := p.resolveCertificate(ctx, domain, tlsStore)
 			if err != nil {
-				logger.Error().Err(err).Strs("domains", domains).Msg("Unable to obtain ACME certificate for domains")
+				if !errors.Is(err, errCertificateNotObtained) {
+					logger.Error().Err(err).Strs("domains", domains).Msg("Unable to obtain ACME certificate for domains")
+				}
 				return
 			}
 
@@ -520,6 +667,8 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 		for {
 			select {
 			case config := <-p.configFromListenerChan:
+				p.lastConfig.Store(&config)
+
 				if config.TCP != nil {
 					for routerName, route := range config.TCP.Routers {
 						if route.TLS == nil || route.TLS.CertResolver != p.ResolverName {
@@ -535,7 +684,9 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 								safe.Go(func() {
 									dom, cert, err := p.resolveCertificate(ctx, domain, traefiktls.DefaultTLSStoreName)
 									if err != nil {
-										logger.Error().Err(err).Strs("domains", domain.ToStrArray()).Msg("Unable to obtain ACME certificate for domains")
+										if !errors.Is(err, errCertificateNotObtained) {
+											logger.Error().Err(err).Strs("domains", domain.ToStrArray()).Msg("Unable to obtain ACME certificate for domains")
+										}
 										return
 									}
 
@@ -571,7 +722,9 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 								safe.Go(func() {
 									dom, cert, err := p.resolveCertificate(ctx, domain, traefiktls.DefaultTLSStoreName)
 									if err != nil {
-										logger.Error().Err(err).Strs("domains", domain.ToStrArray()).Msg("Unable to obtain ACME certificate for domains")
+										if !errors.Is(err, errCertificateNotObtained) {
+											logger.Error().Err(err).Strs("domains", domain.ToStrArray()).Msg("Unable to obtain ACME certificate for domains")
+										}
 										return
 									}
 
@@ -630,7 +783,9 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 					safe.Go(func() {
 						cert, err := p.resolveDefaultCertificate(ctx, validDomains)
 						if err != nil {
-							logger.Error().Err(err).Strs("domains", validDomains).Msgf("Unable to obtain ACME certificate for domain")
+							if !errors.Is(err, errCertificateNotObtained) {
+								logger.Error().Err(err).Strs("domains", validDomains).Msgf("Unable to obtain ACME certificate for domain")
+							}
 							return
 						}
 
@@ -679,6 +834,17 @@ func (p *Provider) resolveDefaultCertificate(ctx context.Context, domains []stri
 
 	defer p.removeResolvingDomains(append(domains, domainKey))
 
+	release, ok := p.acquireLease(ctx, domains)
+	if !ok {
+		return nil, nil
+	}
+	defer release()
+
+	if p.certExists(domains) {
+		// Obtained by another instance sharing the storage.
+		return nil, nil
+	}
+
 	logger.Debug().Msgf("Loading ACME certificates %+v...", domains)
 
 	client, err := p.getClient()
@@ -696,18 +862,13 @@ func (p *Provider) resolveDefaultCertificate(ctx context.Context, domains []stri
 		KeyType:          GetKeyType(ctx, p.KeyType),
 	}
 
-	cert, err := client.Certificate.Obtain(ctx, request)
+	cert, err := p.obtainCertificate(ctx, client, request)
 	if err != nil {
-		return nil, fmt.Errorf("unable to generate a certificate for the domains %v: %w", domains, err)
-	}
-	if cert == nil {
-		return nil, fmt.Errorf("unable to generate a certificate for the domains %v", domains)
-	}
-	if len(cert.Certificate) == 0 || len(cert.PrivateKey) == 0 {
-		return nil, fmt.Errorf("certificate for domains %v is empty: %v", domains, cert)
+		return nil, err
 	}
 
-	logger.Debug().Msgf("Default certificate obtained for domains %+v", domains)
+	domain := types.Domain{Main: domains[0], SANs: domains[1:]}
+	p.shareCertificate(ctx, domain, cert, traefiktls.DefaultTLSStoreName)
 
 	return cert, nil
 }
@@ -726,6 +887,17 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 
 	defer p.removeResolvingDomains(uncheckedDomains)
 
+	release, ok := p.acquireLease(ctx, uncheckedDomains)
+	if !ok {
+		return types.Domain{}, nil, nil
+	}
+	defer release()
+
+	if p.certExists(uncheckedDomains) {
+		// Obtained by another instance sharing the storage.
+		return types.Domain{}, nil, nil
+	}
+
 	logger := log.Ctx(ctx)
 	logger.Debug().Msgf("Loading ACME certificates %+v...", uncheckedDomains)
 
@@ -744,27 +916,55 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 		KeyType:          GetKeyType(ctx, p.KeyType),
 	}
 
-	cert, err := client.Certificate.Obtain(ctx, request)
+	cert, err := p.obtainCertificate(ctx, client, request)
 	if err != nil {
-		return types.Domain{}, nil, fmt.Errorf("unable to generate a certificate for the domains %v: %w", uncheckedDomains, err)
-	}
-	if cert == nil {
-		return types.Domain{}, nil, fmt.Errorf("unable to generate a certificate for the domains %v", uncheckedDomains)
-	}
-	if len(cert.Certificate) == 0 || len(cert.PrivateKey) == 0 {
-		return types.Domain{}, nil, fmt.Errorf("certificate for domains %v is empty: %v", uncheckedDomains, cert)
+		return types.Domain{}, nil, err
 	}
 
-	logger.Debug().Msgf("Certificates obtained for domains %+v", uncheckedDomains)
-
 	domain = types.Domain{Main: uncheckedDomains[0]}
 	if len(uncheckedDomains) > 1 {
 		domain.SANs = uncheckedDomains[1:]
 	}
 
+	p.shareCertificate(ctx, domain, cert, tlsStore)
+
 	return domain, cert, nil
 }
 
+// obtainCertificate obtains the certificate of the request, and publishes the outcome as a certificate lifecycle event.
+// It returns nil if the certificate could not be obtained.
+func (p *Provider) obtainCertificate(ctx context.Context, client *lego.Client, request certificate.ObtainRequest) (*certificate.Resource, error) {
+	cert, err := client.Certificate.Obtain(ctx, request)
+	if err == nil && (cert == nil || len(cert.Certificate) == 0 || len(cert.PrivateKey) == 0) {
+		err = errors.New("empty certificate returned by the CA")
+	}
+	if err != nil {
+		p.certificateEvent(ctx, certificateFailed, request.Domains).Err(err).Msg("Unable to obtain ACME certificate")
+		return nil, fmt.Errorf("%w for the domains %v: %w", errCertificateNotObtained, request.Domains, err)
+	}
+
+	p.certificateEvent(ctx, certificateObtained, request.Domains).Msg("ACME certificate obtained")
+
+	return cert, nil
+}
+
+// shareCertificate saves the obtained certificate in the storage shared with other instances, if any.
+// It is called before the lease on the domains is released, so that the next holder finds the certificate.
+func (p *Provider) shareCertificate(ctx context.Context, domain types.Domain, crt *certificate.Resource, tlsStore string) {
+	if _, ok := p.Store.(Leaser); !ok {
+		return
+	}
+
+	cert := &CertAndStore{
+		Certificate: Certificate{Certificate: crt.Certificate, Key: crt.PrivateKey, Domain: domain},
+		Store:       tlsStore,
+	}
+
+	if err := p.Store.SaveCertificates(p.ResolverName, []*CertAndStore{cert}); err != nil {
+		log.Ctx(ctx).Error().Err(err).Strs("domains", domain.ToStrArray()).Msg("Unable to save ACME certificate in the shared storage")
+	}
+}
+
 func (p *Provider) removeResolvingDomains(resolvingDomains []string) {
 	p.resolvingDomainsMutex.Lock()
 	defer p.resolvingDomainsMutex.Unlock()
@@ -906,33 +1106,89 @@ func (p *Provider) buildMessage() dynamic.Message {
 	return conf
 }
 
-func (p *Provider) renewCertificates(ctx context.Context, renewPeriod time.Duration) {
+func (p *Provider) renewCertificates(ctx context.Context, renewPeriod, renewInterval time.Duration) {
 	logger := log.Ctx(ctx)
 
+	if p.isFollower() {
+		logger.Debug().Msg("Certificates are renewed by the ACME leader")
+		return
+	}
+
 	logger.Info().Msg("Testing certificate renew...")
 
 	p.certificatesMu.RLock()
+	certificates := slices.Clone(p.certificates)
+	p.certificatesMu.RUnlock()
 
-	var certificates []*CertAndStore
-	for _, cert := range p.certificates {
-		crt, err := getX509Certificate(ctx, &cert.Certificate)
-		// If there's an error, we assume the cert is broken, and needs update
-		if err != nil || crt == nil || crt.NotAfter.Before(time.Now().Add(renewPeriod)) {
-			certificates = append(certificates, cert)
+	// The renewal checks may request the CA, they are done without holding the lock on the certificates.
+	for _, cert := range certificates {
+		if !p.needsRenewal(ctx, cert, renewPeriod, renewInterval) {
+			continue
+		}
+
+		if crt, err := getX509Certificate(ctx, &cert.Certificate); err == nil && crt != nil && crt.NotAfter.Before(time.Now().Add(renewPeriod)) {
+			p.certificateEvent(ctx, certificateExpiring, cert.Domain.ToStrArray()).Time("notAfter", crt.NotAfter).Msg("ACME certificate is about to expire")
 		}
+
+		p.renewCertificate(ctx, cert, renewPeriod, renewInterval)
 	}
+}
 
-	p.certificatesMu.RUnlock()
+func (p *Provider) renewCertificate(ctx context.Context, cert *CertAndStore, renewPeriod, renewInterval time.Duration) {
+	domains := cert.Domain.ToStrArray()
 
-	for _, cert := range certificates {
-		client, err := p.getClient()
-		if err != nil {
-			logger.Info().Err(err).Msgf("Error renewing ACME certificate: %+v", cert.Domain)
-			continue
+	release, ok := p.acquireLease(ctx, domains)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if _, ok := p.Store.(Leaser); ok {
+		// The certificate may have been renewed by another instance sharing the storage.
+		cert = p.currentCertificate(cert)
+		if !p.needsRenewal(ctx, cert, renewPeriod, renewInterval) {
+			return
+		}
+	}
+
+	logger := log.Ctx(ctx)
+
+	client, err := p.getClient()
+	if err != nil {
+		p.certificateEvent(ctx, certificateFailed, domains).Err(err).Msg("Unable to get the ACME client to renew the certificate")
+		return
+	}
+
+	logger.Info().Msgf("Renewing ACME certificate: %+v", cert.Domain)
+
+	renewedCert, err := p.renew(ctx, client, cert)
+	if err == nil && (renewedCert == nil || len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0) {
+		err = errors.New("empty certificate returned by the CA")
+	}
+	if err != nil {
+		p.certificateEvent(ctx, certificateFailed, domains).Err(err).Msg("Unable to renew ACME certificate")
+		return
+	}
+
+	p.certificateEvent(ctx, certificateRenewed, domains).Msg("ACME certificate renewed")
+
+	if crt, err := getX509Certificate(ctx, &cert.Certificate); err == nil && crt != nil {
+		if certID, err := api.MakeARICertID(crt); err == nil {
+			p.renewalInfos.delete(certID)
 		}
+	}
 
-		logger.Info().Msgf("Renewing ACME certificate: %+v", cert.Domain)
+	err = p.addCertificateForDomain(cert.Domain, renewedCert, cert.Store)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error adding certificate for domain")
+	}
+}
 
+// renew requests a new certificate for the domains of the given one, reusing its private key.
+// When the ACME Renewal Information is enabled, the new certificate is requested as the replacement of the given one,
+// which lets the CA know that the renewal it suggested has been done.
+func (p *Provider) renew(ctx context.Context, client *lego.Client, cert *CertAndStore) (*certificate.Resource, error) {
+	if !p.RenewalInfo {
 		res := certificate.Resource{
 			ID:          cert.Domain.Main,
 			Domains:     cert.Domain.ToStrArray(),
@@ -947,22 +1203,44 @@ func (p *Provider) renewCertificates(ctx context.Context, renewPeriod time.Durat
 			PreferredChain: p.PreferredChain,
 		}
 
-		renewedCert, err := client.Certificate.Renew(ctx, res, opts)
-		if err != nil {
-			logger.Error().Err(err).Msgf("Error renewing ACME certificate: %v", cert.Domain)
-			continue
-		}
+		return client.Certificate.Renew(ctx, res, opts)
+	}
 
-		if len(renewedCert.Certificate) == 0 || len(renewedCert.PrivateKey) == 0 {
-			logger.Error().Msgf("domains %v renew certificate with no value: %v", cert.Domain.ToStrArray(), cert)
-			continue
-		}
+	privateKey, err := certcrypto.ParsePEMPrivateKey(cert.Key)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
 
-		err = p.addCertificateForDomain(cert.Domain, renewedCert, cert.Store)
-		if err != nil {
-			logger.Error().Err(err).Msg("Error adding certificate for domain")
+	request := certificate.ObtainRequest{
+		Domains:          cert.Domain.ToStrArray(),
+		PrivateKey:       privateKey,
+		Bundle:           true,
+		EmailAddresses:   p.EmailAddresses,
+		Profile:          p.Profile,
+		PreferredChain:   p.PreferredChain,
+		EnableCommonName: !p.DisableCommonName,
+	}
+
+	// The replaced certificate is ignored by the CAs not supporting ARI.
+	if crt, err := getX509Certificate(ctx, &cert.Certificate); err == nil && crt != nil {
+		request.ReplacesCertID, _ = api.MakeARICertID(crt)
+	}
+
+	return client.Certificate.Obtain(ctx, request)
+}
+
+// currentCertificate returns the current certificate of the domain of the given one.
+func (p *Provider) currentCertificate(cert *CertAndStore) *CertAndStore {
+	p.certificatesMu.RLock()
+	defer p.certificatesMu.RUnlock()
+
+	for _, current := range p.certificates {
+		if reflect.DeepEqual(current.Domain, cert.Domain) {
+			return current
 		}
 	}
+
+	return cert
 }
 
 // Get provided certificate which check a domains list (Main and SANs)
@@ -0,0 +1,197 @@
+package acme
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/go-acme/lego/v5/acme/api"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// renewalInfoDefaultRetryAfter is the interval between two renewal information requests
+	// when the CA does not provide one.
+	renewalInfoDefaultRetryAfter = 6 * time.Hour
+	// renewalInfoMaxRetryAfter bounds the interval between two renewal information requests,
+	// so that a change of the suggested window, e.g. after a revocation, is not missed for too long.
+	renewalInfoMaxRetryAfter = 24 * time.Hour
+)
+
+// renewalInfo is the renewal time selected in the renewal window suggested by the CA for a certificate.
+type renewalInfo struct {
+	window         acme.Window
+	renewAt        time.Time
+	explanationURL string
+	nextUpdate     time.Time
+}
+
+// renewalInfoCache holds the renewal information of the certificates, by ARI certificate identifier,
+// until the CA allows to request them again.
+type renewalInfoCache struct {
+	mu    sync.Mutex
+	infos map[string]*renewalInfo
+}
+
+func (c *renewalInfoCache) get(certID string, now time.Time) (*renewalInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, ok := c.infos[certID]
+	if !ok || now.After(info.nextUpdate) {
+		return nil, false
+	}
+
+	return info, true
+}
+
+// update stores the renewal information returned by the CA.
+// The renewal time is selected uniformly in the suggested window, as recommended by RFC 9773,
+// and is kept as long as the window does not change.
+func (c *renewalInfoCache) update(certID string, ext *acme.ExtendedRenewalInfo, now time.Time) *renewalInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.infos == nil {
+		c.infos = make(map[string]*renewalInfo)
+	}
+
+	retryAfter := ext.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = renewalInfoDefaultRetryAfter
+	}
+	retryAfter = min(retryAfter, renewalInfoMaxRetryAfter)
+
+	info, ok := c.infos[certID]
+	if !ok || !info.window.Start.Equal(ext.SuggestedWindow.Start) || !info.window.End.Equal(ext.SuggestedWindow.End) {
+		info = &renewalInfo{
+			window:  ext.SuggestedWindow,
+			renewAt: selectRenewalTime(ext.SuggestedWindow),
+		}
+		c.infos[certID] = info
+	}
+
+	info.explanationURL = ext.ExplanationURL
+	info.nextUpdate = now.Add(retryAfter)
+
+	return info
+}
+
+func (c *renewalInfoCache) delete(certID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.infos, certID)
+}
+
+func selectRenewalTime(window acme.Window) time.Time {
+	renewAt := window.Start
+	if duration := window.End.Sub(window.Start); duration > 0 {
+		renewAt = renewAt.Add(time.Duration(rand.Int64N(int64(duration))))
+	}
+
+	return renewAt
+}
+
+// isRenewalDue returns whether a certificate must be renewed before the next renewal check, given its renewal time.
+// When the CA does not suggest any renewal time, the certificate is renewed during the renew period before its expiration.
+func isRenewalDue(now time.Time, crt *x509.Certificate, info *renewalInfo, renewPeriod, renewInterval time.Duration) bool {
+	if info != nil {
+		return info.renewAt.Before(now.Add(renewInterval))
+	}
+
+	return crt.NotAfter.Before(now.Add(renewPeriod))
+}
+
+// needsRenewal returns whether the given certificate must be renewed now:
+// because it cannot be loaded, it has been revoked, the CA suggests to renew it, or it is about to expire.
+func (p *Provider) needsRenewal(ctx context.Context, cert *CertAndStore, renewPeriod, renewInterval time.Duration) bool {
+	logger := log.Ctx(ctx).With().Strs("domains", cert.Domain.ToStrArray()).Logger()
+
+	crt, err := getX509Certificate(ctx, &cert.Certificate)
+	// If there's an error, we assume the cert is broken, and needs update
+	if err != nil || crt == nil {
+		return true
+	}
+
+	if p.CheckRevocation && p.isRevoked(ctx, cert) {
+		logger.Warn().Str("serial", crt.SerialNumber.String()).Msg("ACME certificate has been revoked, it will be renewed")
+		return true
+	}
+
+	info := p.getRenewalInfo(ctx, crt)
+
+	now := time.Now()
+	if !isRenewalDue(now, crt, info, renewPeriod, renewInterval) {
+		return false
+	}
+
+	if info != nil && crt.NotAfter.After(now.Add(renewPeriod)) {
+		event := logger.Info().Time("renewAt", info.renewAt)
+		if info.explanationURL != "" {
+			event = event.Str("explanationURL", info.explanationURL)
+		}
+		event.Msg("ACME certificate will be renewed in the renewal window suggested by the CA")
+	}
+
+	return true
+}
+
+// getRenewalInfo returns the renewal information of the certificate suggested by the CA with ACME Renewal Information (ARI),
+// or nil if it is disabled or not supported by the CA.
+func (p *Provider) getRenewalInfo(ctx context.Context, crt *x509.Certificate) *renewalInfo {
+	if !p.RenewalInfo {
+		return nil
+	}
+
+	certID, err := api.MakeARICertID(crt)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Unable to compute the ARI identifier of the ACME certificate")
+		return nil
+	}
+
+	now := time.Now()
+	if info, ok := p.renewalInfos.get(certID, now); ok {
+		return info
+	}
+
+	client, err := p.getClient()
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Unable to get the ACME client to request the renewal information")
+		return nil
+	}
+
+	ext, err := client.Certificate.GetRenewalInfo(ctx, crt)
+	if errors.Is(err, api.ErrNoARI) {
+		return nil
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("certID", certID).Msg("Unable to get the ACME renewal information, falling back to the certificate expiration")
+		return nil
+	}
+
+	return p.renewalInfos.update(certID, ext.ExtendedRenewalInfo, now)
+}
+
+// isRevoked returns whether the OCSP responder of the CA reports the certificate as revoked.
+// It reports false when the certificate has no OCSP responder, as the ones issued by Let's Encrypt since it stopped serving OCSP.
+func (p *Provider) isRevoked(ctx context.Context, cert *CertAndStore) bool {
+	client, err := p.getClient()
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Unable to get the ACME client to check the revocation status")
+		return false
+	}
+
+	_, resp, err := client.Certificate.GetOCSP(ctx, cert.Certificate.Certificate)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Strs("domains", cert.Domain.ToStrArray()).Msg("Unable to check the revocation status of the ACME certificate")
+		return false
+	}
+
+	return resp != nil && resp.Status == ocsp.Revoked
+}
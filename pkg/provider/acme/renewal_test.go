@@ -0,0 +1,106 @@
+package acme
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v5/acme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRenewalDue(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		desc     string
+		notAfter time.Time
+		info     *renewalInfo
+		expected bool
+	}{
+		{
+			desc:     "no renewal information, far from expiration",
+			notAfter: now.Add(60 * 24 * time.Hour),
+		},
+		{
+			desc:     "no renewal information, in the renew period",
+			notAfter: now.Add(20 * 24 * time.Hour),
+			expected: true,
+		},
+		{
+			desc:     "renewal time in the past",
+			notAfter: now.Add(60 * 24 * time.Hour),
+			info:     &renewalInfo{renewAt: now.Add(-time.Hour)},
+			expected: true,
+		},
+		{
+			desc:     "renewal time before the next check",
+			notAfter: now.Add(60 * 24 * time.Hour),
+			info:     &renewalInfo{renewAt: now.Add(time.Hour)},
+			expected: true,
+		},
+		{
+			desc:     "renewal time after the next check, in the renew period",
+			notAfter: now.Add(20 * 24 * time.Hour),
+			info:     &renewalInfo{renewAt: now.Add(2 * 24 * time.Hour)},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			crt := &x509.Certificate{NotAfter: test.notAfter}
+
+			assert.Equal(t, test.expected, isRenewalDue(now, crt, test.info, 30*24*time.Hour, 24*time.Hour))
+		})
+	}
+}
+
+func TestRenewalInfoCache(t *testing.T) {
+	now := time.Now()
+
+	window := acme.Window{Start: now.Add(24 * time.Hour), End: now.Add(48 * time.Hour)}
+
+	cache := renewalInfoCache{}
+
+	_, ok := cache.get("foo", now)
+	assert.False(t, ok)
+
+	info := cache.update("foo", &acme.ExtendedRenewalInfo{
+		RenewalInfo: acme.RenewalInfo{SuggestedWindow: window, ExplanationURL: "https://example.com"},
+		RetryAfter:  time.Hour,
+	}, now)
+	assert.False(t, info.renewAt.Before(window.Start))
+	assert.True(t, info.renewAt.Before(window.End))
+	assert.Equal(t, "https://example.com", info.explanationURL)
+
+	cached, ok := cache.get("foo", now.Add(30*time.Minute))
+	require.True(t, ok)
+	assert.Equal(t, info, cached)
+
+	// The renewal information has to be requested again after the retry delay.
+	_, ok = cache.get("foo", now.Add(2*time.Hour))
+	assert.False(t, ok)
+
+	// The selected renewal time is kept while the window does not change.
+	renewAt := info.renewAt
+	info = cache.update("foo", &acme.ExtendedRenewalInfo{RenewalInfo: acme.RenewalInfo{SuggestedWindow: window}}, now.Add(2*time.Hour))
+	assert.Equal(t, renewAt, info.renewAt)
+	assert.Equal(t, now.Add(2*time.Hour+renewalInfoDefaultRetryAfter), info.nextUpdate)
+
+	// A window moved in the past, e.g. after a revocation, makes the certificate due for renewal.
+	revoked := acme.Window{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}
+	info = cache.update("foo", &acme.ExtendedRenewalInfo{
+		RenewalInfo: acme.RenewalInfo{SuggestedWindow: revoked},
+		RetryAfter:  72 * time.Hour,
+	}, now)
+	assert.True(t, info.renewAt.Before(now))
+	assert.Equal(t, now.Add(renewalInfoMaxRetryAfter), info.nextUpdate)
+
+	cache.delete("foo")
+
+	_, ok = cache.get("foo", now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,449 @@
+package acme
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/provider/kv/consul"
+	"github.com/traefik/traefik/v3/pkg/provider/kv/etcd"
+	"github.com/traefik/traefik/v3/pkg/provider/kv/redis"
+	"github.com/traefik/traefik/v3/pkg/provider/kv/zk"
+)
+
+const (
+	sharedStoreTimeout = 30 * time.Second
+	// sharedStoreMaxAttempts is the number of times an update is retried when the data is concurrently modified.
+	sharedStoreMaxAttempts = 5
+)
+
+// errRevisionConflict is returned by the shared backends when the stored value changed since it was read.
+var errRevisionConflict = errors.New("revision conflict")
+
+// SharedStorage holds the configuration of a storage shared by several Traefik instances.
+type SharedStorage struct {
+	Kubernetes *KubernetesStorage      `description:"Stores the ACME data in Kubernetes Secrets." json:"kubernetes,omitempty" toml:"kubernetes,omitempty" yaml:"kubernetes,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Consul     *consul.ProviderBuilder `description:"Stores the ACME data in Consul." json:"consul,omitempty" toml:"consul,omitempty" yaml:"consul,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Etcd       *etcd.Provider          `description:"Stores the ACME data in etcd." json:"etcd,omitempty" toml:"etcd,omitempty" yaml:"etcd,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Redis      *redis.Provider         `description:"Stores the ACME data in Redis." json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	ZooKeeper  *zk.Provider            `description:"Stores the ACME data in ZooKeeper." json:"zooKeeper,omitempty" toml:"zooKeeper,omitempty" yaml:"zooKeeper,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Vault      *VaultStorage           `description:"Stores the ACME data in a HashiCorp Vault KV version 2 secrets engine." json:"vault,omitempty" toml:"vault,omitempty" yaml:"vault,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
+	LeaseDuration ptypes.Duration `description:"Duration of the lease taken by an instance while it obtains or renews a certificate." json:"leaseDuration,omitempty" toml:"leaseDuration,omitempty" yaml:"leaseDuration,omitempty" export:"true"`
+	SyncInterval  ptypes.Duration `description:"Interval at which the certificates obtained by the other instances are loaded from the storage." json:"syncInterval,omitempty" toml:"syncInterval,omitempty" yaml:"syncInterval,omitempty" export:"true"`
+
+	LeaderElection *LeaderElection `description:"Elects a leader among the instances, the only one requesting certificates to the CA." json:"leaderElection,omitempty" toml:"leaderElection,omitempty" yaml:"leaderElection,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (s *SharedStorage) SetDefaults() {
+	s.LeaseDuration = ptypes.Duration(5 * time.Minute)
+	s.SyncInterval = ptypes.Duration(time.Minute)
+}
+
+// sharedValue is a value read from a shared backend, along with the revision needed to update it.
+type sharedValue struct {
+	data     []byte
+	revision any
+}
+
+// sharedBackend is a key-value storage supporting compare-and-swap updates.
+type sharedBackend interface {
+	// get returns the value stored under the key, or nil if the key has never been written.
+	get(ctx context.Context, key string) (*sharedValue, error)
+	// put stores the data under the key if it has not been modified since the previous value was read,
+	// a nil previous value meaning that the key must not exist.
+	// It returns errRevisionConflict otherwise.
+	put(ctx context.Context, key string, data []byte, previous *sharedValue) error
+}
+
+// leaseRecord is the state of a lease.
+type leaseRecord struct {
+	Holder    string        `json:"holder,omitempty"`
+	RenewTime time.Time     `json:"renewTime"`
+	Duration  time.Duration `json:"duration"`
+
+	revision any
+}
+
+// active returns whether the lease is held at the given time.
+func (l *leaseRecord) active(now time.Time) bool {
+	return l.Holder != "" && now.Before(l.RenewTime.Add(l.Duration))
+}
+
+// leaseBackend stores leases, with the same compare-and-swap semantic as sharedBackend.
+type leaseBackend interface {
+	getLease(ctx context.Context, key string) (*leaseRecord, error)
+	putLease(ctx context.Context, key string, lease, previous *leaseRecord) error
+}
+
+// valueLeases stores the leases as JSON values of a sharedBackend.
+type valueLeases struct {
+	backend sharedBackend
+}
+
+func (v valueLeases) getLease(ctx context.Context, key string) (*leaseRecord, error) {
+	value, err := v.backend.get(ctx, "leases/"+key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	lease := &leaseRecord{revision: value}
+	if len(value.data) == 0 {
+		return lease, nil
+	}
+
+	if err := json.Unmarshal(value.data, lease); err != nil {
+		return nil, fmt.Errorf("decoding lease %s: %w", key, err)
+	}
+
+	return lease, nil
+}
+
+func (v valueLeases) putLease(ctx context.Context, key string, lease, previous *leaseRecord) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+
+	var previousValue *sharedValue
+	if previous != nil {
+		previousValue, _ = previous.revision.(*sharedValue)
+	}
+
+	return v.backend.put(ctx, "leases/"+key, data, previousValue)
+}
+
+var (
+	_ Store   = (*SharedStore)(nil)
+	_ Leaser  = (*SharedStore)(nil)
+	_ Elector = (*SharedStore)(nil)
+)
+
+// SharedStore is a Store shared by several Traefik instances.
+// Concurrent updates are merged, and leases make sure that a certificate is obtained or renewed by a single instance.
+type SharedStore struct {
+	backend       sharedBackend
+	leases        leaseBackend
+	elector       elector
+	holder        string
+	leaseDuration time.Duration
+}
+
+// NewSharedStore creates a SharedStore from the given configuration.
+func NewSharedStore(config *SharedStorage) (*SharedStore, error) {
+	var configured int
+	for _, set := range []bool{config.Kubernetes != nil, config.Consul != nil, config.Etcd != nil, config.Redis != nil, config.ZooKeeper != nil, config.Vault != nil} {
+		if set {
+			configured++
+		}
+	}
+
+	switch configured {
+	case 0:
+		return nil, errors.New("no shared storage backend configured")
+	case 1:
+	default:
+		return nil, errors.New("only one shared storage backend can be configured")
+	}
+
+	var (
+		shared sharedBackend
+		leases leaseBackend
+	)
+
+	if config.Kubernetes != nil {
+		backend, err := newKubernetesBackend(config.Kubernetes)
+		if err != nil {
+			return nil, fmt.Errorf("creating Kubernetes storage: %w", err)
+		}
+		shared = backend
+		leases = backend
+	}
+
+	if config.Consul != nil {
+		providers := config.Consul.BuildProviders()
+		if len(providers) > 1 {
+			return nil, errors.New("creating Consul storage: a single namespace is supported")
+		}
+
+		if err := providers[0].Init(); err != nil {
+			return nil, fmt.Errorf("creating Consul storage: %w", err)
+		}
+		shared = newKVBackend(providers[0].KVClient(), providers[0].RootKey)
+	}
+
+	if config.Etcd != nil {
+		if err := config.Etcd.Init(); err != nil {
+			return nil, fmt.Errorf("creating etcd storage: %w", err)
+		}
+		shared = newKVBackend(config.Etcd.KVClient(), config.Etcd.RootKey)
+	}
+
+	if config.Redis != nil {
+		if err := config.Redis.Init(); err != nil {
+			return nil, fmt.Errorf("creating Redis storage: %w", err)
+		}
+		shared = newKVBackend(config.Redis.KVClient(), config.Redis.RootKey)
+	}
+
+	if config.ZooKeeper != nil {
+		if err := config.ZooKeeper.Init(); err != nil {
+			return nil, fmt.Errorf("creating ZooKeeper storage: %w", err)
+		}
+		shared = newKVBackend(config.ZooKeeper.KVClient(), config.ZooKeeper.RootKey)
+	}
+
+	if config.Vault != nil {
+		backend, err := newVaultBackend(config.Vault)
+		if err != nil {
+			return nil, fmt.Errorf("creating Vault storage: %w", err)
+		}
+		shared = backend
+	}
+
+	if leases == nil {
+		leases = valueLeases{backend: shared}
+	}
+
+	store := newSharedStore(shared, leases, time.Duration(config.LeaseDuration))
+
+	if config.LeaderElection != nil {
+		duration := time.Duration(config.LeaderElection.LeaseDuration)
+
+		// The KV stores have native locks, such as Consul sessions, released when their holder stops renewing them.
+		if backend, ok := shared.(*kvBackend); ok {
+			store.elector = &lockElector{backend: backend, holder: store.holder, ttl: duration}
+		} else {
+			store.elector = &leaseElector{leases: leases, holder: store.holder, duration: duration}
+		}
+	}
+
+	return store, nil
+}
+
+func newSharedStore(backend sharedBackend, leases leaseBackend, leaseDuration time.Duration) *SharedStore {
+	holder, err := os.Hostname()
+	if err != nil {
+		holder = "traefik"
+	}
+
+	return &SharedStore{
+		backend:       backend,
+		leases:        leases,
+		holder:        fmt.Sprintf("%s-%d", holder, os.Getpid()),
+		leaseDuration: leaseDuration,
+	}
+}
+
+// GetAccount returns ACME Account.
+func (s *SharedStore) GetAccount(resolverName string) (*Account, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sharedStoreTimeout)
+	defer cancel()
+
+	storedData, _, err := s.get(ctx, resolverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return storedData.Account, nil
+}
+
+// SaveAccount stores ACME Account.
+func (s *SharedStore) SaveAccount(resolverName string, account *Account) error {
+	return s.update(resolverName, func(storedData *StoredData) {
+		storedData.Account = account
+	})
+}
+
+// GetCertificates returns ACME Certificates list.
+func (s *SharedStore) GetCertificates(resolverName string) ([]*CertAndStore, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sharedStoreTimeout)
+	defer cancel()
+
+	storedData, _, err := s.get(ctx, resolverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return storedData.Certificates, nil
+}
+
+// SaveCertificates merges the given certificates with the stored ones, keeping the most recent certificate of each domain.
+func (s *SharedStore) SaveCertificates(resolverName string, certificates []*CertAndStore) error {
+	return s.update(resolverName, func(storedData *StoredData) {
+		storedData.Certificates, _ = mergeCertificates(storedData.Certificates, certificates)
+	})
+}
+
+// AcquireLease acquires the named lease for the given resolver.
+func (s *SharedStore) AcquireLease(ctx context.Context, resolverName, name string) (func(), error) {
+	key := leaseKey(resolverName, name)
+
+	if err := holdLease(ctx, s.leases, key, s.holder, s.leaseDuration); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedStoreTimeout)
+		defer cancel()
+
+		if err := releaseLease(ctx, s.leases, key, s.holder); err != nil {
+			// The lease expires on its own if it cannot be released.
+			log.Debug().Err(err).Str("lease", key).Msg("Unable to release ACME lease")
+		}
+	}, nil
+}
+
+// RunElection takes part in the election of the leader of the resolver.
+func (s *SharedStore) RunElection(ctx context.Context, resolverName string, onLeadership func(leader bool)) {
+	if s.elector == nil {
+		onLeadership(true)
+		return
+	}
+
+	s.elector.run(ctx, resolverName, onLeadership)
+}
+
+func (s *SharedStore) get(ctx context.Context, resolverName string) (*StoredData, *sharedValue, error) {
+	value, err := s.backend.get(ctx, resolverName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading ACME data of %s: %w", resolverName, err)
+	}
+
+	storedData := &StoredData{}
+	if value == nil || len(value.data) == 0 {
+		return storedData, value, nil
+	}
+
+	if err := json.Unmarshal(value.data, storedData); err != nil {
+		return nil, nil, fmt.Errorf("decoding ACME data of %s: %w", resolverName, err)
+	}
+
+	return storedData, value, nil
+}
+
+func (s *SharedStore) update(resolverName string, apply func(storedData *StoredData)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sharedStoreTimeout)
+	defer cancel()
+
+	for range sharedStoreMaxAttempts {
+		storedData, previous, err := s.get(ctx, resolverName)
+		if err != nil {
+			return err
+		}
+
+		apply(storedData)
+
+		data, err := json.MarshalIndent(storedData, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		err = s.backend.put(ctx, resolverName, data, previous)
+		if !errors.Is(err, errRevisionConflict) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("saving ACME data of %s: %w", resolverName, errRevisionConflict)
+}
+
+// leaseKey returns a key, usable by all backends, identifying the lease of the given name.
+func leaseKey(resolverName, name string) string {
+	hash := sha256.Sum256([]byte(name))
+	return resolverName + "-" + hex.EncodeToString(hash[:10])
+}
+
+// holdLease acquires, or renews, the lease of the given key for the holder.
+func holdLease(ctx context.Context, leases leaseBackend, key, holder string, duration time.Duration) error {
+	current, err := leases.getLease(ctx, key)
+	if err != nil {
+		return fmt.Errorf("reading lease: %w", err)
+	}
+
+	now := time.Now()
+	if current != nil && current.active(now) && current.Holder != holder {
+		return ErrLeaseHeld
+	}
+
+	lease := &leaseRecord{Holder: holder, RenewTime: now, Duration: duration}
+	if err := leases.putLease(ctx, key, lease, current); err != nil {
+		if errors.Is(err, errRevisionConflict) {
+			return ErrLeaseHeld
+		}
+		return fmt.Errorf("writing lease: %w", err)
+	}
+
+	return nil
+}
+
+// releaseLease releases the lease of the given key, if it is still held by the holder.
+func releaseLease(ctx context.Context, leases leaseBackend, key, holder string) error {
+	current, err := leases.getLease(ctx, key)
+	if err != nil || current == nil || current.Holder != holder {
+		return err
+	}
+
+	return leases.putLease(ctx, key, &leaseRecord{RenewTime: time.Now()}, current)
+}
+
+// mergeCertificates merges the incoming certificates into the current ones.
+// A certificate replaces the current certificate of the same domain when it expires later.
+// It returns whether the current certificates were modified.
+func mergeCertificates(current, incoming []*CertAndStore) ([]*CertAndStore, bool) {
+	merged := make([]*CertAndStore, len(current), len(current)+len(incoming))
+	copy(merged, current)
+
+	var updated bool
+	for _, cert := range incoming {
+		index := -1
+		for i, existing := range merged {
+			if reflect.DeepEqual(existing.Domain, cert.Domain) {
+				index = i
+				break
+			}
+		}
+
+		if index < 0 {
+			merged = append(merged, cert)
+			updated = true
+			continue
+		}
+
+		if notAfter(cert).After(notAfter(merged[index])) {
+			merged[index] = cert
+			updated = true
+		}
+	}
+
+	return merged, updated
+}
+
+// notAfter returns the expiration date of the certificate, or the zero time if it cannot be parsed.
+func notAfter(cert *CertAndStore) time.Time {
+	tlsCert, err := tls.X509KeyPair(cert.Certificate.Certificate, cert.Key)
+	if err != nil {
+		return time.Time{}
+	}
+
+	crt := tlsCert.Leaf
+	if crt == nil {
+		crt, err = x509.ParseCertificate(tlsCert.Certificate[0])
+		if err != nil {
+			return time.Time{}
+		}
+	}
+
+	return crt.NotAfter
+}
@@ -0,0 +1,222 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/types"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	kubernetesSecretKey     = "acme.json"
+	kubernetesNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+var kubernetesInvalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// KubernetesStorage holds the configuration of the Kubernetes storage.
+type KubernetesStorage struct {
+	Endpoint         string              `description:"Kubernetes server endpoint (required for external cluster client)." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Token            types.FileOrContent `description:"Kubernetes bearer token (not needed for in-cluster client). It accepts either a token value or a file path to the token." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
+	CertAuthFilePath string              `description:"Kubernetes certificate authority file path (not needed for in-cluster client)." json:"certAuthFilePath,omitempty" toml:"certAuthFilePath,omitempty" yaml:"certAuthFilePath,omitempty"`
+	Namespace        string              `description:"Namespace of the Secrets and Leases. Defaults to the namespace of the Traefik pod." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	SecretPrefix     string              `description:"Prefix of the names of the Secrets and Leases." json:"secretPrefix,omitempty" toml:"secretPrefix,omitempty" yaml:"secretPrefix,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (k *KubernetesStorage) SetDefaults() {
+	k.SecretPrefix = "traefik-acme"
+}
+
+// kubernetesBackend stores the ACME data of each resolver in a Secret, and the leases in coordination Leases.
+// Concurrent updates are detected with the resource versions.
+type kubernetesBackend struct {
+	client    kclientset.Interface
+	namespace string
+	prefix    string
+}
+
+func newKubernetesBackend(config *KubernetesStorage) (*kubernetesBackend, error) {
+	restConfig, err := kubernetesRESTConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kclientset.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = "default"
+		if data, err := os.ReadFile(kubernetesNamespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(data))
+		}
+	}
+
+	return &kubernetesBackend{client: client, namespace: namespace, prefix: config.SecretPrefix}, nil
+}
+
+func kubernetesRESTConfig(config *KubernetesStorage) (*rest.Config, error) {
+	switch {
+	case os.Getenv("KUBERNETES_SERVICE_HOST") != "" && os.Getenv("KUBERNETES_SERVICE_PORT") != "":
+		restConfig, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create in-cluster configuration: %w", err)
+		}
+
+		if config.Endpoint != "" {
+			restConfig.Host = config.Endpoint
+		}
+
+		return restConfig, nil
+	case os.Getenv("KUBECONFIG") != "":
+		return clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+	}
+
+	if config.Endpoint == "" {
+		return nil, errors.New("endpoint missing for external cluster client")
+	}
+
+	token, err := config.Token.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read token: %w", err)
+	}
+
+	restConfig := &rest.Config{
+		Host:        config.Endpoint,
+		BearerToken: string(token),
+	}
+
+	if config.CertAuthFilePath != "" {
+		caData, err := os.ReadFile(config.CertAuthFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file %s: %w", config.CertAuthFilePath, err)
+		}
+
+		restConfig.TLSClientConfig = rest.TLSClientConfig{CAData: caData}
+	}
+
+	return restConfig, nil
+}
+
+func (b *kubernetesBackend) get(ctx context.Context, key string) (*sharedValue, error) {
+	secret, err := b.client.CoreV1().Secrets(b.namespace).Get(ctx, b.objectName(key), metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sharedValue{data: secret.Data[kubernetesSecretKey], revision: secret.ResourceVersion}, nil
+}
+
+func (b *kubernetesBackend) put(ctx context.Context, key string, data []byte, previous *sharedValue) error {
+	secret := &corev1.Secret{
+		ObjectMeta: b.objectMeta(key, previous),
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{kubernetesSecretKey: data},
+	}
+
+	var err error
+	if previous == nil {
+		_, err = b.client.CoreV1().Secrets(b.namespace).Create(ctx, secret, metav1.CreateOptions{})
+	} else {
+		_, err = b.client.CoreV1().Secrets(b.namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	}
+
+	return kubernetesConflict(err)
+}
+
+func (b *kubernetesBackend) getLease(ctx context.Context, key string) (*leaseRecord, error) {
+	lease, err := b.client.CoordinationV1().Leases(b.namespace).Get(ctx, b.objectName(key), metav1.GetOptions{})
+	if kerror.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := &leaseRecord{
+		Holder:   ptr.Deref(lease.Spec.HolderIdentity, ""),
+		Duration: time.Duration(ptr.Deref(lease.Spec.LeaseDurationSeconds, 0)) * time.Second,
+		revision: &sharedValue{revision: lease.ResourceVersion},
+	}
+	if lease.Spec.RenewTime != nil {
+		record.RenewTime = lease.Spec.RenewTime.Time
+	}
+
+	return record, nil
+}
+
+func (b *kubernetesBackend) putLease(ctx context.Context, key string, record, previous *leaseRecord) error {
+	var previousValue *sharedValue
+	if previous != nil {
+		previousValue, _ = previous.revision.(*sharedValue)
+	}
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: b.objectMeta(key, previousValue),
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To(record.Holder),
+			LeaseDurationSeconds: ptr.To(int32(record.Duration.Seconds())),
+			RenewTime:            &metav1.MicroTime{Time: record.RenewTime},
+		},
+	}
+
+	var err error
+	if previousValue == nil {
+		_, err = b.client.CoordinationV1().Leases(b.namespace).Create(ctx, lease, metav1.CreateOptions{})
+	} else {
+		_, err = b.client.CoordinationV1().Leases(b.namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	}
+
+	return kubernetesConflict(err)
+}
+
+func (b *kubernetesBackend) objectMeta(key string, previous *sharedValue) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{
+		Name:      b.objectName(key),
+		Namespace: b.namespace,
+		Labels:    map[string]string{"app.kubernetes.io/managed-by": "traefik"},
+	}
+
+	if previous != nil {
+		meta.ResourceVersion, _ = previous.revision.(string)
+	}
+
+	return meta
+}
+
+// objectName returns a valid Kubernetes object name for the given key.
+func (b *kubernetesBackend) objectName(key string) string {
+	name := kubernetesInvalidNameChars.ReplaceAllString(strings.ToLower(b.prefix+"-"+key), "-")
+	if len(name) > 253 {
+		name = name[:253]
+	}
+
+	return strings.Trim(name, "-.")
+}
+
+func kubernetesConflict(err error) error {
+	if kerror.IsConflict(err) || kerror.IsAlreadyExists(err) {
+		return errRevisionConflict
+	}
+
+	return err
+}
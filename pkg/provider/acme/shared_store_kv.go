@@ -0,0 +1,53 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"path"
+
+	"github.com/kvtools/valkeyrie/store"
+)
+
+// kvBackend stores the ACME data in a KV store, under the acme directory of the root key.
+type kvBackend struct {
+	client  store.Store
+	rootKey string
+}
+
+func newKVBackend(client store.Store, rootKey string) *kvBackend {
+	return &kvBackend{client: client, rootKey: rootKey}
+}
+
+func (b *kvBackend) get(ctx context.Context, key string) (*sharedValue, error) {
+	pair, err := b.client.Get(ctx, b.path(key), nil)
+	if errors.Is(err, store.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sharedValue{data: pair.Value, revision: pair}, nil
+}
+
+func (b *kvBackend) put(ctx context.Context, key string, data []byte, previous *sharedValue) error {
+	var previousPair *store.KVPair
+	if previous != nil {
+		previousPair, _ = previous.revision.(*store.KVPair)
+	}
+
+	_, _, err := b.client.AtomicPut(ctx, b.path(key), data, previousPair, nil)
+	switch {
+	case errors.Is(err, store.ErrKeyModified), errors.Is(err, store.ErrKeyExists):
+		return errRevisionConflict
+	case previousPair != nil && errors.Is(err, store.ErrKeyNotFound):
+		// The key has been deleted since it was read.
+		return errRevisionConflict
+	}
+
+	return err
+}
+
+func (b *kvBackend) path(key string) string {
+	return path.Join(b.rootKey, "acme", key)
+}
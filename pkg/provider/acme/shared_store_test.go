@@ -0,0 +1,317 @@
+package acme
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/tls/generate"
+	"github.com/traefik/traefik/v3/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// memoryBackend is an in-memory sharedBackend, optionally failing the first puts with a conflict.
+type memoryBackend struct {
+	mu        sync.Mutex
+	values    map[string]*sharedValue
+	conflicts int
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{values: make(map[string]*sharedValue)}
+}
+
+func (m *memoryBackend) get(_ context.Context, key string) (*sharedValue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.values[key], nil
+}
+
+func (m *memoryBackend) put(_ context.Context, key string, data []byte, previous *sharedValue) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conflicts > 0 {
+		m.conflicts--
+		return errRevisionConflict
+	}
+
+	current := m.values[key]
+	if current != previous {
+		return errRevisionConflict
+	}
+
+	var revision int
+	if current != nil {
+		revision = current.revision.(int) + 1
+	}
+
+	m.values[key] = &sharedValue{data: data, revision: revision}
+
+	return nil
+}
+
+func newTestCertificate(t *testing.T, domain string, expiration time.Time) *CertAndStore {
+	t.Helper()
+
+	certPEM, keyPEM, err := generate.KeyPair(domain, expiration)
+	require.NoError(t, err)
+
+	return &CertAndStore{
+		Certificate: Certificate{Domain: types.Domain{Main: domain}, Certificate: certPEM, Key: keyPEM},
+		Store:       "default",
+	}
+}
+
+func TestMergeCertificates(t *testing.T) {
+	now := time.Now()
+
+	oldFoo := newTestCertificate(t, "foo.localhost", now.Add(time.Hour))
+	newFoo := newTestCertificate(t, "foo.localhost", now.Add(48*time.Hour))
+	bar := newTestCertificate(t, "bar.localhost", now.Add(time.Hour))
+
+	testCases := []struct {
+		desc            string
+		current         []*CertAndStore
+		incoming        []*CertAndStore
+		expected        []*CertAndStore
+		expectedUpdated bool
+	}{
+		{
+			desc:            "new domain",
+			current:         []*CertAndStore{oldFoo},
+			incoming:        []*CertAndStore{bar},
+			expected:        []*CertAndStore{oldFoo, bar},
+			expectedUpdated: true,
+		},
+		{
+			desc:            "more recent certificate",
+			current:         []*CertAndStore{oldFoo, bar},
+			incoming:        []*CertAndStore{newFoo},
+			expected:        []*CertAndStore{newFoo, bar},
+			expectedUpdated: true,
+		},
+		{
+			desc:     "older certificate",
+			current:  []*CertAndStore{newFoo},
+			incoming: []*CertAndStore{oldFoo},
+			expected: []*CertAndStore{newFoo},
+		},
+		{
+			desc:     "same certificates",
+			current:  []*CertAndStore{oldFoo, bar},
+			incoming: []*CertAndStore{oldFoo, bar},
+			expected: []*CertAndStore{oldFoo, bar},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			merged, updated := mergeCertificates(test.current, test.incoming)
+
+			assert.Equal(t, test.expected, merged)
+			assert.Equal(t, test.expectedUpdated, updated)
+		})
+	}
+}
+
+func TestSharedStore_SaveCertificates(t *testing.T) {
+	now := time.Now()
+
+	backend := newMemoryBackend()
+	store := newSharedStore(backend, valueLeases{backend: backend}, time.Minute)
+
+	foo := newTestCertificate(t, "foo.localhost", now.Add(48*time.Hour))
+	bar := newTestCertificate(t, "bar.localhost", now.Add(time.Hour))
+
+	// Two instances each saving the certificate they obtained.
+	require.NoError(t, store.SaveCertificates("test", []*CertAndStore{foo}))
+	require.NoError(t, store.SaveCertificates("test", []*CertAndStore{bar}))
+
+	// A concurrent update happened meanwhile.
+	backend.conflicts = 2
+	require.NoError(t, store.SaveAccount("test", &Account{Email: "foo@localhost"}))
+
+	certificates, err := store.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{foo, bar}, certificates)
+
+	account, err := store.GetAccount("test")
+	require.NoError(t, err)
+	assert.Equal(t, "foo@localhost", account.Email)
+
+	backend.conflicts = sharedStoreMaxAttempts
+	err = store.SaveCertificates("test", []*CertAndStore{foo})
+	require.ErrorIs(t, err, errRevisionConflict)
+}
+
+func TestSharedStore_AcquireLease(t *testing.T) {
+	backend := newMemoryBackend()
+
+	first := newSharedStore(backend, valueLeases{backend: backend}, time.Minute)
+	first.holder = "first"
+
+	second := newSharedStore(backend, valueLeases{backend: backend}, time.Minute)
+	second.holder = "second"
+
+	release, err := first.AcquireLease(t.Context(), "test", "foo.localhost")
+	require.NoError(t, err)
+
+	_, err = second.AcquireLease(t.Context(), "test", "foo.localhost")
+	require.ErrorIs(t, err, ErrLeaseHeld)
+
+	// Leases are independent from each other.
+	releaseBar, err := second.AcquireLease(t.Context(), "test", "bar.localhost")
+	require.NoError(t, err)
+	releaseBar()
+
+	release()
+
+	release, err = second.AcquireLease(t.Context(), "test", "foo.localhost")
+	require.NoError(t, err)
+	defer release()
+
+	// An expired lease can be taken over.
+	lease, err := backend.get(t.Context(), "leases/"+leaseKey("test", "foo.localhost"))
+	require.NoError(t, err)
+
+	expired, err := json.Marshal(leaseRecord{Holder: "second", RenewTime: time.Now().Add(-2 * time.Minute), Duration: time.Minute})
+	require.NoError(t, err)
+	require.NoError(t, backend.put(t.Context(), "leases/"+leaseKey("test", "foo.localhost"), expired, lease))
+
+	_, err = first.AcquireLease(t.Context(), "test", "foo.localhost")
+	require.NoError(t, err)
+}
+
+func TestVaultBackend(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		versions = map[string]int{}
+		values   = map[string]string{}
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Vault-Token") != "token" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		path := strings.TrimPrefix(req.URL.Path, "/v1/kv/data/")
+
+		switch req.Method {
+		case http.MethodGet:
+			if versions[path] == 0 {
+				rw.WriteHeader(http.StatusNotFound)
+				_, _ = rw.Write([]byte(`{"errors":[]}`))
+				return
+			}
+
+			_ = json.NewEncoder(rw).Encode(map[string]any{
+				"data": map[string]any{
+					"data":     map[string]string{"value": values[path]},
+					"metadata": map[string]int{"version": versions[path]},
+				},
+			})
+		case http.MethodPost:
+			var body struct {
+				Options struct {
+					CAS int `json:"cas"`
+				} `json:"options"`
+				Data map[string]string `json:"data"`
+			}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if body.Options.CAS != versions[path] {
+				rw.WriteHeader(http.StatusBadRequest)
+				_, _ = rw.Write([]byte(`{"errors":["check-and-set parameter did not match the current version"]}`))
+				return
+			}
+
+			versions[path]++
+			values[path] = body.Data["value"]
+
+			_, _ = rw.Write([]byte(`{"data":{}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	backend, err := newVaultBackend(&VaultStorage{Endpoint: server.URL, Token: "token", MountPath: "kv", Path: "traefik/acme"})
+	require.NoError(t, err)
+
+	value, err := backend.get(t.Context(), "test")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, backend.put(t.Context(), "test", []byte("foo"), nil))
+
+	err = backend.put(t.Context(), "test", []byte("bar"), nil)
+	require.ErrorIs(t, err, errRevisionConflict)
+
+	value, err = backend.get(t.Context(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("foo"), value.data)
+
+	require.NoError(t, backend.put(t.Context(), "test", []byte("bar"), value))
+
+	err = backend.put(t.Context(), "test", []byte("baz"), value)
+	require.ErrorIs(t, err, errRevisionConflict)
+
+	assert.Equal(t, "bar", values["traefik/acme/test"])
+}
+
+func TestKubernetesBackend(t *testing.T) {
+	backend := &kubernetesBackend{client: kfake.NewClientset(), namespace: "traefik", prefix: "traefik-acme"}
+
+	value, err := backend.get(t.Context(), "My_Resolver")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, backend.put(t.Context(), "My_Resolver", []byte("foo"), nil))
+
+	err = backend.put(t.Context(), "My_Resolver", []byte("bar"), nil)
+	require.ErrorIs(t, err, errRevisionConflict)
+
+	value, err = backend.get(t.Context(), "My_Resolver")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("foo"), value.data)
+
+	require.NoError(t, backend.put(t.Context(), "My_Resolver", []byte("bar"), value))
+
+	secret, err := backend.client.CoreV1().Secrets("traefik").Get(t.Context(), "traefik-acme-my-resolver", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), secret.Data[kubernetesSecretKey])
+
+	store := newSharedStore(backend, backend, time.Minute)
+	store.holder = "first"
+
+	release, err := store.AcquireLease(t.Context(), "My_Resolver", "foo.localhost")
+	require.NoError(t, err)
+
+	other := newSharedStore(backend, backend, time.Minute)
+	other.holder = "second"
+
+	_, err = other.AcquireLease(t.Context(), "My_Resolver", "foo.localhost")
+	require.ErrorIs(t, err, ErrLeaseHeld)
+
+	release()
+
+	_, err = other.AcquireLease(t.Context(), "My_Resolver", "foo.localhost")
+	require.NoError(t, err)
+}
@@ -0,0 +1,162 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+// VaultStorage holds the configuration of the HashiCorp Vault storage.
+type VaultStorage struct {
+	Endpoint  string              `description:"Vault server endpoint." json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	Token     types.FileOrContent `description:"Vault token, or path to a file containing it." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
+	Namespace string              `description:"Vault namespace (Vault Enterprise only)." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	MountPath string              `description:"Mount path of the KV version 2 secrets engine." json:"mountPath,omitempty" toml:"mountPath,omitempty" yaml:"mountPath,omitempty" export:"true"`
+	Path      string              `description:"Path, in the secrets engine, under which the ACME data is stored." json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty" export:"true"`
+	TLS       *types.ClientTLS    `description:"Enable TLS support." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (v *VaultStorage) SetDefaults() {
+	v.Endpoint = "http://127.0.0.1:8200"
+	v.MountPath = "secret"
+	v.Path = "traefik/acme"
+}
+
+// vaultBackend stores the ACME data in a Vault KV version 2 secrets engine,
+// relying on its check-and-set option to detect concurrent updates.
+type vaultBackend struct {
+	client    *http.Client
+	endpoint  string
+	token     string
+	namespace string
+	mountPath string
+	path      string
+}
+
+type vaultSecret struct {
+	Data struct {
+		Data     map[string]string `json:"data"`
+		Metadata struct {
+			Version int `json:"version"`
+		} `json:"metadata"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+func newVaultBackend(config *VaultStorage) (*vaultBackend, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("endpoint is missing")
+	}
+
+	token, err := config.Token.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading token: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.TLS != nil {
+		transport.TLSClientConfig, err = config.TLS.CreateTLSConfig(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("creating TLS configuration: %w", err)
+		}
+	}
+
+	return &vaultBackend{
+		client:    &http.Client{Transport: transport, Timeout: 10 * time.Second},
+		endpoint:  strings.TrimSuffix(config.Endpoint, "/"),
+		token:     strings.TrimSpace(string(token)),
+		namespace: config.Namespace,
+		mountPath: strings.Trim(config.MountPath, "/"),
+		path:      strings.Trim(config.Path, "/"),
+	}, nil
+}
+
+func (b *vaultBackend) get(ctx context.Context, key string) (*sharedValue, error) {
+	resp, err := b.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	secret := vaultSecret{}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("decoding Vault response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && secret.Data.Metadata.Version == 0:
+		return nil, nil
+	case resp.StatusCode == http.StatusNotFound:
+		// The latest version has been deleted, writing the key again requires its version.
+		return &sharedValue{revision: secret.Data.Metadata.Version}, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.Join(secret.Errors, ", "))
+	}
+
+	return &sharedValue{data: []byte(secret.Data.Data["value"]), revision: secret.Data.Metadata.Version}, nil
+}
+
+func (b *vaultBackend) put(ctx context.Context, key string, data []byte, previous *sharedValue) error {
+	var version int
+	if previous != nil {
+		version, _ = previous.revision.(int)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"options": map[string]int{"cas": version},
+		"data":    map[string]string{"value": string(data)},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.do(ctx, http.MethodPost, key, body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	secret := vaultSecret{}
+	_ = json.NewDecoder(resp.Body).Decode(&secret)
+
+	for _, message := range secret.Errors {
+		if strings.Contains(message, "check-and-set") {
+			return errRevisionConflict
+		}
+	}
+
+	return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.Join(secret.Errors, ", "))
+}
+
+func (b *vaultBackend) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	secretPath := (&url.URL{Path: b.path + "/" + key}).EscapedPath()
+
+	req, err := http.NewRequestWithContext(ctx, method, b.endpoint+"/v1/"+b.mountPath+"/data/"+secretPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", b.token)
+	if b.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return b.client.Do(req)
+}
@@ -1,5 +1,10 @@
 package acme
 
+import (
+	"context"
+	"errors"
+)
+
 // StoredData represents the data managed by Store.
 type StoredData struct {
 	Account      *Account
@@ -13,3 +18,21 @@ type Store interface {
 	GetCertificates(resolverName string) ([]*CertAndStore, error)
 	SaveCertificates(resolverName string, certificates []*CertAndStore) error
 }
+
+// ErrLeaseHeld is returned by Leaser.AcquireLease when the lease is held by another instance.
+var ErrLeaseHeld = errors.New("lease held by another instance")
+
+// Leaser is implemented by the stores shared by several Traefik instances,
+// to make sure that only one of them obtains or renews a given certificate at a time.
+type Leaser interface {
+	// AcquireLease acquires the named lease for the given resolver, without waiting for it to be released.
+	// It returns ErrLeaseHeld when another instance holds the lease, and the function releasing it otherwise.
+	AcquireLease(ctx context.Context, resolverName, name string) (func(), error)
+}
+
+// Elector is implemented by the stores electing a leader among the instances sharing them.
+type Elector interface {
+	// RunElection takes part in the election of the leader of the resolver until the context is done.
+	// It calls onLeadership with true when the instance becomes the leader, and with false when it loses the leadership.
+	RunElection(ctx context.Context, resolverName string, onLeadership func(leader bool))
+}
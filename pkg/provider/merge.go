@@ -30,6 +30,7 @@ var resourceLogFields = map[reflect.Type]resourceMeta{
 	reflect.TypeFor[dynamic.TCPServersTransport](): {logs.ServersTransportName, "TCP servers transport"},
 	reflect.TypeFor[dynamic.UDPRouter]():           {logs.RouterName, "UDP router"},
 	reflect.TypeFor[dynamic.UDPService]():          {logs.ServiceName, "UDP service"},
+	reflect.TypeFor[dynamic.UDPMiddleware]():       {logs.MiddlewareName, "UDP middleware"},
 }
 
 // ResourceStrategy defines how the merge should handle resources.
@@ -76,8 +77,9 @@ func Merge(ctx context.Context, configurations []NamedConfiguration, strategy Re
 			ServersTransports: make(map[string]*dynamic.TCPServersTransport),
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  make(map[string]*dynamic.UDPRouter),
-			Services: make(map[string]*dynamic.UDPService),
+			Routers:     make(map[string]*dynamic.UDPRouter),
+			Services:    make(map[string]*dynamic.UDPService),
+			Middlewares: make(map[string]*dynamic.UDPMiddleware),
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Stores: make(map[string]tls.Store),
@@ -532,8 +532,9 @@ func buildExpectedConfiguration(modifier func(*dynamic.Configuration)) *dynamic.
 			ServersTransports: make(map[string]*dynamic.TCPServersTransport),
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  make(map[string]*dynamic.UDPRouter),
-			Services: make(map[string]*dynamic.UDPService),
+			Routers:     make(map[string]*dynamic.UDPRouter),
+			Services:    make(map[string]*dynamic.UDPService),
+			Middlewares: map[string]*dynamic.UDPMiddleware{},
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Stores: make(map[string]tls.Store),
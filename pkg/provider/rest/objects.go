@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// objectMap gives access to the objects of a given kind of the configuration, e.g. the HTTP routers.
+type objectMap interface {
+	get(name string) (any, bool)
+	set(name string, data []byte) (any, error)
+	patch(name string, object any, data []byte) (any, error)
+	remove(name string)
+}
+
+// newObjectMap returns the objects of the configuration for the protocol and kind path variables.
+// The sections of the configuration are initialized when needed.
+func newObjectMap(configuration *dynamic.Configuration, vars map[string]string) (objectMap, error) {
+	protocol, kind := vars["protocol"], vars["kind"]
+
+	switch protocol {
+	case "http":
+		if configuration.HTTP == nil {
+			configuration.HTTP = &dynamic.HTTPConfiguration{}
+		}
+
+		switch kind {
+		case "routers":
+			return objects[dynamic.Router]{items: &configuration.HTTP.Routers}, nil
+		case "services":
+			return objects[dynamic.Service]{items: &configuration.HTTP.Services}, nil
+		case "middlewares":
+			return objects[dynamic.Middleware]{items: &configuration.HTTP.Middlewares}, nil
+		case "serversTransports":
+			return objects[dynamic.ServersTransport]{items: &configuration.HTTP.ServersTransports}, nil
+		}
+
+	case "tcp":
+		if configuration.TCP == nil {
+			configuration.TCP = &dynamic.TCPConfiguration{}
+		}
+
+		switch kind {
+		case "routers":
+			return objects[dynamic.TCPRouter]{items: &configuration.TCP.Routers}, nil
+		case "services":
+			return objects[dynamic.TCPService]{items: &configuration.TCP.Services}, nil
+		case "middlewares":
+			return objects[dynamic.TCPMiddleware]{items: &configuration.TCP.Middlewares}, nil
+		case "serversTransports":
+			return objects[dynamic.TCPServersTransport]{items: &configuration.TCP.ServersTransports}, nil
+		}
+
+	case "udp":
+		if configuration.UDP == nil {
+			configuration.UDP = &dynamic.UDPConfiguration{}
+		}
+
+		switch kind {
+		case "routers":
+			return objects[dynamic.UDPRouter]{items: &configuration.UDP.Routers}, nil
+		case "services":
+			return objects[dynamic.UDPService]{items: &configuration.UDP.Services}, nil
+		case "middlewares":
+			return objects[dynamic.UDPMiddleware]{items: &configuration.UDP.Middlewares}, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown protocol %q", protocol)
+	}
+
+	return nil, fmt.Errorf("unknown %s object kind %q", protocol, kind)
+}
+
+type objects[T any] struct {
+	items *map[string]*T
+}
+
+func (o objects[T]) get(name string) (any, bool) {
+	item, ok := (*o.items)[name]
+	if !ok || item == nil {
+		return nil, false
+	}
+
+	return item, true
+}
+
+func (o objects[T]) set(name string, data []byte) (any, error) {
+	item := new(T)
+	if err := json.Unmarshal(data, item); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	if *o.items == nil {
+		*o.items = make(map[string]*T)
+	}
+	(*o.items)[name] = item
+
+	return item, nil
+}
+
+func (o objects[T]) patch(name string, object any, data []byte) (any, error) {
+	original, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling %s: %w", name, err)
+	}
+
+	patched, err := jsonpatch.MergePatch(original, data)
+	if err != nil {
+		return nil, fmt.Errorf("patching %s: %w", name, err)
+	}
+
+	return o.set(name, patched)
+}
+
+func (o objects[T]) remove(name string) {
+	delete(*o.items, name)
+}
@@ -1,9 +1,15 @@
 package rest
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
@@ -18,10 +24,25 @@ const ProviderName = "rest"
 
 var _ provider.Provider = (*Provider)(nil)
 
+var (
+	errNotFound      = errors.New("not found")
+	errAlreadyExists = errors.New("already exists")
+)
+
 // Provider is a provider.Provider implementation that provides a Rest API.
 type Provider struct {
-	Insecure          bool `description:"Activate REST Provider directly on the entryPoint named traefik." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
+	Insecure          bool   `description:"Activate REST Provider directly on the entryPoint named traefik." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
+	StorageFile       string `description:"File used to persist the configuration across restarts." json:"storageFile,omitempty" toml:"storageFile,omitempty" yaml:"storageFile,omitempty" export:"true"`
 	configurationChan chan<- dynamic.Message
+
+	// mu guards the configuration, which is only updated by one writer at a time,
+	// so that the ETag checks and the updates are atomic.
+	mu            sync.Mutex
+	configuration *dynamic.Configuration
+	etag          string
+
+	// provideMu serializes the sending of the configurations, which happens without holding mu.
+	provideMu sync.Mutex
 }
 
 // SetDefaults sets the default values.
@@ -31,20 +52,45 @@ var templatesRenderer = render.New(render.Options{Directory: "nowhere"})
 
 // Init the provider.
 func (p *Provider) Init() error {
+	configuration := &dynamic.Configuration{}
+
+	if p.StorageFile != "" {
+		var err error
+		configuration, err = loadStorage(p.StorageFile)
+		if err != nil {
+			return fmt.Errorf("loading REST provider storage: %w", err)
+		}
+	}
+
+	etag, err := computeETag(configuration)
+	if err != nil {
+		return err
+	}
+
+	p.configuration = configuration
+	p.etag = etag
+
 	return nil
 }
 
 // CreateRouter creates a router for the Rest API.
 func (p *Provider) CreateRouter() *mux.Router {
 	router := mux.NewRouter()
+	router.Methods(http.MethodGet).Path("/api/providers/{provider}").HandlerFunc(p.getConfiguration)
 	router.Methods(http.MethodPut).Path("/api/providers/{provider}").Handler(p)
+
+	objectPath := "/api/providers/{provider}/{protocol}/{kind}/{name}"
+	router.Methods(http.MethodGet).Path(objectPath).HandlerFunc(p.getObject)
+	router.Methods(http.MethodPost).Path(objectPath).HandlerFunc(p.createObject)
+	router.Methods(http.MethodPatch).Path(objectPath).HandlerFunc(p.patchObject)
+	router.Methods(http.MethodDelete).Path(objectPath).HandlerFunc(p.deleteObject)
+
 	return router
 }
 
+// ServeHTTP replaces the whole configuration.
 func (p *Provider) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	vars := mux.Vars(req)
-	if vars["provider"] != ProviderName {
-		http.Error(rw, "Only 'rest' provider can be updated through the REST API", http.StatusBadRequest)
+	if !checkProvider(rw, req) {
 		return
 	}
 
@@ -56,15 +102,274 @@ func (p *Provider) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	p.configurationChan <- dynamic.Message{ProviderName: ProviderName, Configuration: configuration}
+	ok := p.update(rw, req, func(current *dynamic.Configuration) error {
+		*current = *configuration
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	if err := templatesRenderer.JSON(rw, http.StatusOK, configuration); err != nil {
+		log.Error().Err(err).Send()
+	}
+}
+
+func (p *Provider) getConfiguration(rw http.ResponseWriter, req *http.Request) {
+	if !checkProvider(rw, req) {
+		return
+	}
+
+	p.mu.Lock()
+	configuration, etag := p.configuration.DeepCopy(), p.etag
+	p.mu.Unlock()
+
+	rw.Header().Set("ETag", etag)
 	if err := templatesRenderer.JSON(rw, http.StatusOK, configuration); err != nil {
 		log.Error().Err(err).Send()
 	}
 }
 
+func (p *Provider) getObject(rw http.ResponseWriter, req *http.Request) {
+	if !checkProvider(rw, req) {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	objects, ok := lookupObjects(rw, req, p.configuration)
+	if !ok {
+		return
+	}
+
+	object, exists := objects.get(mux.Vars(req)["name"])
+	if !exists {
+		http.Error(rw, errNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	rw.Header().Set("ETag", p.etag)
+	if err := templatesRenderer.JSON(rw, http.StatusOK, object); err != nil {
+		log.Error().Err(err).Send()
+	}
+}
+
+// createObject adds an object to the configuration, it fails if the object already exists.
+func (p *Provider) createObject(rw http.ResponseWriter, req *http.Request) {
+	p.writeObject(rw, req, http.StatusCreated, func(objects objectMap, name string, data []byte) (any, error) {
+		if _, exists := objects.get(name); exists {
+			return nil, errAlreadyExists
+		}
+
+		return objects.set(name, data)
+	})
+}
+
+// patchObject applies a JSON merge patch (RFC 7396) to an existing object of the configuration.
+func (p *Provider) patchObject(rw http.ResponseWriter, req *http.Request) {
+	p.writeObject(rw, req, http.StatusOK, func(objects objectMap, name string, data []byte) (any, error) {
+		object, exists := objects.get(name)
+		if !exists {
+			return nil, errNotFound
+		}
+
+		return objects.patch(name, object, data)
+	})
+}
+
+func (p *Provider) deleteObject(rw http.ResponseWriter, req *http.Request) {
+	if !checkProvider(rw, req) {
+		return
+	}
+
+	name := mux.Vars(req)["name"]
+
+	ok := p.update(rw, req, func(configuration *dynamic.Configuration) error {
+		objects, err := newObjectMap(configuration, mux.Vars(req))
+		if err != nil {
+			return err
+		}
+
+		if _, exists := objects.get(name); !exists {
+			return errNotFound
+		}
+
+		objects.remove(name)
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (p *Provider) writeObject(rw http.ResponseWriter, req *http.Request, status int, write func(objects objectMap, name string, data []byte) (any, error)) {
+	if !checkProvider(rw, req) {
+		return
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var object any
+	ok := p.update(rw, req, func(configuration *dynamic.Configuration) error {
+		objects, err := newObjectMap(configuration, mux.Vars(req))
+		if err != nil {
+			return err
+		}
+
+		object, err = write(objects, mux.Vars(req)["name"], data)
+		return err
+	})
+	if !ok {
+		return
+	}
+
+	if err := templatesRenderer.JSON(rw, status, object); err != nil {
+		log.Error().Err(err).Send()
+	}
+}
+
+// update applies the given modification to a copy of the configuration, if the If-Match precondition holds.
+// The new configuration is persisted before being provided, and its ETag is set on the response.
+// It reports whether the configuration has been updated, otherwise the error response has been written.
+func (p *Provider) update(rw http.ResponseWriter, req *http.Request, modify func(*dynamic.Configuration) error) bool {
+	if !p.modify(rw, req, modify) {
+		return false
+	}
+
+	p.provide()
+
+	return true
+}
+
+func (p *Provider) modify(rw http.ResponseWriter, req *http.Request, modify func(*dynamic.Configuration) error) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.configurationChan == nil {
+		http.Error(rw, "The REST provider is not started yet", http.StatusServiceUnavailable)
+		return false
+	}
+
+	if ifMatch := req.Header.Get("If-Match"); ifMatch != "" && !matchETag(ifMatch, p.etag) {
+		rw.Header().Set("ETag", p.etag)
+		http.Error(rw, "The configuration has been modified", http.StatusPreconditionFailed)
+		return false
+	}
+
+	configuration := p.configuration.DeepCopy()
+	if configuration == nil {
+		configuration = &dynamic.Configuration{}
+	}
+
+	if err := modify(configuration); err != nil {
+		switch {
+		case errors.Is(err, errNotFound):
+			http.Error(rw, err.Error(), http.StatusNotFound)
+		case errors.Is(err, errAlreadyExists):
+			http.Error(rw, err.Error(), http.StatusConflict)
+		default:
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+		}
+		return false
+	}
+
+	etag, err := computeETag(configuration)
+	if err != nil {
+		log.Error().Err(err).Msg("Error computing configuration ETag")
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if p.StorageFile != "" {
+		if err := saveStorage(p.StorageFile, configuration); err != nil {
+			log.Error().Err(err).Msg("Error persisting configuration")
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+	}
+
+	p.configuration = configuration
+	p.etag = etag
+
+	rw.Header().Set("ETag", etag)
+	return true
+}
+
+// provide sends the current configuration, without holding the configuration lock while the channel is blocked.
+// The configuration is read when it is its turn to be sent,
+// so that the last configuration sent is the last one updated, whatever the order of the concurrent writers.
+func (p *Provider) provide() {
+	p.provideMu.Lock()
+	defer p.provideMu.Unlock()
+
+	p.mu.Lock()
+	configuration := p.configuration.DeepCopy()
+	configurationChan := p.configurationChan
+	p.mu.Unlock()
+
+	configurationChan <- dynamic.Message{ProviderName: ProviderName, Configuration: configuration}
+}
+
 // Provide allows the provider to provide configurations to traefik
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	p.mu.Lock()
 	p.configurationChan = configurationChan
+	p.mu.Unlock()
+
+	// The persisted configuration is provided on startup.
+	if p.StorageFile != "" {
+		p.provide()
+	}
+
 	return nil
 }
+
+func checkProvider(rw http.ResponseWriter, req *http.Request) bool {
+	if mux.Vars(req)["provider"] != ProviderName {
+		http.Error(rw, "Only 'rest' provider can be updated through the REST API", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func lookupObjects(rw http.ResponseWriter, req *http.Request, configuration *dynamic.Configuration) (objectMap, bool) {
+	objects, err := newObjectMap(configuration.DeepCopy(), mux.Vars(req))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusNotFound)
+		return nil, false
+	}
+
+	return objects, true
+}
+
+// computeETag returns a strong ETag identifying the content of the configuration.
+func computeETag(configuration *dynamic.Configuration) (string, error) {
+	content, err := json.Marshal(configuration)
+	if err != nil {
+		return "", fmt.Errorf("marshaling configuration: %w", err)
+	}
+
+	hash := sha256.Sum256(content)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`, nil
+}
+
+// matchETag reports whether the If-Match header value matches the ETag.
+func matchETag(ifMatch, etag string) bool {
+	for value := range strings.SplitSeq(ifMatch, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || value == etag {
+			return true
+		}
+	}
+
+	return false
+}
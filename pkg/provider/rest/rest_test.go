@@ -0,0 +1,275 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestProvider_objects(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expected       *dynamic.Configuration
+	}{
+		{
+			desc:           "get configuration",
+			method:         http.MethodGet,
+			path:           "/api/providers/rest",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "get object",
+			method:         http.MethodGet,
+			path:           "/api/providers/rest/http/routers/foo",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "get missing object",
+			method:         http.MethodGet,
+			path:           "/api/providers/rest/http/routers/bar",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "create object",
+			method:         http.MethodPost,
+			path:           "/api/providers/rest/http/routers/bar",
+			body:           `{"rule":"Host(` + "`bar`" + `)","service":"foo"}`,
+			expectedStatus: http.StatusCreated,
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"foo": {Rule: "Host(`foo`)", Service: "foo"},
+						"bar": {Rule: "Host(`bar`)", Service: "foo"},
+					},
+					Services: map[string]*dynamic.Service{"foo": {}},
+				},
+			},
+		},
+		{
+			desc:           "create object in a new section",
+			method:         http.MethodPost,
+			path:           "/api/providers/rest/tcp/routers/bar",
+			body:           `{"rule":"HostSNI(` + "`*`" + `)","service":"foo"}`,
+			expectedStatus: http.StatusCreated,
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers:  map[string]*dynamic.Router{"foo": {Rule: "Host(`foo`)", Service: "foo"}},
+					Services: map[string]*dynamic.Service{"foo": {}},
+				},
+				TCP: &dynamic.TCPConfiguration{
+					Routers: map[string]*dynamic.TCPRouter{"bar": {Rule: "HostSNI(`*`)", Service: "foo"}},
+				},
+			},
+		},
+		{
+			desc:           "create existing object",
+			method:         http.MethodPost,
+			path:           "/api/providers/rest/http/routers/foo",
+			body:           `{"rule":"Host(` + "`foo`" + `)"}`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			desc:           "create invalid object",
+			method:         http.MethodPost,
+			path:           "/api/providers/rest/http/routers/bar",
+			body:           `{"rule":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "unknown kind",
+			method:         http.MethodPost,
+			path:           "/api/providers/rest/udp/serversTransports/bar",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "patch object",
+			method:         http.MethodPatch,
+			path:           "/api/providers/rest/http/routers/foo",
+			body:           `{"rule":"Host(` + "`baz`" + `)","priority":10}`,
+			expectedStatus: http.StatusOK,
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers:  map[string]*dynamic.Router{"foo": {Rule: "Host(`baz`)", Service: "foo", Priority: 10}},
+					Services: map[string]*dynamic.Service{"foo": {}},
+				},
+			},
+		},
+		{
+			desc:           "patch missing object",
+			method:         http.MethodPatch,
+			path:           "/api/providers/rest/http/routers/bar",
+			body:           `{"priority":10}`,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "delete object",
+			method:         http.MethodDelete,
+			path:           "/api/providers/rest/http/services/foo",
+			expectedStatus: http.StatusNoContent,
+			expected: &dynamic.Configuration{
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers:  map[string]*dynamic.Router{"foo": {Rule: "Host(`foo`)", Service: "foo"}},
+					Services: map[string]*dynamic.Service{},
+				},
+			},
+		},
+		{
+			desc:           "delete missing object",
+			method:         http.MethodDelete,
+			path:           "/api/providers/rest/http/services/bar",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "other provider",
+			method:         http.MethodDelete,
+			path:           "/api/providers/file/http/services/foo",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			configurationChan := make(chan dynamic.Message, 2)
+
+			p := &Provider{}
+			require.NoError(t, p.Init())
+			require.NoError(t, p.Provide(configurationChan, nil))
+
+			put(t, p, `{"http":{"routers":{"foo":{"rule":"Host(`+"`foo`"+`)","service":"foo"}},"services":{"foo":{}}}}`)
+			<-configurationChan
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			p.CreateRouter().ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+
+			if test.expected == nil {
+				assert.Empty(t, configurationChan)
+				return
+			}
+
+			require.Len(t, configurationChan, 1)
+			message := <-configurationChan
+			assert.Equal(t, ProviderName, message.ProviderName)
+			assert.Equal(t, test.expected, message.Configuration)
+			assert.NotEmpty(t, recorder.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestProvider_ifMatch(t *testing.T) {
+	configurationChan := make(chan dynamic.Message, 10)
+
+	p := &Provider{}
+	require.NoError(t, p.Init())
+	require.NoError(t, p.Provide(configurationChan, nil))
+
+	router := p.CreateRouter()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/providers/rest", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// A first writer updates the configuration.
+	req := httptest.NewRequest(http.MethodPost, "/api/providers/rest/http/services/foo", strings.NewReader(`{}`))
+	req.Header.Set("If-Match", etag)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	newETag := recorder.Header().Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+
+	// A second writer, with the previous ETag, is rejected.
+	req = httptest.NewRequest(http.MethodPost, "/api/providers/rest/http/services/bar", strings.NewReader(`{}`))
+	req.Header.Set("If-Match", etag)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	assert.Equal(t, newETag, recorder.Header().Get("ETag"))
+
+	// The current ETag among others is accepted.
+	req = httptest.NewRequest(http.MethodDelete, "/api/providers/rest/http/services/foo", nil)
+	req.Header.Set("If-Match", etag+", "+newETag)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+	assert.Len(t, configurationChan, 2)
+}
+
+func TestProvider_notStarted(t *testing.T) {
+	p := &Provider{}
+	require.NoError(t, p.Init())
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/providers/rest/http/services/foo", strings.NewReader(`{}`))
+	p.CreateRouter().ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	// The configuration is still readable while the update is rejected.
+	recorder = httptest.NewRecorder()
+	p.CreateRouter().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/providers/rest", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestProvider_storage(t *testing.T) {
+	storageFile := filepath.Join(t.TempDir(), "rest.json")
+
+	p := &Provider{StorageFile: storageFile}
+	require.NoError(t, p.Init())
+	// The empty configuration is provided on startup, then the created service.
+	require.NoError(t, p.Provide(make(chan dynamic.Message, 2), nil))
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/providers/rest/http/services/foo", strings.NewReader(`{"loadBalancer":{"servers":[{"url":"http://127.0.0.1"}]}}`))
+	p.CreateRouter().ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	// A new provider, as after a restart, provides the persisted configuration.
+	configurationChan := make(chan dynamic.Message, 1)
+
+	restarted := &Provider{StorageFile: storageFile}
+	require.NoError(t, restarted.Init())
+	require.NoError(t, restarted.Provide(configurationChan, nil))
+
+	require.Len(t, configurationChan, 1)
+	message := <-configurationChan
+
+	expected := &dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Services: map[string]*dynamic.Service{
+				"foo": {LoadBalancer: &dynamic.ServersLoadBalancer{Servers: []dynamic.Server{{URL: "http://127.0.0.1"}}}},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, message.Configuration)
+	assert.Equal(t, recorder.Header().Get("ETag"), restarted.etag)
+}
+
+func put(t *testing.T, p *Provider, body string) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/providers/rest", strings.NewReader(body))
+	p.CreateRouter().ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
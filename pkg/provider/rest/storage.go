@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// loadStorage reads the configuration persisted in the storage file.
+// A missing file is an empty configuration.
+func loadStorage(path string) (*dynamic.Configuration, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &dynamic.Configuration{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	configuration := &dynamic.Configuration{}
+	if len(content) == 0 {
+		return configuration, nil
+	}
+
+	if err := json.Unmarshal(content, configuration); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return configuration, nil
+}
+
+// saveStorage writes the configuration to the storage file.
+// The file is replaced atomically, so that a crash does not leave a truncated configuration behind.
+func saveStorage(path string, configuration *dynamic.Configuration) error {
+	content, err := json.MarshalIndent(configuration, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling configuration: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary file: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writing %s: %w", file.Name(), err)
+	}
+
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("syncing %s: %w", file.Name(), err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", file.Name(), err)
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,85 @@
+package redactor
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// credentialHeaders are the headers always redacted by Headers, as they carry credentials.
+var credentialHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Csrf-Token",
+	"X-Xsrf-Token",
+}
+
+// Headers returns a copy of the given headers where the values of the headers carrying credentials,
+// and of the given extra headers, are redacted.
+func Headers(header http.Header, extra ...string) http.Header {
+	redacted := header.Clone()
+	if redacted == nil {
+		return nil
+	}
+
+	for _, names := range [][]string{credentialHeaders, extra} {
+		for _, name := range names {
+			values := redacted.Values(name)
+			if len(values) == 0 {
+				continue
+			}
+
+			masked := make([]string, len(values))
+			for i := range values {
+				masked[i] = maskShort
+			}
+
+			redacted[http.CanonicalHeaderKey(name)] = masked
+		}
+	}
+
+	return redacted
+}
+
+// credentialQueryParams are the query parameters always redacted by Query, as they carry credentials.
+var credentialQueryParams = []string{
+	"access_token",
+	"api_key",
+	"apikey",
+	"password",
+	"token",
+}
+
+// Query returns the given raw query where the values of the parameters carrying credentials,
+// and of the given extra parameters, are redacted.
+// The parameter names are matched case-insensitively, and the order of the parameters is kept.
+func Query(rawQuery string, extra ...string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key, _, found := strings.Cut(param, "=")
+		if !found {
+			continue
+		}
+
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+
+		if slices.ContainsFunc(credentialQueryParams, func(n string) bool { return strings.EqualFold(n, name) }) ||
+			slices.ContainsFunc(extra, func(n string) bool { return strings.EqualFold(n, name) }) {
+			params[i] = key + "=" + maskShort
+		}
+	}
+
+	return strings.Join(params, "&")
+}
@@ -0,0 +1,70 @@
+package redactor
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization": {"Bearer token"},
+		"Cookie":        {"session=foo", "csrf=bar"},
+		"Content-Type":  {"application/json"},
+		"X-Secret":      {"s3cr3t"},
+	}
+
+	redacted := Headers(header, "x-secret")
+
+	assert.Equal(t, http.Header{
+		"Authorization": {"xxxx"},
+		"Cookie":        {"xxxx", "xxxx"},
+		"Content-Type":  {"application/json"},
+		"X-Secret":      {"xxxx"},
+	}, redacted)
+
+	// The given headers are not modified.
+	assert.Equal(t, []string{"Bearer token"}, header["Authorization"])
+
+	assert.Nil(t, Headers(nil))
+}
+
+func TestQuery(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rawQuery string
+		extra    []string
+		expected string
+	}{
+		{
+			desc:     "empty",
+			rawQuery: "",
+			expected: "",
+		},
+		{
+			desc:     "no credentials",
+			rawQuery: "foo=bar&baz",
+			expected: "foo=bar&baz",
+		},
+		{
+			desc:     "credential parameters",
+			rawQuery: "foo=bar&access_token=secret&Token=secret&token=",
+			expected: "foo=bar&access_token=xxxx&Token=xxxx&token=xxxx",
+		},
+		{
+			desc:     "extra parameters",
+			rawQuery: "foo=bar&x-sig=secret&x%2Dsig=secret&baz",
+			extra:    []string{"X-Sig"},
+			expected: "foo=bar&x-sig=xxxx&x%2Dsig=xxxx&baz",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, Query(test.rawQuery, test.extra...))
+		})
+	}
+}
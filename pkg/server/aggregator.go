@@ -34,8 +34,9 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 			ServersTransports: make(map[string]*dynamic.TCPServersTransport),
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  make(map[string]*dynamic.UDPRouter),
-			Services: make(map[string]*dynamic.UDPService),
+			Routers:     make(map[string]*dynamic.UDPRouter),
+			Services:    make(map[string]*dynamic.UDPService),
+			Middlewares: make(map[string]*dynamic.UDPMiddleware),
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Stores:  make(map[string]traefiktls.Store),
@@ -124,6 +125,9 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 			for serviceName, service := range configuration.UDP.Services {
 				conf.UDP.Services[provider.MakeQualifiedName(pvd, serviceName)] = service
 			}
+			for middlewareName, middleware := range configuration.UDP.Middlewares {
+				conf.UDP.Middlewares[provider.MakeQualifiedName(pvd, middlewareName)] = middleware
+			}
 		}
 
 		if configuration.TLS != nil {
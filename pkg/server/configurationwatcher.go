@@ -3,7 +3,10 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"maps"
 	"reflect"
+	"slices"
+	"sync"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -11,6 +14,7 @@ import (
 	"github.com/traefik/traefik/v3/pkg/observability/logs"
 	"github.com/traefik/traefik/v3/pkg/provider"
 	"github.com/traefik/traefik/v3/pkg/safe"
+	"github.com/traefik/traefik/v3/pkg/server/history"
 	"github.com/traefik/traefik/v3/pkg/tls"
 	"github.com/traefik/traefik/v3/pkg/types"
 )
@@ -27,11 +31,21 @@ type ConfigurationWatcher struct {
 
 	newConfigs chan dynamic.Configurations
 
+	refresh chan struct{}
+	// applied is the configuration last applied, only accessed by applyConfigurations.
+	applied *dynamic.Configuration
+
 	requiredProvider       string
 	configurationListeners []func(dynamic.Configuration)
 
 	configurationTransformers []func(context.Context, dynamic.Configurations) dynamic.Configurations
 
+	// providerConfigs holds the last configuration received from each provider, before transformation.
+	providerConfigsMu sync.RWMutex
+	providerConfigs   dynamic.Configurations
+
+	history *history.History
+
 	routinesPool *safe.Pool
 }
 
@@ -47,6 +61,7 @@ func NewConfigurationWatcher(
 		providerAggregator:  pvd,
 		allProvidersConfigs: make(chan dynamic.Message, 100),
 		newConfigs:          make(chan dynamic.Configurations),
+		refresh:             make(chan struct{}, 1),
 		routinesPool:        routinesPool,
 		defaultEntryPoints:  defaultEntryPoints,
 		requiredProvider:    requiredProvider,
@@ -72,6 +87,21 @@ func (c *ConfigurationWatcher) AddListener(listener func(dynamic.Configuration))
 	c.configurationListeners = append(c.configurationListeners, listener)
 }
 
+// Refresh applies the current configuration again, for instance when a runtime override changes how it is built.
+func (c *ConfigurationWatcher) Refresh() {
+	select {
+	case c.refresh <- struct{}{}:
+	default:
+		// A refresh is already pending.
+	}
+}
+
+// SetHistory sets the history recording the applied configurations,
+// and through which an older configuration can be applied again.
+func (c *ConfigurationWatcher) SetHistory(h *history.History) {
+	c.history = h
+}
+
 // AddTransformer registers a function to modify configurations before they are applied.
 func (c *ConfigurationWatcher) AddTransformer(transformer func(context.Context, dynamic.Configurations) dynamic.Configurations) {
 	c.configurationTransformers = append(c.configurationTransformers, transformer)
@@ -140,10 +170,11 @@ func (c *ConfigurationWatcher) receiveConfigurations(ctx context.Context) {
 
 				newConfigurations[configMsg.ProviderName] = configMsg.Configuration.DeepCopy()
 
-				transformedConfigurations = newConfigurations
-				for _, transform := range c.configurationTransformers {
-					transformedConfigurations = transform(logger.WithContext(ctx), transformedConfigurations.DeepCopy())
-				}
+				c.providerConfigsMu.Lock()
+				c.providerConfigs = newConfigurations.DeepCopy()
+				c.providerConfigsMu.Unlock()
+
+				transformedConfigurations = c.transform(logger.WithContext(ctx), newConfigurations)
 
 				output = c.newConfigs
 
@@ -158,8 +189,23 @@ func (c *ConfigurationWatcher) receiveConfigurations(ctx context.Context) {
 // applyConfigurations receives the full set of configurations from
 // receiveConfigurations and applies them if they differ from the previous set.
 // It waits for the required provider's configuration before applying any configs.
+// It also handles the requests to apply a configuration of the history again,
+// in which case the configurations of the providers are not applied while a snapshot is pinned.
 func (c *ConfigurationWatcher) applyConfigurations(ctx context.Context) {
+	var historyRequests <-chan history.Request
+	if c.history != nil {
+		historyRequests = c.history.Requests()
+	}
+
 	var lastConfigurations dynamic.Configurations
+
+	// latest is the configuration built from the last configurations of the providers,
+	// stale reports whether it is not the applied one,
+	// and pendingProviders are the providers whose configuration changed since it was last applied.
+	var latest *dynamic.Configuration
+	var stale bool
+	pendingProviders := map[string]struct{}{}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -178,21 +224,132 @@ func (c *ConfigurationWatcher) applyConfigurations(ctx context.Context) {
 				continue
 			}
 
-			conf := mergeConfiguration(newConfigs.DeepCopy(), c.defaultEntryPoints)
-			conf = applyModel(conf)
-			if conf.HTTP != nil {
-				conf.HTTP.Routers = resolveHTTPTLSOptions(conf.HTTP.Routers, c.strictTLSOptions)
+			conf := c.buildConfiguration(newConfigs.DeepCopy())
+
+			for name, config := range newConfigs {
+				if !reflect.DeepEqual(lastConfigurations[name], config) {
+					pendingProviders[name] = struct{}{}
+				}
 			}
+			for name := range lastConfigurations {
+				if _, ok := newConfigs[name]; !ok {
+					pendingProviders[name] = struct{}{}
+				}
+			}
+
+			lastConfigurations = newConfigs
+			latest = conf.DeepCopy()
+
+			if c.history != nil && c.history.Pinned() != 0 {
+				log.Ctx(ctx).Info().Msgf("Configuration snapshot %d is pinned, skipping the providers configuration", c.history.Pinned())
+				stale = true
+				continue
+			}
+
+			c.apply(conf, slices.Sorted(maps.Keys(pendingProviders)), 0)
+			clear(pendingProviders)
+			stale = false
 
+		case <-c.refresh:
+			if c.applied == nil {
+				continue
+			}
+
+			conf := c.applied.DeepCopy()
 			for _, listener := range c.configurationListeners {
-				listener(conf)
+				listener(*conf)
 			}
 
-			lastConfigurations = newConfigs
+		case req := <-historyRequests:
+			switch req.Action {
+			case history.ActionUnpin:
+				if !stale || latest == nil {
+					req.Done(history.Snapshot{}, nil)
+					continue
+				}
+
+				snapshot := c.apply(*latest.DeepCopy(), slices.Sorted(maps.Keys(pendingProviders)), 0)
+				clear(pendingProviders)
+				stale = false
+
+				req.Done(snapshot, nil)
+
+			default:
+				old, ok := c.history.Get(req.ID)
+				if !ok {
+					req.Done(history.Snapshot{}, history.ErrNotFound)
+					continue
+				}
+
+				log.Ctx(ctx).Info().Msgf("Applying configuration snapshot %d (%s)", old.ID, req.Action)
+
+				req.Done(c.apply(*old.Configuration.DeepCopy(), nil, old.ID), nil)
+				stale = true
+			}
 		}
 	}
 }
 
+// apply notifies the listeners of the configuration, and records it in the history.
+func (c *ConfigurationWatcher) apply(conf dynamic.Configuration, providers []string, reappliedFrom uint64) history.Snapshot {
+	var snapshot history.Snapshot
+	if c.history != nil {
+		// The configuration is recorded before the listeners may modify it.
+		snapshot = c.history.Record(conf, providers, reappliedFrom)
+	}
+
+	c.applied = conf.DeepCopy()
+
+	for _, listener := range c.configurationListeners {
+		listener(conf)
+	}
+
+	return snapshot
+}
+
+// DryRun returns the configuration which is currently applied,
+// and the one which would be applied if the given provider provided the candidate configuration.
+// As for the configurations received from the providers, an empty candidate configuration is ignored.
+func (c *ConfigurationWatcher) DryRun(ctx context.Context, providerName string, candidate *dynamic.Configuration) (dynamic.Configuration, dynamic.Configuration) {
+	c.providerConfigsMu.RLock()
+	configs := c.providerConfigs.DeepCopy()
+	c.providerConfigsMu.RUnlock()
+
+	if configs == nil {
+		configs = make(dynamic.Configurations)
+	}
+
+	// The merge modifies the given configurations.
+	current := c.buildConfiguration(c.transform(ctx, configs.DeepCopy()))
+
+	if candidate != nil && !isEmptyConfiguration(candidate.DeepCopy()) {
+		configs[providerName] = candidate.DeepCopy()
+	}
+
+	return current, c.buildConfiguration(c.transform(ctx, configs))
+}
+
+// transform applies the configuration transformers to a copy of the given configurations.
+func (c *ConfigurationWatcher) transform(ctx context.Context, configurations dynamic.Configurations) dynamic.Configurations {
+	transformed := configurations
+	for _, transform := range c.configurationTransformers {
+		transformed = transform(ctx, transformed.DeepCopy())
+	}
+
+	return transformed
+}
+
+// buildConfiguration merges the configurations of the providers into the configuration to apply.
+func (c *ConfigurationWatcher) buildConfiguration(configurations dynamic.Configurations) dynamic.Configuration {
+	conf := mergeConfiguration(configurations, c.defaultEntryPoints)
+	conf = applyModel(conf)
+	if conf.HTTP != nil {
+		conf.HTTP.Routers = resolveHTTPTLSOptions(conf.HTTP.Routers, c.strictTLSOptions)
+	}
+
+	return conf
+}
+
 func logConfiguration(logger zerolog.Logger, configMsg dynamic.Message) {
 	if logger.GetLevel() > zerolog.DebugLevel {
 		return
@@ -258,7 +415,7 @@ func isEmptyConfiguration(conf *dynamic.Configuration) bool {
 	httpEmpty := conf.HTTP.Routers == nil && conf.HTTP.Services == nil && conf.HTTP.Middlewares == nil
 	tlsEmpty := conf.TLS == nil || conf.TLS.Certificates == nil && conf.TLS.Stores == nil && conf.TLS.Options == nil
 	tcpEmpty := conf.TCP.Routers == nil && conf.TCP.Services == nil && conf.TCP.Middlewares == nil
-	udpEmpty := conf.UDP.Routers == nil && conf.UDP.Services == nil
+	udpEmpty := conf.UDP.Routers == nil && conf.UDP.Services == nil && conf.UDP.Middlewares == nil
 
 	return httpEmpty && tlsEmpty && tcpEmpty && udpEmpty
 }
@@ -3,15 +3,19 @@ package server
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
 	"strconv"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/provider/aggregator"
 	"github.com/traefik/traefik/v3/pkg/safe"
+	"github.com/traefik/traefik/v3/pkg/server/history"
 	th "github.com/traefik/traefik/v3/pkg/testhelpers"
 	"github.com/traefik/traefik/v3/pkg/tls"
 )
@@ -103,8 +107,9 @@ func TestNewConfigurationWatcher(t *testing.T) {
 				Stores: map[string]tls.Store{},
 			},
 			UDP: &dynamic.UDPConfiguration{
-				Routers:  map[string]*dynamic.UDPRouter{},
-				Services: map[string]*dynamic.UDPService{},
+				Routers:     map[string]*dynamic.UDPRouter{},
+				Services:    map[string]*dynamic.UDPService{},
+				Middlewares: map[string]*dynamic.UDPMiddleware{},
 			},
 		}
 
@@ -194,8 +199,9 @@ func TestIgnoreTransientConfiguration(t *testing.T) {
 			ServersTransports: map[string]*dynamic.TCPServersTransport{},
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  map[string]*dynamic.UDPRouter{},
-			Services: map[string]*dynamic.UDPService{},
+			Routers:     map[string]*dynamic.UDPRouter{},
+			Services:    map[string]*dynamic.UDPService{},
+			Middlewares: map[string]*dynamic.UDPMiddleware{},
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Options: map[string]tls.Options{
@@ -221,8 +227,9 @@ func TestIgnoreTransientConfiguration(t *testing.T) {
 			ServersTransports: map[string]*dynamic.TCPServersTransport{},
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  map[string]*dynamic.UDPRouter{},
-			Services: map[string]*dynamic.UDPService{},
+			Routers:     map[string]*dynamic.UDPRouter{},
+			Services:    map[string]*dynamic.UDPService{},
+			Middlewares: map[string]*dynamic.UDPMiddleware{},
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Options: map[string]tls.Options{
@@ -482,8 +489,9 @@ func TestListenProvidersDoesNotSkipFlappingConfiguration(t *testing.T) {
 			ServersTransports: map[string]*dynamic.TCPServersTransport{},
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  map[string]*dynamic.UDPRouter{},
-			Services: map[string]*dynamic.UDPService{},
+			Routers:     map[string]*dynamic.UDPRouter{},
+			Services:    map[string]*dynamic.UDPService{},
+			Middlewares: map[string]*dynamic.UDPMiddleware{},
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Options: map[string]tls.Options{
@@ -579,8 +587,9 @@ func TestListenProvidersIgnoreSameConfig(t *testing.T) {
 			ServersTransports: map[string]*dynamic.TCPServersTransport{},
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  map[string]*dynamic.UDPRouter{},
-			Services: map[string]*dynamic.UDPService{},
+			Routers:     map[string]*dynamic.UDPRouter{},
+			Services:    map[string]*dynamic.UDPService{},
+			Middlewares: map[string]*dynamic.UDPMiddleware{},
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Options: map[string]tls.Options{
@@ -727,8 +736,9 @@ func TestListenProvidersIgnoreIntermediateConfigs(t *testing.T) {
 			ServersTransports: map[string]*dynamic.TCPServersTransport{},
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  map[string]*dynamic.UDPRouter{},
-			Services: map[string]*dynamic.UDPService{},
+			Routers:     map[string]*dynamic.UDPRouter{},
+			Services:    map[string]*dynamic.UDPService{},
+			Middlewares: map[string]*dynamic.UDPMiddleware{},
 		},
 		TLS: &dynamic.TLSConfiguration{
 			Options: map[string]tls.Options{
@@ -804,8 +814,9 @@ func TestListenProvidersPublishesConfigForEachProvider(t *testing.T) {
 			Stores: map[string]tls.Store{},
 		},
 		UDP: &dynamic.UDPConfiguration{
-			Routers:  map[string]*dynamic.UDPRouter{},
-			Services: map[string]*dynamic.UDPService{},
+			Routers:     map[string]*dynamic.UDPRouter{},
+			Services:    map[string]*dynamic.UDPService{},
+			Middlewares: map[string]*dynamic.UDPMiddleware{},
 		},
 	}
 
@@ -1041,3 +1052,148 @@ func TestEntryPointTLSResolvedOptions(t *testing.T) {
 
 	<-run
 }
+
+func TestConfigurationWatcher_DryRun(t *testing.T) {
+	routinesPool := safe.NewPool(t.Context())
+	t.Cleanup(routinesPool.Stop)
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{{
+			ProviderName: "mock",
+			Configuration: &dynamic.Configuration{
+				HTTP: th.BuildConfiguration(
+					th.WithRouters(th.WithRouter("foo", th.WithServiceName("scv"))),
+				),
+			},
+		}},
+	}
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{"web"}, "", false)
+	watcher.AddTransformer(func(_ context.Context, configurations dynamic.Configurations) dynamic.Configurations {
+		for _, configuration := range configurations {
+			for _, router := range configuration.HTTP.Routers {
+				router.Priority = 42
+			}
+		}
+		return configurations
+	})
+
+	run := make(chan struct{})
+	watcher.AddListener(func(_ dynamic.Configuration) {
+		close(run)
+	})
+
+	watcher.Start()
+	<-run
+
+	candidate := &dynamic.Configuration{
+		HTTP: th.BuildConfiguration(
+			th.WithRouters(th.WithRouter("bar", th.WithServiceName("scv"))),
+		),
+	}
+
+	current, next := watcher.DryRun(t.Context(), "other", candidate)
+
+	assert.Len(t, current.HTTP.Routers, 1)
+	assert.Equal(t, []string{"web"}, current.HTTP.Routers["foo@mock"].EntryPoints)
+
+	assert.Len(t, next.HTTP.Routers, 2)
+	assert.Equal(t, 42, next.HTTP.Routers["bar@other"].Priority)
+	assert.Equal(t, []string{"web"}, next.HTTP.Routers["bar@other"].EntryPoints)
+
+	// The candidate replaces the configuration of its provider.
+	current, next = watcher.DryRun(t.Context(), "mock", candidate)
+
+	assert.Contains(t, current.HTTP.Routers, "foo@mock")
+	assert.Len(t, next.HTTP.Routers, 1)
+	assert.Contains(t, next.HTTP.Routers, "bar@mock")
+
+	// An empty candidate is ignored, as a provider empty configuration.
+	_, next = watcher.DryRun(t.Context(), "mock", &dynamic.Configuration{})
+
+	assert.Contains(t, next.HTTP.Routers, "foo@mock")
+}
+
+func TestConfigurationWatcher_history(t *testing.T) {
+	routinesPool := safe.NewPool(t.Context())
+	t.Cleanup(routinesPool.Stop)
+
+	message := func(routerName string) dynamic.Message {
+		return dynamic.Message{
+			ProviderName: "mock",
+			Configuration: &dynamic.Configuration{
+				HTTP: th.BuildConfiguration(th.WithRouters(th.WithRouter(routerName))),
+			},
+		}
+	}
+
+	pvd := &mockProvider{messages: []dynamic.Message{message("foo")}}
+
+	configurationHistory := history.New(10)
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "", false)
+	watcher.SetHistory(configurationHistory)
+
+	applied := make(chan dynamic.Configuration, 10)
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		applied <- conf
+	})
+
+	watcher.Start()
+
+	assertApplied := func(t *testing.T, routerName string) {
+		t.Helper()
+
+		select {
+		case conf := <-applied:
+			assert.Equal(t, []string{routerName + "@mock"}, slices.Collect(maps.Keys(conf.HTTP.Routers)))
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the configuration to be applied")
+		}
+	}
+
+	assertApplied(t, "foo")
+
+	watcher.allProvidersConfigs <- message("bar")
+	assertApplied(t, "bar")
+
+	snapshots := configurationHistory.Snapshots()
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, uint64(2), snapshots[0].ID)
+	assert.Equal(t, []string{"mock"}, snapshots[0].Providers)
+	assert.NotEqual(t, snapshots[0].Hash, snapshots[1].Hash)
+
+	// The pinned snapshot stays applied when a provider configuration changes.
+	snapshot, err := configurationHistory.Pin(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), snapshot.ID)
+	assert.Equal(t, uint64(1), snapshot.ReappliedFrom)
+	assert.Equal(t, snapshots[1].Hash, snapshot.Hash)
+	assert.Equal(t, uint64(3), configurationHistory.Pinned())
+	assertApplied(t, "foo")
+
+	watcher.allProvidersConfigs <- message("baz")
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, applied)
+
+	// Unpinning applies the latest provider configuration.
+	snapshot, err = configurationHistory.Unpin(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), snapshot.ID)
+	assert.Equal(t, []string{"mock"}, snapshot.Providers)
+	assert.Zero(t, configurationHistory.Pinned())
+	assertApplied(t, "baz")
+
+	snapshot, err = configurationHistory.Reapply(t.Context(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), snapshot.ReappliedFrom)
+	assertApplied(t, "bar")
+
+	// The re-applied snapshot is replaced by the next provider configuration change.
+	watcher.allProvidersConfigs <- message("qux")
+	assertApplied(t, "qux")
+
+	_, err = configurationHistory.Reapply(t.Context(), 42)
+	require.ErrorIs(t, err, history.ErrNotFound)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+)
+
+// DryRunner evaluates candidate provider configurations without applying them,
+// with the same merge, transformation and build steps as the applied configurations.
+type DryRunner struct {
+	mu            sync.RWMutex
+	watcher       *ConfigurationWatcher
+	routerFactory *RouterFactory
+}
+
+// NewDryRunner creates a new DryRunner.
+// It is available once set up, as the API handler using it is built before the watcher and the router factory.
+func NewDryRunner() *DryRunner {
+	return &DryRunner{}
+}
+
+// SetUp sets the configuration watcher and the router factory used to evaluate the configurations.
+func (d *DryRunner) SetUp(watcher *ConfigurationWatcher, routerFactory *RouterFactory) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.watcher = watcher
+	d.routerFactory = routerFactory
+}
+
+// DryRun returns the runtime configuration currently applied,
+// and the one which would be applied if the given provider provided the candidate configuration.
+// The routers of the candidate configuration are built, but not run, to report their errors.
+func (d *DryRunner) DryRun(ctx context.Context, providerName string, configuration *dynamic.Configuration) (*runtime.Configuration, *runtime.Configuration, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.watcher == nil || d.routerFactory == nil {
+		return nil, nil, errors.New("configuration dry run is not available")
+	}
+
+	current, candidate := d.watcher.DryRun(ctx, providerName, configuration)
+
+	rtConf := runtime.NewConfig(candidate)
+	d.routerFactory.CheckRouters(rtConf)
+
+	return runtime.NewConfig(current), rtConf, nil
+}
@@ -0,0 +1,198 @@
+package history
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// ErrNotFound is returned when the requested snapshot is not, or no longer, in the history.
+var ErrNotFound = errors.New("snapshot not found")
+
+// Action is an operation requested on the applied configuration.
+type Action string
+
+// Actions which can be requested on the applied configuration.
+const (
+	// ActionReapply applies a snapshot until the next configuration change of a provider.
+	ActionReapply Action = "reapply"
+	// ActionPin applies a snapshot and ignores the configuration changes of the providers until unpinned.
+	ActionPin Action = "pin"
+	// ActionUnpin applies the latest configuration of the providers again, in place of a re-applied snapshot.
+	ActionUnpin Action = "unpin"
+)
+
+// Snapshot is a configuration applied by the configuration watcher.
+type Snapshot struct {
+	ID   uint64    `json:"id"`
+	Date time.Time `json:"date"`
+	// Providers are the providers whose configuration change led to this snapshot.
+	Providers []string `json:"providers,omitempty"`
+	// Hash is the SHA-256 of the JSON representation of the configuration.
+	Hash string `json:"hash"`
+	// ReappliedFrom is the ID of the snapshot re-applied by this one, if any.
+	ReappliedFrom uint64 `json:"reappliedFrom,omitempty"`
+
+	Configuration dynamic.Configuration `json:"-"`
+}
+
+// Request is a request to change the applied configuration, handled by the configuration watcher.
+type Request struct {
+	Action Action
+	// ID is the ID of the snapshot to apply, unused by ActionUnpin.
+	ID uint64
+
+	history *History
+	result  chan result
+}
+
+type result struct {
+	snapshot Snapshot
+	err      error
+}
+
+// Done reports the outcome of the request,
+// with the snapshot recorded when the configuration has been applied.
+func (r Request) Done(snapshot Snapshot, err error) {
+	if err == nil {
+		r.history.mu.Lock()
+		switch r.Action {
+		case ActionPin:
+			r.history.pinned = snapshot.ID
+		default:
+			r.history.pinned = 0
+		}
+		r.history.mu.Unlock()
+	}
+
+	r.result <- result{snapshot: snapshot, err: err}
+}
+
+// History is a bounded, in-memory, history of the applied configurations.
+type History struct {
+	mu        sync.RWMutex
+	size      int
+	snapshots []Snapshot
+	lastID    uint64
+	pinned    uint64
+
+	requests chan Request
+}
+
+// New creates a History keeping the given number of snapshots.
+func New(size int) *History {
+	return &History{
+		size:     max(size, 1),
+		requests: make(chan Request),
+	}
+}
+
+// Record adds an applied configuration to the history, evicting the oldest snapshot when the history is full.
+func (h *History) Record(conf dynamic.Configuration, providers []string, reappliedFrom uint64) Snapshot {
+	var hash string
+	if content, err := json.Marshal(conf); err == nil {
+		sum := sha256.Sum256(content)
+		hash = hex.EncodeToString(sum[:])
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+
+	snapshot := Snapshot{
+		ID:            h.lastID,
+		Date:          time.Now().UTC(),
+		Providers:     providers,
+		Hash:          hash,
+		ReappliedFrom: reappliedFrom,
+		Configuration: *conf.DeepCopy(),
+	}
+
+	if len(h.snapshots) >= h.size {
+		h.snapshots = slices.Delete(h.snapshots, 0, len(h.snapshots)-h.size+1)
+	}
+	h.snapshots = append(h.snapshots, snapshot)
+
+	return snapshot
+}
+
+// Snapshots returns the snapshots of the history, the most recent first.
+func (h *History) Snapshots() []Snapshot {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	snapshots := slices.Clone(h.snapshots)
+	slices.Reverse(snapshots)
+
+	return snapshots
+}
+
+// Get returns the snapshot with the given ID.
+func (h *History) Get(id uint64) (Snapshot, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, snapshot := range h.snapshots {
+		if snapshot.ID == id {
+			return snapshot, true
+		}
+	}
+
+	return Snapshot{}, false
+}
+
+// Pinned returns the ID of the pinned snapshot, or zero when no snapshot is pinned.
+func (h *History) Pinned() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.pinned
+}
+
+// Requests returns the channel of the requests to change the applied configuration.
+func (h *History) Requests() <-chan Request {
+	return h.requests
+}
+
+// Reapply applies the snapshot with the given ID again, until the next configuration change of a provider.
+// It unpins the pinned snapshot, if any.
+func (h *History) Reapply(ctx context.Context, id uint64) (Snapshot, error) {
+	return h.do(ctx, Request{Action: ActionReapply, ID: id})
+}
+
+// Pin applies the snapshot with the given ID again, and keeps it applied until unpinned.
+func (h *History) Pin(ctx context.Context, id uint64) (Snapshot, error) {
+	return h.do(ctx, Request{Action: ActionPin, ID: id})
+}
+
+// Unpin applies the latest configuration of the providers again,
+// if a snapshot is pinned or has been re-applied since it was last applied.
+func (h *History) Unpin(ctx context.Context) (Snapshot, error) {
+	return h.do(ctx, Request{Action: ActionUnpin})
+}
+
+func (h *History) do(ctx context.Context, req Request) (Snapshot, error) {
+	req.history = h
+	req.result = make(chan result, 1)
+
+	select {
+	case <-ctx.Done():
+		return Snapshot{}, ctx.Err()
+	case h.requests <- req:
+	}
+
+	select {
+	case <-ctx.Done():
+		return Snapshot{}, ctx.Err()
+	case res := <-req.result:
+		return res.snapshot, res.err
+	}
+}
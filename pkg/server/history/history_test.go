@@ -0,0 +1,77 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestHistory_Record(t *testing.T) {
+	h := New(2)
+
+	configuration := func(routerName string) dynamic.Configuration {
+		return dynamic.Configuration{
+			HTTP: &dynamic.HTTPConfiguration{
+				Routers: map[string]*dynamic.Router{routerName: {Service: "foo"}},
+			},
+		}
+	}
+
+	first := h.Record(configuration("foo"), []string{"file"}, 0)
+	h.Record(configuration("bar"), []string{"docker"}, 0)
+	last := h.Record(configuration("foo"), nil, first.ID)
+
+	snapshots := h.Snapshots()
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, uint64(3), snapshots[0].ID)
+	assert.Equal(t, uint64(2), snapshots[1].ID)
+	assert.Equal(t, []string{"docker"}, snapshots[1].Providers)
+
+	// Identical configurations have the same hash.
+	assert.Len(t, last.Hash, 64)
+	assert.Equal(t, first.Hash, last.Hash)
+	assert.NotEqual(t, snapshots[1].Hash, last.Hash)
+
+	_, ok := h.Get(first.ID)
+	assert.False(t, ok, "the oldest snapshot should have been evicted")
+
+	snapshot, ok := h.Get(last.ID)
+	require.True(t, ok)
+	assert.Equal(t, first.ID, snapshot.ReappliedFrom)
+	assert.Equal(t, configuration("foo"), snapshot.Configuration)
+}
+
+func TestHistory_requests(t *testing.T) {
+	h := New(10)
+	snapshot := h.Record(dynamic.Configuration{}, []string{"file"}, 0)
+
+	go func() {
+		req := <-h.Requests()
+		assert.Equal(t, ActionPin, req.Action)
+		req.Done(h.Record(dynamic.Configuration{}, nil, req.ID), nil)
+
+		req = <-h.Requests()
+		assert.Equal(t, ActionReapply, req.Action)
+		req.Done(Snapshot{}, ErrNotFound)
+
+		req = <-h.Requests()
+		assert.Equal(t, ActionUnpin, req.Action)
+		req.Done(Snapshot{}, nil)
+	}()
+
+	pinned, err := h.Pin(t.Context(), snapshot.ID)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.ID, pinned.ReappliedFrom)
+	assert.Equal(t, pinned.ID, h.Pinned())
+
+	// A failed request does not change the pinned snapshot.
+	_, err = h.Reapply(t.Context(), 42)
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, pinned.ID, h.Pinned())
+
+	_, err = h.Unpin(t.Context())
+	require.NoError(t, err)
+	assert.Zero(t, h.Pinned())
+}
@@ -12,16 +12,20 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/middlewares/addprefix"
 	"github.com/traefik/traefik/v3/pkg/middlewares/auth"
+	"github.com/traefik/traefik/v3/pkg/middlewares/bodyrewrite"
 	"github.com/traefik/traefik/v3/pkg/middlewares/buffering"
+	"github.com/traefik/traefik/v3/pkg/middlewares/cache"
 	"github.com/traefik/traefik/v3/pkg/middlewares/chain"
 	"github.com/traefik/traefik/v3/pkg/middlewares/circuitbreaker"
 	"github.com/traefik/traefik/v3/pkg/middlewares/compress"
 	"github.com/traefik/traefik/v3/pkg/middlewares/contenttype"
 	"github.com/traefik/traefik/v3/pkg/middlewares/customerrors"
+	"github.com/traefik/traefik/v3/pkg/middlewares/debugcapture"
 	"github.com/traefik/traefik/v3/pkg/middlewares/encodedcharacters"
 	"github.com/traefik/traefik/v3/pkg/middlewares/gatewayapi/headermodifier"
 	gapiredirect "github.com/traefik/traefik/v3/pkg/middlewares/gatewayapi/redirect"
 	"github.com/traefik/traefik/v3/pkg/middlewares/gatewayapi/urlrewrite"
+	"github.com/traefik/traefik/v3/pkg/middlewares/grpctranscoder"
 	"github.com/traefik/traefik/v3/pkg/middlewares/grpcweb"
 	"github.com/traefik/traefik/v3/pkg/middlewares/headers"
 	"github.com/traefik/traefik/v3/pkg/middlewares/inflightreq"
@@ -32,7 +36,9 @@ import (
 	"github.com/traefik/traefik/v3/pkg/middlewares/ingressnginx/upstreamvhost"
 	"github.com/traefik/traefik/v3/pkg/middlewares/ipallowlist"
 	"github.com/traefik/traefik/v3/pkg/middlewares/ipwhitelist"
+	"github.com/traefik/traefik/v3/pkg/middlewares/jwt"
 	"github.com/traefik/traefik/v3/pkg/middlewares/observability"
+	"github.com/traefik/traefik/v3/pkg/middlewares/oidc"
 	"github.com/traefik/traefik/v3/pkg/middlewares/passtlsclientcert"
 	"github.com/traefik/traefik/v3/pkg/middlewares/ratelimiter"
 	"github.com/traefik/traefik/v3/pkg/middlewares/redirect"
@@ -41,15 +47,20 @@ import (
 	"github.com/traefik/traefik/v3/pkg/middlewares/retry"
 	"github.com/traefik/traefik/v3/pkg/middlewares/stripprefix"
 	"github.com/traefik/traefik/v3/pkg/middlewares/stripprefixregex"
+	"github.com/traefik/traefik/v3/pkg/middlewares/waf"
+	"github.com/traefik/traefik/v3/pkg/observability/metrics"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
 	"github.com/traefik/traefik/v3/pkg/server/recursion"
 )
 
 // Builder the middleware builder.
 type Builder struct {
-	configs        map[string]*runtime.MiddlewareInfo
-	pluginBuilder  PluginsBuilder
-	serviceBuilder serviceBuilder
+	configs         map[string]*runtime.MiddlewareInfo
+	pluginBuilder   PluginsBuilder
+	serviceBuilder  serviceBuilder
+	metricsRegistry metrics.Registry
+
+	dryRun bool
 }
 
 type serviceBuilder interface {
@@ -57,8 +68,14 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder}
+func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder, metricsRegistry metrics.Registry) *Builder {
+	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder, metricsRegistry: metricsRegistry}
+}
+
+// SetDryRun makes the builder check the middlewares keeping state across configuration reloads,
+// without creating or replacing the state of the applied ones, nor connecting to external stores.
+func (b *Builder) SetDryRun(dryRun bool) {
+	b.dryRun = dryRun
 }
 
 // BuildMiddlewareChain creates a middleware chain.
@@ -124,6 +141,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// BodyRewrite
+	if config.BodyRewrite != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return bodyrewrite.New(ctx, next, *config.BodyRewrite, middlewareName)
+		}
+	}
+
 	// Buffering
 	if config.Buffering != nil {
 		if middleware != nil {
@@ -134,6 +161,23 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// Cache
+	if config.Cache != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			if b.dryRun {
+				if err := cache.Validate(*config.Cache); err != nil {
+					return nil, err
+				}
+				return next, nil
+			}
+
+			return cache.New(ctx, next, *config.Cache, b.metricsRegistry, middlewareName)
+		}
+	}
+
 	// Chain
 	if config.Chain != nil {
 		if middleware != nil {
@@ -200,6 +244,23 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// DebugCapture
+	if config.DebugCapture != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			if b.dryRun {
+				if err := debugcapture.Validate(*config.DebugCapture); err != nil {
+					return nil, err
+				}
+				return next, nil
+			}
+
+			return debugcapture.New(ctx, next, *config.DebugCapture, middlewareName)
+		}
+	}
+
 	// DigestAuth
 	if config.DigestAuth != nil {
 		if middleware != nil {
@@ -230,6 +291,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// GrpcTranscoder
+	if config.GrpcTranscoder != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return grpctranscoder.New(ctx, next, *config.GrpcTranscoder, middlewareName)
+		}
+	}
+
 	// Headers
 	if config.Headers != nil {
 		if middleware != nil {
@@ -273,6 +344,26 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// JWT
+	if config.JWT != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return jwt.New(ctx, next, *config.JWT, middlewareName)
+		}
+	}
+
+	// OIDC
+	if config.OIDC != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return oidc.New(ctx, next, *config.OIDC, middlewareName)
+		}
+	}
+
 	// PassTLSClientCert
 	if config.PassTLSClientCert != nil {
 		if middleware != nil {
@@ -299,7 +390,13 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName)
+			rateLimit := *config.RateLimit
+			if b.dryRun {
+				// The buckets are checked in memory.
+				rateLimit.Redis = nil
+			}
+
+			return ratelimiter.New(ctx, next, rateLimit, middlewareName)
 		}
 	}
 
@@ -404,6 +501,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// WAF
+	if config.WAF != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return waf.New(ctx, next, *config.WAF, middlewareName)
+		}
+	}
+
 	// Plugin
 	if config.Plugin != nil && !reflect.ValueOf(b.pluginBuilder).IsNil() { // Using "reflect" because "b.pluginBuilder" is an interface.
 		if middleware != nil {
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/middlewares/cache"
+	"github.com/traefik/traefik/v3/pkg/middlewares/debugcapture"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
 )
 
@@ -17,7 +19,7 @@ func TestBuilder_BuildChainNilConfig(t *testing.T) {
 	testConfig := map[string]*runtime.MiddlewareInfo{
 		"empty": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, nil)
 
 	chain := middlewaresBuilder.BuildMiddlewareChain(t.Context(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -28,7 +30,7 @@ func TestBuilder_BuildChainNonExistentChain(t *testing.T) {
 	testConfig := map[string]*runtime.MiddlewareInfo{
 		"foobar": {},
 	}
-	middlewaresBuilder := NewBuilder(testConfig, nil, nil)
+	middlewaresBuilder := NewBuilder(testConfig, nil, nil, nil)
 
 	chain := middlewaresBuilder.BuildMiddlewareChain(t.Context(), []string{"empty"})
 	_, err := chain.Then(nil)
@@ -269,7 +271,7 @@ func TestBuilder_BuildChainWithContext(t *testing.T) {
 					Middlewares: test.configuration,
 				},
 			})
-			builder := NewBuilder(rtConf.Middlewares, nil, nil)
+			builder := NewBuilder(rtConf.Middlewares, nil, nil, nil)
 
 			result := builder.BuildMiddlewareChain(ctx, test.buildChain)
 
@@ -328,7 +330,7 @@ func TestBuilder_buildConstructor(t *testing.T) {
 			Middlewares: testConfig,
 		},
 	})
-	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil)
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil, nil)
 
 	testCases := []struct {
 		desc          string
@@ -380,3 +382,82 @@ func TestBuilder_buildConstructor(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_dryRun(t *testing.T) {
+	cacheConfig := &dynamic.Cache{}
+	cacheConfig.SetDefaults()
+
+	captureConfig := &dynamic.DebugCapture{}
+	captureConfig.SetDefaults()
+
+	rtConf := runtime.NewConfig(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"cache-dryrun":         {Cache: cacheConfig},
+				"cache-invalid-dryrun": {Cache: &dynamic.Cache{}},
+				"capture-dryrun":       {DebugCapture: captureConfig},
+				"capture-invalid-dryrun": {DebugCapture: &dynamic.DebugCapture{
+					MaxRecords: 1,
+					Rule:       "Invalid(`foo`)",
+				}},
+				"ratelimit-dryrun": {RateLimit: &dynamic.RateLimit{
+					Average: 10,
+					Redis:   &dynamic.Redis{Endpoints: []string{"localhost:6379"}},
+				}},
+			},
+		},
+	})
+
+	middlewaresBuilder := NewBuilder(rtConf.Middlewares, nil, nil, nil)
+	middlewaresBuilder.SetDryRun(true)
+
+	testCases := []struct {
+		desc          string
+		middlewareID  string
+		expectedError bool
+	}{
+		{
+			desc:         "cache",
+			middlewareID: "cache-dryrun",
+		},
+		{
+			desc:          "invalid cache",
+			middlewareID:  "cache-invalid-dryrun",
+			expectedError: true,
+		},
+		{
+			desc:         "debug capture",
+			middlewareID: "capture-dryrun",
+		},
+		{
+			desc:          "invalid debug capture",
+			middlewareID:  "capture-invalid-dryrun",
+			expectedError: true,
+		},
+		{
+			desc:         "rate limit with Redis",
+			middlewareID: "ratelimit-dryrun",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			constructor, err := middlewaresBuilder.buildConstructor(t.Context(), test.middlewareID)
+			require.NoError(t, err)
+
+			_, err = constructor(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// The state of the middlewares has not been created.
+			assert.ErrorIs(t, cache.Purge(test.middlewareID, ""), cache.ErrNotFound)
+			_, err = debugcapture.Records(test.middlewareID)
+			assert.ErrorIs(t, err, debugcapture.ErrNotFound)
+		})
+	}
+}
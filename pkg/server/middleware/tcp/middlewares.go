@@ -11,6 +11,7 @@ import (
 	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/inflightconn"
 	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/ipallowlist"
 	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/ipwhitelist"
+	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/ratelimiter"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
 	"github.com/traefik/traefik/v3/pkg/tcp"
 )
@@ -24,6 +25,8 @@ const (
 // Builder the middleware builder.
 type Builder struct {
 	configs map[string]*runtime.TCPMiddlewareInfo
+
+	dryRun bool
 }
 
 // NewBuilder creates a new Builder.
@@ -31,6 +34,11 @@ func NewBuilder(configs map[string]*runtime.TCPMiddlewareInfo) *Builder {
 	return &Builder{configs: configs}
 }
 
+// SetDryRun makes the builder check the rate limiters without connecting to Redis.
+func (b *Builder) SetDryRun(dryRun bool) {
+	b.dryRun = dryRun
+}
+
 // BuildChain creates a middleware chain.
 func (b *Builder) BuildChain(ctx context.Context, middlewares []string) *tcp.Chain {
 	chain := tcp.NewChain()
@@ -113,6 +121,19 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// RateLimit
+	if config.RateLimit != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			rateLimit := *config.RateLimit
+			if b.dryRun {
+				// The buckets are checked in memory.
+				rateLimit.Redis = nil
+			}
+
+			return ratelimiter.New(ctx, next, rateLimit, middlewareName)
+		}
+	}
+
 	if middleware == nil {
 		return nil, fmt.Errorf("invalid middleware %q configuration: invalid middleware type or middleware does not exist", middlewareName)
 	}
@@ -0,0 +1,209 @@
+package override
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// Protocols of the services whose servers can be overridden.
+const (
+	ProtocolHTTP = "http"
+	ProtocolTCP  = "tcp"
+	ProtocolUDP  = "udp"
+)
+
+// State is the administrative state of a server.
+type State string
+
+// Administrative states of a server.
+const (
+	// StateEnabled is the default state, where the server receives its share of the traffic.
+	StateEnabled State = "enabled"
+	// StateDrain stops sending new requests to an HTTP server,
+	// while the sticky sessions and the in-flight requests keep being served.
+	// It is not available for the TCP and UDP servers, whose established connections are always kept.
+	StateDrain State = "drain"
+	// StateDisabled removes the server from the load balancer, including for the sticky sessions.
+	StateDisabled State = "disabled"
+)
+
+// Override is the administrative state and weight of a server, set at runtime, which take precedence over its configuration.
+type Override struct {
+	State  State `json:"state,omitempty"`
+	Weight *int  `json:"weight,omitempty"`
+}
+
+// Validate checks the override.
+func (o Override) Validate() error {
+	switch o.State {
+	case "", StateEnabled, StateDrain, StateDisabled:
+	default:
+		return fmt.Errorf("invalid state %q, expected one of %q, %q or %q", o.State, StateEnabled, StateDrain, StateDisabled)
+	}
+
+	if o.Weight != nil && *o.Weight < 1 {
+		return errors.New("weight must be greater than zero, use the drain state to stop sending traffic to the server")
+	}
+
+	return nil
+}
+
+type key struct {
+	protocol string
+	service  string
+	server   string
+}
+
+// Store holds the server overrides, which are kept across the configuration reloads until they are deleted.
+// Its methods are safe to call on a nil Store, which has no overrides.
+type Store struct {
+	mu        sync.RWMutex
+	overrides map[key]Override
+
+	listeners []func()
+}
+
+// NewStore creates a new Store.
+func NewStore() *Store {
+	return &Store{overrides: make(map[key]Override)}
+}
+
+// OnChange registers a function called when the overrides change, to rebuild the load balancers.
+func (s *Store) OnChange(listener func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+// Set sets the override of a server, identified by its URL for HTTP and by its address for TCP and UDP.
+func (s *Store) Set(protocol, serviceName, server string, o Override) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+
+	if protocol != ProtocolHTTP && o.State == StateDrain {
+		return fmt.Errorf("the %q state is only available for the HTTP servers, use the %q state to stop sending new connections to the server", StateDrain, StateDisabled)
+	}
+
+	s.mu.Lock()
+	s.overrides[key{protocol: protocol, service: serviceName, server: server}] = o
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener()
+	}
+
+	return nil
+}
+
+// Delete deletes the override of a server, or of all the servers of the service if server is empty.
+// It reports whether an override was deleted.
+func (s *Store) Delete(protocol, serviceName, server string) bool {
+	s.mu.Lock()
+
+	var deleted bool
+	for k := range s.overrides {
+		if k.protocol == protocol && k.service == serviceName && (server == "" || k.server == server) {
+			delete(s.overrides, k)
+			deleted = true
+		}
+	}
+
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	if deleted {
+		for _, listener := range listeners {
+			listener()
+		}
+	}
+
+	return deleted
+}
+
+// Get returns the override of a server.
+func (s *Store) Get(protocol, serviceName, server string) (Override, bool) {
+	if s == nil {
+		return Override{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.overrides[key{protocol: protocol, service: serviceName, server: server}]
+	return o, ok
+}
+
+// Service returns the overrides of the servers of a service, keyed by server.
+func (s *Store) Service(protocol, serviceName string) map[string]Override {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var overrides map[string]Override
+	for k, o := range s.overrides {
+		if k.protocol != protocol || k.service != serviceName {
+			continue
+		}
+
+		if overrides == nil {
+			overrides = make(map[string]Override)
+		}
+		overrides[k.server] = o
+	}
+
+	return overrides
+}
+
+// ApplyHTTP applies the override of an HTTP server to its configuration.
+// It reports false when the server is disabled, and must not be added to the load balancer.
+func (s *Store) ApplyHTTP(serviceName string, server dynamic.Server) (dynamic.Server, bool) {
+	o, ok := s.Get(ProtocolHTTP, serviceName, server.URL)
+	if !ok {
+		return server, true
+	}
+
+	switch o.State {
+	case StateDisabled:
+		return server, false
+	case StateDrain:
+		// Fenced servers only serve the sticky sessions.
+		server.Fenced = true
+	}
+
+	if o.Weight != nil {
+		weight := *o.Weight
+		server.Weight = &weight
+	}
+
+	return server, true
+}
+
+// Apply applies the override of a TCP or UDP server, given its configured weight.
+// It reports false when the server is disabled, and must not receive new connections.
+// The connections already established with a disabled server are kept.
+func (s *Store) Apply(protocol, serviceName, address string, weight *int) (*int, bool) {
+	o, ok := s.Get(protocol, serviceName, address)
+	if !ok {
+		return weight, true
+	}
+
+	if o.State == StateDisabled {
+		return weight, false
+	}
+
+	if o.Weight != nil {
+		w := *o.Weight
+		return &w, true
+	}
+
+	return weight, true
+}
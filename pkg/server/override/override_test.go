@@ -0,0 +1,154 @@
+package override
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestStore_ApplyHTTP(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		override        *Override
+		expectedServer  dynamic.Server
+		expectedEnabled bool
+	}{
+		{
+			desc:            "without override",
+			expectedServer:  dynamic.Server{URL: "http://127.0.0.1", Weight: pointer(1)},
+			expectedEnabled: true,
+		},
+		{
+			desc:            "drain",
+			override:        &Override{State: StateDrain},
+			expectedServer:  dynamic.Server{URL: "http://127.0.0.1", Weight: pointer(1), Fenced: true},
+			expectedEnabled: true,
+		},
+		{
+			desc:     "disabled",
+			override: &Override{State: StateDisabled},
+		},
+		{
+			desc:            "weight",
+			override:        &Override{State: StateEnabled, Weight: pointer(5)},
+			expectedServer:  dynamic.Server{URL: "http://127.0.0.1", Weight: pointer(5)},
+			expectedEnabled: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewStore()
+			if test.override != nil {
+				require.NoError(t, store.Set(ProtocolHTTP, "foo", "http://127.0.0.1", *test.override))
+			}
+
+			server, enabled := store.ApplyHTTP("foo", dynamic.Server{URL: "http://127.0.0.1", Weight: pointer(1)})
+			assert.Equal(t, test.expectedEnabled, enabled)
+			if enabled {
+				assert.Equal(t, test.expectedServer, server)
+			}
+		})
+	}
+}
+
+func TestStore_Apply(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		override        *Override
+		expectedWeight  *int
+		expectedEnabled bool
+	}{
+		{
+			desc:            "without override",
+			expectedWeight:  pointer(2),
+			expectedEnabled: true,
+		},
+		{
+			desc:     "disabled",
+			override: &Override{State: StateDisabled, Weight: pointer(5)},
+		},
+		{
+			desc:            "weight",
+			override:        &Override{Weight: pointer(5)},
+			expectedWeight:  pointer(5),
+			expectedEnabled: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store := NewStore()
+			if test.override != nil {
+				require.NoError(t, store.Set(ProtocolTCP, "foo", "127.0.0.1:80", *test.override))
+			}
+
+			weight, enabled := store.Apply(ProtocolTCP, "foo", "127.0.0.1:80", pointer(2))
+			assert.Equal(t, test.expectedEnabled, enabled)
+			if enabled {
+				assert.Equal(t, test.expectedWeight, weight)
+			}
+		})
+	}
+}
+
+func TestStore_Set_drainNotHTTP(t *testing.T) {
+	store := NewStore()
+
+	require.Error(t, store.Set(ProtocolTCP, "foo", "127.0.0.1:80", Override{State: StateDrain}))
+	require.Error(t, store.Set(ProtocolUDP, "foo", "127.0.0.1:53", Override{State: StateDrain}))
+
+	assert.Empty(t, store.Service(ProtocolTCP, "foo"))
+	assert.Empty(t, store.Service(ProtocolUDP, "foo"))
+}
+
+func TestStore_SetDelete(t *testing.T) {
+	store := NewStore()
+
+	var changes int
+	store.OnChange(func() { changes++ })
+
+	require.Error(t, store.Set(ProtocolHTTP, "foo", "a", Override{State: "foo"}))
+	require.Error(t, store.Set(ProtocolHTTP, "foo", "a", Override{Weight: pointer(0)}))
+	assert.Equal(t, 0, changes)
+
+	require.NoError(t, store.Set(ProtocolHTTP, "foo", "a", Override{State: StateDrain}))
+	require.NoError(t, store.Set(ProtocolHTTP, "foo", "b", Override{State: StateDisabled}))
+	require.NoError(t, store.Set(ProtocolTCP, "foo", "a", Override{State: StateDisabled}))
+	assert.Equal(t, 3, changes)
+
+	assert.Equal(t, map[string]Override{"a": {State: StateDrain}, "b": {State: StateDisabled}}, store.Service(ProtocolHTTP, "foo"))
+
+	assert.True(t, store.Delete(ProtocolHTTP, "foo", "a"))
+	assert.False(t, store.Delete(ProtocolHTTP, "foo", "a"))
+	assert.Equal(t, 4, changes)
+
+	assert.True(t, store.Delete(ProtocolHTTP, "foo", ""))
+	assert.Nil(t, store.Service(ProtocolHTTP, "foo"))
+	assert.Equal(t, 5, changes)
+
+	_, ok := store.Get(ProtocolTCP, "foo", "a")
+	assert.True(t, ok)
+}
+
+func TestStore_nil(t *testing.T) {
+	var store *Store
+
+	server, enabled := store.ApplyHTTP("foo", dynamic.Server{URL: "http://127.0.0.1"})
+	assert.True(t, enabled)
+	assert.Equal(t, dynamic.Server{URL: "http://127.0.0.1"}, server)
+
+	weight, enabled := store.Apply(ProtocolUDP, "foo", "127.0.0.1:53", nil)
+	assert.True(t, enabled)
+	assert.Nil(t, weight)
+
+	assert.Nil(t, store.Service(ProtocolUDP, "foo"))
+}
+
+func pointer[T any](v T) *T { return &v }
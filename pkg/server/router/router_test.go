@@ -326,7 +326,7 @@ func TestRouterManager_Get(t *testing.T) {
 			transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
 
 			serviceManager := service.NewManager(rtConf.Services, nil, nil, transportManager, proxyBuilderMock{})
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 			tlsManager := traefiktls.NewManager(nil)
 
 			parser, err := httpmuxer.NewSyntaxParser()
@@ -744,7 +744,7 @@ func TestRuntimeConfiguration(t *testing.T) {
 			transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
 
 			serviceManager := service.NewManager(rtConf.Services, nil, nil, transportManager, proxyBuilderMock{})
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 			tlsManager := traefiktls.NewManager(nil)
 			tlsManager.UpdateConfigs(t.Context(), nil, test.tlsOptions, nil)
 
@@ -826,7 +826,7 @@ func TestProviderOnMiddlewares(t *testing.T) {
 	transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
 
 	serviceManager := service.NewManager(rtConf.Services, nil, nil, transportManager, nil)
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 	tlsManager := traefiktls.NewManager(nil)
 
 	parser, err := httpmuxer.NewSyntaxParser()
@@ -881,7 +881,7 @@ func BenchmarkRouterServe(b *testing.B) {
 	})
 
 	serviceManager := service.NewManager(rtConf.Services, nil, nil, staticTransportManager{res}, nil)
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 	tlsManager := traefiktls.NewManager(nil)
 
 	parser, err := httpmuxer.NewSyntaxParser()
@@ -1066,7 +1066,7 @@ func TestProvidersPrecedence(t *testing.T) {
 			transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
 
 			serviceManager := service.NewManager(rtConf.Services, nil, nil, transportManager, labellingProxyBuilder{})
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 			tlsManager := traefiktls.NewManager(nil)
 
 			parser, err := httpmuxer.NewSyntaxParser()
@@ -0,0 +1,88 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/rs/zerolog/log"
+	tcpmuxer "github.com/traefik/traefik/v3/pkg/muxer/tcp"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+// serveTCPTLS serves a connection with the handler of the TCP TLS route matching it before the TLS handshake.
+// When TLS is terminated and a TCP TLS route matches on the client certificate,
+// the connection is served by the route matching it once the client certificate is known,
+// or by the HTTPS routers if no TCP TLS route matches it anymore.
+func (r *Router) serveTCPTLS(conn tcp.WriteCloser, connData tcpmuxer.ConnData, handler tcp.Handler) {
+	tlsHandler, ok := handler.(*tcp.TLSHandler)
+	if !ok || !r.muxerTCPTLS.HasClientCertRoutes() {
+		handler.ServeTCP(conn)
+		return
+	}
+
+	next, tlsConn, err := r.routeClientCert(conn, connData, tlsHandler)
+	if err != nil {
+		opErr, ok := errors.AsType[*net.OpError](err)
+		if !errors.Is(err, io.EOF) && (!ok || !opErr.Timeout()) {
+			log.Debug().Err(err).Msg("Error while routing TLS connection on its client certificate")
+		}
+		_ = conn.Close()
+		return
+	}
+
+	if next == nil {
+		next = r.httpsHandlerAfterHandshake(connData)
+	}
+
+	if next == nil {
+		log.Debug().Msg("No route matches the TLS connection once the client certificate is known")
+		_ = tlsConn.Close()
+		return
+	}
+
+	next.ServeTCP(tlsConn)
+}
+
+// httpsHandlerAfterHandshake returns the handler serving with the HTTPS routers a connection on which TLS is already terminated.
+// The HTTP layer checks on its own that the TLS options negotiated during the handshake are the ones of the matching router.
+func (r *Router) httpsHandlerAfterHandshake(connData tcpmuxer.ConnData) tcp.Handler {
+	handler, _ := r.muxerHTTPS.Match(connData)
+	if handler == nil {
+		handler = r.httpsForwarder
+	}
+
+	tlsHandler, ok := handler.(*tcp.TLSHandler)
+	if !ok {
+		return nil
+	}
+
+	return tlsHandler.Next
+}
+
+// routeClientCert completes the TLS handshake with the TLS config of the route matching the connection before it,
+// and routes the connection again with its client certificate.
+// It returns the handler of the route matching the connection, if any, and the TLS connection to serve.
+func (r *Router) routeClientCert(conn tcp.WriteCloser, connData tcpmuxer.ConnData, tlsHandler *tcp.TLSHandler) (tcp.Handler, tcp.WriteCloser, error) {
+	tlsConn := tls.Server(tcp.TLSConn{WriteCloser: conn, TLSOptionsName: tlsHandler.TLSOptionsName}, tlsHandler.Config)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, nil, fmt.Errorf("TLS handshake: %w", err)
+	}
+
+	connData.SetTLSConnectionState(tlsConn.ConnectionState())
+
+	handler, _ := r.muxerTCPTLS.Match(connData)
+	if handler == nil {
+		return nil, tlsConn, nil
+	}
+
+	// The TLS connection can only be served by a route terminating TLS with the TLS options of the handshake.
+	next, ok := handler.(*tcp.TLSHandler)
+	if !ok || next.TLSOptionsName != tlsHandler.TLSOptionsName {
+		return nil, nil, errors.New("the TCP TLS route matching the client certificate does not terminate TLS with the negotiated TLS options")
+	}
+
+	return next.Next, tlsConn, nil
+}
@@ -0,0 +1,222 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ldapStartTLSOID is the name of the StartTLS extended operation (RFC 4511).
+const ldapStartTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// ldapMaxMessageSize is the maximum size of the LDAP messages read during a STARTTLS negotiation.
+const ldapMaxMessageSize = 1024
+
+// BER tags of the LDAP messages exchanged during a STARTTLS negotiation.
+const (
+	berTagInteger          = 0x02
+	berTagOctetString      = 0x04
+	berTagEnumerated       = 0x0a
+	berTagSequence         = 0x30
+	ldapTagExtendedRequest = 0x77 // [APPLICATION 23], constructed.
+	ldapTagExtendedResp    = 0x78 // [APPLICATION 24], constructed.
+	ldapTagRequestName     = 0x80 // [0], primitive.
+	ldapTagResponseName    = 0x8a // [10], primitive.
+)
+
+// acceptLDAP negotiates an LDAP STARTTLS session with a client,
+// which has to send the StartTLS extended request as its first message.
+// It returns the StartTLS extended request of the client.
+func acceptLDAP(rw *bufio.ReadWriter) ([]byte, error) {
+	request, err := readBERElement(rw.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading request: %w", err)
+	}
+
+	messageID, opTag, op, err := parseLDAPMessage(request)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, name, _, err := parseBERElement(op)
+	if err != nil {
+		return nil, err
+	}
+
+	if opTag != ldapTagExtendedRequest || tag != ldapTagRequestName || string(name) != ldapStartTLSOID {
+		return nil, errors.New("first message is not a StartTLS extended request")
+	}
+
+	response := berElement(ldapTagExtendedResp,
+		berElement(berTagEnumerated, []byte{0}), // success
+		berElement(berTagOctetString, nil),      // matchedDN
+		berElement(berTagOctetString, nil),      // diagnosticMessage
+		berElement(ldapTagResponseName, []byte(ldapStartTLSOID)),
+	)
+
+	if _, err := rw.Write(berElement(berTagSequence, berElement(berTagInteger, messageID), response)); err != nil {
+		return nil, err
+	}
+
+	return request, rw.Flush()
+}
+
+// initiateLDAP negotiates an LDAP STARTTLS session with a backend,
+// sending it the StartTLS extended request of the client.
+func initiateLDAP(rw *bufio.ReadWriter, request []byte) error {
+	if _, err := rw.Write(request); err != nil {
+		return err
+	}
+
+	if err := rw.Flush(); err != nil {
+		return err
+	}
+
+	response, err := readBERElement(rw.Reader)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	_, opTag, op, err := parseLDAPMessage(response)
+	if err != nil {
+		return err
+	}
+
+	if opTag != ldapTagExtendedResp {
+		return fmt.Errorf("unexpected response with tag %#x", opTag)
+	}
+
+	tag, resultCode, _, err := parseBERElement(op)
+	if err != nil {
+		return err
+	}
+
+	if tag != berTagEnumerated || !bytes.Equal(resultCode, []byte{0}) {
+		return fmt.Errorf("unexpected StartTLS result code %v", resultCode)
+	}
+
+	return nil
+}
+
+// parseLDAPMessage parses an LDAP message, and returns its message ID, and the tag and content of its operation.
+func parseLDAPMessage(msg []byte) ([]byte, byte, []byte, error) {
+	tag, content, _, err := parseBERElement(msg)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if tag != berTagSequence {
+		return nil, 0, nil, fmt.Errorf("unexpected message with tag %#x", tag)
+	}
+
+	tag, messageID, content, err := parseBERElement(content)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if tag != berTagInteger {
+		return nil, 0, nil, fmt.Errorf("unexpected message ID with tag %#x", tag)
+	}
+
+	opTag, op, _, err := parseBERElement(content)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	return messageID, opTag, op, nil
+}
+
+// readBERElement reads a BER element, using the definite length form, from r.
+func readBERElement(r *bufio.Reader) ([]byte, error) {
+	header, err := r.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := 2
+	if header[1]&0x80 != 0 {
+		// Long form: the low bits are the number of bytes of the length.
+		headerSize += int(header[1] & 0x7f)
+		if header, err = r.Peek(headerSize); err != nil {
+			return nil, err
+		}
+	}
+
+	_, size, err := berLength(header[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	if size > ldapMaxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes is too long", size)
+	}
+
+	element := make([]byte, headerSize+size)
+	if _, err := io.ReadFull(r, element); err != nil {
+		return nil, err
+	}
+
+	return element, nil
+}
+
+// parseBERElement parses the first BER element of b,
+// and returns its tag, its content, and the bytes following it.
+func parseBERElement(b []byte) (byte, []byte, []byte, error) {
+	if len(b) < 2 {
+		return 0, nil, nil, errors.New("truncated BER element")
+	}
+
+	lengthSize, size, err := berLength(b[1:])
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	start := 1 + lengthSize
+	if len(b)-start < size {
+		return 0, nil, nil, errors.New("truncated BER element")
+	}
+
+	return b[0], b[start : start+size], b[start+size:], nil
+}
+
+// berLength decodes the length at the beginning of b, and returns the number of bytes it used.
+func berLength(b []byte) (int, int, error) {
+	if b[0]&0x80 == 0 {
+		return 1, int(b[0]), nil
+	}
+
+	n := int(b[0] & 0x7f)
+	if n == 0 || n > 4 {
+		return 0, 0, errors.New("unsupported BER length")
+	}
+
+	if len(b) < 1+n {
+		return 0, 0, errors.New("truncated BER length")
+	}
+
+	var size int
+	for _, c := range b[1 : 1+n] {
+		size = size<<8 | int(c)
+	}
+
+	return 1 + n, size, nil
+}
+
+// berElement encodes a BER element with the given tag and the concatenation of the contents.
+func berElement(tag byte, contents ...[]byte) []byte {
+	content := bytes.Join(contents, nil)
+
+	element := []byte{tag}
+	switch size := len(content); {
+	case size < 0x80:
+		element = append(element, byte(size))
+	case size <= 0xff:
+		element = append(element, 0x81, byte(size))
+	default:
+		element = append(element, 0x82, byte(size>>8), byte(size))
+	}
+
+	return append(element, content...)
+}
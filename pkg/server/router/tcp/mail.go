@@ -0,0 +1,245 @@
+package tcp
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// acceptSMTP negotiates an SMTP STARTTLS session (RFC 3207) with a client.
+// It returns the domain sent by the client with EHLO.
+func acceptSMTP(rw *bufio.ReadWriter) ([]byte, error) {
+	if err := writeLines(rw.Writer, "220 "+startTLSHostname+" ESMTP ready"); err != nil {
+		return nil, err
+	}
+
+	domain := startTLSHostname
+	for range startTLSMaxCommands {
+		line, err := readLine(rw.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if arg != "" {
+				domain = arg
+			}
+			err = writeLines(rw.Writer, "250-"+startTLSHostname, "250 STARTTLS")
+		case "HELO":
+			err = writeLines(rw.Writer, "250 "+startTLSHostname)
+		case "NOOP", "RSET":
+			err = writeLines(rw.Writer, "250 2.0.0 OK")
+		case "STARTTLS":
+			return []byte(domain), writeLines(rw.Writer, "220 2.0.0 Ready to start TLS")
+		case "QUIT":
+			_ = writeLines(rw.Writer, "221 2.0.0 Bye")
+			return nil, errClientQuit
+		default:
+			err = writeLines(rw.Writer, "530 5.7.0 Must issue a STARTTLS command first")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("no STARTTLS command after %d commands", startTLSMaxCommands)
+}
+
+// initiateSMTP negotiates an SMTP STARTTLS session with a backend,
+// introducing itself with the domain sent by the client.
+func initiateSMTP(rw *bufio.ReadWriter, domain []byte) error {
+	if err := readSMTPReply(rw.Reader, "220"); err != nil {
+		return fmt.Errorf("reading greeting: %w", err)
+	}
+
+	if err := writeLines(rw.Writer, "EHLO "+string(domain)); err != nil {
+		return err
+	}
+
+	if err := readSMTPReply(rw.Reader, "250"); err != nil {
+		return fmt.Errorf("reading EHLO reply: %w", err)
+	}
+
+	if err := writeLines(rw.Writer, "STARTTLS"); err != nil {
+		return err
+	}
+
+	if err := readSMTPReply(rw.Reader, "220"); err != nil {
+		return fmt.Errorf("reading STARTTLS reply: %w", err)
+	}
+
+	return nil
+}
+
+// readSMTPReply reads a, possibly multiline, SMTP reply, and checks its code.
+func readSMTPReply(r *bufio.Reader, code string) error {
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return err
+		}
+
+		if !strings.HasPrefix(line, code) {
+			return fmt.Errorf("unexpected reply %q", line)
+		}
+
+		if isSMTPReplyEnd(line) {
+			return nil
+		}
+	}
+}
+
+// isSMTPReplyEnd reports whether the line is the last one of an SMTP reply,
+// in which the code is not followed by a hyphen.
+func isSMTPReplyEnd(line string) bool {
+	return len(line) < 4 || line[3] != '-'
+}
+
+// acceptIMAP negotiates an IMAP STARTTLS session (RFC 2595) with a client.
+func acceptIMAP(rw *bufio.ReadWriter) ([]byte, error) {
+	const capabilities = "IMAP4rev1 STARTTLS LOGINDISABLED"
+
+	if err := writeLines(rw.Writer, "* OK [CAPABILITY "+capabilities+"] "+startTLSHostname+" ready"); err != nil {
+		return nil, err
+	}
+
+	for range startTLSMaxCommands {
+		line, err := readLine(rw.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		tag, command, ok := strings.Cut(line, " ")
+		if !ok {
+			if err := writeLines(rw.Writer, "* BAD Invalid command"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		command, _, _ = strings.Cut(command, " ")
+		switch strings.ToUpper(command) {
+		case "CAPABILITY":
+			err = writeLines(rw.Writer, "* CAPABILITY "+capabilities, tag+" OK CAPABILITY completed")
+		case "NOOP":
+			err = writeLines(rw.Writer, tag+" OK NOOP completed")
+		case "STARTTLS":
+			return nil, writeLines(rw.Writer, tag+" OK Begin TLS negotiation now")
+		case "LOGOUT":
+			_ = writeLines(rw.Writer, "* BYE Logging out", tag+" OK LOGOUT completed")
+			return nil, errClientQuit
+		default:
+			err = writeLines(rw.Writer, tag+" BAD Must issue a STARTTLS command first")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("no STARTTLS command after %d commands", startTLSMaxCommands)
+}
+
+// initiateIMAP negotiates an IMAP STARTTLS session with a backend.
+func initiateIMAP(rw *bufio.ReadWriter, _ []byte) error {
+	const tag = "T1"
+
+	line, err := readLine(rw.Reader)
+	if err != nil {
+		return fmt.Errorf("reading greeting: %w", err)
+	}
+
+	if !strings.HasPrefix(line, "* OK") {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+
+	if err := writeLines(rw.Writer, tag+" STARTTLS"); err != nil {
+		return err
+	}
+
+	for {
+		line, err := readLine(rw.Reader)
+		if err != nil {
+			return fmt.Errorf("reading STARTTLS response: %w", err)
+		}
+
+		// Untagged responses, such as capabilities, can be sent before the tagged one.
+		if !strings.HasPrefix(line, tag+" ") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, tag+" OK") {
+			return fmt.Errorf("unexpected STARTTLS response %q", line)
+		}
+
+		return nil
+	}
+}
+
+// acceptPOP3 negotiates a POP3 STARTTLS session (RFC 2595) with a client.
+func acceptPOP3(rw *bufio.ReadWriter) ([]byte, error) {
+	if err := writeLines(rw.Writer, "+OK "+startTLSHostname+" ready"); err != nil {
+		return nil, err
+	}
+
+	for range startTLSMaxCommands {
+		line, err := readLine(rw.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		command, _, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(command) {
+		case "CAPA":
+			err = writeLines(rw.Writer, "+OK Capability list follows", "STLS", ".")
+		case "STLS":
+			return nil, writeLines(rw.Writer, "+OK Begin TLS negotiation")
+		case "QUIT":
+			_ = writeLines(rw.Writer, "+OK Bye")
+			return nil, errClientQuit
+		default:
+			err = writeLines(rw.Writer, "-ERR Must issue a STLS command first")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("no STLS command after %d commands", startTLSMaxCommands)
+}
+
+// initiatePOP3 negotiates a POP3 STARTTLS session with a backend.
+func initiatePOP3(rw *bufio.ReadWriter, _ []byte) error {
+	line, err := readLine(rw.Reader)
+	if err != nil {
+		return fmt.Errorf("reading greeting: %w", err)
+	}
+
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+
+	if err := writeLines(rw.Writer, "STLS"); err != nil {
+		return err
+	}
+
+	line, err = readLine(rw.Reader)
+	if err != nil {
+		return fmt.Errorf("reading STLS response: %w", err)
+	}
+
+	if !strings.HasPrefix(line, "+OK") {
+		return fmt.Errorf("unexpected STLS response %q", line)
+	}
+
+	return nil
+}
+
+// isSingleLineGreetingEnd is the isGreetingEnd of the IMAP and POP3 greetings, which are single lines.
+func isSingleLineGreetingEnd(string) bool {
+	return true
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -253,6 +254,41 @@ func (m *Manager) addTCPHandlers(ctx context.Context, configs map[string]*runtim
 			continue
 		}
 
+		clientCertSANs, err := tcpmuxer.ParseClientCertSANs(routerConfig.Rule)
+		if err != nil {
+			routerErr := fmt.Errorf("invalid rule: %q , %w", routerConfig.Rule, err)
+			routerConfig.AddError(routerErr, true)
+			logger.Error().Err(routerErr).Send()
+			continue
+		}
+
+		// The client certificate is only known when TLS is terminated on the router.
+		if len(clientCertSANs) > 0 && (routerConfig.TLS == nil || routerConfig.TLS.Passthrough) {
+			routerErr := fmt.Errorf("invalid rule: %q , has ClientCertSAN matcher, but TLS is not terminated on router", routerConfig.Rule)
+			routerConfig.AddError(routerErr, true)
+			logger.Error().Err(routerErr).Send()
+			continue
+		}
+
+		// The ClientCertSAN matcher matches any connection before the TLS handshake,
+		// so the rest of the rule has to select the connections on which the TLS options of the router are used.
+		if len(clientCertSANs) > 0 && !slices.ContainsFunc(domains, func(domain string) bool { return domain != "*" }) {
+			sniRegexps, err := tcpmuxer.ParseHostSNIRegexps(routerConfig.Rule)
+			if err != nil {
+				routerErr := fmt.Errorf("invalid rule: %q , %w", routerConfig.Rule, err)
+				routerConfig.AddError(routerErr, true)
+				logger.Error().Err(routerErr).Send()
+				continue
+			}
+
+			if len(sniRegexps) == 0 {
+				routerErr := fmt.Errorf("invalid rule: %q , has ClientCertSAN matcher, but no HostSNI or HostSNIRegexp matcher selecting the connections before the TLS handshake", routerConfig.Rule)
+				routerConfig.AddError(routerErr, true)
+				logger.Error().Err(routerErr).Send()
+				continue
+			}
+		}
+
 		if routerConfig.Priority > maxUserPriority && !strings.HasSuffix(routerName, "@internal") {
 			routerErr := fmt.Errorf("the router priority %d exceeds the max user-defined priority %d", routerConfig.Priority, maxUserPriority)
 			routerConfig.AddError(routerErr, true)
@@ -335,6 +371,9 @@ func (m *Manager) addTCPHandlers(ctx context.Context, configs map[string]*runtim
 		// i.e. same HostSNI but different tlsOptions
 		// This is only applicable if the muxer can decide about the routing _before_ telling the client about the tlsConf (i.e. before the TLS HandShake).
 		// This seems to be the case so far with the existing matchers (HostSNI, and ClientIP), so it's all good.
+		// The ClientCertSAN matcher is the exception: it matches any connection before the TLS handshake,
+		// and the connection is routed again once the client certificate is known,
+		// which is only possible if the route matching it then has the same tlsOptions.
 		// Otherwise, we would have to do as for HTTPS, i.e. disallow different TLS configs for the same HostSNIs.
 
 		handler, err = m.buildTCPHandler(ctxRouter, routerConfig)
@@ -0,0 +1,187 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MySQL capability flags.
+const (
+	mysqlClientLongPassword               = 0x00000001
+	mysqlClientFoundRows                  = 0x00000002
+	mysqlClientLongFlag                   = 0x00000004
+	mysqlClientConnectWithDB              = 0x00000008
+	mysqlClientProtocol41                 = 0x00000200
+	mysqlClientSSL                        = 0x00000800
+	mysqlClientTransactions               = 0x00002000
+	mysqlClientSecureConnection           = 0x00008000
+	mysqlClientMultiStatements            = 0x00010000
+	mysqlClientMultiResults               = 0x00020000
+	mysqlClientPSMultiResults             = 0x00040000
+	mysqlClientPluginAuth                 = 0x00080000
+	mysqlClientConnectAttrs               = 0x00100000
+	mysqlClientPluginAuthLenencClientData = 0x00200000
+)
+
+const (
+	// mysqlServerCapabilities are the capabilities announced by Traefik in its handshake.
+	// They are a subset of the ones of the MySQL servers supporting TLS,
+	// as the client uses them along with the backend once TLS is started.
+	mysqlServerCapabilities = mysqlClientLongPassword | mysqlClientFoundRows | mysqlClientLongFlag |
+		mysqlClientConnectWithDB | mysqlClientProtocol41 | mysqlClientSSL | mysqlClientTransactions |
+		mysqlClientSecureConnection | mysqlClientMultiStatements | mysqlClientMultiResults |
+		mysqlClientPSMultiResults | mysqlClientPluginAuth | mysqlClientConnectAttrs | mysqlClientPluginAuthLenencClientData
+
+	mysqlServerVersion = "8.0.0-traefik"
+	mysqlAuthPlugin    = "caching_sha2_password"
+	// mysqlSSLRequestSize is the size of the payload of an SSLRequest packet.
+	mysqlSSLRequestSize = 32
+	// mysqlMaxHandshakeSize is the maximum size of the payload of the handshake read from a backend.
+	mysqlMaxHandshakeSize = 1024
+)
+
+// acceptMySQL negotiates a MySQL TLS session with a client, which is requested with an SSLRequest packet,
+// in response to the handshake of the server.
+// It returns the SSLRequest packet of the client.
+func acceptMySQL(rw *bufio.ReadWriter) ([]byte, error) {
+	if _, err := rw.Write(mysqlPacket(0, mysqlHandshake())); err != nil {
+		return nil, err
+	}
+
+	if err := rw.Flush(); err != nil {
+		return nil, err
+	}
+
+	request, err := readMySQLPacket(rw.Reader, mysqlSSLRequestSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading SSLRequest: %w", err)
+	}
+
+	payload := request[4:]
+	if len(payload) != mysqlSSLRequestSize || binary.LittleEndian.Uint32(payload)&mysqlClientSSL == 0 {
+		return nil, errors.New("client did not request TLS")
+	}
+
+	return request, nil
+}
+
+// initiateMySQL negotiates a MySQL TLS session with a backend,
+// sending it the SSLRequest packet of the client.
+func initiateMySQL(rw *bufio.ReadWriter, request []byte) error {
+	handshake, err := readMySQLPacket(rw.Reader, mysqlMaxHandshakeSize)
+	if err != nil {
+		return fmt.Errorf("reading handshake: %w", err)
+	}
+
+	capabilities, err := mysqlHandshakeCapabilities(handshake[4:])
+	if err != nil {
+		return err
+	}
+
+	if capabilities&mysqlClientSSL == 0 {
+		return errors.New("TLS is not supported by the server")
+	}
+
+	if _, err := rw.Write(request); err != nil {
+		return err
+	}
+
+	return rw.Flush()
+}
+
+// mysqlHandshake returns the payload of the handshake (protocol version 10) sent by Traefik.
+func mysqlHandshake() []byte {
+	// The authentication data is not used, but the clients expect it to be made of non-zero bytes.
+	authData := make([]byte, 20)
+	_, _ = rand.Read(authData)
+	for i, b := range authData {
+		authData[i] = b%94 + 33
+	}
+
+	var payload bytes.Buffer
+	payload.WriteByte(10)
+	payload.WriteString(mysqlServerVersion + "\x00")
+	payload.Write(binary.LittleEndian.AppendUint32(nil, 1)) // connection ID
+	payload.Write(authData[:8])
+	payload.WriteByte(0)
+	payload.Write(binary.LittleEndian.AppendUint16(nil, uint16(mysqlServerCapabilities&0xffff)))
+	payload.WriteByte(0xff)                                 // utf8mb4_0900_ai_ci
+	payload.Write(binary.LittleEndian.AppendUint16(nil, 2)) // SERVER_STATUS_AUTOCOMMIT
+	payload.Write(binary.LittleEndian.AppendUint16(nil, uint16(mysqlServerCapabilities>>16)))
+	payload.WriteByte(byte(len(authData) + 1))
+	payload.Write(make([]byte, 10))
+	payload.Write(authData[8:])
+	payload.WriteByte(0)
+	payload.WriteString(mysqlAuthPlugin + "\x00")
+
+	return payload.Bytes()
+}
+
+// mysqlHandshakeCapabilities returns the capability flags from the payload of the handshake of a server.
+func mysqlHandshakeCapabilities(payload []byte) (uint32, error) {
+	if len(payload) == 0 {
+		return 0, errors.New("empty handshake")
+	}
+
+	switch payload[0] {
+	case 10:
+	case 0xff:
+		return 0, errors.New("server replied with an error")
+	default:
+		return 0, fmt.Errorf("unsupported protocol version %d", payload[0])
+	}
+
+	// Skips the protocol version, and the NUL-terminated server version.
+	end := bytes.IndexByte(payload[1:], 0)
+	if end < 0 {
+		return 0, errors.New("truncated handshake")
+	}
+
+	// Skips the connection ID, the first part of the authentication data, and the filler.
+	rest := payload[1+end+1:]
+	if len(rest) < 4+8+1+2 {
+		return 0, errors.New("truncated handshake")
+	}
+
+	capabilities := uint32(binary.LittleEndian.Uint16(rest[13:]))
+
+	// The upper capability flags follow the character set and the status flags.
+	if len(rest) >= 15+1+2+2 {
+		capabilities |= uint32(binary.LittleEndian.Uint16(rest[18:])) << 16
+	}
+
+	return capabilities, nil
+}
+
+// mysqlPacket returns a packet with the given sequence ID and payload.
+func mysqlPacket(sequenceID byte, payload []byte) []byte {
+	size := len(payload)
+	packet := []byte{byte(size), byte(size >> 8), byte(size >> 16), sequenceID}
+
+	return append(packet, payload...)
+}
+
+// readMySQLPacket reads a packet, with a payload of at most maxSize bytes.
+func readMySQLPacket(r *bufio.Reader, maxSize int) ([]byte, error) {
+	header, err := r.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+
+	size := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if size > maxSize {
+		return nil, fmt.Errorf("packet of %d bytes is too long", size)
+	}
+
+	packet := make([]byte, 4+size)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return nil, err
+	}
+
+	return packet, nil
+}
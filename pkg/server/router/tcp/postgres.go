@@ -82,7 +82,7 @@ func (r *Router) servePostgres(conn *peekConn) error {
 		proxiedConn = &postgresConn{WriteCloser: conn}
 	}
 
-	handlerTCPTLS.ServeTCP(proxiedConn)
+	r.serveTCPTLS(proxiedConn, connData, handlerTCPTLS)
 	return nil
 }
 
@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-acme/lego/v5/challenge/tlsalpn01"
@@ -31,6 +32,9 @@ type tlsConfigWithOptionsName struct {
 type Router struct {
 	acmeTLSPassthrough bool
 
+	// startTLS is the STARTTLS protocol negotiated with every client before routing, if any.
+	startTLS *startTLSProtocol
+
 	// Contains TCP routes.
 	muxerTCP tcpmuxer.Muxer
 	// Contains TCP TLS routes.
@@ -99,12 +103,28 @@ func (r *Router) HTTP3TLSConfigMatcherFunc() func(connData tcpmuxer.ConnData) (*
 
 // ServeTCP forwards the connection to the right TCP/HTTP handler.
 func (r *Router) ServeTCP(conn tcp.WriteCloser) {
+	// Most clients of the STARTTLS protocol set on the entryPoint wait for the server to speak first,
+	// so the negotiation is started before reading anything from them.
+	if r.startTLS != nil {
+		pConn := newPeekConn(conn)
+		if err := r.serveStartTLS(pConn); err != nil {
+			opErr, ok := errors.AsType[*net.OpError](err)
+			if !errors.Is(err, io.EOF) && (!ok || !opErr.Timeout()) {
+				log.Debug().Err(err).Msg("Error while serving STARTTLS connection")
+			}
+		}
+		_ = pConn.Close()
+		return
+	}
+
 	// Handling Non-TLS TCP connection early if there is neither HTTP(S) nor TLS routers on the entryPoint,
 	// and if there is at least one non-TLS TCP router.
 	// In the case of a non-TLS TCP client (that does not "send" first),
 	// we would block forever on clientHelloInfo,
 	// which is why we want to detect and handle that case first and foremost.
-	if r.muxerTCP.HasRoutes() && !r.muxerTCPTLS.HasRoutes() && !r.muxerHTTPS.HasRoutes() {
+	// The routers matching the first bytes sent by the client are the exception,
+	// as such a client has to send first anyway.
+	if r.muxerTCP.HasRoutes() && !r.muxerTCP.HasPayloadRoutes() && !r.muxerTCPTLS.HasRoutes() && !r.muxerHTTPS.HasRoutes() {
 		connData, err := tcpmuxer.NewConnData("", conn.RemoteAddr(), nil)
 		if err != nil {
 			log.Error().Err(err).Msg("Error while reading TCP connection data")
@@ -164,6 +184,12 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 		return
 	}
 
+	// The first read of a non-TLS client may not hold all the bytes matched by the payload matchers,
+	// which are therefore waited for, within the same deadline as the ClientHello.
+	if !hello.isTLS && r.muxerTCP.HasPayloadRoutes() {
+		pConn.peekPayload(r.muxerTCP.PayloadLength())
+	}
+
 	// The deadline was set to avoid blocking on the initial read of the ClientHello,
 	// but now that we have it, we can remove it,
 	// and delegate this to underlying TCP server (for now only handled by HTTP Server).
@@ -171,6 +197,8 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 		log.Error().Err(err).Msg("Error while setting deadline")
 	}
 
+	pConn.serverName = hello.serverName
+
 	connData, err := tcpmuxer.NewConnData(hello.serverName, pConn.RemoteAddr(), hello.protos)
 	if err != nil {
 		log.Error().Err(err).Msg("Error while reading TCP connection data")
@@ -179,6 +207,8 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 	}
 
 	if !hello.isTLS {
+		connData.SetPayload(pConn.Buffered())
+
 		handler, _ := r.muxerTCP.Match(connData)
 		switch {
 		case handler != nil:
@@ -214,7 +244,7 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 	// Contains also TCP TLS passthrough routes.
 	handlerTCPTLS, catchAllTCPTLS := r.muxerTCPTLS.Match(connData)
 	if handlerTCPTLS != nil && !catchAllTCPTLS {
-		handlerTCPTLS.ServeTCP(pConn)
+		r.serveTCPTLS(pConn, connData, handlerTCPTLS)
 		return
 	}
 
@@ -228,7 +258,7 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 
 	// Fallback on TCP TLS catchAll.
 	if handlerTCPTLS != nil {
-		handlerTCPTLS.ServeTCP(pConn)
+		r.serveTCPTLS(pConn, connData, handlerTCPTLS)
 		return
 	}
 
@@ -324,6 +354,12 @@ func (r *Router) EnableACMETLSPassthrough() {
 	r.acmeTLSPassthrough = true
 }
 
+// SetStartTLSProtocol makes the router negotiate a STARTTLS session with every client,
+// using the given protocol, before routing the TLS connection with the TCP TLS routes.
+func (r *Router) SetStartTLSProtocol(protocol string) {
+	r.startTLS = startTLSProtocols[strings.ToLower(protocol)]
+}
+
 // acmeTLSALPNHandler returns a special handler to solve ACME-TLS/1 challenges.
 func (r *Router) acmeTLSALPNHandler() tcp.Handler {
 	if r.httpsTLSConfig == nil {
@@ -363,6 +399,8 @@ type peekConn struct {
 
 	peeked []byte
 	reader *bufio.Reader
+	// serverName is the TLS server name (SNI) read from the ClientHello, if any.
+	serverName string
 }
 
 func newPeekConn(conn tcp.WriteCloser) *peekConn {
@@ -372,11 +410,30 @@ func newPeekConn(conn tcp.WriteCloser) *peekConn {
 	}
 }
 
+// ServerName returns the TLS server name (SNI) read from the ClientHello, if any.
+func (c *peekConn) ServerName() string {
+	return c.serverName
+}
+
 // Peek allows peeking into the connection without consuming bytes, by using the bufio.Reader's Peek method.
 func (c *peekConn) Peek(n int) ([]byte, error) {
 	return c.reader.Peek(n)
 }
 
+// Buffered returns the bytes that have already been received from the connection and not consumed yet, without consuming them.
+func (c *peekConn) Buffered() []byte {
+	buffered, _ := c.reader.Peek(c.reader.Buffered())
+	return buffered
+}
+
+// peekPayload peeks up to n bytes from the connection, without consuming them.
+// Fewer bytes are peeked when the client stops sending before n bytes, and the deadline of the connection is reached.
+func (c *peekConn) peekPayload(n int) {
+	// The errors are ignored, as the bytes received until then are matched anyway:
+	// a timeout only means that the client did not send more bytes, and the next reads return the other errors again.
+	_, _ = c.reader.Peek(min(n, c.reader.Size()))
+}
+
 // PeekRead reads from the connection and accumulates the read bytes into the peeked buffer, allowing them to be replayed later.
 // Note that PeekRead advances the reader, thus the next call to Peek will not return the same result as the previous one.
 func (c *peekConn) PeekRead(p []byte) (int, error) {
@@ -4,10 +4,16 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"net/url"
@@ -991,6 +997,256 @@ func TestPostgresTLSPassthrough(t *testing.T) {
 	assert.Equal(t, "OK", string(buf[:n]))
 }
 
+func TestPayloadRouting(t *testing.T) {
+	router, err := NewRouter(nil)
+	require.NoError(t, err)
+
+	// Each handler replies with its name, followed by the first line sent by the client,
+	// so that we can check that the peeked bytes are not consumed.
+	handler := func(name string) traefiktcp.Handler {
+		return traefiktcp.HandlerFunc(func(conn traefiktcp.WriteCloser) {
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			_, _ = conn.Write([]byte(name + ": " + line))
+			_ = conn.Close()
+		})
+	}
+
+	err = router.muxerTCP.AddRoute("Protocol(`ssh`)", "", 0, "", handler("ssh"))
+	require.NoError(t, err)
+	err = router.muxerTCP.AddRoute("Protocol(`redis`)", "", 0, "", handler("redis"))
+	require.NoError(t, err)
+	err = router.muxerTCP.AddRoute("PayloadPrefix(`MQTT`)", "", 0, "", handler("mqtt"))
+	require.NoError(t, err)
+	router.SetHTTPForwarder(handler("http"))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go router.ServeTCP(conn.(*net.TCPConn))
+		}
+	}()
+
+	testCases := []struct {
+		desc     string
+		payloads []string
+		expected string
+	}{
+		{
+			desc:     "SSH",
+			payloads: []string{"SSH-2.0-OpenSSH_9.6\r\n"},
+			expected: "ssh: SSH-2.0-OpenSSH_9.6\r\n",
+		},
+		{
+			desc:     "Redis",
+			payloads: []string{"*1\r\n$4\r\nPING\r\n"},
+			expected: "redis: *1\r\n",
+		},
+		{
+			desc:     "Redis sent in several writes",
+			payloads: []string{"*", "1\r\n", "$4\r\nPING\r\n"},
+			expected: "redis: *1\r\n",
+		},
+		{
+			desc:     "Payload prefix",
+			payloads: []string{"MQTT\n"},
+			expected: "mqtt: MQTT\n",
+		},
+		{
+			desc:     "Payload prefix sent in several writes",
+			payloads: []string{"MQ", "TT\n"},
+			expected: "mqtt: MQTT\n",
+		},
+		{
+			desc:     "HTTP",
+			payloads: []string{"GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"},
+			expected: "http: GET / HTTP/1.1\r\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			for i, payload := range test.payloads {
+				if i > 0 {
+					// Makes sure that the payload is received in several reads.
+					time.Sleep(50 * time.Millisecond)
+				}
+
+				_, err = conn.Write([]byte(payload))
+				require.NoError(t, err)
+			}
+
+			reply, err := io.ReadAll(conn)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(reply))
+		})
+	}
+}
+
+func TestClientCertRouting(t *testing.T) {
+	certPEM, keyPEM, err := generate.KeyPair("test.localhost", time.Time{})
+	require.NoError(t, err)
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	require.NoError(t, err)
+
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	clientCert := func(san string) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			DNSNames:     []string{san},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, ca, key.Public(), caKey)
+		require.NoError(t, err)
+
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+
+	tlsConf := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    clientCAs,
+	}
+
+	router, err := NewRouter(nil)
+	require.NoError(t, err)
+
+	handler := func(name string) traefiktcp.Handler {
+		return &traefiktcp.TLSHandler{
+			Config:         tlsConf,
+			TLSOptionsName: "default",
+			Next: traefiktcp.HandlerFunc(func(conn traefiktcp.WriteCloser) {
+				_, _ = conn.Write([]byte(name))
+				_ = conn.Close()
+			}),
+		}
+	}
+
+	err = router.muxerTCPTLS.AddRoute("HostSNI(`test.localhost`) && ClientCertSAN(`foo.client`)", "", 2, "", handler("foo"))
+	require.NoError(t, err)
+	err = router.muxerTCPTLS.AddRoute("HostSNI(`test.localhost`)", "", 1, "", handler("default"))
+	require.NoError(t, err)
+	err = router.muxerTCPTLS.AddRoute("HostSNI(`https.localhost`) && ClientCertSAN(`foo.client`)", "", 2, "", handler("foo"))
+	require.NoError(t, err)
+
+	// Connections matching no TCP TLS route once the client certificate is known fall back to the HTTPS forwarder.
+	router.SetHTTPSHandler(nil, tlsConf)
+	router.SetHTTPSForwarder(traefiktcp.HandlerFunc(func(conn traefiktcp.WriteCloser) {
+		_, _ = conn.Write([]byte("https"))
+		_ = conn.Close()
+	}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go router.ServeTCP(conn.(*net.TCPConn))
+		}
+	}()
+
+	testCases := []struct {
+		desc         string
+		serverName   string
+		certificates []tls.Certificate
+		expected     string
+	}{
+		{
+			desc:         "Matching client certificate",
+			serverName:   "test.localhost",
+			certificates: []tls.Certificate{clientCert("foo.client")},
+			expected:     "foo",
+		},
+		{
+			desc:         "Not matching client certificate",
+			serverName:   "test.localhost",
+			certificates: []tls.Certificate{clientCert("bar.client")},
+			expected:     "default",
+		},
+		{
+			desc:       "No client certificate",
+			serverName: "test.localhost",
+			expected:   "default",
+		},
+		{
+			desc:         "Matching client certificate without TCP TLS fallback route",
+			serverName:   "https.localhost",
+			certificates: []tls.Certificate{clientCert("foo.client")},
+			expected:     "foo",
+		},
+		{
+			desc:         "Not matching client certificate without TCP TLS fallback route",
+			serverName:   "https.localhost",
+			certificates: []tls.Certificate{clientCert("bar.client")},
+			expected:     "https",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+				ServerName:         test.serverName,
+				InsecureSkipVerify: true,
+				Certificates:       test.certificates,
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			reply, err := io.ReadAll(conn)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(reply))
+		})
+	}
+}
+
 // routerTCPCatchAll configures a TCP CatchAll No TLS - HostSNI(`*`) router.
 func routerTCPCatchAll(conf *runtime.Configuration) {
 	conf.TCPRouters["tcp-catchall"] = &runtime.TCPRouterInfo{
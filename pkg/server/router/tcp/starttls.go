@@ -0,0 +1,316 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	tcpmuxer "github.com/traefik/traefik/v3/pkg/muxer/tcp"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+const (
+	// startTLSHostname is the hostname announced by Traefik in the greetings sent to the clients.
+	startTLSHostname = "traefik"
+	// startTLSMaxCommands is the number of commands a client can send before requesting STARTTLS.
+	startTLSMaxCommands = 10
+	// startTLSMaxGreetingSize is the maximum size of the greeting of a backend dropped by a greetingConn.
+	startTLSMaxGreetingSize = 4096
+)
+
+var errClientQuit = errors.New("client quit before starting TLS")
+
+// startTLSProtocol describes the STARTTLS negotiation of a protocol.
+// Unlike Postgres, which is detected from the first bytes sent by the clients,
+// these protocols are enabled on an entryPoint, as most of their servers speak first.
+type startTLSProtocol struct {
+	// accept negotiates the STARTTLS session with a client, Traefik acting as the server.
+	// It returns the STARTTLS request of the client, which is replayed by initiate to the backend.
+	accept func(rw *bufio.ReadWriter) ([]byte, error)
+	// initiate negotiates the STARTTLS session with a backend, Traefik acting as the client,
+	// when the TLS connection is passed through.
+	initiate func(rw *bufio.ReadWriter, request []byte) error
+	// isGreetingEnd reports whether a line is the last one of the greeting sent by a backend.
+	// When the TLS connection is terminated, this greeting is dropped,
+	// as the client has already received the one of Traefik.
+	// It is nil for the protocols where the client speaks first once TLS is started.
+	isGreetingEnd func(line string) bool
+	// passthroughOnly is whether the TLS connections of the protocol can only be passed through.
+	passthroughOnly bool
+}
+
+// startTLSProtocols are the STARTTLS protocols, keyed by the names used in the entryPoint configuration.
+var startTLSProtocols = map[string]*startTLSProtocol{
+	"smtp":  {accept: acceptSMTP, initiate: initiateSMTP, isGreetingEnd: isSMTPReplyEnd},
+	"imap":  {accept: acceptIMAP, initiate: initiateIMAP, isGreetingEnd: isSingleLineGreetingEnd},
+	"pop3":  {accept: acceptPOP3, initiate: initiatePOP3, isGreetingEnd: isSingleLineGreetingEnd},
+	"ldap":  {accept: acceptLDAP, initiate: initiateLDAP},
+	"xmpp":  {accept: acceptXMPP, initiate: initiateXMPP},
+	"mysql": {accept: acceptMySQL, initiate: initiateMySQL, passthroughOnly: true},
+}
+
+// IsStartTLSProtocol reports whether the router supports the given STARTTLS protocol.
+func IsStartTLSProtocol(protocol string) bool {
+	_, ok := startTLSProtocols[strings.ToLower(protocol)]
+	return ok
+}
+
+// serveStartTLS serves a connection with a client negotiating a STARTTLS session with the protocol of the entryPoint.
+// It handles TCP TLS routing, after negotiating the STARTTLS session.
+func (r *Router) serveStartTLS(conn *peekConn) error {
+	rw := bufio.NewReadWriter(conn.reader, bufio.NewWriter(conn))
+
+	request, err := r.startTLS.accept(rw)
+	if err != nil {
+		return fmt.Errorf("negotiating STARTTLS: %w", err)
+	}
+
+	hello, err := clientHelloInfo(conn)
+	if err != nil {
+		return fmt.Errorf("reading clientHello: %w", err)
+	}
+
+	if !hello.isTLS {
+		return nil
+	}
+
+	// The deadline was there to prevent hanging connections while waiting for the client,
+	// now that the STARTTLS negotiation is over and the Client Hello has been read,
+	// we can remove it and leave its handling to the TCP reverse proxy eventually.
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		log.Error().Err(err).Msg("Error while setting deadline")
+	}
+
+	conn.serverName = hello.serverName
+
+	connData, err := tcpmuxer.NewConnData(hello.serverName, conn.RemoteAddr(), hello.protos)
+	if err != nil {
+		log.Error().Err(err).Msg("Error while reading TCP connection data")
+		return nil
+	}
+
+	// Contains also TCP TLS passthrough routes.
+	handlerTCPTLS, _ := r.muxerTCPTLS.Match(connData)
+	if handlerTCPTLS == nil {
+		return nil
+	}
+
+	tlsHandler, ok := handlerTCPTLS.(*tcp.TLSHandler)
+	if !ok {
+		// We are in TLS mode and if the handler is not TLSHandler, we are in passthrough.
+		handlerTCPTLS.ServeTCP(newStartTLSConn(conn, func(rw *bufio.ReadWriter) error {
+			return r.startTLS.initiate(rw, request)
+		}))
+		return nil
+	}
+
+	if r.startTLS.passthroughOnly {
+		return errors.New("TLS termination is not supported for this STARTTLS protocol")
+	}
+
+	if r.muxerTCPTLS.HasClientCertRoutes() {
+		next, tlsConn, err := r.routeClientCert(conn, connData, tlsHandler)
+		if err != nil {
+			return err
+		}
+
+		if next == nil {
+			_ = tlsConn.Close()
+			return errors.New("no TCP TLS route matches the client certificate")
+		}
+
+		if r.startTLS.isGreetingEnd != nil {
+			tlsConn = &greetingConn{WriteCloser: tlsConn, isGreetingEnd: r.startTLS.isGreetingEnd}
+		}
+
+		next.ServeTCP(tlsConn)
+		return nil
+	}
+
+	if r.startTLS.isGreetingEnd != nil {
+		next, isGreetingEnd := tlsHandler.Next, r.startTLS.isGreetingEnd
+		tlsHandler = &tcp.TLSHandler{
+			Next: tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+				next.ServeTCP(&greetingConn{WriteCloser: conn, isGreetingEnd: isGreetingEnd})
+			}),
+			Config:         tlsHandler.Config,
+			TLSOptionsName: tlsHandler.TLSOptionsName,
+		}
+	}
+
+	tlsHandler.ServeTCP(conn)
+	return nil
+}
+
+// startTLSConn is a tcp.WriteCloser that negotiates a STARTTLS session with the backend,
+// before exchanging any data.
+// The bytes read from it are the ones sent to the backend by the negotiation,
+// and the bytes written to it are the ones received from the backend by the negotiation,
+// until it is over.
+type startTLSConn struct {
+	tcp.WriteCloser
+
+	toBackend   *io.PipeReader
+	fromBackend *io.PipeWriter
+
+	negotiated bool // whether all the bytes of the negotiation have been read.
+	done       chan struct{}
+	err        error
+}
+
+func newStartTLSConn(conn tcp.WriteCloser, initiate func(rw *bufio.ReadWriter) error) *startTLSConn {
+	toBackendReader, toBackendWriter := io.Pipe()
+	fromBackendReader, fromBackendWriter := io.Pipe()
+
+	c := &startTLSConn{
+		WriteCloser: conn,
+		toBackend:   toBackendReader,
+		fromBackend: fromBackendWriter,
+		done:        make(chan struct{}),
+	}
+
+	go func() {
+		rw := bufio.NewReadWriter(bufio.NewReader(fromBackendReader), bufio.NewWriter(toBackendWriter))
+
+		c.err = initiate(rw)
+		close(c.done)
+
+		// Unblocks the pending Read and Write calls.
+		_ = fromBackendReader.CloseWithError(errors.New("STARTTLS negotiation is over"))
+		_ = toBackendWriter.CloseWithError(c.err)
+	}()
+
+	return c
+}
+
+// Read reads the bytes sent to the backend by the STARTTLS negotiation,
+// and then the bytes sent by the client.
+// Read does not support concurrent calls.
+func (c *startTLSConn) Read(p []byte) (int, error) {
+	if !c.negotiated {
+		n, err := c.toBackend.Read(p)
+		if err == nil {
+			return n, nil
+		}
+
+		c.negotiated = true
+	}
+
+	<-c.done
+	if c.err != nil {
+		return 0, fmt.Errorf("negotiating STARTTLS with the backend: %w", c.err)
+	}
+
+	return c.WriteCloser.Read(p)
+}
+
+// Write provides the bytes sent by the backend to the STARTTLS negotiation,
+// and then writes them to the client.
+// Write does not support concurrent calls.
+func (c *startTLSConn) Write(p []byte) (int, error) {
+	select {
+	case <-c.done:
+		if c.err != nil {
+			return 0, fmt.Errorf("negotiating STARTTLS with the backend: %w", c.err)
+		}
+
+		return c.WriteCloser.Write(p)
+	default:
+	}
+
+	if _, err := c.fromBackend.Write(p); err != nil {
+		<-c.done
+		if c.err != nil {
+			return 0, fmt.Errorf("negotiating STARTTLS with the backend: %w", c.err)
+		}
+	}
+
+	// The backend is not expected to send anything else before the TLS handshake,
+	// so nothing sent along with the end of the negotiation is forwarded to the client.
+	return len(p), nil
+}
+
+// Close closes the connection, and stops the STARTTLS negotiation if it is still in progress.
+func (c *startTLSConn) Close() error {
+	_ = c.fromBackend.CloseWithError(io.ErrUnexpectedEOF)
+	_ = c.toBackend.CloseWithError(io.ErrClosedPipe)
+
+	return c.WriteCloser.Close()
+}
+
+// greetingConn is a tcp.WriteCloser dropping the greeting written by the backend,
+// as the client has already received the one of Traefik during the STARTTLS negotiation.
+type greetingConn struct {
+	tcp.WriteCloser
+
+	isGreetingEnd func(line string) bool
+	greeting      []byte
+	dropped       bool // whether the whole greeting has been dropped.
+}
+
+// Write drops the lines of the greeting, and writes the remaining bytes to the underlying connection.
+// Write does not support concurrent calls.
+func (c *greetingConn) Write(p []byte) (int, error) {
+	if c.dropped {
+		return c.WriteCloser.Write(p)
+	}
+
+	c.greeting = append(c.greeting, p...)
+
+	for {
+		i := bytes.IndexByte(c.greeting, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := strings.TrimRight(string(c.greeting[:i]), "\r")
+		c.greeting = c.greeting[i+1:]
+
+		if !c.isGreetingEnd(line) {
+			continue
+		}
+
+		c.dropped = true
+		if len(c.greeting) > 0 {
+			if _, err := c.WriteCloser.Write(c.greeting); err != nil {
+				return 0, err
+			}
+		}
+		c.greeting = nil
+
+		return len(p), nil
+	}
+
+	if len(c.greeting) > startTLSMaxGreetingSize {
+		return 0, errors.New("greeting of the backend is too long")
+	}
+
+	return len(p), nil
+}
+
+// readLine reads a line, without its line ending.
+func readLine(r *bufio.Reader) (string, error) {
+	// ReadSlice fails with bufio.ErrBufferFull on lines longer than the buffer.
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// writeLines writes lines ended with CRLF, and flushes them.
+func writeLines(w *bufio.Writer, lines ...string) error {
+	for _, line := range lines {
+		if _, err := w.WriteString(line + "\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
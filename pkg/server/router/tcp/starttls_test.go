@@ -0,0 +1,432 @@
+package tcp
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	traefiktcp "github.com/traefik/traefik/v3/pkg/tcp"
+	"github.com/traefik/traefik/v3/pkg/tls/generate"
+)
+
+const (
+	xmppClientHeader = "<?xml version='1.0'?><stream:stream to='test.localhost' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>"
+	xmppStartTLS     = "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>"
+	xmppProceed      = "<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>"
+)
+
+var (
+	ldapStartTLSRequest = append([]byte{0x30, 0x1d, 0x02, 0x01, 0x01, 0x77, 0x18, 0x80, 0x16}, ldapStartTLSOID...)
+	mysqlSSLRequest     = append([]byte{32, 0, 0, 1, 0x00, 0x8a, 0x00, 0x00, 0, 0, 0, 1, 0xff}, make([]byte, 23)...)
+)
+
+// startTLSExchange is the STARTTLS negotiation of a protocol, on the client side, and on the backend side.
+type startTLSExchange struct {
+	protocol string
+	client   func(t *testing.T, conn net.Conn)
+	backend  func(t *testing.T, conn traefiktcp.WriteCloser)
+	// greeting is sent by the backend, when the TLS connection is terminated.
+	greeting string
+}
+
+var startTLSExchanges = []startTLSExchange{
+	{
+		protocol: "smtp",
+		client: func(t *testing.T, conn net.Conn) {
+			t.Helper()
+
+			expectString(t, conn, "220 traefik ESMTP ready\r\n")
+			writeString(t, conn, "EHLO client.localhost\r\n")
+			expectString(t, conn, "250-traefik\r\n250 STARTTLS\r\n")
+			writeString(t, conn, "MAIL FROM:<foo@test.localhost>\r\n")
+			expectString(t, conn, "530 5.7.0 Must issue a STARTTLS command first\r\n")
+			writeString(t, conn, "STARTTLS\r\n")
+			expectString(t, conn, "220 2.0.0 Ready to start TLS\r\n")
+		},
+		backend: func(t *testing.T, conn traefiktcp.WriteCloser) {
+			t.Helper()
+
+			writeString(t, conn, "220 backend ESMTP\r\n")
+			expectString(t, conn, "EHLO client.localhost\r\n")
+			writeString(t, conn, "250-backend\r\n250-PIPELINING\r\n250 STARTTLS\r\n")
+			expectString(t, conn, "STARTTLS\r\n")
+			writeString(t, conn, "220 Go ahead\r\n")
+		},
+		greeting: "220-backend ESMTP\r\n220 ready\r\n",
+	},
+	{
+		protocol: "imap",
+		client: func(t *testing.T, conn net.Conn) {
+			t.Helper()
+
+			expectString(t, conn, "* OK [CAPABILITY IMAP4rev1 STARTTLS LOGINDISABLED] traefik ready\r\n")
+			writeString(t, conn, "a1 CAPABILITY\r\n")
+			expectString(t, conn, "* CAPABILITY IMAP4rev1 STARTTLS LOGINDISABLED\r\na1 OK CAPABILITY completed\r\n")
+			writeString(t, conn, "a2 STARTTLS\r\n")
+			expectString(t, conn, "a2 OK Begin TLS negotiation now\r\n")
+		},
+		backend: func(t *testing.T, conn traefiktcp.WriteCloser) {
+			t.Helper()
+
+			writeString(t, conn, "* OK IMAP ready\r\n")
+			expectString(t, conn, "T1 STARTTLS\r\n")
+			writeString(t, conn, "* CAPABILITY IMAP4rev1 STARTTLS\r\nT1 OK Begin TLS\r\n")
+		},
+		greeting: "* OK IMAP ready\r\n",
+	},
+	{
+		protocol: "pop3",
+		client: func(t *testing.T, conn net.Conn) {
+			t.Helper()
+
+			expectString(t, conn, "+OK traefik ready\r\n")
+			writeString(t, conn, "CAPA\r\n")
+			expectString(t, conn, "+OK Capability list follows\r\nSTLS\r\n.\r\n")
+			writeString(t, conn, "STLS\r\n")
+			expectString(t, conn, "+OK Begin TLS negotiation\r\n")
+		},
+		backend: func(t *testing.T, conn traefiktcp.WriteCloser) {
+			t.Helper()
+
+			writeString(t, conn, "+OK POP3 ready\r\n")
+			expectString(t, conn, "STLS\r\n")
+			writeString(t, conn, "+OK\r\n")
+		},
+		greeting: "+OK POP3 ready\r\n",
+	},
+	{
+		protocol: "ldap",
+		client: func(t *testing.T, conn net.Conn) {
+			t.Helper()
+
+			writeString(t, conn, string(ldapStartTLSRequest))
+			expectString(t, conn, string(append([]byte{0x30, 0x24, 0x02, 0x01, 0x01, 0x78, 0x1f, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00, 0x8a, 0x16}, ldapStartTLSOID...)))
+		},
+		backend: func(t *testing.T, conn traefiktcp.WriteCloser) {
+			t.Helper()
+
+			expectString(t, conn, string(ldapStartTLSRequest))
+			writeString(t, conn, string([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00}))
+		},
+	},
+	{
+		protocol: "xmpp",
+		client: func(t *testing.T, conn net.Conn) {
+			t.Helper()
+
+			writeString(t, conn, xmppClientHeader)
+			features := readUntil(t, conn, "</stream:features>")
+			assert.Contains(t, features, "from='test.localhost'")
+			assert.Contains(t, features, "<starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'><required/></starttls>")
+			writeString(t, conn, xmppStartTLS)
+			expectString(t, conn, xmppProceed)
+		},
+		backend: func(t *testing.T, conn traefiktcp.WriteCloser) {
+			t.Helper()
+
+			expectString(t, conn, xmppClientHeader)
+			writeString(t, conn, "<?xml version='1.0'?><stream:stream xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' id='foo' from='test.localhost' version='1.0'>")
+			writeString(t, conn, "<stream:features><starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/><mechanisms/></stream:features>")
+			expectString(t, conn, xmppStartTLS)
+			writeString(t, conn, xmppProceed)
+		},
+	},
+	{
+		protocol: "mysql",
+		client: func(t *testing.T, conn net.Conn) {
+			t.Helper()
+
+			header := make([]byte, 4)
+			_, err := io.ReadFull(conn, header)
+			require.NoError(t, err)
+
+			handshake := make([]byte, int(header[0])|int(header[1])<<8|int(header[2])<<16)
+			_, err = io.ReadFull(conn, handshake)
+			require.NoError(t, err)
+
+			capabilities, err := mysqlHandshakeCapabilities(handshake)
+			require.NoError(t, err)
+			assert.NotZero(t, capabilities&mysqlClientSSL)
+			assert.NotZero(t, capabilities&mysqlClientPluginAuth)
+
+			writeString(t, conn, string(mysqlSSLRequest))
+		},
+		backend: func(t *testing.T, conn traefiktcp.WriteCloser) {
+			t.Helper()
+
+			writeString(t, conn, string(mysqlPacket(0, mysqlHandshake())))
+			expectString(t, conn, string(mysqlSSLRequest))
+		},
+	},
+}
+
+func TestIsStartTLSProtocol(t *testing.T) {
+	assert.True(t, IsStartTLSProtocol("smtp"))
+	assert.True(t, IsStartTLSProtocol("MySQL"))
+	assert.False(t, IsStartTLSProtocol("postgres"))
+	assert.False(t, IsStartTLSProtocol("foo"))
+}
+
+func TestStartTLSTermination(t *testing.T) {
+	for _, exchange := range startTLSExchanges {
+		t.Run(exchange.protocol, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter(nil)
+			require.NoError(t, err)
+
+			router.SetStartTLSProtocol(exchange.protocol)
+
+			// Register a TCPTLS route (TLS termination, not passthrough) with a TLSHandler,
+			// whose handler is a plain text backend.
+			err = router.muxerTCPTLS.AddRoute("HostSNI(`test.localhost`)", "", 0, "", &traefiktcp.TLSHandler{
+				Config: startTLSServerConfig(t),
+				Next: traefiktcp.HandlerFunc(func(conn traefiktcp.WriteCloser) {
+					_, _ = conn.Write([]byte(exchange.greeting + "OK"))
+					_ = conn.Close()
+				}),
+			})
+			require.NoError(t, err)
+
+			clientConn := serveStartTLS(t, router)
+
+			exchange.client(t, clientConn)
+
+			tlsClient := tls.Client(clientConn, &tls.Config{
+				ServerName:         "test.localhost",
+				InsecureSkipVerify: true,
+			})
+			t.Cleanup(func() { _ = tlsClient.Close() })
+
+			if exchange.protocol == "mysql" {
+				// The TLS connections of MySQL can only be passed through.
+				require.Error(t, tlsClient.Handshake())
+				return
+			}
+
+			require.NoError(t, tlsClient.Handshake())
+
+			// The greeting of the backend is dropped, as the client received the one of Traefik.
+			data, err := io.ReadAll(tlsClient)
+			require.NoError(t, err)
+			assert.Equal(t, "OK", string(data))
+		})
+	}
+}
+
+func TestStartTLSPassthrough(t *testing.T) {
+	for _, exchange := range startTLSExchanges {
+		t.Run(exchange.protocol, func(t *testing.T) {
+			t.Parallel()
+
+			router, err := NewRouter(nil)
+			require.NoError(t, err)
+
+			router.SetStartTLSProtocol(exchange.protocol)
+
+			tlsConf := startTLSServerConfig(t)
+
+			// Register a TCPTLS route (TLS passthrough) with a tcp.Handler acting as the backend.
+			err = router.muxerTCPTLS.AddRoute("HostSNI(`test.localhost`)", "", 0, "", traefiktcp.HandlerFunc(func(conn traefiktcp.WriteCloser) {
+				// First, the STARTTLS negotiation is replayed with the backend.
+				exchange.backend(t, conn)
+
+				// Then we should do the TLS handshake.
+				tlsConn := tls.Server(conn, tlsConf)
+				require.NoError(t, tlsConn.Handshake())
+
+				// Finally we write the response through the TLS connection.
+				_, err := tlsConn.Write([]byte("OK"))
+				require.NoError(t, err)
+			}))
+			require.NoError(t, err)
+
+			clientConn := serveStartTLS(t, router)
+
+			exchange.client(t, clientConn)
+
+			tlsClient := tls.Client(clientConn, &tls.Config{
+				ServerName:         "test.localhost",
+				InsecureSkipVerify: true,
+			})
+			require.NoError(t, tlsClient.Handshake())
+			t.Cleanup(func() { _ = tlsClient.Close() })
+
+			buf := make([]byte, 256)
+			n, err := tlsClient.Read(buf)
+			require.NoError(t, err)
+			assert.Equal(t, "OK", string(buf[:n]))
+		})
+	}
+}
+
+func TestStartTLSPassthrough_backendError(t *testing.T) {
+	router, err := NewRouter(nil)
+	require.NoError(t, err)
+
+	router.SetStartTLSProtocol("smtp")
+
+	backendErr := make(chan error, 1)
+	err = router.muxerTCPTLS.AddRoute("HostSNI(`test.localhost`)", "", 0, "", traefiktcp.HandlerFunc(func(conn traefiktcp.WriteCloser) {
+		writeString(t, conn, "220 backend ESMTP\r\n")
+		expectString(t, conn, "EHLO client.localhost\r\n")
+		writeString(t, conn, "250 backend\r\n")
+		expectString(t, conn, "STARTTLS\r\n")
+		writeString(t, conn, "454 TLS not available\r\n")
+
+		_, err := conn.Read(make([]byte, 1))
+		backendErr <- err
+	}))
+	require.NoError(t, err)
+
+	clientConn := serveStartTLS(t, router)
+
+	startTLSExchanges[0].client(t, clientConn)
+
+	tlsClient := tls.Client(clientConn, &tls.Config{
+		ServerName:         "test.localhost",
+		InsecureSkipVerify: true,
+	})
+	t.Cleanup(func() { _ = tlsClient.Close() })
+
+	// The ClientHello is not forwarded to a backend which refused to start TLS.
+	go func() { _ = tlsClient.Handshake() }()
+
+	select {
+	case err := <-backendErr:
+		assert.ErrorContains(t, err, `unexpected reply "454 TLS not available"`)
+	case <-time.After(5 * time.Second):
+		t.Fatal("STARTTLS negotiation with the backend did not fail")
+	}
+}
+
+func TestStartTLS_clientQuit(t *testing.T) {
+	router, err := NewRouter(nil)
+	require.NoError(t, err)
+
+	router.SetStartTLSProtocol("pop3")
+
+	clientConn := serveStartTLS(t, router)
+
+	expectString(t, clientConn, "+OK traefik ready\r\n")
+	writeString(t, clientConn, "USER foo\r\n")
+	expectString(t, clientConn, "-ERR Must issue a STLS command first\r\n")
+	writeString(t, clientConn, "QUIT\r\n")
+	expectString(t, clientConn, "+OK Bye\r\n")
+
+	// The connection is closed by Traefik.
+	_, err = clientConn.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func startTLSServerConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	certPEM, keyPEM, err := generate.KeyPair("test.localhost", time.Time{})
+	require.NoError(t, err)
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}
+}
+
+// serveStartTLS serves a connection with the router, and returns the client side of the connection.
+func serveStartTLS(t *testing.T, router *Router) net.Conn {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+
+		router.ServeTCP(conn.(*net.TCPConn))
+	}()
+
+	clientConn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientConn.Close() })
+
+	require.NoError(t, clientConn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	return clientConn
+}
+
+func writeString(t *testing.T, conn io.Writer, s string) {
+	t.Helper()
+
+	_, err := conn.Write([]byte(s))
+	require.NoError(t, err)
+}
+
+func expectString(t *testing.T, conn io.Reader, expected string) {
+	t.Helper()
+
+	buf := make([]byte, len(expected))
+	_, err := io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(buf))
+}
+
+// readUntil reads from conn byte by byte, so that nothing is read after the suffix.
+func readUntil(t *testing.T, conn io.Reader, suffix string) string {
+	t.Helper()
+
+	var b strings.Builder
+	buf := make([]byte, 1)
+	for !strings.HasSuffix(b.String(), suffix) {
+		_, err := conn.Read(buf)
+		require.NoError(t, err)
+
+		b.Write(buf)
+	}
+
+	return b.String()
+}
+
+func Test_greetingConn(t *testing.T) {
+	conn := &greetingConn{WriteCloser: &bufferConn{}, isGreetingEnd: isSMTPReplyEnd}
+
+	for _, data := range []string{"220-backend", " ESMTP\r\n220 re", "ady\r\n250 OK", "\r\n"} {
+		n, err := conn.Write([]byte(data))
+		require.NoError(t, err)
+		assert.Equal(t, len(data), n)
+	}
+
+	assert.Equal(t, "250 OK\r\n", conn.WriteCloser.(*bufferConn).String())
+}
+
+func Test_mysqlHandshakeCapabilities(t *testing.T) {
+	capabilities, err := mysqlHandshakeCapabilities(mysqlHandshake())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(mysqlServerCapabilities), capabilities)
+
+	_, err = mysqlHandshakeCapabilities(append([]byte{0xff}, binary.LittleEndian.AppendUint16(nil, 1045)...))
+	require.Error(t, err)
+}
+
+// bufferConn is a traefiktcp.WriteCloser keeping the written bytes.
+type bufferConn struct {
+	net.Conn
+
+	strings.Builder
+}
+
+func (c *bufferConn) Write(p []byte) (int, error) {
+	return c.Builder.Write(p)
+}
+
+func (c *bufferConn) CloseWrite() error {
+	return nil
+}
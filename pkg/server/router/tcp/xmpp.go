@@ -0,0 +1,169 @@
+package tcp
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// xmppTLSNamespace is the namespace of the STARTTLS negotiation elements (RFC 6120).
+const xmppTLSNamespace = "urn:ietf:params:xml:ns:xmpp-tls"
+
+// xmppMaxFeaturesSize is the maximum size of the stream features read from a backend.
+const xmppMaxFeaturesSize = 65536
+
+var xmppAttributeRegexp = regexp.MustCompile(`([\w:]+)\s*=\s*(?:'([^']*)'|"([^"]*)")`)
+
+// acceptXMPP negotiates an XMPP STARTTLS session with a client.
+// It returns the stream header sent by the client.
+func acceptXMPP(rw *bufio.ReadWriter) ([]byte, error) {
+	header, err := readXMLTag(rw.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading stream header: %w", err)
+	}
+
+	streamHeader := header
+	if strings.HasPrefix(header, "<?xml") {
+		if header, err = readXMLTag(rw.Reader); err != nil {
+			return nil, fmt.Errorf("reading stream header: %w", err)
+		}
+		streamHeader += header
+	}
+
+	if !strings.HasPrefix(header, "<stream:stream") {
+		return nil, fmt.Errorf("unexpected stream header %q", header)
+	}
+
+	attributes := xmlAttributes(header)
+	namespace := attributes["xmlns"]
+	if namespace != "jabber:client" && namespace != "jabber:server" {
+		return nil, fmt.Errorf("unsupported stream namespace %q", namespace)
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	response := fmt.Sprintf("<?xml version='1.0'?>"+
+		"<stream:stream xmlns='%s' xmlns:stream='http://etherx.jabber.org/streams' id='%s' from='%s' version='1.0'>"+
+		"<stream:features><starttls xmlns='%s'><required/></starttls></stream:features>",
+		namespace, hex.EncodeToString(id), xmlEscape(attributes["to"]), xmppTLSNamespace)
+
+	if _, err := rw.WriteString(response); err != nil {
+		return nil, err
+	}
+
+	if err := rw.Flush(); err != nil {
+		return nil, err
+	}
+
+	starttls, err := readXMLTag(rw.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading starttls: %w", err)
+	}
+
+	if !strings.HasPrefix(starttls, "<starttls") || xmlAttributes(starttls)["xmlns"] != xmppTLSNamespace {
+		return nil, fmt.Errorf("unexpected element %q", starttls)
+	}
+
+	if !strings.HasSuffix(starttls, "/>") {
+		// Reads the closing tag of the starttls element.
+		if _, err := readXMLTag(rw.Reader); err != nil {
+			return nil, fmt.Errorf("reading starttls: %w", err)
+		}
+	}
+
+	if _, err := rw.WriteString("<proceed xmlns='" + xmppTLSNamespace + "'/>"); err != nil {
+		return nil, err
+	}
+
+	return []byte(streamHeader), rw.Flush()
+}
+
+// initiateXMPP negotiates an XMPP STARTTLS session with a backend,
+// opening the stream with the stream header sent by the client.
+func initiateXMPP(rw *bufio.ReadWriter, streamHeader []byte) error {
+	if _, err := rw.Write(streamHeader); err != nil {
+		return err
+	}
+
+	if err := rw.Flush(); err != nil {
+		return err
+	}
+
+	var features strings.Builder
+	for !strings.HasSuffix(features.String(), "</stream:features>") && !strings.HasSuffix(features.String(), "<stream:features/>") {
+		tag, err := readXMLTag(rw.Reader)
+		if err != nil {
+			return fmt.Errorf("reading stream features: %w", err)
+		}
+
+		if features.Len()+len(tag) > xmppMaxFeaturesSize {
+			return errors.New("stream features are too long")
+		}
+
+		features.WriteString(tag)
+	}
+
+	if !strings.Contains(features.String(), xmppTLSNamespace) {
+		return errors.New("STARTTLS is not supported by the server")
+	}
+
+	if _, err := rw.WriteString("<starttls xmlns='" + xmppTLSNamespace + "'/>"); err != nil {
+		return err
+	}
+
+	if err := rw.Flush(); err != nil {
+		return err
+	}
+
+	proceed, err := readXMLTag(rw.Reader)
+	if err != nil {
+		return fmt.Errorf("reading proceed: %w", err)
+	}
+
+	if !strings.HasPrefix(proceed, "<proceed") {
+		return fmt.Errorf("unexpected element %q", proceed)
+	}
+
+	if !strings.HasSuffix(proceed, "/>") {
+		// Reads the closing tag of the proceed element.
+		if _, err := readXMLTag(rw.Reader); err != nil {
+			return fmt.Errorf("reading proceed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readXMLTag reads the next XML tag, along with the text preceding it, which is trimmed.
+func readXMLTag(r *bufio.Reader) (string, error) {
+	// ReadSlice fails with bufio.ErrBufferFull on tags longer than the buffer.
+	tag, err := r.ReadSlice('>')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(tag)), nil
+}
+
+// xmlAttributes returns the attributes of an XML tag, keyed by name.
+func xmlAttributes(tag string) map[string]string {
+	attributes := make(map[string]string)
+	for _, match := range xmppAttributeRegexp.FindAllStringSubmatch(tag, -1) {
+		attributes[match[1]] = match[2] + match[3]
+	}
+
+	return attributes
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
@@ -0,0 +1,464 @@
+package udp
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/quic-go/quic-go/quicvarint"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// maxDatagramSize is the maximum size of a UDP datagram.
+const maxDatagramSize = 65535
+
+// maxClientHelloDatagrams is the maximum number of datagrams read to get a ClientHello,
+// as large ClientHellos (e.g. with post-quantum key shares) span several datagrams.
+const maxClientHelloDatagrams = 4
+
+const (
+	recordTypeHandshake       = 0x16
+	handshakeTypeClientHello  = 0x01
+	handshakeHeaderLen        = 4
+	extensionServerName       = 0
+	extensionALPN             = 16
+	dtlsRecordHeaderLen       = 13
+	dtlsHandshakeHeaderLen    = 12
+	quicVersion1              = 0x00000001
+	quicLongHeaderForm        = 0x80
+	quicFixedBit              = 0x40
+	quicLongPacketTypeMask    = 0x30
+	quicFrameTypePadding      = 0x00
+	quicFrameTypePing         = 0x01
+	quicFrameTypeACK          = 0x02
+	quicFrameTypeACKECN       = 0x03
+	quicFrameTypeCrypto       = 0x06
+	quicHeaderProtectionLen   = 16
+	quicPacketNumberMaxLength = 4
+)
+
+// quicV1InitialSalt is the salt used to derive the Initial secrets of QUIC version 1 (RFC 9001, section 5.2).
+var quicV1InitialSalt = []byte{
+	0x38, 0x76, 0x2c, 0xf7, 0xf5, 0x59, 0x34, 0xb3, 0x4d, 0x17,
+	0x9a, 0xe6, 0xa4, 0xc8, 0x0c, 0xad, 0xcc, 0xbb, 0x7f, 0x0a,
+}
+
+type clientHello struct {
+	serverName string
+	protos     []string
+}
+
+// clientHelloInfo extracts the server name and ALPN protocols of the TLS ClientHello
+// starting a DTLS or QUIC (version 1) session.
+// The ClientHello is read from the first datagram and, if it does not fit in it, from the next datagrams returned by next.
+// It returns an empty clientHello if the first datagram is neither a DTLS nor a QUIC Initial packet.
+func clientHelloInfo(first []byte, next func() ([]byte, error)) (*clientHello, error) {
+	var (
+		addFragments func(datagram []byte, fragments *fragments) error
+		dtls         bool
+	)
+
+	switch {
+	case isDTLSHandshake(first):
+		addFragments = dtlsClientHelloFragments
+		dtls = true
+	case isQUICInitial(first):
+		addFragments = quicCryptoFragments
+	default:
+		return &clientHello{}, nil
+	}
+
+	fragments := &fragments{data: make(map[int][]byte)}
+
+	datagram := first
+	for i := range maxClientHelloDatagrams {
+		if err := addFragments(datagram, fragments); err != nil {
+			return nil, err
+		}
+
+		if fragments.complete() || i == maxClientHelloDatagrams-1 {
+			break
+		}
+
+		var err error
+		datagram, err = next()
+		if err != nil {
+			// Goes on with the beginning of the ClientHello.
+			break
+		}
+	}
+
+	body, err := fragments.body()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClientHello(body, dtls)
+}
+
+// fragments holds the fragments of a ClientHello handshake message, keyed by offset.
+// The offsets take the 4 bytes TLS handshake message header into account, as in QUIC CRYPTO frames.
+type fragments struct {
+	data map[int][]byte
+}
+
+func (f *fragments) add(offset int, data []byte) {
+	if len(data) > len(f.data[offset]) {
+		f.data[offset] = data
+	}
+}
+
+// contiguous returns the contiguous data starting at offset 0.
+func (f *fragments) contiguous() []byte {
+	var data []byte
+	for {
+		fragment, ok := f.data[len(data)]
+		if !ok || len(fragment) == 0 {
+			return data
+		}
+		data = append(data, fragment...)
+	}
+}
+
+func (f *fragments) complete() bool {
+	data := f.contiguous()
+
+	return len(data) >= handshakeHeaderLen && len(data) >= handshakeHeaderLen+uint24(data[1:4])
+}
+
+// body returns the ClientHello message body, which may be truncated.
+func (f *fragments) body() ([]byte, error) {
+	data := f.contiguous()
+
+	if len(data) < handshakeHeaderLen || data[0] != handshakeTypeClientHello {
+		return nil, errors.New("first handshake message is not a ClientHello")
+	}
+
+	end := min(handshakeHeaderLen+uint24(data[1:4]), len(data))
+
+	return data[handshakeHeaderLen:end], nil
+}
+
+// parseClientHello parses the body of a ClientHello handshake message.
+// As the end of the ClientHello may be missing, the extensions are read as long as they are complete.
+func parseClientHello(body []byte, dtls bool) (*clientHello, error) {
+	s := cryptobyte.String(body)
+
+	var sessionID, cookie, cipherSuites, compressionMethods cryptobyte.String
+	if !s.Skip(2+32) ||
+		!s.ReadUint8LengthPrefixed(&sessionID) ||
+		(dtls && !s.ReadUint8LengthPrefixed(&cookie)) ||
+		!s.ReadUint16LengthPrefixed(&cipherSuites) ||
+		!s.ReadUint8LengthPrefixed(&compressionMethods) {
+		return nil, errors.New("truncated ClientHello")
+	}
+
+	hello := &clientHello{}
+
+	var extensionsLen uint16
+	if !s.ReadUint16(&extensionsLen) {
+		return hello, nil
+	}
+
+	extensions := s
+	if int(extensionsLen) < len(extensions) {
+		extensions = extensions[:extensionsLen]
+	}
+
+	for !extensions.Empty() {
+		var extension uint16
+		var data cryptobyte.String
+		if !extensions.ReadUint16(&extension) || !extensions.ReadUint16LengthPrefixed(&data) {
+			break
+		}
+
+		switch extension {
+		case extensionServerName:
+			var names cryptobyte.String
+			if !data.ReadUint16LengthPrefixed(&names) {
+				return nil, errors.New("invalid server_name extension")
+			}
+
+			for !names.Empty() {
+				var nameType uint8
+				var name cryptobyte.String
+				if !names.ReadUint8(&nameType) || !names.ReadUint16LengthPrefixed(&name) {
+					return nil, errors.New("invalid server_name extension")
+				}
+
+				// Only the host_name type is defined.
+				if nameType == 0 {
+					hello.serverName = string(name)
+				}
+			}
+
+		case extensionALPN:
+			var protos cryptobyte.String
+			if !data.ReadUint16LengthPrefixed(&protos) {
+				return nil, errors.New("invalid ALPN extension")
+			}
+
+			for !protos.Empty() {
+				var proto cryptobyte.String
+				if !protos.ReadUint8LengthPrefixed(&proto) {
+					return nil, errors.New("invalid ALPN extension")
+				}
+
+				hello.protos = append(hello.protos, string(proto))
+			}
+		}
+	}
+
+	return hello, nil
+}
+
+func isDTLSHandshake(datagram []byte) bool {
+	// DTLS versions are the one's complement of the corresponding TLS versions, hence the 0xfe major version.
+	return len(datagram) > dtlsRecordHeaderLen && datagram[0] == recordTypeHandshake && datagram[1] == 0xfe
+}
+
+func isQUICInitial(datagram []byte) bool {
+	return len(datagram) > 5 &&
+		datagram[0]&(quicLongHeaderForm|quicFixedBit) == quicLongHeaderForm|quicFixedBit &&
+		datagram[0]&quicLongPacketTypeMask == 0 &&
+		binary.BigEndian.Uint32(datagram[1:5]) == quicVersion1
+}
+
+// dtlsClientHelloFragments adds the ClientHello fragments of the DTLS records of the datagram.
+// A DTLS ClientHello is a TLS ClientHello with an additional cookie after the session ID.
+func dtlsClientHelloFragments(datagram []byte, fragments *fragments) error {
+	for len(datagram) >= dtlsRecordHeaderLen {
+		recordLen := int(binary.BigEndian.Uint16(datagram[11:13]))
+		if datagram[0] != recordTypeHandshake || len(datagram) < dtlsRecordHeaderLen+recordLen {
+			return nil
+		}
+
+		fragment := datagram[dtlsRecordHeaderLen : dtlsRecordHeaderLen+recordLen]
+		datagram = datagram[dtlsRecordHeaderLen+recordLen:]
+
+		if len(fragment) < dtlsHandshakeHeaderLen || fragment[0] != handshakeTypeClientHello {
+			continue
+		}
+
+		// msg_type (1), length (3), message_seq (2), fragment_offset (3), fragment_length (3).
+		fragmentOffset := uint24(fragment[6:9])
+		fragmentLen := uint24(fragment[9:12])
+		if len(fragment) < dtlsHandshakeHeaderLen+fragmentLen {
+			return errors.New("truncated DTLS ClientHello fragment")
+		}
+
+		data := fragment[dtlsHandshakeHeaderLen : dtlsHandshakeHeaderLen+fragmentLen]
+		if fragmentOffset == 0 {
+			// The first fragment is prefixed with the TLS handshake message header: msg_type and length.
+			fragments.add(0, append(bytes.Clone(fragment[:handshakeHeaderLen]), data...))
+			continue
+		}
+
+		fragments.add(handshakeHeaderLen+fragmentOffset, data)
+	}
+
+	return nil
+}
+
+// quicCryptoFragments decrypts the QUIC Initial packet starting the datagram (RFC 9001, section 5),
+// and adds the data of its CRYPTO frames.
+func quicCryptoFragments(datagram []byte, fragments *fragments) error {
+	if !isQUICInitial(datagram) {
+		return nil
+	}
+
+	pos := 5
+
+	dcidLen := int(datagram[pos])
+	pos++
+	if len(datagram) < pos+dcidLen+1 {
+		return errors.New("truncated QUIC Initial packet")
+	}
+	dcid := datagram[pos : pos+dcidLen]
+	pos += dcidLen
+
+	scidLen := int(datagram[pos])
+	pos += 1 + scidLen
+
+	tokenLen, err := readVarint(datagram, &pos)
+	if err != nil {
+		return err
+	}
+	pos += int(tokenLen)
+
+	length, err := readVarint(datagram, &pos)
+	if err != nil {
+		return err
+	}
+
+	pnOffset := pos
+	packetEnd := pnOffset + int(length)
+	if length < quicPacketNumberMaxLength+quicHeaderProtectionLen || len(datagram) < packetEnd {
+		return errors.New("truncated QUIC Initial packet")
+	}
+
+	key, iv, hp, err := quicClientInitialKeys(dcid)
+	if err != nil {
+		return err
+	}
+
+	packet := bytes.Clone(datagram[:packetEnd])
+
+	// Removes the header protection.
+	hpBlock, err := aes.NewCipher(hp)
+	if err != nil {
+		return err
+	}
+	mask := make([]byte, aes.BlockSize)
+	sampleOffset := pnOffset + quicPacketNumberMaxLength
+	hpBlock.Encrypt(mask, packet[sampleOffset:sampleOffset+quicHeaderProtectionLen])
+
+	packet[0] ^= mask[0] & 0x0f
+	pnLen := int(packet[0]&0x03) + 1
+
+	var packetNumber uint64
+	for i := range pnLen {
+		packet[pnOffset+i] ^= mask[1+i]
+		packetNumber = packetNumber<<8 | uint64(packet[pnOffset+i])
+	}
+
+	// Decrypts the payload.
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	nonce := bytes.Clone(iv)
+	for i := range 8 {
+		nonce[len(nonce)-1-i] ^= byte(packetNumber >> (8 * i))
+	}
+
+	headerEnd := pnOffset + pnLen
+	payload, err := aead.Open(nil, nonce, packet[headerEnd:], packet[:headerEnd])
+	if err != nil {
+		return fmt.Errorf("decrypting QUIC Initial packet: %w", err)
+	}
+
+	pos = 0
+	for pos < len(payload) {
+		frameType, err := readVarint(payload, &pos)
+		if err != nil {
+			return err
+		}
+
+		switch frameType {
+		case quicFrameTypePadding, quicFrameTypePing:
+
+		case quicFrameTypeACK, quicFrameTypeACKECN:
+			// Largest Acknowledged, ACK Delay, ACK Range Count, and First ACK Range.
+			var rangeCount uint64
+			for i := range 4 {
+				v, err := readVarint(payload, &pos)
+				if err != nil {
+					return err
+				}
+				if i == 2 {
+					rangeCount = v
+				}
+			}
+
+			fields := 2 * rangeCount
+			if frameType == quicFrameTypeACKECN {
+				fields += 3
+			}
+			for range fields {
+				if _, err := readVarint(payload, &pos); err != nil {
+					return err
+				}
+			}
+
+		case quicFrameTypeCrypto:
+			offset, err := readVarint(payload, &pos)
+			if err != nil {
+				return err
+			}
+			length, err := readVarint(payload, &pos)
+			if err != nil {
+				return err
+			}
+			if uint64(len(payload)-pos) < length || offset > maxDatagramSize*maxClientHelloDatagrams {
+				return errors.New("invalid QUIC CRYPTO frame")
+			}
+
+			fragments.add(int(offset), payload[pos:pos+int(length)])
+			pos += int(length)
+
+		default:
+			// The other frames are not expected in the Initial packets of a client.
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// quicClientInitialKeys derives the client Initial packet protection keys from the Destination Connection ID.
+func quicClientInitialKeys(dcid []byte) (key, iv, hp []byte, err error) {
+	initialSecret, err := hkdf.Extract(sha256.New, dcid, quicV1InitialSalt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	clientSecret, err := hkdfExpandLabel(initialSecret, "client in", sha256.Size)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if key, err = hkdfExpandLabel(clientSecret, "quic key", 16); err != nil {
+		return nil, nil, nil, err
+	}
+	if iv, err = hkdfExpandLabel(clientSecret, "quic iv", 12); err != nil {
+		return nil, nil, nil, err
+	}
+	if hp, err = hkdfExpandLabel(clientSecret, "quic hp", 16); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return key, iv, hp, nil
+}
+
+// hkdfExpandLabel implements the TLS 1.3 HKDF-Expand-Label function, with an empty context.
+func hkdfExpandLabel(secret []byte, label string, length int) ([]byte, error) {
+	fullLabel := "tls13 " + label
+
+	info := make([]byte, 0, 4+len(fullLabel))
+	info = binary.BigEndian.AppendUint16(info, uint16(length))
+	info = append(info, byte(len(fullLabel)))
+	info = append(info, fullLabel...)
+	info = append(info, 0)
+
+	return hkdf.Expand(sha256.New, secret, string(info), length)
+}
+
+func readVarint(b []byte, pos *int) (uint64, error) {
+	if *pos >= len(b) {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	v, n, err := quicvarint.Parse(b[*pos:])
+	if err != nil {
+		return 0, err
+	}
+	*pos += n
+
+	return v, nil
+}
+
+func uint24(b []byte) int {
+	return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+}
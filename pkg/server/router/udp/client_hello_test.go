@@ -0,0 +1,211 @@
+package udp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_clientHelloInfo(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		datagrams        func(t *testing.T) [][]byte
+		expectedHello    *clientHello
+		expectedErrorMsg string
+	}{
+		{
+			desc: "not a DTLS or QUIC datagram",
+			datagrams: func(t *testing.T) [][]byte {
+				t.Helper()
+				return [][]byte{[]byte("PING")}
+			},
+			expectedHello: &clientHello{},
+		},
+		{
+			desc: "DTLS ClientHello",
+			datagrams: func(t *testing.T) [][]byte {
+				t.Helper()
+				return dtlsClientHelloDatagrams(t, &tls.Config{ServerName: "example.com", NextProtos: []string{"coap"}}, 0)
+			},
+			expectedHello: &clientHello{serverName: "example.com", protos: []string{"coap"}},
+		},
+		{
+			desc: "DTLS ClientHello without SNI",
+			datagrams: func(t *testing.T) [][]byte {
+				t.Helper()
+				return dtlsClientHelloDatagrams(t, &tls.Config{InsecureSkipVerify: true}, 0)
+			},
+			expectedHello: &clientHello{},
+		},
+		{
+			desc: "fragmented DTLS ClientHello",
+			datagrams: func(t *testing.T) [][]byte {
+				t.Helper()
+				return dtlsClientHelloDatagrams(t, &tls.Config{ServerName: "example.com", NextProtos: []string{"coap"}}, 3)
+			},
+			expectedHello: &clientHello{serverName: "example.com", protos: []string{"coap"}},
+		},
+		{
+			desc: "DTLS ClientHello with missing fragments",
+			datagrams: func(t *testing.T) [][]byte {
+				t.Helper()
+				return dtlsClientHelloDatagrams(t, &tls.Config{ServerName: "example.com"}, 20)[:1]
+			},
+			expectedErrorMsg: "truncated ClientHello",
+		},
+		{
+			desc: "QUIC Initial",
+			datagrams: func(t *testing.T) [][]byte {
+				t.Helper()
+				return quicInitialDatagrams(t, &tls.Config{ServerName: "example.com", NextProtos: []string{"h3"}})
+			},
+			expectedHello: &clientHello{serverName: "example.com", protos: []string{"h3"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			datagrams := test.datagrams(t)
+			next := func() ([]byte, error) {
+				if len(datagrams) < 2 {
+					return nil, os.ErrDeadlineExceeded
+				}
+				datagrams = datagrams[1:]
+				return datagrams[0], nil
+			}
+
+			hello, err := clientHelloInfo(datagrams[0], next)
+			if test.expectedErrorMsg != "" {
+				require.ErrorContains(t, err, test.expectedErrorMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedHello, hello)
+		})
+	}
+}
+
+// dtlsClientHelloDatagrams builds the DTLS 1.2 ClientHello datagrams from the ClientHello sent by a TLS client.
+// The ClientHello is split in the given number of fragments, each one in its own datagram.
+func dtlsClientHelloDatagrams(t *testing.T, config *tls.Config, fragmentsCount int) [][]byte {
+	t.Helper()
+
+	record := tlsClientHelloRecord(t, config)
+
+	// Inserts an empty cookie after the session ID.
+	body := record[5+4:]
+	sessionIDEnd := 2 + 32 + 1 + int(body[2+32])
+	dtlsBody := append(append(append([]byte{}, body[:sessionIDEnd]...), 0), body[sessionIDEnd:]...)
+
+	fragmentLen := len(dtlsBody)
+	if fragmentsCount > 1 {
+		fragmentLen = len(dtlsBody)/fragmentsCount + 1
+	}
+
+	var datagrams [][]byte
+	for offset := 0; offset < len(dtlsBody); offset += fragmentLen {
+		fragment := dtlsBody[offset:min(offset+fragmentLen, len(dtlsBody))]
+
+		handshake := make([]byte, dtlsHandshakeHeaderLen)
+		handshake[0] = handshakeTypeClientHello
+		putUint24(handshake[1:4], len(dtlsBody))
+		putUint24(handshake[6:9], offset)
+		putUint24(handshake[9:12], len(fragment))
+		handshake = append(handshake, fragment...)
+
+		datagram := make([]byte, dtlsRecordHeaderLen)
+		datagram[0] = recordTypeHandshake
+		binary.BigEndian.PutUint16(datagram[1:3], 0xfefd)
+		binary.BigEndian.PutUint16(datagram[11:13], uint16(len(handshake)))
+
+		datagrams = append(datagrams, append(datagram, handshake...))
+	}
+
+	return datagrams
+}
+
+// tlsClientHelloRecord returns the first record sent by a TLS client, which holds its ClientHello.
+func tlsClientHelloRecord(t *testing.T, config *tls.Config) []byte {
+	t.Helper()
+
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() { _ = serverConn.Close() })
+
+	config.MaxVersion = tls.VersionTLS12
+	go func() {
+		_ = tls.Client(clientConn, config).Handshake()
+	}()
+	t.Cleanup(func() { _ = clientConn.Close() })
+
+	header := make([]byte, 5)
+	_, err := readFull(serverConn, header)
+	require.NoError(t, err)
+
+	record := make([]byte, 5+int(binary.BigEndian.Uint16(header[3:5])))
+	copy(record, header)
+	_, err = readFull(serverConn, record[5:])
+	require.NoError(t, err)
+
+	return record
+}
+
+// quicInitialDatagrams returns the first datagrams sent by a QUIC client.
+func quicInitialDatagrams(t *testing.T, config *tls.Config) [][]byte {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+
+	go func() {
+		_, _ = quic.DialAddr(ctx, conn.LocalAddr().String(), config, nil)
+	}()
+
+	var datagrams [][]byte
+	for range maxClientHelloDatagrams {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+
+		datagram := make([]byte, maxDatagramSize)
+		n, _, err := conn.ReadFrom(datagram)
+		if err != nil {
+			break
+		}
+
+		datagrams = append(datagrams, datagram[:n])
+	}
+	require.NotEmpty(t, datagrams)
+
+	return datagrams
+}
+
+func readFull(conn net.Conn, p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		read, err := conn.Read(p[n:])
+		if err != nil {
+			return n, err
+		}
+		n += read
+	}
+	return n, nil
+}
+
+func putUint24(b []byte, v int) {
+	b[0] = byte(v >> 16)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v)
+}
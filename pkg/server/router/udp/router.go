@@ -3,29 +3,49 @@ package udp
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	udpmuxer "github.com/traefik/traefik/v3/pkg/muxer/udp"
 	"github.com/traefik/traefik/v3/pkg/observability/logs"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
 	udpservice "github.com/traefik/traefik/v3/pkg/server/service/udp"
 	"github.com/traefik/traefik/v3/pkg/udp"
 )
 
+const maxUserPriority = math.MaxInt - 1000
+
+// clientHelloTimeout is the maximum duration to wait for each datagram carrying the ClientHello.
+const clientHelloTimeout = time.Second
+
+// catchAllRule is the rule of the routers without rule.
+const catchAllRule = "HostSNI(`*`)"
+
+type middlewareBuilder interface {
+	BuildChain(ctx context.Context, names []string) *udp.Chain
+}
+
 // Manager is a route/router manager.
 type Manager struct {
-	serviceManager *udpservice.Manager
-	conf           *runtime.Configuration
+	serviceManager     *udpservice.Manager
+	middlewaresBuilder middlewareBuilder
+	conf               *runtime.Configuration
 }
 
 // NewManager Creates a new Manager.
 func NewManager(conf *runtime.Configuration,
 	serviceManager *udpservice.Manager,
+	middlewaresBuilder middlewareBuilder,
 ) *Manager {
 	return &Manager{
-		serviceManager: serviceManager,
-		conf:           conf,
+		serviceManager:     serviceManager,
+		middlewaresBuilder: middlewaresBuilder,
+		conf:               conf,
 	}
 }
 
@@ -40,15 +60,14 @@ func (m *Manager) BuildHandlers(rootCtx context.Context, entryPoints []string) m
 		logger := log.Ctx(rootCtx).With().Str(logs.EntryPointName, entryPointName).Logger()
 		ctx := logger.WithContext(rootCtx)
 
-		if len(routers) > 1 {
-			logger.Warn().Msg("Config has more than one udp router for a given entrypoint.")
+		handler, err := m.buildEntryPointHandler(ctx, routers)
+		if err != nil {
+			logger.Error().Err(err).Send()
+			continue
 		}
 
-		handlers := m.buildEntryPointHandlers(ctx, routers)
-
-		if len(handlers) > 0 {
-			// As UDP support only one router per entrypoint, we only take the first one.
-			entryPointHandlers[entryPointName] = handlers[0]
+		if handler != nil {
+			entryPointHandlers[entryPointName] = handler
 		}
 	}
 	return entryPointHandlers
@@ -62,7 +81,7 @@ func (m *Manager) getUDPRouters(ctx context.Context, entryPoints []string) map[s
 	return make(map[string]map[string]*runtime.UDPRouterInfo)
 }
 
-func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[string]*runtime.UDPRouterInfo) []udp.Handler {
+func (m *Manager) buildEntryPointHandler(ctx context.Context, configs map[string]*runtime.UDPRouterInfo) (udp.Handler, error) {
 	var rtNames []string
 	for routerName := range configs {
 		rtNames = append(rtNames, routerName)
@@ -72,29 +91,133 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 		return rtNames[i] > rtNames[j]
 	})
 
-	var handlers []udp.Handler
+	muxer, err := udpmuxer.NewMuxer()
+	if err != nil {
+		return nil, err
+	}
 
+	var hasRuleLessRouter bool
 	for _, routerName := range rtNames {
 		routerConfig := configs[routerName]
 		logger := log.Ctx(ctx).With().Str(logs.RouterName, routerName).Logger()
 		ctxRouter := logger.WithContext(provider.AddInContext(ctx, routerName))
 
-		if routerConfig.Service == "" {
-			err := errors.New("the service is missing on the udp router")
+		// A router without rule handles all the sessions of the entrypoint,
+		// and there can be only one such router per entrypoint.
+		rule := routerConfig.Rule
+		if rule == "" {
+			if hasRuleLessRouter {
+				logger.Warn().Msg("Config has more than one udp router without rule for a given entrypoint.")
+				continue
+			}
+
+			rule = catchAllRule
+		}
+
+		if routerConfig.Priority == 0 {
+			routerConfig.Priority = udpmuxer.GetRulePriority(rule)
+		}
+
+		if routerConfig.Priority > maxUserPriority && !strings.HasSuffix(routerName, "@internal") {
+			routerErr := fmt.Errorf("the router priority %d exceeds the max user-defined priority %d", routerConfig.Priority, maxUserPriority)
+			routerConfig.AddError(routerErr, true)
+			logger.Error().Err(routerErr).Send()
+			continue
+		}
+
+		handler, err := m.buildUDPHandler(ctxRouter, routerConfig)
+		if err != nil {
 			routerConfig.AddError(err, true)
 			logger.Error().Err(err).Send()
 			continue
 		}
 
-		handler, err := m.serviceManager.BuildUDP(ctxRouter, routerConfig.Service)
-		if err != nil {
+		if err := muxer.AddRoute(rule, routerConfig.Priority, handler); err != nil {
 			routerConfig.AddError(err, true)
 			logger.Error().Err(err).Send()
 			continue
 		}
 
-		handlers = append(handlers, handler)
+		if routerConfig.Rule == "" {
+			hasRuleLessRouter = true
+		}
+	}
+
+	if !muxer.HasRoutes() {
+		return nil, nil
+	}
+
+	// Without rules to evaluate, the sessions do not need to be looked at before being handled.
+	if handler, ok := muxer.CatchAllHandler(); ok {
+		return handler, nil
+	}
+
+	return &Router{muxer: muxer}, nil
+}
+
+func (m *Manager) buildUDPHandler(ctx context.Context, router *runtime.UDPRouterInfo) (udp.Handler, error) {
+	var qualifiedNames []string
+	for _, name := range router.Middlewares {
+		qualifiedNames = append(qualifiedNames, provider.GetQualifiedName(ctx, name))
+	}
+	router.Middlewares = qualifiedNames
+
+	if router.Service == "" {
+		return nil, errors.New("the service is missing on the udp router")
+	}
+
+	sHandler, err := m.serviceManager.BuildUDP(ctx, router.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	mHandler := m.middlewaresBuilder.BuildChain(ctx, router.Middlewares)
+
+	return udp.NewChain().Extend(*mHandler).Then(sHandler)
+}
+
+// Router routes the UDP sessions of an entrypoint to the handler of the first matching route,
+// based on the client IP and, for DTLS and QUIC, the ClientHello of the first datagram.
+type Router struct {
+	muxer *udpmuxer.Muxer
+}
+
+// ServeUDP forwards the session to the handler of the matching route.
+func (r *Router) ServeUDP(conn *udp.Conn) {
+	logger := log.With().Str("remoteAddr", conn.RemoteAddr().String()).Logger()
+
+	datagram := make([]byte, maxDatagramSize)
+	n, err := conn.Peek(datagram, clientHelloTimeout)
+	if err != nil {
+		logger.Debug().Err(err).Msg("Error while peeking first datagram")
+		conn.Close()
+		return
+	}
+
+	hello, err := clientHelloInfo(datagram[:n], func() ([]byte, error) {
+		next := make([]byte, maxDatagramSize)
+		n, err := conn.Peek(next, clientHelloTimeout)
+		return next[:n], err
+	})
+	if err != nil {
+		// The session can still match the rules not involving the ClientHello.
+		logger.Debug().Err(err).Msg("Error while reading ClientHello")
+		hello = &clientHello{}
+	}
+
+	connData, err := udpmuxer.NewConnData(hello.serverName, conn.RemoteAddr(), hello.protos)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error while reading session metadata")
+		conn.Close()
+		return
+	}
+
+	handler := r.muxer.Match(connData)
+	if handler == nil {
+		logger.Debug().Msg("No matching UDP route")
+		conn.Close()
+		return
 	}
 
-	return handlers
+	handler.ServeUDP(conn)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	udpmiddleware "github.com/traefik/traefik/v3/pkg/server/middleware/udp"
 	"github.com/traefik/traefik/v3/pkg/server/service/udp"
 )
 
@@ -83,6 +84,71 @@ func TestRuntimeConfiguration(t *testing.T) {
 			},
 			expectedError: 1,
 		},
+		{
+			desc: "Routers with rules",
+			serviceConfig: map[string]*runtime.UDPServiceInfo{
+				"foo-service": {
+					UDPService: &dynamic.UDPService{
+						LoadBalancer: &dynamic.UDPServersLoadBalancer{
+							Servers: []dynamic.UDPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			routerConfig: map[string]*runtime.UDPRouterInfo{
+				"foo": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "ClientIP(`10.0.0.0/8`)",
+					},
+				},
+				"bar": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "HostSNI(`example.com`)",
+					},
+				},
+				"baz": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+					},
+				},
+			},
+			expectedError: 0,
+		},
+		{
+			desc: "Router with invalid rule",
+			serviceConfig: map[string]*runtime.UDPServiceInfo{
+				"foo-service": {
+					UDPService: &dynamic.UDPService{
+						LoadBalancer: &dynamic.UDPServersLoadBalancer{
+							Servers: []dynamic.UDPServer{
+								{
+									Address: "127.0.0.1:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			routerConfig: map[string]*runtime.UDPRouterInfo{
+				"foo": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						Rule:        "Host(`example.com`)",
+					},
+				},
+			},
+			expectedError: 1,
+		},
 		{
 			desc: "Router with broken service",
 			serviceConfig: map[string]*runtime.UDPServiceInfo{
@@ -115,7 +181,8 @@ func TestRuntimeConfiguration(t *testing.T) {
 				UDPRouters:  test.routerConfig,
 			}
 			serviceManager := udp.NewManager(conf)
-			routerManager := NewManager(conf, serviceManager)
+			middlewaresBuilder := udpmiddleware.NewBuilder(conf.UDPMiddlewares)
+			routerManager := NewManager(conf, serviceManager, middlewaresBuilder)
 
 			_ = routerManager.BuildHandlers(t.Context(), entryPoints)
 
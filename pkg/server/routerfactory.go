@@ -7,9 +7,13 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/middlewares/cache"
+	"github.com/traefik/traefik/v3/pkg/middlewares/debugcapture"
 	httpmuxer "github.com/traefik/traefik/v3/pkg/muxer/http"
 	"github.com/traefik/traefik/v3/pkg/server/middleware"
 	tcpmiddleware "github.com/traefik/traefik/v3/pkg/server/middleware/tcp"
+	udpmiddleware "github.com/traefik/traefik/v3/pkg/server/middleware/udp"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 	"github.com/traefik/traefik/v3/pkg/server/router"
 	tcprouter "github.com/traefik/traefik/v3/pkg/server/router/tcp"
 	udprouter "github.com/traefik/traefik/v3/pkg/server/router/udp"
@@ -27,6 +31,7 @@ type RouterFactory struct {
 	entryPointsUDP []string
 
 	allowACMEByPass map[string]bool
+	startTLS        map[string]string
 
 	managerFactory *service.ManagerFactory
 
@@ -37,6 +42,8 @@ type RouterFactory struct {
 
 	dialerManager *tcp.DialerManager
 
+	overrides *override.Store
+
 	cancelPrevState func()
 
 	parser              httpmuxer.SyntaxParser
@@ -56,9 +63,13 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 	}
 
 	allowACMEByPass := map[string]bool{}
+	startTLS := map[string]string{}
 	var entryPointsTCP, entryPointsUDP []string
 	for name, ep := range staticConfiguration.EntryPoints {
 		allowACMEByPass[name] = ep.AllowACMEByPass || !handlesTLSChallenge
+		if ep.StartTLS != "" {
+			startTLS[name] = ep.StartTLS
+		}
 
 		protocol, err := ep.GetProtocol()
 		if err != nil {
@@ -92,11 +103,17 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 		pluginBuilder:       pluginBuilder,
 		dialerManager:       dialerManager,
 		allowACMEByPass:     allowACMEByPass,
+		startTLS:            startTLS,
 		parser:              parser,
 		providersPrecedence: providersPrecedence,
 	}, nil
 }
 
+// SetOverrides sets the server overrides store applied to the TCP and UDP load balancers.
+func (f *RouterFactory) SetOverrides(overrides *override.Store) {
+	f.overrides = overrides
+}
+
 // CreateRouters creates new TCPRouters and UDPRouters.
 func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string]*tcprouter.Router, map[string]udp.Handler) {
 	if f.cancelPrevState != nil {
@@ -106,10 +123,50 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	var ctx context.Context
 	ctx, f.cancelPrevState = context.WithCancel(context.Background())
 
+	routersTCP, routersUDP, launchHealthChecks := f.buildRouters(ctx, rtConf, false)
+
+	releaseRemovedMiddlewares(rtConf)
+
+	launchHealthChecks(ctx)
+
+	return routersTCP, routersUDP
+}
+
+// releaseRemovedMiddlewares releases the state kept across configuration reloads by the middlewares
+// which are not in the applied configuration anymore.
+func releaseRemovedMiddlewares(rtConf *runtime.Configuration) {
+	var cacheNames, debugCaptureNames []string
+	for name, middlewareInfo := range rtConf.Middlewares {
+		if middlewareInfo.Cache != nil {
+			cacheNames = append(cacheNames, name)
+		}
+		if middlewareInfo.DebugCapture != nil {
+			debugCaptureNames = append(debugCaptureNames, name)
+		}
+	}
+
+	cache.Prune(cacheNames)
+	debugcapture.Prune(debugCaptureNames)
+}
+
+// CheckRouters builds the routers of the runtime configuration without running them,
+// to populate the errors and statuses of the runtime configuration.
+// The middlewares keeping state across configuration reloads are only checked,
+// so that the state of the applied configuration is left untouched.
+func (f *RouterFactory) CheckRouters(rtConf *runtime.Configuration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f.buildRouters(ctx, rtConf, true)
+}
+
+// buildRouters builds the TCP and UDP routers, and returns the function launching the health checks of their services.
+func (f *RouterFactory) buildRouters(ctx context.Context, rtConf *runtime.Configuration, dryRun bool) (map[string]*tcprouter.Router, map[string]udp.Handler, func(context.Context)) {
 	// HTTP
 	serviceManager := f.managerFactory.Build(rtConf)
 
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder, f.observabilityMgr.MetricsRegistry())
+	middlewaresBuilder.SetDryRun(dryRun)
 
 	serviceManager.SetMiddlewareChainBuilder(middlewaresBuilder)
 
@@ -120,12 +177,12 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	handlersNonTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, false)
 	handlersTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, true)
 
-	serviceManager.LaunchHealthCheck(ctx)
-
 	// TCP
 	svcTCPManager := tcpsvc.NewManager(rtConf, f.dialerManager)
+	svcTCPManager.SetOverrides(f.overrides)
 
 	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares)
+	middlewaresTCPBuilder.SetDryRun(dryRun)
 
 	rtTCPManager := tcprouter.NewManager(rtConf, svcTCPManager, middlewaresTCPBuilder, handlersNonTLS, handlersTLS, f.tlsManager, f.providersPrecedence)
 	routersTCP := rtTCPManager.BuildHandlers(ctx, f.entryPointsTCP)
@@ -134,16 +191,29 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 		if allowACMEByPass, ok := f.allowACMEByPass[ep]; ok && allowACMEByPass {
 			r.EnableACMETLSPassthrough()
 		}
-	}
 
-	svcTCPManager.LaunchHealthCheck(ctx)
+		if protocol, ok := f.startTLS[ep]; ok {
+			r.SetStartTLSProtocol(protocol)
+		}
+	}
 
 	// UDP
 	svcUDPManager := udpsvc.NewManager(rtConf)
-	rtUDPManager := udprouter.NewManager(rtConf, svcUDPManager)
+	svcUDPManager.SetOverrides(f.overrides)
+
+	middlewaresUDPBuilder := udpmiddleware.NewBuilder(rtConf.UDPMiddlewares)
+	middlewaresUDPBuilder.SetDryRun(dryRun)
+
+	rtUDPManager := udprouter.NewManager(rtConf, svcUDPManager, middlewaresUDPBuilder)
 	routersUDP := rtUDPManager.BuildHandlers(ctx, f.entryPointsUDP)
 
 	rtConf.PopulateUsedBy()
 
-	return routersTCP, routersUDP
+	launchHealthChecks := func(ctx context.Context) {
+		serviceManager.LaunchHealthCheck(ctx)
+		svcTCPManager.LaunchHealthCheck(ctx)
+		svcUDPManager.LaunchHealthCheck(ctx)
+	}
+
+	return routersTCP, routersUDP, launchHealthChecks
 }
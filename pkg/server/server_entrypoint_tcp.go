@@ -181,6 +181,10 @@ type TCPEntryPoint struct {
 
 // NewTCPEntryPoint creates a new TCPEntryPoint.
 func NewTCPEntryPoint(ctx context.Context, name string, config *static.EntryPoint, hostResolverConfig *types.HostResolverConfig, openConnectionsGauge gokitmetrics.Gauge) (*TCPEntryPoint, error) {
+	if config.StartTLS != "" && !tcprouter.IsStartTLSProtocol(config.StartTLS) {
+		return nil, fmt.Errorf("invalid startTLS value %q", config.StartTLS)
+	}
+
 	tracker := newConnectionTracker(openConnectionsGauge)
 
 	listener, err := buildListener(ctx, name, config)
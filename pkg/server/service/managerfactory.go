@@ -11,7 +11,9 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/static"
 	"github.com/traefik/traefik/v3/pkg/observability/metrics"
 	"github.com/traefik/traefik/v3/pkg/safe"
+	"github.com/traefik/traefik/v3/pkg/server/history"
 	"github.com/traefik/traefik/v3/pkg/server/middleware"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 	"github.com/traefik/traefik/v3/pkg/tls"
 )
 
@@ -22,6 +24,7 @@ type ManagerFactory struct {
 	transportManager *TransportManager
 	proxyBuilder     ProxyBuilder
 
+	apiHandler       *api.Handler
 	api              func(configuration *runtime.Configuration) http.Handler
 	restHandler      http.Handler
 	dashboardHandler http.Handler
@@ -29,6 +32,8 @@ type ManagerFactory struct {
 	pingHandler      http.Handler
 	acmeHTTPHandler  http.Handler
 
+	overrides *override.Store
+
 	routinesPool *safe.Pool
 }
 
@@ -43,7 +48,8 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 	}
 
 	if staticConfiguration.API != nil {
-		apiRouterBuilder := api.NewBuilder(staticConfiguration, tlsManager)
+		factory.apiHandler = api.New(staticConfiguration, nil).WithTLSManager(tlsManager)
+		apiRouterBuilder := factory.apiHandler.Builder()
 
 		if staticConfiguration.API.Dashboard {
 			factory.dashboardHandler = dashboard.Handler{BasePath: staticConfiguration.API.BasePath}
@@ -78,6 +84,40 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 	return factory
 }
 
+// WithDryRunner enables the configuration dry run endpoint of the API.
+func (f *ManagerFactory) WithDryRunner(dryRunner api.DryRunner) *ManagerFactory {
+	if f.apiHandler != nil {
+		f.apiHandler.WithDryRunner(dryRunner)
+	}
+	return f
+}
+
+// WithHistory enables the configuration history endpoints of the API.
+func (f *ManagerFactory) WithHistory(configurationHistory *history.History) *ManagerFactory {
+	if f.apiHandler != nil {
+		f.apiHandler.WithHistory(configurationHistory)
+	}
+	return f
+}
+
+// WithEvents enables the events streaming endpoint of the API.
+func (f *ManagerFactory) WithEvents(eventHub *api.EventHub) *ManagerFactory {
+	if f.apiHandler != nil {
+		f.apiHandler.WithEvents(eventHub)
+	}
+	return f
+}
+
+// WithOverrides sets the server overrides store applied to the load balancers of the built managers,
+// and enables the server overrides endpoints of the API.
+func (f *ManagerFactory) WithOverrides(overrides *override.Store) *ManagerFactory {
+	f.overrides = overrides
+	if f.apiHandler != nil {
+		f.apiHandler.WithOverrides(overrides)
+	}
+	return f
+}
+
 // Build creates a service manager.
 func (f *ManagerFactory) Build(configuration *runtime.Configuration) *Manager {
 	var apiHandler http.Handler
@@ -86,5 +126,8 @@ func (f *ManagerFactory) Build(configuration *runtime.Configuration) *Manager {
 	}
 
 	internalHandlers := NewInternalHandlers(apiHandler, f.restHandler, f.metricsHandler, f.pingHandler, f.dashboardHandler, f.acmeHTTPHandler)
-	return NewManager(configuration.Services, f.observabilityMgr, f.routinesPool, f.transportManager, f.proxyBuilder, internalHandlers)
+	manager := NewManager(configuration.Services, f.observabilityMgr, f.routinesPool, f.transportManager, f.proxyBuilder, internalHandlers)
+	manager.SetOverrides(f.overrides)
+
+	return manager
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/traefik/traefik/v3/pkg/safe"
 	"github.com/traefik/traefik/v3/pkg/server/cookie"
 	"github.com/traefik/traefik/v3/pkg/server/middleware"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
 	"github.com/traefik/traefik/v3/pkg/server/recursion"
 	"github.com/traefik/traefik/v3/pkg/server/service/loadbalancer/failover"
@@ -65,6 +66,7 @@ type Manager struct {
 	healthCheckers         map[string]*healthcheck.ServiceHealthChecker
 	rand                   *rand.Rand // For the initial shuffling of load-balancers.
 	middlewareChainBuilder middlewareChainBuilder
+	overrides              *override.Store
 }
 
 // NewManager creates a new Manager.
@@ -87,6 +89,11 @@ func (m *Manager) SetMiddlewareChainBuilder(middlewareChainBuilder middlewareCha
 	m.middlewareChainBuilder = middlewareChainBuilder
 }
 
+// SetOverrides sets the store of the server overrides applied when building the load balancers.
+func (m *Manager) SetOverrides(overrides *override.Store) {
+	m.overrides = overrides
+}
+
 // BuildHTTP Creates a http.Handler for a service configuration.
 func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.Handler, error) {
 	serviceName = provider.GetQualifiedName(rootCtx, serviceName)
@@ -463,6 +470,14 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 	healthCheckTargets := make(map[string]*url.URL)
 
 	for i, server := range shuffle(service.Servers, m.rand) {
+		var enabled bool
+		server, enabled = m.overrides.ApplyHTTP(serviceName, server)
+		if !enabled {
+			logger.Debug().Int(logs.ServerIndex, i).Str("URL", server.URL).
+				Msg("Skipping server disabled by an override")
+			continue
+		}
+
 		target, err := url.Parse(server.URL)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing server URL %s: %w", server.URL, err)
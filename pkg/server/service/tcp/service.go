@@ -11,19 +11,30 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/healthcheck"
 	"github.com/traefik/traefik/v3/pkg/observability/logs"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
 	"github.com/traefik/traefik/v3/pkg/tcp"
 )
 
+// serversLoadBalancer balances the connections between the servers of a service.
+type serversLoadBalancer interface {
+	tcp.Handler
+	healthcheck.StatusSetter
+
+	Add(name string, handler tcp.Handler, weight *int)
+}
+
 // Manager is the TCPHandlers factory.
 type Manager struct {
 	dialerManager  *tcp.DialerManager
 	configs        map[string]*runtime.TCPServiceInfo
 	rand           *rand.Rand // For the initial shuffling of load-balancers.
 	healthCheckers map[string]*healthcheck.ServiceTCPHealthChecker
+	overrides      *override.Store
 }
 
 // NewManager creates a new manager.
@@ -36,6 +47,11 @@ func NewManager(conf *runtime.Configuration, dialerManager *tcp.DialerManager) *
 	}
 }
 
+// SetOverrides sets the store of the server overrides applied when building the load balancers.
+func (m *Manager) SetOverrides(overrides *override.Store) {
+	m.overrides = overrides
+}
+
 // BuildTCP Creates a tcp.Handler for a service configuration.
 func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Handler, error) {
 	serviceQualifiedName := provider.GetQualifiedName(rootCtx, serviceName)
@@ -48,7 +64,7 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 		return nil, fmt.Errorf("the service %q does not exist", serviceQualifiedName)
 	}
 
-	if conf.LoadBalancer != nil && conf.Weighted != nil {
+	if countServiceTypes(conf) > 1 {
 		err := errors.New("cannot create service: multi-types service not supported, consider declaring two different pieces of service instead")
 		conf.AddError(err, true)
 		return nil, err
@@ -56,7 +72,11 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := tcp.NewWRRLoadBalancer(conf.LoadBalancer.HealthCheck != nil)
+		loadBalancer, err := newServersLoadBalancer(conf.LoadBalancer)
+		if err != nil {
+			conf.AddError(err, true)
+			return nil, err
+		}
 
 		if conf.LoadBalancer.TerminationDelay != nil {
 			log.Ctx(ctx).Warn().Msgf("Service %q load balancer uses `TerminationDelay`, but this option is deprecated, please use ServersTransport configuration instead.", serviceName)
@@ -82,6 +102,12 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 				continue
 			}
 
+			weight, enabled := m.overrides.Apply(override.ProtocolTCP, serviceQualifiedName, server.Address, nil)
+			if !enabled {
+				srvLogger.Debug().Msg("Skipping server drained or disabled by an override")
+				continue
+			}
+
 			dialer, err := m.dialerManager.Build(conf.LoadBalancer, server.TLS)
 			if err != nil {
 				return nil, err
@@ -93,7 +119,7 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 				continue
 			}
 
-			loadBalancer.Add(server.Address, handler, nil)
+			loadBalancer.Add(server.Address, handler, weight)
 
 			// Servers are considered UP by default.
 			conf.UpdateServerStatus(server.Address, runtime.StatusUp)
@@ -152,6 +178,24 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 
 		return loadBalancer, nil
 
+	case conf.Failover != nil:
+		handler, err := m.buildFailover(ctx, serviceName, conf)
+		if err != nil {
+			conf.AddError(err, true)
+			return nil, err
+		}
+
+		return handler, nil
+
+	case conf.Mirroring != nil:
+		handler, err := m.buildMirroring(ctx, conf)
+		if err != nil {
+			conf.AddError(err, true)
+			return nil, err
+		}
+
+		return handler, nil
+
 	default:
 		err := fmt.Errorf("the service %q does not have any type defined", serviceQualifiedName)
 		conf.AddError(err, true)
@@ -159,6 +203,73 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 	}
 }
 
+func (m *Manager) buildFailover(ctx context.Context, serviceName string, conf *runtime.TCPServiceInfo) (tcp.Handler, error) {
+	serviceHandler, err := m.BuildTCP(ctx, conf.Failover.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	updater, ok := serviceHandler.(healthcheck.StatusUpdater)
+	if !ok {
+		return nil, fmt.Errorf("child service %v of %v not a healthcheck.StatusUpdater (%T)", conf.Failover.Service, serviceName, serviceHandler)
+	}
+
+	f := tcp.NewFailover(conf.Failover.HealthCheck != nil)
+	f.SetHandler(serviceHandler)
+
+	if err := updater.RegisterStatusUpdater(func(up bool) {
+		f.SetHandlerStatus(ctx, up)
+	}); err != nil {
+		return nil, fmt.Errorf("cannot register %v as updater for %v: %w", conf.Failover.Service, serviceName, err)
+	}
+
+	fallbackHandler, err := m.BuildTCP(ctx, conf.Failover.Fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	f.SetFallbackHandler(fallbackHandler)
+
+	// Do not report the health of the fallback handler.
+	if conf.Failover.HealthCheck == nil {
+		return f, nil
+	}
+
+	fallbackUpdater, ok := fallbackHandler.(healthcheck.StatusUpdater)
+	if !ok {
+		return nil, fmt.Errorf("child service %v of %v not a healthcheck.StatusUpdater (%T)", conf.Failover.Fallback, serviceName, fallbackHandler)
+	}
+
+	if err := fallbackUpdater.RegisterStatusUpdater(func(up bool) {
+		f.SetFallbackHandlerStatus(ctx, up)
+	}); err != nil {
+		return nil, fmt.Errorf("cannot register %v as updater for %v: %w", conf.Failover.Fallback, serviceName, err)
+	}
+
+	return f, nil
+}
+
+func (m *Manager) buildMirroring(ctx context.Context, conf *runtime.TCPServiceInfo) (tcp.Handler, error) {
+	serviceHandler, err := m.BuildTCP(ctx, conf.Mirroring.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := tcp.NewMirroring(serviceHandler, conf.Mirroring.HealthCheck != nil)
+	for _, mirror := range conf.Mirroring.Mirrors {
+		mirrorHandler, err := m.BuildTCP(ctx, mirror.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := handler.AddMirror(mirrorHandler, mirror.Percent); err != nil {
+			return nil, err
+		}
+	}
+
+	return handler, nil
+}
+
 // LaunchHealthCheck launches the health checks.
 func (m *Manager) LaunchHealthCheck(ctx context.Context) {
 	for serviceName, hc := range m.healthCheckers {
@@ -167,6 +278,26 @@ func (m *Manager) LaunchHealthCheck(ctx context.Context) {
 	}
 }
 
+func newServersLoadBalancer(config *dynamic.TCPServersLoadBalancer) (serversLoadBalancer, error) {
+	switch config.Strategy {
+	case dynamic.TCPBalancerStrategyWRR, "":
+		return tcp.NewWRRLoadBalancer(config.HealthCheck != nil), nil
+	default:
+		return tcp.NewBalancer(config.Strategy, config.HealthCheck != nil)
+	}
+}
+
+func countServiceTypes(conf *runtime.TCPServiceInfo) int {
+	var count int
+	for _, defined := range []bool{conf.LoadBalancer != nil, conf.Weighted != nil, conf.Failover != nil, conf.Mirroring != nil} {
+		if defined {
+			count++
+		}
+	}
+
+	return count
+}
+
 func shuffle[T any](values []T, r *rand.Rand) []T {
 	shuffled := make([]T, len(values))
 	copy(shuffled, values)
@@ -276,6 +276,206 @@ func TestManager_BuildTCP(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "least-connections strategy",
+			stConfigs:   map[string]*dynamic.TCPServersTransport{"default@internal": {}},
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Strategy: dynamic.TCPBalancerStrategyLeastConn,
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
+		{
+			desc:        "unsupported strategy",
+			stConfigs:   map[string]*dynamic.TCPServersTransport{"default@internal": {}},
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Strategy: "foo",
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			providerName:  "provider-1",
+			expectedError: `unsupported load-balancer strategy "foo"`,
+		},
+		{
+			desc:        "Failover",
+			stConfigs:   map[string]*dynamic.TCPServersTransport{"default@internal": {}},
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						Failover: &dynamic.TCPFailover{
+							Service:  "foobar@provider-1",
+							Fallback: "foobar2@provider-1",
+						},
+					},
+				},
+				"foobar@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+							},
+							HealthCheck: &dynamic.TCPServerHealthCheck{},
+						},
+					},
+				},
+				"foobar2@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.13:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
+		{
+			desc:        "Failover with a service without healthcheck",
+			stConfigs:   map[string]*dynamic.TCPServersTransport{"default@internal": {}},
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						Failover: &dynamic.TCPFailover{
+							Service:  "foobar@provider-1",
+							Fallback: "foobar2@provider-1",
+						},
+					},
+				},
+				"foobar@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+							},
+						},
+					},
+				},
+				"foobar2@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.13:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			providerName:  "provider-1",
+			expectedError: "cannot register foobar@provider-1 as updater for serviceName: healthCheck not enabled in config for this weighted service",
+		},
+		{
+			desc:        "Mirroring",
+			stConfigs:   map[string]*dynamic.TCPServersTransport{"default@internal": {}},
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						Mirroring: &dynamic.TCPMirroring{
+							Service: "foobar@provider-1",
+							Mirrors: []dynamic.TCPMirrorService{
+								{Name: "foobar2@provider-1", Percent: 50},
+							},
+						},
+					},
+				},
+				"foobar@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+							},
+						},
+					},
+				},
+				"foobar2@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.13:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
+		{
+			desc:        "Mirroring with an invalid percent",
+			stConfigs:   map[string]*dynamic.TCPServersTransport{"default@internal": {}},
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						Mirroring: &dynamic.TCPMirroring{
+							Service: "foobar@provider-1",
+							Mirrors: []dynamic.TCPMirrorService{
+								{Name: "foobar2@provider-1", Percent: 101},
+							},
+						},
+					},
+				},
+				"foobar@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+							},
+						},
+					},
+				},
+				"foobar2@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "192.168.0.13:80",
+								},
+							},
+						},
+					},
+				},
+			},
+			providerName:  "provider-1",
+			expectedError: "percent must be between 0 and 100",
+		},
 	}
 
 	for _, test := range testCases {
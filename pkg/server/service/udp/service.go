@@ -4,31 +4,43 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"math/rand"
 	"net"
+	"slices"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/healthcheck"
 	"github.com/traefik/traefik/v3/pkg/observability/logs"
+	"github.com/traefik/traefik/v3/pkg/server/override"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
 	"github.com/traefik/traefik/v3/pkg/udp"
 )
 
 // Manager handles UDP services creation.
 type Manager struct {
-	configs map[string]*runtime.UDPServiceInfo
-	rand    *rand.Rand // For the initial shuffling of load-balancers.
+	configs        map[string]*runtime.UDPServiceInfo
+	rand           *rand.Rand // For the initial shuffling of load-balancers.
+	healthCheckers map[string]*healthcheck.ServiceUDPHealthChecker
+	overrides      *override.Store
 }
 
 // NewManager creates a new manager.
 func NewManager(conf *runtime.Configuration) *Manager {
 	return &Manager{
-		configs: conf.UDPServices,
-		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		configs:        conf.UDPServices,
+		rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		healthCheckers: make(map[string]*healthcheck.ServiceUDPHealthChecker),
 	}
 }
 
+// SetOverrides sets the store of the server overrides applied when building the load balancers.
+func (m *Manager) SetOverrides(overrides *override.Store) {
+	m.overrides = overrides
+}
+
 // BuildUDP creates the UDP handler for the given service name.
 func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Handler, error) {
 	serviceQualifiedName := provider.GetQualifiedName(rootCtx, serviceName)
@@ -49,7 +61,9 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := udp.NewWRRLoadBalancer()
+		loadBalancer := udp.NewWRRLoadBalancer(conf.LoadBalancer.HealthCheck != nil)
+
+		uniqHealthCheckTargets := make(map[string]healthcheck.UDPHealthCheckTarget, len(conf.LoadBalancer.Servers))
 
 		for index, server := range shuffle(conf.LoadBalancer.Servers, m.rand) {
 			srvLogger := logger.With().
@@ -61,20 +75,44 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 				continue
 			}
 
+			weight, enabled := m.overrides.Apply(override.ProtocolUDP, serviceQualifiedName, server.Address, nil)
+			if !enabled {
+				srvLogger.Debug().Msg("Skipping server drained or disabled by an override")
+				continue
+			}
+
 			handler, err := udp.NewProxy(server.Address)
 			if err != nil {
 				srvLogger.Error().Err(err).Msg("Failed to create server")
 				continue
 			}
 
-			loadBalancer.AddServer(handler)
+			loadBalancer.Add(server.Address, handler, weight)
+
+			// Servers are considered UP by default.
+			conf.UpdateServerStatus(server.Address, runtime.StatusUp)
+
+			uniqHealthCheckTargets[server.Address] = healthcheck.UDPHealthCheckTarget{
+				Address: server.Address,
+			}
+
 			srvLogger.Debug().Msg("Creating UDP server")
 		}
 
+		if conf.LoadBalancer.HealthCheck != nil {
+			m.healthCheckers[serviceName] = healthcheck.NewServiceUDPHealthChecker(
+				ctx,
+				conf.LoadBalancer.HealthCheck,
+				loadBalancer,
+				conf,
+				slices.Collect(maps.Values(uniqHealthCheckTargets)),
+				serviceQualifiedName)
+		}
+
 		return loadBalancer, nil
 
 	case conf.Weighted != nil:
-		loadBalancer := udp.NewWRRLoadBalancer()
+		loadBalancer := udp.NewWRRLoadBalancer(conf.Weighted.HealthCheck != nil)
 
 		for _, service := range shuffle(conf.Weighted.Services, m.rand) {
 			handler, err := m.BuildUDP(ctx, service.Name)
@@ -83,7 +121,25 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 				return nil, err
 			}
 
-			loadBalancer.AddWeightedServer(handler, service.Weight)
+			loadBalancer.Add(service.Name, handler, service.Weight)
+
+			if conf.Weighted.HealthCheck == nil {
+				continue
+			}
+
+			updater, ok := handler.(healthcheck.StatusUpdater)
+			if !ok {
+				return nil, fmt.Errorf("child service %v of %v not a healthcheck.StatusUpdater (%T)", service.Name, serviceName, handler)
+			}
+
+			if err := updater.RegisterStatusUpdater(func(up bool) {
+				loadBalancer.SetStatus(ctx, service.Name, up)
+			}); err != nil {
+				return nil, fmt.Errorf("cannot register %v as updater for %v: %w", service.Name, serviceName, err)
+			}
+
+			log.Ctx(ctx).Debug().Str("parent", serviceName).Str("child", service.Name).
+				Msg("Child service will update parent on status change")
 		}
 
 		return loadBalancer, nil
@@ -95,6 +151,14 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 	}
 }
 
+// LaunchHealthCheck launches the health checks.
+func (m *Manager) LaunchHealthCheck(ctx context.Context) {
+	for serviceName, hc := range m.healthCheckers {
+		logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
+		go hc.Launch(logger.WithContext(ctx))
+	}
+}
+
 func shuffle[T any](values []T, r *rand.Rand) []T {
 	shuffled := make([]T, len(values))
 	copy(shuffled, values)
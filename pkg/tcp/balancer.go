@@ -0,0 +1,274 @@
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+type balancedServer struct {
+	Handler
+
+	name   string
+	weight int
+	// active is the number of connections currently served by the server.
+	active atomic.Int64
+}
+
+func (s *balancedServer) ServeTCP(conn WriteCloser) {
+	s.active.Add(1)
+	defer s.active.Add(-1)
+
+	s.Handler.ServeTCP(conn)
+}
+
+// Balancer is a load balancer for TCP services,
+// selecting the servers based on their active connections (leastconn, p2c),
+// or on a hash of the client IP or of the TLS server name (hrwclientip, hrwsni).
+type Balancer struct {
+	strategy dynamic.TCPBalancerStrategy
+
+	// serversMu is a mutex to protect the servers slice and the status.
+	serversMu sync.RWMutex
+	servers   []*balancedServer
+	// status is a record of which child services of the Balancer are healthy, keyed
+	// by name of child service. A service is initially added to the map when it is
+	// created via Add, and it is later removed or added to the map as needed,
+	// through the SetStatus method.
+	status map[string]struct{}
+
+	// updaters is the list of hooks that are run (to update the Balancer parent(s)), whenever the Balancer status changes.
+	// No mutex is needed, as it is modified only during the configuration build.
+	updaters         []func(bool)
+	wantsHealthCheck bool
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// NewBalancer creates a new Balancer with the given strategy.
+func NewBalancer(strategy dynamic.TCPBalancerStrategy, wantsHealthCheck bool) (*Balancer, error) {
+	switch strategy {
+	case dynamic.TCPBalancerStrategyLeastConn, dynamic.TCPBalancerStrategyP2C,
+		dynamic.TCPBalancerStrategyHRWClientIP, dynamic.TCPBalancerStrategyHRWSNI:
+	default:
+		return nil, fmt.Errorf("unsupported load-balancer strategy %q", strategy)
+	}
+
+	return &Balancer{
+		strategy:         strategy,
+		status:           make(map[string]struct{}),
+		wantsHealthCheck: wantsHealthCheck,
+		rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// ServeTCP forwards the connection to the server selected by the strategy.
+func (b *Balancer) ServeTCP(conn WriteCloser) {
+	next, err := b.nextServer(conn)
+	if err != nil {
+		if !errors.Is(err, errNoServersInPool) {
+			log.Error().Err(err).Msg("Error during load balancing")
+		}
+		_ = conn.Close()
+		return
+	}
+
+	next.ServeTCP(conn)
+}
+
+// Add appends a server to the existing list with a name and weight.
+func (b *Balancer) Add(name string, handler Handler, weight *int) {
+	w := 1
+	if weight != nil {
+		w = *weight
+	}
+
+	b.serversMu.Lock()
+	b.servers = append(b.servers, &balancedServer{Handler: handler, name: name, weight: w})
+	b.status[name] = struct{}{}
+	b.serversMu.Unlock()
+}
+
+// SetStatus sets status (UP or DOWN) of a target server.
+func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
+	b.serversMu.Lock()
+	defer b.serversMu.Unlock()
+
+	upBefore := len(b.status) > 0
+
+	status := "DOWN"
+	if up {
+		status = "UP"
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
+
+	if up {
+		b.status[childName] = struct{}{}
+	} else {
+		delete(b.status, childName)
+	}
+
+	upAfter := len(b.status) > 0
+	status = "DOWN"
+	if upAfter {
+		status = "UP"
+	}
+
+	// No Status Change
+	if upBefore == upAfter {
+		// We're still with the same status, no need to propagate
+		log.Ctx(ctx).Debug().Msgf("Still %s, no need to propagate", status)
+		return
+	}
+
+	// Status Change
+	log.Ctx(ctx).Debug().Msgf("Propagating new %s status", status)
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the
+// status of the Balancer changes.
+// Not thread safe.
+func (b *Balancer) RegisterStatusUpdater(fn func(up bool)) error {
+	if !b.wantsHealthCheck {
+		return fmt.Errorf("healthCheck not enabled in config for this %s service", b.strategy)
+	}
+
+	b.updaters = append(b.updaters, fn)
+	return nil
+}
+
+func (b *Balancer) nextServer(conn WriteCloser) (*balancedServer, error) {
+	b.serversMu.RLock()
+	var healthy []*balancedServer
+	for _, s := range b.servers {
+		if _, ok := b.status[s.name]; ok && s.weight > 0 {
+			healthy = append(healthy, s)
+		}
+	}
+	b.serversMu.RUnlock()
+
+	if len(healthy) == 0 {
+		return nil, errNoServersInPool
+	}
+
+	switch b.strategy {
+	case dynamic.TCPBalancerStrategyLeastConn:
+		return leastConn(healthy), nil
+
+	case dynamic.TCPBalancerStrategyP2C:
+		if len(healthy) == 1 {
+			return healthy[0], nil
+		}
+
+		b.randMu.Lock()
+		n1, n2 := b.rand.Intn(len(healthy)), b.rand.Intn(len(healthy)-1)
+		b.randMu.Unlock()
+
+		// Makes sure that the two choices are different servers.
+		if n2 >= n1 {
+			n2++
+		}
+
+		return leastConn([]*balancedServer{healthy[n1], healthy[n2]}), nil
+
+	case dynamic.TCPBalancerStrategyHRWSNI:
+		if name := serverName(conn); name != "" {
+			return highestRandomWeight(healthy, name), nil
+		}
+
+		// Connections without server name, e.g. not using TLS, are balanced on the client IP.
+		return highestRandomWeight(healthy, clientIP(conn)), nil
+
+	default:
+		return highestRandomWeight(healthy, clientIP(conn)), nil
+	}
+}
+
+// leastConn returns the server with the lowest number of active connections relative to its weight.
+func leastConn(servers []*balancedServer) *balancedServer {
+	var selected *balancedServer
+	var selectedLoad float64
+	for _, s := range servers {
+		load := float64(s.active.Load()) / float64(s.weight)
+		if selected == nil || load < selectedLoad {
+			selected, selectedLoad = s, load
+		}
+	}
+
+	return selected
+}
+
+// highestRandomWeight returns the server with the highest score for the key,
+// so that a key is consistently balanced on the same server while it is healthy,
+// and only the keys of a server going down are moved to the other servers.
+func highestRandomWeight(servers []*balancedServer, key string) *balancedServer {
+	var selected *balancedServer
+	var selectedScore float64
+	for _, s := range servers {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		// The separator prevents different key and server name pairs from hashing the same concatenation.
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(s.name))
+
+		// The hash is mapped to ]0,1[ on the 53 bits exactly represented by a float64,
+		// as the logarithm of 0 or 1 would give an infinite or a null score.
+		score := (float64(h.Sum64()>>11) + 1) / (1<<53 + 1)
+		score = float64(s.weight) / -math.Log(score)
+
+		if selected == nil || score > selectedScore {
+			selected, selectedScore = s, score
+		}
+	}
+
+	return selected
+}
+
+func clientIP(conn WriteCloser) string {
+	addr := conn.RemoteAddr().String()
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+// serverName returns the TLS server name (SNI) of the connection, if known,
+// unwrapping the connections wrapping it, e.g. by a middleware.
+// The handshake of a TLS connection terminated by Traefik is completed to get it.
+func serverName(conn WriteCloser) string {
+	for {
+		switch c := conn.(type) {
+		case *tls.Conn:
+			if err := c.Handshake(); err != nil {
+				return ""
+			}
+
+			return c.ConnectionState().ServerName
+		case interface{ ServerName() string }:
+			return c.ServerName()
+		case unwrapConn:
+			conn = c.Unwrap()
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package tcp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestNewBalancer(t *testing.T) {
+	_, err := NewBalancer(dynamic.TCPBalancerStrategyLeastConn, false)
+	require.NoError(t, err)
+
+	_, err = NewBalancer("foo", false)
+	require.Error(t, err)
+}
+
+func TestBalancer_leastConn(t *testing.T) {
+	balancer, err := NewBalancer(dynamic.TCPBalancerStrategyLeastConn, false)
+	require.NoError(t, err)
+
+	balancer.Add("first", writerHandler("first"), new(1))
+	balancer.Add("second", writerHandler("second"), new(2))
+
+	balancer.servers[0].active.Store(2)
+	balancer.servers[1].active.Store(3)
+
+	conn := &balancerConn{fakeConn: &fakeConn{writeCall: make(map[string]int)}, remoteAddr: "10.0.0.1:1234"}
+	balancer.ServeTCP(conn)
+	assert.Equal(t, map[string]int{"second": 1}, conn.writeCall)
+
+	// The active connections are released once served.
+	assert.Equal(t, int64(3), balancer.servers[1].active.Load())
+
+	balancer.servers[1].active.Store(5)
+
+	balancer.ServeTCP(conn)
+	assert.Equal(t, map[string]int{"first": 1, "second": 1}, conn.writeCall)
+}
+
+func TestBalancer_p2c(t *testing.T) {
+	balancer, err := NewBalancer(dynamic.TCPBalancerStrategyP2C, false)
+	require.NoError(t, err)
+
+	balancer.Add("first", writerHandler("first"), nil)
+	balancer.Add("second", writerHandler("second"), nil)
+
+	balancer.servers[0].active.Store(1)
+
+	// With two servers, both are always chosen, and the one with fewer connections is selected.
+	conn := &balancerConn{fakeConn: &fakeConn{writeCall: make(map[string]int)}, remoteAddr: "10.0.0.1:1234"}
+	for range 10 {
+		balancer.ServeTCP(conn)
+	}
+
+	assert.Equal(t, map[string]int{"second": 10}, conn.writeCall)
+}
+
+func TestBalancer_hrw(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		strategy dynamic.TCPBalancerStrategy
+		conns    []*balancerConn
+	}{
+		{
+			desc:     "client IP",
+			strategy: dynamic.TCPBalancerStrategyHRWClientIP,
+			conns: []*balancerConn{
+				{remoteAddr: "10.0.0.1:1000"},
+				{remoteAddr: "10.0.0.1:2000"},
+				{remoteAddr: "10.0.0.1:3000"},
+			},
+		},
+		{
+			desc:     "server name",
+			strategy: dynamic.TCPBalancerStrategyHRWSNI,
+			conns: []*balancerConn{
+				{remoteAddr: "10.0.0.1:1000", serverName: "foo.example.com"},
+				{remoteAddr: "10.0.0.2:1000", serverName: "foo.example.com"},
+				{remoteAddr: "10.0.0.3:1000", serverName: "foo.example.com"},
+			},
+		},
+		{
+			desc:     "server name, falling back on the client IP",
+			strategy: dynamic.TCPBalancerStrategyHRWSNI,
+			conns: []*balancerConn{
+				{remoteAddr: "10.0.0.1:1000"},
+				{remoteAddr: "10.0.0.1:2000"},
+				{remoteAddr: "10.0.0.1:3000"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer, err := NewBalancer(test.strategy, false)
+			require.NoError(t, err)
+
+			balancer.Add("first", writerHandler("first"), nil)
+			balancer.Add("second", writerHandler("second"), nil)
+			balancer.Add("third", writerHandler("third"), nil)
+
+			writeCall := make(map[string]int)
+			for _, conn := range test.conns {
+				conn.fakeConn = &fakeConn{writeCall: writeCall}
+				balancer.ServeTCP(conn)
+			}
+
+			// All the connections are balanced on the same server.
+			require.Len(t, writeCall, 1)
+
+			var selected string
+			for name := range writeCall {
+				selected = name
+			}
+
+			// The connections are balanced on another server when the selected one is down.
+			balancer.SetStatus(t.Context(), selected, false)
+
+			writeCall = make(map[string]int)
+			for _, conn := range test.conns {
+				conn.fakeConn = &fakeConn{writeCall: writeCall}
+				balancer.ServeTCP(conn)
+			}
+
+			require.Len(t, writeCall, 1)
+			assert.NotContains(t, writeCall, selected)
+		})
+	}
+}
+
+func TestServerName(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		conn     WriteCloser
+		expected string
+	}{
+		{
+			desc:     "server name",
+			conn:     &balancerConn{serverName: "foo.example.com"},
+			expected: "foo.example.com",
+		},
+		{
+			desc:     "wrapped connection",
+			conn:     &wrappingConn{WriteCloser: &wrappingConn{WriteCloser: &balancerConn{serverName: "foo.example.com"}}},
+			expected: "foo.example.com",
+		},
+		{
+			desc: "no server name",
+			conn: &wrappingConn{WriteCloser: &fakeConn{}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, serverName(test.conn))
+		})
+	}
+}
+
+func TestBalancer_NoServiceUp(t *testing.T) {
+	balancer, err := NewBalancer(dynamic.TCPBalancerStrategyLeastConn, true)
+	require.NoError(t, err)
+
+	var statuses []bool
+	require.NoError(t, balancer.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	}))
+
+	balancer.Add("first", writerHandler("first"), nil)
+	balancer.Add("second", writerHandler("second"), nil)
+
+	balancer.SetStatus(t.Context(), "first", false)
+	balancer.SetStatus(t.Context(), "second", false)
+
+	conn := &balancerConn{fakeConn: &fakeConn{writeCall: make(map[string]int)}, remoteAddr: "10.0.0.1:1234"}
+	balancer.ServeTCP(conn)
+
+	assert.Empty(t, conn.writeCall)
+	assert.Equal(t, 1, conn.closeCall)
+	assert.Equal(t, []bool{false}, statuses)
+}
+
+func writerHandler(name string) Handler {
+	return HandlerFunc(func(conn WriteCloser) {
+		_, _ = conn.Write([]byte(name))
+	})
+}
+
+type balancerConn struct {
+	*fakeConn
+
+	remoteAddr string
+	serverName string
+}
+
+func (c *balancerConn) RemoteAddr() net.Addr {
+	addr, _ := net.ResolveTCPAddr("tcp", c.remoteAddr)
+	return addr
+}
+
+func (c *balancerConn) ServerName() string {
+	return c.serverName
+}
+
+// wrappingConn wraps a connection, as the TCP middlewares do.
+type wrappingConn struct {
+	WriteCloser
+}
+
+func (c *wrappingConn) Unwrap() WriteCloser {
+	return c.WriteCloser
+}
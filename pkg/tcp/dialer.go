@@ -7,10 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pires/go-proxyproto"
+	"github.com/pires/go-proxyproto/tlvparse"
 	"github.com/rs/zerolog/log"
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -31,6 +33,46 @@ type ClientConn interface {
 	RemoteAddr() net.Addr
 }
 
+// PROXY protocol v2 TLV types carrying the identity of the client certificate,
+// sent next to the PP2_TYPE_SSL TLV.
+// They are in the range of the custom types reserved for applications.
+const (
+	// PP2TypeClientCertSubject is the distinguished name of the subject of the client certificate.
+	PP2TypeClientCertSubject proxyproto.PP2Type = 0xE0
+	// PP2TypeClientCertSAN is a Subject Alternative Name of the client certificate, prefixed with its type
+	// (e.g. DNS:example.com, IP:10.0.0.1, email:foo@example.com or URI:https://example.com).
+	// There is one TLV for each SAN.
+	PP2TypeClientCertSAN proxyproto.PP2Type = 0xE1
+	// PP2TypeClientCertSpiffeID is the SPIFFE ID of the client certificate.
+	PP2TypeClientCertSpiffeID proxyproto.PP2Type = 0xE2
+)
+
+// tlsClientConn is implemented by the client connections on which TLS is terminated.
+type tlsClientConn interface {
+	HandshakeContext(ctx context.Context) error
+	ConnectionState() tls.ConnectionState
+}
+
+// unwrapConn is implemented by the client connections wrapping another one, e.g. by a middleware.
+type unwrapConn interface {
+	Unwrap() WriteCloser
+}
+
+// clientTLSConn returns the client connection on which TLS is terminated, if any,
+// unwrapping the connections wrapping it.
+func clientTLSConn(conn ClientConn) (tlsClientConn, bool) {
+	for {
+		switch c := conn.(type) {
+		case tlsClientConn:
+			return c, true
+		case unwrapConn:
+			conn = c.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
 // Dialer is an interface to dial a network connection, with support for PROXY protocol and termination delay.
 type Dialer interface {
 	Dial(network, addr string, clientConn ClientConn) (c net.Conn, err error)
@@ -63,6 +105,20 @@ func (d tcpDialer) DialContext(ctx context.Context, network, addr string, client
 
 	if d.proxyProtocol != nil && clientConn != nil && d.proxyProtocol.Version > 0 && d.proxyProtocol.Version < 3 {
 		header := proxyproto.HeaderProxyFromAddrs(byte(d.proxyProtocol.Version), clientConn.RemoteAddr(), clientConn.LocalAddr())
+
+		if tlsConn, ok := clientTLSConn(clientConn); ok && d.proxyProtocol.Version == 2 && d.proxyProtocol.TLSInfo {
+			tlvs, err := sslTLVs(ctx, tlsConn)
+			if err != nil {
+				_ = conn.Close()
+				return nil, fmt.Errorf("building PROXY Protocol SSL TLVs: %w", err)
+			}
+
+			if err := header.SetTLVs(tlvs); err != nil {
+				_ = conn.Close()
+				return nil, fmt.Errorf("setting PROXY Protocol TLVs: %w", err)
+			}
+		}
+
 		if _, err := header.WriteTo(conn); err != nil {
 			_ = conn.Close()
 			return nil, fmt.Errorf("writing PROXY Protocol header: %w", err)
@@ -72,6 +128,89 @@ func (d tcpDialer) DialContext(ctx context.Context, network, addr string, client
 	return conn, nil
 }
 
+// sslTLVs returns the PP2_TYPE_SSL TLV describing the TLS connection with the client,
+// followed by the TLVs carrying the identity of its certificate, if it has been verified.
+// It completes the TLS handshake with the client if needed.
+func sslTLVs(ctx context.Context, conn tlsClientConn) ([]proxyproto.TLV, error) {
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return nil, fmt.Errorf("TLS handshake with the client: %w", err)
+	}
+
+	state := conn.ConnectionState()
+
+	ssl := tlvparse.PP2SSL{
+		Client: tlvparse.PP2_BITFIELD_CLIENT_SSL,
+		// Zero only if the client presented a certificate which has been verified.
+		Verify: 1,
+		TLV: []proxyproto.TLV{
+			{Type: proxyproto.PP2_SUBTYPE_SSL_VERSION, Value: []byte(strings.Replace(tls.VersionName(state.Version), "TLS ", "TLSv", 1))},
+			{Type: proxyproto.PP2_SUBTYPE_SSL_CIPHER, Value: []byte(tls.CipherSuiteName(state.CipherSuite))},
+		},
+	}
+
+	var certTLVs []proxyproto.TLV
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+
+		ssl.Client |= tlvparse.PP2_BITFIELD_CLIENT_CERT_SESS
+		if !state.DidResume {
+			ssl.Client |= tlvparse.PP2_BITFIELD_CLIENT_CERT_CONN
+		}
+
+		// The identity of the client certificate is only sent once it has been verified,
+		// as the custom TLVs do not carry the verify field and the client could claim any identity otherwise.
+		if len(state.VerifiedChains) > 0 {
+			ssl.Verify = 0
+
+			if cert.Subject.CommonName != "" {
+				ssl.TLV = append(ssl.TLV, proxyproto.TLV{Type: proxyproto.PP2_SUBTYPE_SSL_CN, Value: []byte(cert.Subject.CommonName)})
+			}
+
+			certTLVs = clientCertTLVs(cert)
+		}
+	}
+
+	sslTLV, err := ssl.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]proxyproto.TLV{sslTLV}, certTLVs...), nil
+}
+
+// clientCertTLVs returns the custom TLVs carrying the identity of the client certificate.
+func clientCertTLVs(cert *x509.Certificate) []proxyproto.TLV {
+	var tlvs []proxyproto.TLV
+
+	if subject := cert.Subject.String(); subject != "" {
+		tlvs = append(tlvs, proxyproto.TLV{Type: PP2TypeClientCertSubject, Value: []byte(subject)})
+	}
+
+	var sans []string
+	for _, name := range cert.DNSNames {
+		sans = append(sans, "DNS:"+name)
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, "IP:"+ip.String())
+	}
+	for _, email := range cert.EmailAddresses {
+		sans = append(sans, "email:"+email)
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, "URI:"+uri.String())
+	}
+
+	for _, san := range sans {
+		tlvs = append(tlvs, proxyproto.TLV{Type: PP2TypeClientCertSAN, Value: []byte(san)})
+	}
+
+	if id, err := x509svid.IDFromCert(cert); err == nil {
+		tlvs = append(tlvs, proxyproto.TLV{Type: PP2TypeClientCertSpiffeID, Value: []byte(id.String())})
+	}
+
+	return tlvs
+}
+
 type tcpTLSDialer struct {
 	tcpDialer
 
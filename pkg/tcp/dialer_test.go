@@ -9,6 +9,7 @@ import (
 	"crypto/x509/pkix"
 	"errors"
 	"io"
+	"maps"
 	"math/big"
 	"net"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	"time"
 
 	"github.com/pires/go-proxyproto"
+	"github.com/pires/go-proxyproto/tlvparse"
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
@@ -698,6 +700,220 @@ func TestProxyProtocolWithTLS(t *testing.T) {
 	}
 }
 
+func TestProxyProtocolSSLTLV(t *testing.T) {
+	trustDomain := spiffeid.RequireTrustDomainFromString("spiffe://traefik.test")
+	pki := newFakeSpiffePKI(t, trustDomain)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(pki.bundle.X509Authorities()[0])
+
+	clientKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	clientCertDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber:   big.NewInt(300001),
+		Subject:        pkix.Name{CommonName: "client.example.com", Organization: []string{"Traefik"}},
+		DNSNames:       []string{"client.example.com"},
+		EmailAddresses: []string{"client@example.com"},
+		URIs:           []*url.URL{spiffeid.RequireFromPath(trustDomain, "/client").URL()},
+		NotBefore:      time.Now(),
+		NotAfter:       time.Now().Add(time.Hour),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, pki.bundle.X509Authorities()[0], clientKey.Public(), pki.caPrivateKey)
+	require.NoError(t, err)
+
+	serverCert, err := tls.X509KeyPair(localhostCert, localhostKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc            string
+		tlsInfo         bool
+		clientCert      bool
+		unverified      bool
+		wrap            bool
+		expectedSSLTLV  map[proxyproto.PP2Type][]string
+		expectedCertTLV map[proxyproto.PP2Type][]string
+		verified        bool
+	}{
+		{
+			desc:       "without tlsInfo",
+			clientCert: true,
+		},
+		{
+			desc:    "without client certificate",
+			tlsInfo: true,
+			expectedSSLTLV: map[proxyproto.PP2Type][]string{
+				proxyproto.PP2_SUBTYPE_SSL_VERSION: {"TLSv1.3"},
+			},
+			expectedCertTLV: map[proxyproto.PP2Type][]string{},
+		},
+		{
+			desc:       "with client certificate",
+			tlsInfo:    true,
+			clientCert: true,
+			verified:   true,
+			expectedSSLTLV: map[proxyproto.PP2Type][]string{
+				proxyproto.PP2_SUBTYPE_SSL_VERSION: {"TLSv1.3"},
+				proxyproto.PP2_SUBTYPE_SSL_CN:      {"client.example.com"},
+			},
+			expectedCertTLV: map[proxyproto.PP2Type][]string{
+				PP2TypeClientCertSubject:  {"CN=client.example.com,O=Traefik"},
+				PP2TypeClientCertSAN:      {"DNS:client.example.com", "email:client@example.com", "URI:spiffe://traefik.test/client"},
+				PP2TypeClientCertSpiffeID: {"spiffe://traefik.test/client"},
+			},
+		},
+		{
+			desc:       "with unverified client certificate",
+			tlsInfo:    true,
+			clientCert: true,
+			unverified: true,
+			expectedSSLTLV: map[proxyproto.PP2Type][]string{
+				proxyproto.PP2_SUBTYPE_SSL_VERSION: {"TLSv1.3"},
+			},
+			expectedCertTLV: map[proxyproto.PP2Type][]string{},
+		},
+		{
+			desc:       "with client certificate on a wrapped connection",
+			tlsInfo:    true,
+			clientCert: true,
+			wrap:       true,
+			verified:   true,
+			expectedSSLTLV: map[proxyproto.PP2Type][]string{
+				proxyproto.PP2_SUBTYPE_SSL_VERSION: {"TLSv1.3"},
+				proxyproto.PP2_SUBTYPE_SSL_CN:      {"client.example.com"},
+			},
+			expectedCertTLV: map[proxyproto.PP2Type][]string{
+				PP2TypeClientCertSubject:  {"CN=client.example.com,O=Traefik"},
+				PP2TypeClientCertSAN:      {"DNS:client.example.com", "email:client@example.com", "URI:spiffe://traefik.test/client"},
+				PP2TypeClientCertSpiffeID: {"spiffe://traefik.test/client"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			backendListener, err := net.Listen("tcp", ":0")
+			require.NoError(t, err)
+
+			var ssl tlvparse.PP2SSL
+			var sslFound bool
+			certTLVs := make(map[proxyproto.PP2Type][]string)
+			proxyBackendListener := proxyproto.Listener{
+				Listener: backendListener,
+				ValidateHeader: func(h *proxyproto.Header) error {
+					tlvs, err := h.TLVs()
+					if err != nil {
+						return err
+					}
+
+					ssl, sslFound = tlvparse.FindSSL(tlvs)
+					for _, tlv := range tlvs {
+						if tlv.Type != proxyproto.PP2_TYPE_SSL {
+							certTLVs[tlv.Type] = append(certTLVs[tlv.Type], string(tlv.Value))
+						}
+					}
+					return nil
+				},
+			}
+			defer proxyBackendListener.Close()
+
+			go fakeServer(t, &proxyBackendListener)
+
+			_, port, err := net.SplitHostPort(backendListener.Addr().String())
+			require.NoError(t, err)
+
+			dialerManager := NewDialerManager(nil)
+			dialerManager.Update(map[string]*dynamic.TCPServersTransport{
+				"test": {
+					ProxyProtocol: &dynamic.ProxyProtocol{
+						Version: 2,
+						TLSInfo: test.tlsInfo,
+					},
+				},
+			})
+
+			dialer, err := dialerManager.Build(&dynamic.TCPServersLoadBalancer{ServersTransport: "test"}, false)
+			require.NoError(t, err)
+
+			// The TLS connection with the client, terminated by Traefik.
+			clientListener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer clientListener.Close()
+
+			go func() {
+				clientConfig := &tls.Config{InsecureSkipVerify: true}
+				if test.clientCert {
+					clientConfig.Certificates = []tls.Certificate{{Certificate: [][]byte{clientCertDER}, PrivateKey: clientKey}}
+				}
+
+				conn, err := tls.Dial("tcp", clientListener.Addr().String(), clientConfig)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				_, _ = io.Copy(io.Discard, conn)
+			}()
+
+			rawConn, err := clientListener.Accept()
+			require.NoError(t, err)
+
+			serverConfig := &tls.Config{
+				Certificates: []tls.Certificate{serverCert},
+				ClientAuth:   tls.VerifyClientCertIfGiven,
+				ClientCAs:    clientCAs,
+			}
+			if test.unverified {
+				// The client certificate is accepted without being verified.
+				serverConfig.ClientAuth = tls.RequestClientCert
+			}
+
+			tlsConn := tls.Server(rawConn, serverConfig)
+			defer tlsConn.Close()
+
+			var clientConn ClientConn = tlsConn
+			if test.wrap {
+				clientConn = wrappedConn{WriteCloser: tlsConn}
+			}
+
+			conn, err := dialer.Dial("tcp", ":"+port, clientConn)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("ping"))
+			require.NoError(t, err)
+
+			buf := make([]byte, 64)
+			n, err := conn.Read(buf)
+			require.NoError(t, err)
+			assert.Equal(t, "PONG", string(buf[:n]))
+
+			if !test.tlsInfo {
+				assert.False(t, sslFound)
+				assert.Empty(t, certTLVs)
+				return
+			}
+
+			require.True(t, sslFound)
+			assert.True(t, ssl.ClientSSL())
+			assert.Equal(t, test.clientCert, ssl.ClientCertConn())
+			assert.Equal(t, test.verified, ssl.Verified())
+
+			// The cipher suite depends on the hardware support for AES.
+			expectedSSLTLV := maps.Clone(test.expectedSSLTLV)
+			expectedSSLTLV[proxyproto.PP2_SUBTYPE_SSL_CIPHER] = []string{tls.CipherSuiteName(tlsConn.ConnectionState().CipherSuite)}
+
+			sslTLVs := make(map[proxyproto.PP2Type][]string)
+			for _, tlv := range ssl.TLV {
+				sslTLVs[tlv.Type] = append(sslTLVs[tlv.Type], string(tlv.Value))
+			}
+			assert.Equal(t, expectedSSLTLV, sslTLVs)
+			assert.Equal(t, test.expectedCertTLV, certTLVs)
+		})
+	}
+}
+
 func TestProxyProtocolDisabled(t *testing.T) {
 	backendListener, err := net.Listen("tcp", ":0")
 	require.NoError(t, err)
@@ -825,6 +1041,15 @@ func TestPeerCertSANs(t *testing.T) {
 	}
 }
 
+// wrappedConn is a client connection wrapping another one, as done by some middlewares.
+type wrappedConn struct {
+	WriteCloser
+}
+
+func (w wrappedConn) Unwrap() WriteCloser {
+	return w.WriteCloser
+}
+
 type fakeClientConn struct {
 	remoteAddr *net.TCPAddr
 	localAddr  *net.TCPAddr
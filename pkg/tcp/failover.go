@@ -0,0 +1,129 @@
+package tcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Failover is a TCP handler forwarding the connections to the fallback handler
+// when the main handler status is down.
+type Failover struct {
+	wantsHealthCheck bool
+	handler          Handler
+	fallbackHandler  Handler
+	// updaters is the list of hooks that are run (to update the Failover
+	// parent(s)), whenever the Failover status changes.
+	// No mutex is needed, as it is modified only during the configuration build.
+	updaters []func(bool)
+
+	// statusMu protects the handler and fallback handler statuses.
+	statusMu       sync.RWMutex
+	handlerStatus  bool
+	fallbackStatus bool
+}
+
+// NewFailover creates a new Failover handler.
+func NewFailover(wantsHealthCheck bool) *Failover {
+	return &Failover{wantsHealthCheck: wantsHealthCheck}
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the
+// status of the Failover changes.
+// Not thread safe.
+func (f *Failover) RegisterStatusUpdater(fn func(up bool)) error {
+	if !f.wantsHealthCheck {
+		return errors.New("healthCheck not enabled in config for this failover service")
+	}
+
+	f.updaters = append(f.updaters, fn)
+
+	return nil
+}
+
+// ServeTCP forwards the connection to the main handler, or to the fallback handler when the main one is down.
+func (f *Failover) ServeTCP(conn WriteCloser) {
+	f.statusMu.RLock()
+	handlerStatus, fallbackStatus := f.handlerStatus, f.fallbackStatus
+	f.statusMu.RUnlock()
+
+	switch {
+	case handlerStatus:
+		f.handler.ServeTCP(conn)
+	case fallbackStatus:
+		f.fallbackHandler.ServeTCP(conn)
+	default:
+		_ = conn.Close()
+	}
+}
+
+// SetHandler sets the main handler.
+func (f *Failover) SetHandler(handler Handler) {
+	f.statusMu.Lock()
+	defer f.statusMu.Unlock()
+
+	f.handler = handler
+	f.handlerStatus = true
+}
+
+// SetHandlerStatus sets the main handler status.
+func (f *Failover) SetHandlerStatus(ctx context.Context, up bool) {
+	f.statusMu.Lock()
+	defer f.statusMu.Unlock()
+
+	if up == f.handlerStatus {
+		// We're still with the same status, no need to propagate.
+		log.Ctx(ctx).Debug().Msgf("Still %s, no need to propagate", statusName(up))
+		return
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Propagating new %s status", statusName(up))
+	f.handlerStatus = up
+
+	f.propagateStatus()
+}
+
+// SetFallbackHandler sets the fallback handler.
+func (f *Failover) SetFallbackHandler(handler Handler) {
+	f.statusMu.Lock()
+	defer f.statusMu.Unlock()
+
+	f.fallbackHandler = handler
+	f.fallbackStatus = true
+}
+
+// SetFallbackHandlerStatus sets the fallback handler status.
+func (f *Failover) SetFallbackHandlerStatus(ctx context.Context, up bool) {
+	f.statusMu.Lock()
+	defer f.statusMu.Unlock()
+
+	if up == f.fallbackStatus {
+		// We're still with the same status, no need to propagate.
+		log.Ctx(ctx).Debug().Msgf("Still %s, no need to propagate", statusName(up))
+		return
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Propagating new %s status", statusName(up))
+	f.fallbackStatus = up
+
+	f.propagateStatus()
+}
+
+// propagateStatus reports the Failover status to its parents.
+// The Failover service is DOWN when both the main and fallback handlers are DOWN.
+// It must be called with the status mutex held.
+func (f *Failover) propagateStatus() {
+	for _, fn := range f.updaters {
+		fn(f.handlerStatus || f.fallbackStatus)
+	}
+}
+
+func statusName(up bool) string {
+	if up {
+		return "UP"
+	}
+
+	return "DOWN"
+}
@@ -0,0 +1,50 @@
+package tcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailover(t *testing.T) {
+	failover := NewFailover(true)
+
+	var statuses []bool
+	require.NoError(t, failover.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	}))
+
+	failover.SetHandler(HandlerFunc(func(conn WriteCloser) {
+		_, err := conn.Write([]byte("handler"))
+		require.NoError(t, err)
+	}))
+
+	failover.SetFallbackHandler(HandlerFunc(func(conn WriteCloser) {
+		_, err := conn.Write([]byte("fallback"))
+		require.NoError(t, err)
+	}))
+
+	conn := &fakeConn{writeCall: make(map[string]int)}
+	failover.ServeTCP(conn)
+
+	failover.SetHandlerStatus(t.Context(), false)
+	failover.ServeTCP(conn)
+
+	failover.SetFallbackHandlerStatus(t.Context(), false)
+	failover.ServeTCP(conn)
+
+	failover.SetHandlerStatus(t.Context(), true)
+	failover.ServeTCP(conn)
+
+	assert.Equal(t, map[string]int{"handler": 2, "fallback": 1}, conn.writeCall)
+	assert.Equal(t, 1, conn.closeCall)
+	assert.Equal(t, []bool{true, false, true}, statuses)
+}
+
+func TestFailover_noHealthCheck(t *testing.T) {
+	failover := NewFailover(false)
+
+	err := failover.RegisterStatusUpdater(func(up bool) {})
+	assert.Error(t, err)
+}
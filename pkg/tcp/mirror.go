@@ -0,0 +1,268 @@
+package tcp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// mirrorMaxPendingChunks is the number of chunks of data read from a client which can be waiting
+// to be sent to a mirror. A mirror slower than the client is dropped once it is reached,
+// so that it never slows down the connection to the main handler.
+const mirrorMaxPendingChunks = 64
+
+// Mirroring is a TCP handler forwarding the connections to a handler,
+// and copying the data sent by the clients to mirror handlers, whose responses are discarded.
+type Mirroring struct {
+	handler          Handler
+	mirrorHandlers   []*mirrorHandler
+	wantsHealthCheck bool
+
+	lock  sync.Mutex
+	total uint64
+}
+
+type mirrorHandler struct {
+	Handler
+
+	percent int
+	count   uint64
+}
+
+// NewMirroring creates a new Mirroring handler.
+func NewMirroring(handler Handler, wantsHealthCheck bool) *Mirroring {
+	return &Mirroring{
+		handler:          handler,
+		wantsHealthCheck: wantsHealthCheck,
+	}
+}
+
+// AddMirror adds a handler receiving a copy of the given percentage of the connections.
+func (m *Mirroring) AddMirror(handler Handler, percent int) error {
+	if percent < 0 || percent > 100 {
+		return errors.New("percent must be between 0 and 100")
+	}
+
+	m.mirrorHandlers = append(m.mirrorHandlers, &mirrorHandler{Handler: handler, percent: percent})
+
+	return nil
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the
+// status of the handler of the Mirroring changes.
+// Not thread safe.
+func (m *Mirroring) RegisterStatusUpdater(fn func(up bool)) error {
+	if !m.wantsHealthCheck {
+		return errors.New("healthCheck not enabled in config for this mirroring service")
+	}
+
+	updater, ok := m.handler.(interface{ RegisterStatusUpdater(func(up bool)) error })
+	if !ok {
+		return fmt.Errorf("service of mirroring %T does not support status updates", m.handler)
+	}
+
+	if err := updater.RegisterStatusUpdater(fn); err != nil {
+		return fmt.Errorf("cannot register service of mirroring as updater: %w", err)
+	}
+
+	return nil
+}
+
+// ServeTCP forwards the connection to the handler, and copies the data sent by the client to the active mirrors.
+func (m *Mirroring) ServeTCP(conn WriteCloser) {
+	mirrors := m.getActiveMirrors()
+	if len(mirrors) == 0 {
+		m.handler.ServeTCP(conn)
+		return
+	}
+
+	tee := &teeConn{WriteCloser: conn}
+	for _, handler := range mirrors {
+		mirror := newMirrorConn(conn)
+		tee.mirrors = append(tee.mirrors, mirror)
+
+		go handler.ServeTCP(mirror)
+	}
+
+	m.handler.ServeTCP(tee)
+
+	// The client stream may not have been read until its end, e.g. when the backend closed the connection.
+	tee.endMirrors()
+}
+
+func (m *Mirroring) getActiveMirrors() []Handler {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.total++
+
+	var mirrors []Handler
+	for _, handler := range m.mirrorHandlers {
+		if handler.count*100 < m.total*uint64(handler.percent) {
+			handler.count++
+			mirrors = append(mirrors, handler)
+		}
+	}
+
+	return mirrors
+}
+
+// teeConn is a client connection copying the data read from it to mirror connections.
+type teeConn struct {
+	WriteCloser
+
+	mirrors []*mirrorConn
+}
+
+func (t *teeConn) Read(p []byte) (int, error) {
+	n, err := t.WriteCloser.Read(p)
+	if n > 0 {
+		for _, mirror := range t.mirrors {
+			mirror.send(p[:n])
+		}
+	}
+
+	if err != nil {
+		t.endMirrors()
+	}
+
+	return n, err
+}
+
+// Unwrap returns the client connection.
+func (t *teeConn) Unwrap() WriteCloser {
+	return t.WriteCloser
+}
+
+func (t *teeConn) endMirrors() {
+	for _, mirror := range t.mirrors {
+		mirror.end()
+	}
+}
+
+// mirrorConn is the connection served to a mirror handler.
+// It reads the data sent by the client, and discards the data written to it.
+type mirrorConn struct {
+	localAddr  net.Addr
+	remoteAddr net.Addr
+
+	chunks  chan []byte
+	pending []byte
+
+	// mu protects ended, so that no chunk is sent after the chunks channel is closed.
+	mu    sync.Mutex
+	ended bool
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newMirrorConn(conn net.Conn) *mirrorConn {
+	return &mirrorConn{
+		localAddr:  conn.LocalAddr(),
+		remoteAddr: conn.RemoteAddr(),
+		chunks:     make(chan []byte, mirrorMaxPendingChunks),
+		closed:     make(chan struct{}),
+	}
+}
+
+// send queues a copy of data read from the client.
+func (c *mirrorConn) send(data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ended {
+		return
+	}
+
+	select {
+	case c.chunks <- bytes.Clone(data):
+	default:
+		log.Debug().Str("remoteAddr", c.remoteAddr.String()).Msg("Mirror is too slow, ending the mirrored connection")
+		c.ended = true
+		close(c.chunks)
+	}
+}
+
+// end signals the end of the data sent by the client.
+func (c *mirrorConn) end() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ended {
+		return
+	}
+
+	c.ended = true
+	close(c.chunks)
+}
+
+func (c *mirrorConn) Read(p []byte) (int, error) {
+	if len(c.pending) == 0 {
+		select {
+		case chunk, ok := <-c.chunks:
+			if !ok {
+				return 0, io.EOF
+			}
+			c.pending = chunk
+		case <-c.closed:
+			return 0, net.ErrClosed
+		}
+	}
+
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+
+	return n, nil
+}
+
+// Write discards the data sent by the mirror.
+func (c *mirrorConn) Write(p []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, net.ErrClosed
+	default:
+		return len(p), nil
+	}
+}
+
+func (c *mirrorConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+
+	// Stops queuing the data of the client.
+	c.end()
+
+	return nil
+}
+
+func (c *mirrorConn) CloseWrite() error {
+	return nil
+}
+
+func (c *mirrorConn) LocalAddr() net.Addr {
+	return c.localAddr
+}
+
+func (c *mirrorConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+func (c *mirrorConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *mirrorConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *mirrorConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
@@ -0,0 +1,99 @@
+package tcp
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirroring(t *testing.T) {
+	handler := HandlerFunc(func(conn WriteCloser) {
+		buf := make([]byte, len("PING"))
+		_, err := io.ReadFull(conn, buf)
+		require.NoError(t, err)
+
+		_, err = conn.Write([]byte("PONG"))
+		require.NoError(t, err)
+	})
+
+	mirrored := make(chan string, 1)
+	mirror := HandlerFunc(func(conn WriteCloser) {
+		defer conn.Close()
+
+		data, err := io.ReadAll(conn)
+		require.NoError(t, err)
+
+		// The responses of the mirror are discarded.
+		_, err = conn.Write([]byte("MIRROR"))
+		require.NoError(t, err)
+
+		mirrored <- string(data)
+	})
+
+	mirroring := NewMirroring(handler, false)
+	require.NoError(t, mirroring.AddMirror(mirror, 100))
+
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() { _ = clientConn.Close() })
+
+	go mirroring.ServeTCP(pipeConn{Conn: serverConn})
+
+	_, err := clientConn.Write([]byte("PING"))
+	require.NoError(t, err)
+
+	buf := make([]byte, len("PONG"))
+	_, err = io.ReadFull(clientConn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "PONG", string(buf))
+
+	assert.Equal(t, "PING", <-mirrored)
+}
+
+func TestMirroring_percent(t *testing.T) {
+	mirroring := NewMirroring(HandlerFunc(func(conn WriteCloser) {}), false)
+	require.NoError(t, mirroring.AddMirror(HandlerFunc(func(conn WriteCloser) {}), 50))
+	require.NoError(t, mirroring.AddMirror(HandlerFunc(func(conn WriteCloser) {}), 10))
+
+	var mirrored int
+	for range 10 {
+		mirrored += len(mirroring.getActiveMirrors())
+	}
+
+	assert.Equal(t, 6, mirrored)
+}
+
+func TestMirroring_AddMirror(t *testing.T) {
+	mirroring := NewMirroring(HandlerFunc(func(conn WriteCloser) {}), false)
+
+	assert.Error(t, mirroring.AddMirror(HandlerFunc(func(conn WriteCloser) {}), -1))
+	assert.Error(t, mirroring.AddMirror(HandlerFunc(func(conn WriteCloser) {}), 101))
+}
+
+func TestMirrorConn_slowMirror(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+		_ = serverConn.Close()
+	})
+
+	conn := newMirrorConn(serverConn)
+	for range mirrorMaxPendingChunks + 1 {
+		conn.send([]byte("data"))
+	}
+
+	// The connection is ended once the mirror falls behind, after the queued chunks.
+	data, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Len(t, data, mirrorMaxPendingChunks*len("data"))
+}
+
+type pipeConn struct {
+	net.Conn
+}
+
+func (p pipeConn) CloseWrite() error {
+	return nil
+}
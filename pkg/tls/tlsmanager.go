@@ -52,6 +52,13 @@ type OCSPConfig struct {
 	ResponderOverrides map[string]string `description:"Defines a map of OCSP responders to replace for querying OCSP servers." json:"responderOverrides,omitempty" toml:"responderOverrides,omitempty" yaml:"responderOverrides,omitempty"`
 }
 
+// OnDemandResolver obtains certificates during the TLS handshakes.
+type OnDemandResolver interface {
+	// GetOnDemandCertificate returns the certificate obtained for the server name of the handshake,
+	// or nil if the server name is not allowed, or its certificate could not be obtained in time.
+	GetOnDemandCertificate(clientHello *tls.ClientHelloInfo) *tls.Certificate
+}
+
 // Manager is the TLS option/store/configuration factory.
 type Manager struct {
 	lock         sync.RWMutex
@@ -60,6 +67,8 @@ type Manager struct {
 	configs      map[string]Options
 	certs        []*CertAndStores
 
+	onDemandResolvers []OnDemandResolver
+
 	// As of today, the TLS manager contains and is responsible for creating/starting the OCSP ocspStapler.
 	// It would likely have been a Configuration listener but this implies that certs are re-parsed.
 	// But this would probably have impact on resource consumption.
@@ -88,6 +97,14 @@ func (m *Manager) Run(ctx context.Context) {
 	}
 }
 
+// AddOnDemandResolver adds a resolver of the certificates of the server names matching none of the certificates.
+func (m *Manager) AddOnDemandResolver(resolver OnDemandResolver) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.onDemandResolvers = append(m.onDemandResolvers, resolver)
+}
+
 // UpdateConfigs updates the TLS* configuration options.
 // It initializes the default TLS store, and the TLS store for the ACME challenges.
 func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, configs map[string]Options, certs []*CertAndStores) {
@@ -249,6 +266,8 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 		err = fmt.Errorf("ACME TLS store %s not found", tlsalpn01.ACMETLS1Protocol)
 	}
 
+	onDemandResolvers := m.onDemandResolvers
+
 	tlsConfig.GetCertificate = func(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
 		domainToCheck := types.CanonicalDomain(clientHello.ServerName)
 
@@ -274,6 +293,14 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 			return bestCertificate, nil
 		}
 
+		if store != nil && domainToCheck != "" {
+			for _, resolver := range onDemandResolvers {
+				if certificate := resolver.GetOnDemandCertificate(clientHello); certificate != nil {
+					return certificate, nil
+				}
+			}
+		}
+
 		if sniStrict {
 			log.Debug().Msgf("TLS: strict SNI enabled - No certificate found for domain: %q, closing connection", domainToCheck)
 			// Same comment as above, as in the isACMETLS case.
@@ -185,6 +185,60 @@ func TestManager_Get(t *testing.T) {
 	}
 }
 
+type onDemandResolverMock struct {
+	certificate *tls.Certificate
+	serverNames []string
+}
+
+func (r *onDemandResolverMock) GetOnDemandCertificate(clientHello *tls.ClientHelloInfo) *tls.Certificate {
+	r.serverNames = append(r.serverNames, clientHello.ServerName)
+
+	if clientHello.ServerName != "on-demand.localhost" {
+		return nil
+	}
+
+	return r.certificate
+}
+
+func TestManager_Get_OnDemand(t *testing.T) {
+	dynamicConfigs := []*CertAndStores{{
+		Certificate: Certificate{
+			CertFile: localhostCert,
+			KeyFile:  localhostKey,
+		},
+	}}
+
+	onDemandCert, err := tls.X509KeyPair([]byte(localhostCert), []byte(localhostKey))
+	require.NoError(t, err)
+
+	resolver := &onDemandResolverMock{certificate: &onDemandCert}
+
+	tlsManager := NewManager(nil)
+	tlsManager.AddOnDemandResolver(resolver)
+	tlsManager.UpdateConfigs(t.Context(), nil, map[string]Options{"default": {}}, dynamicConfigs)
+
+	config, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	// The certificate matching the server name is served without calling the resolver.
+	certificate, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	require.NoError(t, err)
+	require.NotNil(t, certificate)
+	assert.Empty(t, resolver.serverNames)
+
+	certificate, err = config.GetCertificate(&tls.ClientHelloInfo{ServerName: "on-demand.localhost"})
+	require.NoError(t, err)
+	assert.Same(t, &onDemandCert, certificate)
+
+	// The default certificate is served when the resolver does not obtain any certificate.
+	certificate, err = config.GetCertificate(&tls.ClientHelloInfo{ServerName: "unknown.localhost"})
+	require.NoError(t, err)
+	require.NotNil(t, certificate)
+	assert.NotSame(t, &onDemandCert, certificate)
+
+	assert.Equal(t, []string{"on-demand.localhost", "unknown.localhost"}, resolver.serverNames)
+}
+
 func TestClientAuth(t *testing.T) {
 	tlsConfigs := map[string]Options{
 		"eca": {
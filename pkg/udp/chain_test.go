@@ -0,0 +1,40 @@
+package udp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain_Then(t *testing.T) {
+	var calls []string
+
+	tagMiddleware := func(tag string) Constructor {
+		return func(next Handler) (Handler, error) {
+			return HandlerFunc(func(conn *Conn) {
+				calls = append(calls, tag)
+				next.ServeUDP(conn)
+			}), nil
+		}
+	}
+
+	app := HandlerFunc(func(conn *Conn) {
+		calls = append(calls, "app")
+	})
+
+	chain := NewChain(tagMiddleware("t1"), tagMiddleware("t2")).
+		Extend(NewChain(tagMiddleware("t3")))
+
+	handler, err := chain.Then(app)
+	require.NoError(t, err)
+
+	handler.ServeUDP(nil)
+
+	assert.Equal(t, []string{"t1", "t2", "t3", "app"}, calls)
+}
+
+func TestChain_Then_nilHandler(t *testing.T) {
+	_, err := NewChain().Then(nil)
+	assert.Error(t, err)
+}
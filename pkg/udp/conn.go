@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"sync"
 	"time"
 )
@@ -212,6 +213,7 @@ type Conn struct {
 	readCh    chan []byte // to receive the buffer into which we should Read
 	sizeCh    chan int    // to synchronize with the end of a Read
 	msgs      [][]byte    // to store data from listener, to be consumed by Reads
+	peeked    [][]byte    // datagrams read by Peek, to be consumed by the next Reads
 
 	muActivity   sync.RWMutex
 	lastActivity time.Time // the last time the session saw either read or write activity
@@ -219,20 +221,82 @@ type Conn struct {
 	timeout  time.Duration // for timeouts
 	doneOnce sync.Once
 	doneCh   chan struct{}
+
+	// filters decide whether the datagrams received from the client are read or dropped.
+	filters []func(p []byte) bool
+}
+
+// RemoteAddr returns the address of the client of the session.
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.rAddr
+}
+
+// AddReadFilter adds a filter on the datagrams received from the client.
+// The datagrams rejected by a filter are dropped instead of being read.
+// It must be called before the first Read, which is the case when called by a middleware.
+func (c *Conn) AddReadFilter(filter func(p []byte) bool) {
+	c.filters = append(c.filters, filter)
 }
 
 // Read reads up to len(p) bytes into p from the connection.
 // Each call corresponds to at most one datagram.
 // If p is smaller than the datagram, the extra bytes will be discarded.
 func (c *Conn) Read(p []byte) (int, error) {
+	for len(c.peeked) > 0 {
+		n := copy(p, c.peeked[0])
+		c.peeked = c.peeked[1:]
+
+		if c.filter(p[:n]) {
+			return n, nil
+		}
+	}
+
+	for {
+		n, err := c.read(p, nil)
+		if err != nil {
+			return 0, err
+		}
+
+		if c.filter(p[:n]) {
+			return n, nil
+		}
+	}
+}
+
+// Peek reads up to len(p) bytes of the next datagram which has not been peeked yet into p, without consuming it:
+// the peeked datagrams are returned again, in full and in order, by the next Reads.
+// The datagrams are not subject to the read filters until they are actually read.
+// It returns os.ErrDeadlineExceeded if no datagram is received before the timeout.
+func (c *Conn) Peek(p []byte, timeout time.Duration) (int, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	buf := make([]byte, maxDatagramSize)
+	n, err := c.read(buf, timer.C)
+	if err != nil {
+		return 0, err
+	}
+
+	c.peeked = append(c.peeked, buf[:n])
+
+	return copy(p, buf[:n]), nil
+}
+
+// read reads the next datagram received from the client into p,
+// or fails with os.ErrDeadlineExceeded when the timeout channel fires first.
+func (c *Conn) read(p []byte, timeout <-chan time.Time) (int, error) {
 	select {
 	case c.readCh <- p:
 		n := <-c.sizeCh
 		c.muActivity.Lock()
 		c.lastActivity = time.Now()
 		c.muActivity.Unlock()
+
 		return n, nil
 
+	case <-timeout:
+		return 0, os.ErrDeadlineExceeded
+
 	case <-c.doneCh:
 		return 0, io.EOF
 	}
@@ -304,6 +368,17 @@ func (c *Conn) readLoop() {
 	}
 }
 
+// filter reports whether the datagram is accepted by all the filters.
+func (c *Conn) filter(p []byte) bool {
+	for _, filter := range c.filters {
+		if !filter(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (c *Conn) close() {
 	c.doneOnce.Do(func() {
 		close(c.doneCh)
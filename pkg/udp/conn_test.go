@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"os"
 	"testing"
 	"time"
 
@@ -332,3 +333,113 @@ func requireEcho(t *testing.T, data string, conn io.ReadWriter, timeout time.Dur
 		t.Fatalf("Timeout during echo for: %s", data)
 	}
 }
+
+func TestReadFilter(t *testing.T) {
+	ln, err := Listen(net.ListenConfig{}, "udp", ":0", 3*time.Second)
+	require.NoError(t, err)
+	defer func() {
+		err := ln.Close()
+		require.NoError(t, err)
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, errClosedListener) {
+				return
+			}
+			require.NoError(t, err)
+
+			conn.AddReadFilter(func(p []byte) bool {
+				return string(p) != "DROP"
+			})
+
+			go func() {
+				b := make([]byte, 2048)
+				for {
+					n, err := conn.Read(b)
+					if err != nil {
+						return
+					}
+
+					_, err = conn.Write(b[:n])
+					require.NoError(t, err)
+				}
+			}()
+		}
+	}()
+
+	udpConn, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+
+	_, err = udpConn.Write([]byte("DROP"))
+	require.NoError(t, err)
+
+	// The dropped datagram is not echoed back.
+	requireEcho(t, "TEST", udpConn, time.Second)
+}
+
+func TestPeek(t *testing.T) {
+	ln, err := Listen(net.ListenConfig{}, "udp", ":0", 3*time.Second)
+	require.NoError(t, err)
+	defer func() {
+		err := ln.Close()
+		require.NoError(t, err)
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, errClosedListener) {
+				return
+			}
+			require.NoError(t, err)
+
+			go func() {
+				// The peeked datagrams are echoed back by the reads.
+				b := make([]byte, 2)
+				for _, expected := range []string{"TE", "TE"} {
+					n, err := conn.Peek(b, time.Second)
+					if err != nil || string(b[:n]) != expected {
+						return
+					}
+				}
+
+				_, err := conn.Peek(b, 10*time.Millisecond)
+				if !errors.Is(err, os.ErrDeadlineExceeded) {
+					return
+				}
+
+				b = make([]byte, 2048)
+				for {
+					n, err := conn.Read(b)
+					if err != nil {
+						return
+					}
+
+					_, err = conn.Write(b[:n])
+					require.NoError(t, err)
+				}
+			}()
+		}
+	}()
+
+	udpConn, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+
+	_, err = udpConn.Write([]byte("TEST1"))
+	require.NoError(t, err)
+	_, err = udpConn.Write([]byte("TEST2"))
+	require.NoError(t, err)
+
+	for _, expected := range []string{"TEST1", "TEST2"} {
+		require.NoError(t, udpConn.SetReadDeadline(time.Now().Add(time.Second)))
+
+		b := make([]byte, 2048)
+		n, err := udpConn.Read(b)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(b[:n]))
+	}
+
+	requireEcho(t, "TEST3", udpConn, time.Second)
+}
@@ -1,41 +1,58 @@
 package udp
 
 import (
+	"context"
 	"errors"
 	"sync"
 
 	"github.com/rs/zerolog/log"
 )
 
+var errNoServersInPool = errors.New("no servers in the pool")
+
 type server struct {
 	Handler
 
+	name   string
 	weight int
 }
 
 // WRRLoadBalancer is a naive RoundRobin load balancer for UDP services.
 type WRRLoadBalancer struct {
-	servers       []server
-	lock          sync.Mutex
-	currentWeight int
-	index         int
+	// lock is a mutex to protect the servers slice and the status.
+	lock    sync.Mutex
+	servers []server
+	// status is a record of which child services of the Balancer are healthy, keyed
+	// by name of child service. A service is initially added to the map when it is
+	// created via Add, and it is later removed or added to the map as needed,
+	// through the SetStatus method.
+	status map[string]struct{}
+
+	// updaters is the list of hooks that are run (to update the Balancer parent(s)), whenever the Balancer status changes.
+	// No mutex is needed, as it is modified only during the configuration build.
+	updaters []func(bool)
+
+	currentWeight    int
+	index            int
+	wantsHealthCheck bool
 }
 
 // NewWRRLoadBalancer creates a new WRRLoadBalancer.
-func NewWRRLoadBalancer() *WRRLoadBalancer {
+func NewWRRLoadBalancer(wantsHealthCheck bool) *WRRLoadBalancer {
 	return &WRRLoadBalancer{
-		index: -1,
+		status:           make(map[string]struct{}),
+		index:            -1,
+		wantsHealthCheck: wantsHealthCheck,
 	}
 }
 
 // ServeUDP forwards the connection to the right service.
 func (b *WRRLoadBalancer) ServeUDP(conn *Conn) {
-	b.lock.Lock()
-	next, err := b.next()
-	b.lock.Unlock()
-
+	next, err := b.nextServer()
 	if err != nil {
-		log.Error().Err(err).Msg("Error during load balancing")
+		if !errors.Is(err, errNoServersInPool) {
+			log.Error().Err(err).Msg("Error during load balancing")
+		}
 		conn.Close()
 		return
 	}
@@ -43,22 +60,68 @@ func (b *WRRLoadBalancer) ServeUDP(conn *Conn) {
 	next.ServeUDP(conn)
 }
 
-// AddServer appends a handler to the existing list.
-func (b *WRRLoadBalancer) AddServer(serverHandler Handler) {
+// Add appends a server to the existing list with a name and weight.
+func (b *WRRLoadBalancer) Add(name string, handler Handler, weight *int) {
 	w := 1
-	b.AddWeightedServer(serverHandler, &w)
+	if weight != nil {
+		w = *weight
+	}
+
+	b.lock.Lock()
+	b.servers = append(b.servers, server{Handler: handler, name: name, weight: w})
+	b.status[name] = struct{}{}
+	b.lock.Unlock()
 }
 
-// AddWeightedServer appends a handler to the existing list with a weight.
-func (b *WRRLoadBalancer) AddWeightedServer(serverHandler Handler, weight *int) {
+// SetStatus sets status (UP or DOWN) of a target server.
+func (b *WRRLoadBalancer) SetStatus(ctx context.Context, childName string, up bool) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
-	w := 1
-	if weight != nil {
-		w = *weight
+	upBefore := len(b.status) > 0
+
+	status := "DOWN"
+	if up {
+		status = "UP"
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Setting status of %s to %v", childName, status)
+
+	if up {
+		b.status[childName] = struct{}{}
+	} else {
+		delete(b.status, childName)
+	}
+
+	upAfter := len(b.status) > 0
+	status = "DOWN"
+	if upAfter {
+		status = "UP"
+	}
+
+	// No Status Change
+	if upBefore == upAfter {
+		// We're still with the same status, no need to propagate
+		log.Ctx(ctx).Debug().Msgf("Still %s, no need to propagate", status)
+		return
+	}
+
+	// Status Change
+	log.Ctx(ctx).Debug().Msgf("Propagating new %s status", status)
+	for _, fn := range b.updaters {
+		fn(upAfter)
 	}
-	b.servers = append(b.servers, server{Handler: serverHandler, weight: w})
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the
+// status of the Balancer changes.
+func (b *WRRLoadBalancer) RegisterStatusUpdater(fn func(up bool)) error {
+	if !b.wantsHealthCheck {
+		return errors.New("healthCheck not enabled in config for this weighted service")
+	}
+
+	b.updaters = append(b.updaters, fn)
+	return nil
 }
 
 func (b *WRRLoadBalancer) maxWeight() int {
@@ -90,9 +153,12 @@ func gcd(a, b int) int {
 	return a
 }
 
-func (b *WRRLoadBalancer) next() (Handler, error) {
-	if len(b.servers) == 0 {
-		return nil, errors.New("no servers in the pool")
+func (b *WRRLoadBalancer) nextServer() (Handler, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.servers) == 0 || len(b.status) == 0 {
+		return nil, errNoServersInPool
 	}
 
 	// The algorithm below may look messy,
@@ -117,7 +183,8 @@ func (b *WRRLoadBalancer) next() (Handler, error) {
 			}
 		}
 		srv := b.servers[b.index]
-		if srv.weight >= b.currentWeight {
+
+		if _, ok := b.status[srv.name]; ok && srv.weight >= b.currentWeight {
 			return srv, nil
 		}
 	}
@@ -0,0 +1,132 @@
+package udp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWRRLoadBalancer_LoadBalancing(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		serversWeight map[string]int
+		totalCall     int
+		expectedCall  map[string]int
+	}{
+		{
+			desc: "RoundRobin",
+			serversWeight: map[string]int{
+				"h1": 1,
+				"h2": 1,
+			},
+			totalCall: 4,
+			expectedCall: map[string]int{
+				"h1": 2,
+				"h2": 2,
+			},
+		},
+		{
+			desc: "WeighedRoundRobin",
+			serversWeight: map[string]int{
+				"h1": 3,
+				"h2": 1,
+			},
+			totalCall: 16,
+			expectedCall: map[string]int{
+				"h1": 12,
+				"h2": 4,
+			},
+		},
+		{
+			desc: "WeighedRoundRobin with one 0 weight server",
+			serversWeight: map[string]int{
+				"h1": 3,
+				"h2": 0,
+			},
+			totalCall: 16,
+			expectedCall: map[string]int{
+				"h1": 16,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			calls := make(map[string]int)
+
+			balancer := NewWRRLoadBalancer(false)
+			for server, weight := range test.serversWeight {
+				balancer.Add(server, countingHandler(calls, server), &weight)
+			}
+
+			for range test.totalCall {
+				balancer.ServeUDP(&Conn{})
+			}
+
+			assert.Equal(t, test.expectedCall, calls)
+		})
+	}
+}
+
+func TestWRRLoadBalancer_DownThenUp(t *testing.T) {
+	calls := make(map[string]int)
+
+	balancer := NewWRRLoadBalancer(false)
+	balancer.Add("first", countingHandler(calls, "first"), new(1))
+	balancer.Add("second", countingHandler(calls, "second"), new(1))
+
+	balancer.SetStatus(t.Context(), "second", false)
+
+	for range 3 {
+		balancer.ServeUDP(&Conn{})
+	}
+	assert.Equal(t, map[string]int{"first": 3}, calls)
+
+	balancer.SetStatus(t.Context(), "second", true)
+
+	clear(calls)
+	for range 2 {
+		balancer.ServeUDP(&Conn{})
+	}
+	assert.Equal(t, map[string]int{"first": 1, "second": 1}, calls)
+}
+
+func TestWRRLoadBalancer_Propagate(t *testing.T) {
+	calls := make(map[string]int)
+
+	balancer1 := NewWRRLoadBalancer(true)
+	balancer1.Add("first", countingHandler(calls, "first"), new(1))
+	balancer1.Add("second", countingHandler(calls, "second"), new(1))
+
+	balancer2 := NewWRRLoadBalancer(true)
+	balancer2.Add("third", countingHandler(calls, "third"), new(1))
+
+	topBalancer := NewWRRLoadBalancer(true)
+	topBalancer.Add("balancer1", balancer1, new(1))
+	topBalancer.Add("balancer2", balancer2, new(1))
+
+	require.NoError(t, balancer1.RegisterStatusUpdater(func(up bool) {
+		topBalancer.SetStatus(t.Context(), "balancer1", up)
+	}))
+	require.NoError(t, balancer2.RegisterStatusUpdater(func(up bool) {
+		topBalancer.SetStatus(t.Context(), "balancer2", up)
+	}))
+
+	balancer2.SetStatus(t.Context(), "third", false)
+
+	for range 4 {
+		topBalancer.ServeUDP(&Conn{})
+	}
+	assert.Equal(t, map[string]int{"first": 2, "second": 2}, calls)
+
+	assert.Error(t, NewWRRLoadBalancer(false).RegisterStatusUpdater(func(bool) {}))
+}
+
+func countingHandler(calls map[string]int, name string) Handler {
+	return HandlerFunc(func(*Conn) {
+		calls[name]++
+	})
+}